@@ -20,6 +20,24 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// pipelines are the agents of the named pipelines of the config, run
+	// alongside the top-level plugins.
+	pipelines []*Agent
+
+	// running is set while Run is active and guarded by mu, the plugins
+	// may only be changed while holding the lock.
+	mu      sync.Mutex
+	running *runState
+}
+
+// runState holds the units of the running agent.
+type runState struct {
+	ctx     context.Context
+	inputs  *inputUnit
+	outputs *outputUnit
+	outputC chan<- telegraf.Metric
+	swap    chan *pipelineUnit
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -27,9 +45,40 @@ func NewAgent(config *config.Config) (*Agent, error) {
 	a := &Agent{
 		Config: config,
 	}
+	for _, pipeline := range config.Pipelines {
+		pa, err := NewAgent(pipeline)
+		if err != nil {
+			return nil, err
+		}
+		a.pipelines = append(a.pipelines, pa)
+	}
 	return a, nil
 }
 
+// agents returns the agents of the top-level plugins and of the named
+// pipelines.  The top-level agent is left out when all inputs and outputs are
+// in pipelines.
+func (a *Agent) agents() []*Agent {
+	if len(a.pipelines) == 0 {
+		return []*Agent{a}
+	}
+
+	var agents []*Agent
+	if len(a.Config.Inputs) != 0 || len(a.Config.Outputs) != 0 {
+		agents = append(agents, a)
+	}
+	return append(agents, a.pipelines...)
+}
+
+// pluginName returns the name of a plugin of the agent qualified by the name
+// of its pipeline.
+func (a *Agent) pluginName(name string) string {
+	if a.Config.Name == "" {
+		return name
+	}
+	return a.Config.Name + "/" + name
+}
+
 // inputUnit is a group of input plugins and the shared channel they write to.
 //
 // ┌───────┐
@@ -44,6 +93,24 @@ func NewAgent(config *config.Config) (*Agent, error) {
 type inputUnit struct {
 	dst    chan<- telegraf.Metric
 	inputs []*models.RunningInput
+
+	// Gather loops started by runInputs.
+	mu        sync.Mutex
+	ctx       context.Context
+	startTime time.Time
+	loops     map[*models.RunningInput]*loopTask
+}
+
+// loopTask is a goroutine running the gather loop of an input or the flush
+// loop of an output.
+type loopTask struct {
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+
+	// trigger requests an immediate gather or flush, the result is sent on
+	// the channel passed.
+	trigger chan chan error
 }
 
 //  ______     ┌───────────┐     ______
@@ -75,6 +142,10 @@ type aggregatorUnit struct {
 	aggC        chan<- telegraf.Metric
 	outputC     chan<- telegraf.Metric
 	aggregators []*models.RunningAggregator
+
+	// replay makes the aggregation periods follow the timestamps of the
+	// metrics instead of the clock.
+	replay bool
 }
 
 // outputUnit is a group of Outputs and their source channel.  Metrics on the
@@ -92,14 +163,77 @@ type aggregatorUnit struct {
 type outputUnit struct {
 	src     <-chan telegraf.Metric
 	outputs []*models.RunningOutput
+
+	// Flush loops started by runOutputs.
+	mu    sync.Mutex
+	ctx   context.Context
+	loops map[*models.RunningOutput]*loopTask
+	wg    sync.WaitGroup
 }
 
-// Run starts and runs the Agent until the context is done.
+// pipelineUnit is the chain of processors and aggregators between the inputs
+// and the outputs.  It is replaced as a whole on reload when any of its
+// plugins changed, the old chain finishes processing its metrics in the
+// background.
+//
+//  ______     ┌────────────┐     ┌─────────────┐     ______
+// ()_____)──▶ │ Processors │──▶ │ Aggregators │──▶ ()_____)
+//             └────────────┘     └─────────────┘
+type pipelineUnit struct {
+	src chan<- telegraf.Metric
+	out <-chan telegraf.Metric
+	apu []*processorUnit
+	au  *aggregatorUnit
+	pu  []*processorUnit
+}
+
+// Run starts and runs the Agent until the context is done.  The named
+// pipelines run concurrently, when one of them fails to start all are stopped.
 func (a *Agent) Run(ctx context.Context) error {
-	log.Printf("I! [agent] Config: Interval:%s, Quiet:%#v, Hostname:%#v, "+
-		"Flush Interval:%s",
-		a.Config.Agent.Interval.Duration, a.Config.Agent.Quiet,
-		a.Config.Agent.Hostname, a.Config.Agent.FlushInterval.Duration)
+	a.Config.RegisterSecretStores()
+	if len(a.pipelines) == 0 {
+		return a.run(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	agents := a.agents()
+	errs := make(chan error, len(agents))
+	for _, ag := range agents {
+		go func(ag *Agent) {
+			err := ag.run(ctx)
+			if err != nil {
+				if ag.Config.Name != "" {
+					err = fmt.Errorf("pipeline %s: %w", ag.Config.Name, err)
+				}
+				cancel()
+			}
+			errs <- err
+		}(ag)
+	}
+
+	var err error
+	for range agents {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// run starts and runs the plugins of the agent until the context is done.
+func (a *Agent) run(ctx context.Context) error {
+	if a.Config.Name != "" {
+		log.Printf("I! [agent] Pipeline %s: Interval:%s, Flush Interval:%s",
+			a.Config.Name, a.Config.Agent.Interval.Duration,
+			a.Config.Agent.FlushInterval.Duration)
+	} else {
+		log.Printf("I! [agent] Config: Interval:%s, Quiet:%#v, Hostname:%#v, "+
+			"Flush Interval:%s",
+			a.Config.Agent.Interval.Duration, a.Config.Agent.Quiet,
+			a.Config.Agent.Hostname, a.Config.Agent.FlushInterval.Duration)
+	}
 
 	log.Printf("D! [agent] Initializing plugins")
 	err := a.initPlugins()
@@ -115,36 +249,44 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		aggC := next
-		if len(a.Config.AggProcessors) != 0 {
-			aggC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au, err = a.startAggregators(aggC, next, a.Config.Aggregators)
-		if err != nil {
-			return err
-		}
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
-		if err != nil {
-			return err
-		}
+	pipeline, err := a.startPipeline(a.Config.Processors, a.Config.AggProcessors, a.Config.Aggregators)
+	if err != nil {
+		return err
 	}
 
-	iu, err := a.startInputs(next, a.Config.Inputs)
+	inputC := make(chan telegraf.Metric, 100)
+	iu, err := a.startInputs(inputC, a.Config.Inputs)
 	if err != nil {
 		return err
 	}
 
+	// Shutdown is only started while holding the lock, so that it does not
+	// interfere with a reload in progress.
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a.mu.Lock()
+	a.running = &runState{
+		ctx:     ctx,
+		inputs:  iu,
+		outputs: ou,
+		outputC: next,
+		swap:    make(chan *pipelineUnit),
+	}
+	state := a.running
+	a.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-runCtx.Done():
+		}
+		a.mu.Lock()
+		a.running = nil
+		a.mu.Unlock()
+		cancel()
+	}()
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -155,41 +297,19 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}()
 
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			err := a.runProcessors(apu)
-			if err != nil {
-				log.Printf("E! [agent] Error running processors: %v", err)
-			}
-		}()
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			err := a.runAggregators(startTime, au)
-			if err != nil {
-				log.Printf("E! [agent] Error running aggregators: %v", err)
-			}
-		}()
-	}
-
-	if pu != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			err := a.runProcessors(pu)
-			if err != nil {
-				log.Printf("E! [agent] Error running processors: %v", err)
-			}
-		}()
-	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := a.runPipelines(startTime, inputC, pipeline, state)
+		if err != nil {
+			log.Printf("E! [agent] Error running processors: %v", err)
+		}
+	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		err := a.runInputs(ctx, startTime, iu)
+		err := a.runInputs(runCtx, startTime, iu)
 		if err != nil {
 			log.Printf("E! [agent] Error running inputs: %v", err)
 		}
@@ -203,6 +323,39 @@ func (a *Agent) Run(ctx context.Context) error {
 
 // initPlugins runs the Init function on plugins.
 func (a *Agent) initPlugins() error {
+	if err := a.initPipelinePlugins(); err != nil {
+		return err
+	}
+	for _, output := range a.Config.Outputs {
+		err := output.Init()
+		if err != nil {
+			return fmt.Errorf("could not initialize output %s: %v",
+				output.Config.Name, err)
+		}
+	}
+	return a.initRouter()
+}
+
+// initRouter initializes the router and checks that outputs subscribe to
+// routes only when a router is configured.
+func (a *Agent) initRouter() error {
+	if a.Config.Router == nil {
+		for _, output := range a.Config.Outputs {
+			if len(output.Config.Routes) > 0 {
+				return fmt.Errorf("output %s subscribes to routes but no router is configured",
+					output.LogName())
+			}
+		}
+		return nil
+	}
+	if err := a.Config.Router.Init(); err != nil {
+		return fmt.Errorf("could not initialize router: %v", err)
+	}
+	return nil
+}
+
+// initPipelinePlugins initializes all plugins but the outputs.
+func (a *Agent) initPipelinePlugins() error {
 	for _, input := range a.Config.Inputs {
 		err := input.Init()
 		if err != nil {
@@ -231,13 +384,6 @@ func (a *Agent) initPlugins() error {
 				processor.Config.Name, err)
 		}
 	}
-	for _, output := range a.Config.Outputs {
-		err := output.Init()
-		if err != nil {
-			return fmt.Errorf("could not initialize output %s: %v",
-				output.Config.Name, err)
-		}
-	}
 	return nil
 }
 
@@ -252,27 +398,10 @@ func (a *Agent) startInputs(
 	}
 
 	for _, input := range inputs {
-		if si, ok := input.Input.(telegraf.ServiceInput); ok {
-			// Service input plugins are not normally subject to timestamp
-			// rounding except for when precision is set on the input plugin.
-			//
-			// This only applies to the accumulator passed to Start(), the
-			// Gather() accumulator does apply rounding according to the
-			// precision and interval agent/plugin settings.
-			var interval time.Duration
-			var precision time.Duration
-			if input.Config.Precision != 0 {
-				precision = input.Config.Precision
-			}
-
-			acc := NewAccumulator(input, dst)
-			acc.SetPrecision(getPrecision(precision, interval))
-
-			err := si.Start(acc)
-			if err != nil {
-				stopServiceInputs(unit.inputs)
-				return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
-			}
+		err := startServiceInput(dst, input)
+		if err != nil {
+			stopServiceInputs(unit.inputs)
+			return nil, err
 		}
 		unit.inputs = append(unit.inputs, input)
 	}
@@ -280,6 +409,35 @@ func (a *Agent) startInputs(
 	return unit, nil
 }
 
+// startServiceInput calls Start if the input is a service input.
+func startServiceInput(dst chan<- telegraf.Metric, input *models.RunningInput) error {
+	si, ok := input.Input.(telegraf.ServiceInput)
+	if !ok {
+		return nil
+	}
+
+	// Service input plugins are not normally subject to timestamp
+	// rounding except for when precision is set on the input plugin.
+	//
+	// This only applies to the accumulator passed to Start(), the
+	// Gather() accumulator does apply rounding according to the
+	// precision and interval agent/plugin settings.
+	var interval time.Duration
+	var precision time.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	acc := NewAccumulator(input, dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	err := si.Start(acc)
+	if err != nil {
+		return fmt.Errorf("starting input %s: %w", input.LogName(), err)
+	}
+	return nil
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -289,48 +447,25 @@ func (a *Agent) runInputs(
 	startTime time.Time,
 	unit *inputUnit,
 ) error {
-	var wg sync.WaitGroup
+	unit.mu.Lock()
+	unit.ctx = ctx
+	unit.startTime = startTime
+	unit.loops = make(map[*models.RunningInput]*loopTask)
 	for _, input := range unit.inputs {
-		// Overwrite agent interval if this plugin has its own.
-		interval := a.Config.Agent.Interval.Duration
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
-
-		// Overwrite agent precision if this plugin has its own.
-		precision := a.Config.Agent.Precision.Duration
-		if input.Config.Precision != 0 {
-			precision = input.Config.Precision
-		}
-
-		// Overwrite agent collection_jitter if this plugin has its own.
-		jitter := a.Config.Agent.CollectionJitter.Duration
-		if input.Config.CollectionJitter != 0 {
-			jitter = input.Config.CollectionJitter
-		}
-
-		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
-			ticker = NewAlignedTicker(startTime, interval, jitter)
-		} else {
-			ticker = NewUnalignedTicker(interval, jitter)
-		}
-		defer ticker.Stop()
+		a.startGatherLoop(unit, input)
+	}
+	unit.mu.Unlock()
 
-		acc := NewAccumulator(input, unit.dst)
-		acc.SetPrecision(getPrecision(precision, interval))
+	<-ctx.Done()
 
-		wg.Add(1)
-		go func(input *models.RunningInput) {
-			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+	unit.mu.Lock()
+	for _, loop := range unit.loops {
+		<-loop.done
 	}
 
-	wg.Wait()
-
 	log.Printf("D! [agent] Stopping service inputs")
 	stopServiceInputs(unit.inputs)
+	unit.mu.Unlock()
 
 	close(unit.dst)
 	log.Printf("D! [agent] Input channel closed")
@@ -338,6 +473,58 @@ func (a *Agent) runInputs(
 	return nil
 }
 
+// startGatherLoop starts the periodic gather of an input.  Must be called
+// with the unit locked.
+func (a *Agent) startGatherLoop(unit *inputUnit, input *models.RunningInput) {
+	// Overwrite agent interval if this plugin has its own.
+	interval := a.Config.Agent.Interval.Duration
+	if input.Config.Interval != 0 {
+		interval = input.Config.Interval
+	}
+
+	// Overwrite agent precision if this plugin has its own.
+	precision := a.Config.Agent.Precision.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	// Overwrite agent collection_jitter if this plugin has its own.
+	jitter := a.Config.Agent.CollectionJitter.Duration
+	if input.Config.CollectionJitter != 0 {
+		jitter = input.Config.CollectionJitter
+	}
+
+	var ticker Ticker
+	switch {
+	case input.Config.Schedule != nil:
+		ticker = NewScheduleTicker(input.Config.Schedule, jitter)
+	case a.Config.Agent.RoundInterval:
+		ticker = NewAlignedTicker(unit.startTime, interval, jitter)
+	default:
+		ticker = NewUnalignedTicker(interval, jitter)
+	}
+	if input.Config.ActiveWindow != nil {
+		ticker = NewWindowTicker(ticker, input.Config.ActiveWindow)
+	}
+
+	acc := NewAccumulator(input, unit.dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	loop := &loopTask{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		trigger: make(chan chan error),
+	}
+	unit.loops[input] = loop
+
+	go func() {
+		defer close(loop.done)
+		defer ticker.Stop()
+		a.gatherLoop(ctx, loop.trigger, acc, input, ticker, interval)
+	}()
+}
+
 // testStartInputs is a variation of startInputs for use in --test and --once
 // mode.  It differs by logging Start errors and returning only plugins
 // successfully started.
@@ -448,9 +635,10 @@ func stopServiceInputs(inputs []*models.RunningInput) {
 }
 
 // gather runs an input's gather function periodically until the context is
-// done.
+// done.  Requests on trigger run an additional gather.
 func (a *Agent) gatherLoop(
 	ctx context.Context,
+	trigger <-chan chan error,
 	acc telegraf.Accumulator,
 	input *models.RunningInput,
 	ticker Ticker,
@@ -465,6 +653,12 @@ func (a *Agent) gatherLoop(
 			if err != nil {
 				acc.AddError(err)
 			}
+		case result := <-trigger:
+			err := a.gatherOnce(acc, input, ticker, interval)
+			if err != nil {
+				acc.AddError(err)
+			}
+			result <- err
 		case <-ctx.Done():
 			return
 		}
@@ -597,7 +791,7 @@ func (a *Agent) runAggregators(
 
 	// Before calling Add, initialize the aggregation window.  This ensures
 	// that any metric created after start time will be aggregated.
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
 		agg.UpdateWindow(since, until)
 	}
@@ -608,7 +802,7 @@ func (a *Agent) runAggregators(
 		defer wg.Done()
 		for metric := range unit.src {
 			var dropOriginal bool
-			for _, agg := range a.Config.Aggregators {
+			for _, agg := range unit.aggregators {
 				if ok := agg.Add(metric); ok {
 					dropOriginal = true
 				}
@@ -623,7 +817,7 @@ func (a *Agent) runAggregators(
 		cancel()
 	}()
 
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		wg.Add(1)
 		go func(agg *models.RunningAggregator) {
 			defer wg.Done()
@@ -648,6 +842,140 @@ func (a *Agent) runAggregators(
 	return nil
 }
 
+// startPipeline sets up the processor and aggregator chain between the inputs
+// and the outputs.
+func (a *Agent) startPipeline(
+	processors models.RunningProcessors,
+	aggProcessors models.RunningProcessors,
+	aggregators []*models.RunningAggregator,
+) (*pipelineUnit, error) {
+	out := make(chan telegraf.Metric, 100)
+	unit := &pipelineUnit{out: out}
+
+	var err error
+	next := (chan<- telegraf.Metric)(out)
+	if len(aggregators) != 0 {
+		aggC := next
+		if len(aggProcessors) != 0 {
+			aggC, unit.apu, err = a.startProcessors(next, aggProcessors)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		next, unit.au, err = a.startAggregators(aggC, next, aggregators)
+		if err != nil {
+			stopProcessors(unit.apu)
+			return nil, err
+		}
+	}
+
+	if len(processors) != 0 {
+		next, unit.pu, err = a.startProcessors(next, processors)
+		if err != nil {
+			stopProcessors(unit.apu)
+			return nil, err
+		}
+	}
+
+	unit.src = next
+	return unit, nil
+}
+
+// stopProcessors stops processors that were started but never run.
+func stopProcessors(units []*processorUnit) {
+	for _, u := range units {
+		u.processor.Stop()
+	}
+}
+
+// runPipelines passes the metrics from the inputs to the current pipeline
+// until the source channel is closed.  A pipeline received on the swap
+// channel replaces the current one, which is closed and left to finish.
+func (a *Agent) runPipelines(
+	startTime time.Time,
+	src <-chan telegraf.Metric,
+	unit *pipelineUnit,
+	state *runState,
+) error {
+	var wg sync.WaitGroup
+	run := func(unit *pipelineUnit, startTime time.Time) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runPipeline(startTime, unit, state.outputC)
+		}()
+	}
+
+	run(unit, startTime)
+	for {
+		select {
+		case metric, ok := <-src:
+			if !ok {
+				close(unit.src)
+				wg.Wait()
+				close(state.outputC)
+				return nil
+			}
+			unit.src <- metric
+		case next := <-state.swap:
+			close(unit.src)
+			unit = next
+			run(unit, time.Now())
+		}
+	}
+}
+
+// runPipeline runs the processors and aggregators of the pipeline and copies
+// the resulting metrics to dst, until the source channel of the pipeline is
+// closed and all metrics have been written.
+func (a *Agent) runPipeline(
+	startTime time.Time,
+	unit *pipelineUnit,
+	dst chan<- telegraf.Metric,
+) {
+	var wg sync.WaitGroup
+	if unit.au != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := a.runProcessors(unit.apu)
+			if err != nil {
+				log.Printf("E! [agent] Error running processors: %v", err)
+			}
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run := a.runAggregators
+			if unit.au.replay {
+				run = a.replayAggregators
+			}
+			err := run(startTime, unit.au)
+			if err != nil {
+				log.Printf("E! [agent] Error running aggregators: %v", err)
+			}
+		}()
+	}
+
+	if unit.pu != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := a.runProcessors(unit.pu)
+			if err != nil {
+				log.Printf("E! [agent] Error running processors: %v", err)
+			}
+		}()
+	}
+
+	for metric := range unit.out {
+		dst <- metric
+	}
+	wg.Wait()
+}
+
 func updateWindow(start time.Time, roundInterval bool, period time.Duration) (time.Time, time.Time) {
 	var until time.Time
 	if roundInterval {
@@ -739,59 +1067,85 @@ func (a *Agent) connectOutput(ctx context.Context, output *models.RunningOutput)
 func (a *Agent) runOutputs(
 	unit *outputUnit,
 ) error {
-	var wg sync.WaitGroup
-
-	// Start flush loop
-	interval := a.Config.Agent.FlushInterval.Duration
-	jitter := a.Config.Agent.FlushJitter.Duration
-
 	ctx, cancel := context.WithCancel(context.Background())
 
+	unit.mu.Lock()
+	unit.ctx = ctx
+	unit.loops = make(map[*models.RunningOutput]*loopTask)
 	for _, output := range unit.outputs {
-		interval := interval
-		// Overwrite agent flush_interval if this plugin has its own.
-		if output.Config.FlushInterval != 0 {
-			interval = output.Config.FlushInterval
-		}
-
-		jitter := jitter
-		// Overwrite agent flush_jitter if this plugin has its own.
-		if output.Config.FlushJitter != 0 {
-			jitter = output.Config.FlushJitter
-		}
-
-		wg.Add(1)
-		go func(output *models.RunningOutput) {
-			defer wg.Done()
-
-			ticker := NewRollingTicker(interval, jitter)
-			defer ticker.Stop()
-
-			a.flushLoop(ctx, output, ticker)
-		}(output)
+		a.startFlushLoop(unit, output)
 	}
+	unit.mu.Unlock()
 
 	for metric := range unit.src {
-		for i, output := range unit.outputs {
-			if i == len(a.Config.Outputs)-1 {
+		unit.mu.Lock()
+		outputs := unit.outputs
+		if a.Config.Router != nil {
+			outputs = a.Config.Router.Outputs(metric, outputs)
+		}
+		if len(outputs) == 0 {
+			metric.Drop()
+		}
+		for i, output := range outputs {
+			if i == len(outputs)-1 {
 				output.AddMetric(metric)
 			} else {
 				output.AddMetric(metric.Copy())
 			}
 		}
+		unit.mu.Unlock()
 	}
 
 	log.Println("I! [agent] Hang on, flushing any cached metrics before shutdown")
 	cancel()
-	wg.Wait()
+	unit.wg.Wait()
 
 	return nil
 }
 
+// startFlushLoop starts the periodic flush of an output.  Must be called with
+// the unit locked.
+func (a *Agent) startFlushLoop(unit *outputUnit, output *models.RunningOutput) {
+	// Overwrite agent flush_interval if this plugin has its own.
+	interval := a.Config.Agent.FlushInterval.Duration
+	if output.Config.FlushInterval != 0 {
+		interval = output.Config.FlushInterval
+	}
+
+	// Overwrite agent flush_jitter if this plugin has its own.
+	jitter := a.Config.Agent.FlushJitter.Duration
+	if output.Config.FlushJitter != 0 {
+		jitter = output.Config.FlushJitter
+	}
+
+	ctx, cancel := context.WithCancel(unit.ctx)
+	loop := &loopTask{
+		cancel:  cancel,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		trigger: make(chan chan error),
+	}
+	unit.loops[output] = loop
+
+	unit.wg.Add(1)
+	go func() {
+		defer unit.wg.Done()
+		defer close(loop.done)
+
+		ticker := NewRollingTicker(interval, jitter)
+		defer ticker.Stop()
+
+		a.flushLoop(ctx, loop.stop, loop.trigger, output, ticker)
+	}()
+}
+
 // flushLoop runs an output's flush function periodically until the context is
-// done.
+// done.  When stop is closed it returns without a final flush, leaving the
+// metrics in the buffer.  Requests on trigger flush the output immediately.
 func (a *Agent) flushLoop(
 	ctx context.Context,
+	stop <-chan struct{},
+	trigger <-chan chan error,
 	output *models.RunningOutput,
 	ticker Ticker,
 ) {
@@ -809,20 +1163,28 @@ func (a *Agent) flushLoop(
 	for {
 		// Favor shutdown over other methods.
 		select {
+		case <-stop:
+			return
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.Flush))
 			return
 		default:
 		}
 
 		select {
+		case <-stop:
+			return
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.flushOnce(output, ticker, output.Flush))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-flushRequested:
 			logError(a.flushOnce(output, ticker, output.Write))
+		case result := <-trigger:
+			err := a.flushOnce(output, ticker, output.Flush)
+			logError(err)
+			result <- err
 		case <-output.BatchReady:
 			// Favor the ticker over batch ready
 			select {
@@ -861,8 +1223,26 @@ func (a *Agent) flushOnce(
 }
 
 // Test runs the inputs, processors and aggregators for a single gather and
-// writes the metrics to stdout.
+// writes the metrics to stdout.  The named pipelines are run one after the
+// other.
 func (a *Agent) Test(ctx context.Context, wait time.Duration) error {
+	for _, ag := range a.agents() {
+		if ag.Config.Name != "" {
+			fmt.Printf("# pipeline %s\n", ag.Config.Name)
+		}
+		if err := ag.testPrint(ctx, wait); err != nil {
+			return err
+		}
+	}
+
+	if models.GlobalGatherErrors.Get() != 0 {
+		return fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
+	}
+	return nil
+}
+
+// testPrint runs the test of the agent and writes the metrics to stdout.
+func (a *Agent) testPrint(ctx context.Context, wait time.Duration) error {
 	src := make(chan telegraf.Metric, 100)
 
 	var wg sync.WaitGroup
@@ -887,10 +1267,6 @@ func (a *Agent) Test(ctx context.Context, wait time.Duration) error {
 	}
 
 	wg.Wait()
-
-	if models.GlobalGatherErrors.Get() != 0 {
-		return fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
-	}
 	return nil
 }
 
@@ -987,11 +1363,15 @@ func (a *Agent) test(ctx context.Context, wait time.Duration, outputC chan<- tel
 	return nil
 }
 
-// Once runs the full agent for a single gather.
+// Once runs the full agent for a single gather.  The named pipelines are run
+// one after the other.
 func (a *Agent) Once(ctx context.Context, wait time.Duration) error {
-	err := a.once(ctx, wait)
-	if err != nil {
-		return err
+	agents := a.agents()
+	for _, ag := range agents {
+		err := ag.once(ctx, wait)
+		if err != nil {
+			return err
+		}
 	}
 
 	if models.GlobalGatherErrors.Get() != 0 {
@@ -999,8 +1379,10 @@ func (a *Agent) Once(ctx context.Context, wait time.Duration) error {
 	}
 
 	unsent := 0
-	for _, output := range a.Config.Outputs {
-		unsent += output.BufferLength()
+	for _, ag := range agents {
+		for _, output := range ag.Config.Outputs {
+			unsent += output.BufferLength()
+		}
 	}
 	if unsent != 0 {
 		return fmt.Errorf("output plugins unable to send %d metrics", unsent)
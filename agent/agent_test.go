@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -166,3 +167,68 @@ func TestWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestAgent_Pipelines(t *testing.T) {
+	c := loadReloadConfig(t, `
+[pipelines.infra]
+  interval = "20ms"
+  [pipelines.infra.tags]
+    team = "infra"
+  [[pipelines.infra.inputs.reload_test]]
+    value = 1
+  [[pipelines.infra.outputs.reload_test]]
+
+[pipelines.app]
+  [[pipelines.app.inputs.reload_test]]
+    value = 2
+  [[pipelines.app.outputs.reload_test]]
+`)
+	require.Len(t, c.Pipelines, 2)
+
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+	require.Eventually(t, a.Ready, 5*time.Second, 10*time.Millisecond)
+
+	var pipelines []string
+	for _, p := range a.Plugins() {
+		pipelines = append(pipelines, p.Pipeline)
+	}
+	require.ElementsMatch(t, []string{"infra", "infra", "app", "app"}, pipelines)
+
+	require.Eventually(t, func() bool {
+		for _, p := range c.Pipelines {
+			if p.Outputs[0].BufferLength() == 0 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, a.Flush(ctx))
+
+	cancel()
+	require.NoError(t, <-done)
+
+	for _, p := range c.Pipelines {
+		output := p.Outputs[0].Output.(*reloadOutput)
+		output.Lock()
+		for _, m := range output.metrics {
+			value, _ := m.GetField("value")
+			team, _ := m.GetTag("team")
+			switch p.Name {
+			case "infra":
+				require.Equal(t, int64(1), value)
+				require.Equal(t, "infra", team)
+			case "app":
+				require.Equal(t, int64(2), value)
+				require.Equal(t, "", team)
+			}
+		}
+		output.Unlock()
+	}
+}
@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// APIServer serves the HTTP API of the agent.
+//
+//	GET  /health/live    the agent process is up
+//	GET  /health/ready   all plugins are started
+//	GET  /plugins        list the plugins and their state
+//	GET  /outputs        buffer and write status of the outputs
+//	POST /reload         reload the configuration
+//	POST /flush          write the buffered metrics of all outputs now
+//	POST /gather?input=  run one gather of the inputs with the name or alias
+//
+// The POST endpoints require the token, when no token is set they only
+// accept requests from the loopback interface.
+type APIServer struct {
+	Address string
+	Agent   *Agent
+
+	// Token is the bearer token required by the POST endpoints.
+	Token internal.Secret
+
+	// Reload loads the configuration and applies it to the agent.
+	Reload func() error
+
+	server *http.Server
+}
+
+// Start listens on the address and serves the API in the background.
+func (s *APIServer) Start() error {
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health/live", s.get(s.serveLive))
+	mux.HandleFunc("/health/ready", s.get(s.serveReady))
+	mux.HandleFunc("/plugins", s.get(s.servePlugins))
+	mux.HandleFunc("/outputs", s.get(s.serveOutputs))
+	mux.HandleFunc("/reload", s.post(s.serveReload))
+	mux.HandleFunc("/flush", s.post(s.serveFlush))
+	mux.HandleFunc("/gather", s.post(s.serveGather))
+
+	s.server = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: time.Minute,
+	}
+
+	go func() {
+		err := s.server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("E! [agent] Error serving API: %v", err)
+		}
+	}()
+
+	log.Printf("I! [agent] Serving API on %s", listener.Addr())
+	return nil
+}
+
+// Stop shuts the server down, waiting for active requests to complete.
+func (s *APIServer) Stop() {
+	if s.server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Error stopping API: %v", err)
+	}
+}
+
+// get restricts the handler to GET requests.
+func (s *APIServer) get(handler http.HandlerFunc) http.HandlerFunc {
+	return allowMethod(http.MethodGet, handler)
+}
+
+// post restricts the handler to authorized POST requests.
+func (s *APIServer) post(handler http.HandlerFunc) http.HandlerFunc {
+	return allowMethod(http.MethodPost, s.authorize(handler))
+}
+
+// authorize rejects requests without the bearer token, or when no token is
+// set, requests not made from the loopback interface.
+func (s *APIServer) authorize(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Token.Empty() {
+			if !isLoopback(r.RemoteAddr) {
+				writeJSON(w, http.StatusForbidden,
+					apiError{Error: "api_token is required for requests from other hosts"})
+				return
+			}
+			handler(w, r)
+			return
+		}
+
+		token, err := s.Token.Get()
+		if err != nil {
+			log.Printf("E! [agent] Error getting API token: %v", err)
+			writeJSON(w, http.StatusInternalServerError, apiError{Error: "token not available"})
+			return
+		}
+		auth := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("Bearer "+token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "unauthorized"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func allowMethod(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func (s *APIServer) serveLive(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, apiStatus{Status: "ok"})
+}
+
+func (s *APIServer) serveReady(w http.ResponseWriter, r *http.Request) {
+	if !s.Agent.Ready() {
+		writeJSON(w, http.StatusServiceUnavailable, apiStatus{Status: "not ready"})
+		return
+	}
+	writeJSON(w, http.StatusOK, apiStatus{Status: "ready"})
+}
+
+func (s *APIServer) servePlugins(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Agent.Plugins())
+}
+
+func (s *APIServer) serveOutputs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Agent.Outputs())
+}
+
+func (s *APIServer) serveFlush(w http.ResponseWriter, r *http.Request) {
+	if err := s.Agent.Flush(r.Context()); err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, apiStatus{Status: "ok"})
+}
+
+func (s *APIServer) serveGather(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("input")
+	if name == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "missing input parameter"})
+		return
+	}
+
+	err := s.Agent.Gather(r.Context(), name)
+	switch {
+	case errors.Is(err, ErrInputNotFound):
+		writeJSON(w, http.StatusNotFound, apiError{Error: err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, apiStatus{Status: "ok"})
+	}
+}
+
+func (s *APIServer) serveReload(w http.ResponseWriter, r *http.Request) {
+	err := s.Reload()
+	switch {
+	case errors.Is(err, ErrRestartRequired):
+		writeJSON(w, http.StatusAccepted, apiStatus{Status: "restarting"})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, apiStatus{Status: "ok"})
+	}
+}
+
+type apiStatus struct {
+	Status string `json:"status"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("E! [agent] Error writing API response: %v", err)
+	}
+}
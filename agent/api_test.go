@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIServer_Reload(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		code   int
+		body   string
+	}{
+		{
+			name:   "reloaded",
+			method: http.MethodPost,
+			code:   http.StatusOK,
+			body:   `{"status":"ok"}` + "\n",
+		},
+		{
+			name:   "restart",
+			method: http.MethodPost,
+			err:    fmt.Errorf("%w: changed", ErrRestartRequired),
+			code:   http.StatusAccepted,
+			body:   `{"status":"restarting"}` + "\n",
+		},
+		{
+			name:   "error",
+			method: http.MethodPost,
+			err:    errors.New("invalid config"),
+			code:   http.StatusInternalServerError,
+			body:   `{"error":"invalid config"}` + "\n",
+		},
+		{
+			name:   "wrong method",
+			method: http.MethodGet,
+			code:   http.StatusMethodNotAllowed,
+			body:   `{"error":"method not allowed"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &APIServer{
+				Reload: func() error { return tt.err },
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "/reload", nil)
+			r.RemoteAddr = "127.0.0.1:1234"
+			s.post(s.serveReload)(w, r)
+
+			require.Equal(t, tt.code, w.Code)
+			require.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}
+
+func TestAPIServer_Authorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		remote string
+		auth   string
+		code   int
+	}{
+		{
+			name:   "loopback without token",
+			remote: "127.0.0.1:1234",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "remote without token",
+			remote: "192.0.2.1:1234",
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "token",
+			token:  "secret",
+			remote: "192.0.2.1:1234",
+			auth:   "Bearer secret",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "wrong token",
+			token:  "secret",
+			remote: "127.0.0.1:1234",
+			auth:   "Bearer other",
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "missing token",
+			token:  "secret",
+			remote: "127.0.0.1:1234",
+			code:   http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &APIServer{
+				Token:  internal.NewSecret(tt.token),
+				Reload: func() error { return nil },
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/reload", nil)
+			r.RemoteAddr = tt.remote
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			s.post(s.serveReload)(w, r)
+
+			require.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestAPIServer_Status(t *testing.T) {
+	serve := func(handler http.HandlerFunc, method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, target, nil)
+		r.RemoteAddr = "[::1]:1234"
+		handler(w, r)
+		return w
+	}
+
+	c := loadReloadConfig(t, `
+[[inputs.reload_test]]
+  alias = "first"
+  value = 42
+[[outputs.reload_test]]
+`)
+
+	stopped, err := NewAgent(c)
+	require.NoError(t, err)
+	s := &APIServer{Agent: stopped}
+
+	w := serve(s.get(s.serveLive), http.MethodGet, "/health/live")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(s.get(s.serveReady), http.MethodGet, "/health/ready")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	w = serve(s.post(s.serveFlush), http.MethodPost, "/flush")
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+
+	a, stop := runReloadAgent(t, c)
+	defer stop()
+	s = &APIServer{Agent: a}
+
+	w = serve(s.get(s.serveReady), http.MethodGet, "/health/ready")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"status":"ready"}`+"\n", w.Body.String())
+
+	w = serve(s.get(s.servePlugins), http.MethodGet, "/plugins")
+	require.Equal(t, http.StatusOK, w.Code)
+	var plugins []PluginStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plugins))
+	require.Equal(t, []PluginStatus{
+		{Type: "input", Name: "reload_test", Alias: "first", ID: a.Config.Inputs[0].ID, State: StateRunning},
+		{Type: "output", Name: "reload_test", ID: a.Config.Outputs[0].ID, State: StateRunning},
+	}, plugins)
+
+	output := a.Config.Outputs[0].Output.(*reloadOutput)
+	require.Eventually(t, func() bool {
+		return a.Config.Outputs[0].BufferLength() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	w = serve(s.get(s.serveOutputs), http.MethodGet, "/outputs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var outputs []OutputStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &outputs))
+	require.Len(t, outputs, 1)
+	require.Equal(t, "reload_test", outputs[0].Name)
+	require.Equal(t, StateRunning, outputs[0].State)
+	require.Greater(t, outputs[0].BufferSize, 0)
+	require.Equal(t, a.Config.Outputs[0].MetricBufferLimit, outputs[0].BufferLimit)
+
+	w = serve(s.post(s.serveFlush), http.MethodPost, "/flush")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Greater(t, output.count(), 0)
+
+	w = serve(s.post(s.serveGather), http.MethodPost, "/gather?input=first")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = serve(s.post(s.serveGather), http.MethodPost, "/gather?input=missing")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(s.post(s.serveGather), http.MethodPost, "/gather")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
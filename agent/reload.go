@@ -0,0 +1,431 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+// ErrRestartRequired is returned by Reload when the new configuration cannot
+// be applied to the running agent and it must be restarted instead.
+var ErrRestartRequired = errors.New("configuration change requires a restart")
+
+// Reload applies a new configuration to the running agent.  Plugins are
+// compared using their ID, only the inputs and outputs that changed are
+// stopped and started.  Processors and aggregators form a single chain and
+// are replaced together when any of them changed.
+//
+// An output whose settings changed is replaced by the new output with the
+// same name and alias, the metrics waiting in its buffer are moved over.
+//
+// If the new configuration cannot be started nothing is changed and the
+// error is returned.  Changes to the agent settings, global tags, router or
+// secret store ids, and configurations with named pipelines, return
+// ErrRestartRequired.  The secret stores of the new configuration replace the
+// running ones only once the new plugins are started.
+func (a *Agent) Reload(c *config.Config) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state := a.running
+	if state == nil {
+		return errors.New("agent is not running")
+	}
+
+	if !reflect.DeepEqual(a.Config.Agent, c.Agent) || !reflect.DeepEqual(a.Config.Tags, c.Tags) {
+		return ErrRestartRequired
+	}
+	if len(a.pipelines) != 0 || len(c.Pipelines) != 0 {
+		// The plugins of the pipelines are not matched, any change restarts
+		// all pipelines and the plugins outside of them.
+		return fmt.Errorf("%w: configurations with named pipelines are not reloaded in place",
+			ErrRestartRequired)
+	}
+	if !sameRouter(a.Config.Router, c.Router) {
+		return ErrRestartRequired
+	}
+	if !sameSecretStores(a.Config, c) {
+		// The new plugins are started with the running secret stores.
+		return fmt.Errorf("%w: secret stores changed", ErrRestartRequired)
+	}
+
+	r := &reload{agent: a, state: state, config: c}
+	if err := r.start(); err != nil {
+		r.rollback()
+		return err
+	}
+	r.apply()
+
+	log.Printf("I! [agent] Reloaded config: %d plugins started, %d stopped",
+		r.started, r.stopped)
+	return nil
+}
+
+// reload holds the changes between the running and the new configuration.
+type reload struct {
+	agent  *Agent
+	state  *runState
+	config *config.Config
+
+	inputs        []*models.RunningInput // new and unchanged inputs
+	addedInputs   []*models.RunningInput
+	removedInputs []*models.RunningInput
+	serviceInputs []*models.RunningInput // added inputs already started
+
+	outputs          []*models.RunningOutput // new and unchanged outputs
+	removedOutputs   []*models.RunningOutput
+	replaced         map[*models.RunningOutput]*models.RunningOutput // new to old
+	initOutputs      []*models.RunningOutput
+	connectedOutputs []*models.RunningOutput
+
+	pipeline *pipelineUnit
+
+	started int
+	stopped int
+}
+
+// start initializes and starts the new plugins, without passing any metrics
+// to them yet.
+func (r *reload) start() error {
+	a := r.agent
+
+	// Inputs
+	match, removed := matchIDs(inputIDs(a.Config.Inputs), inputIDs(r.config.Inputs))
+	for i, input := range r.config.Inputs {
+		if match[i] >= 0 {
+			r.inputs = append(r.inputs, a.Config.Inputs[match[i]])
+			continue
+		}
+		if err := input.Init(); err != nil {
+			return fmt.Errorf("could not initialize input %s: %v", input.LogName(), err)
+		}
+		r.inputs = append(r.inputs, input)
+		r.addedInputs = append(r.addedInputs, input)
+	}
+	for _, i := range removed {
+		r.removedInputs = append(r.removedInputs, a.Config.Inputs[i])
+	}
+
+	// Outputs
+	match, removed = matchIDs(outputIDs(a.Config.Outputs), outputIDs(r.config.Outputs))
+	r.replaced = make(map[*models.RunningOutput]*models.RunningOutput)
+	for i, output := range r.config.Outputs {
+		if match[i] >= 0 {
+			r.outputs = append(r.outputs, a.Config.Outputs[match[i]])
+			continue
+		}
+		r.outputs = append(r.outputs, output)
+
+		// Replace the first old output with the same name and alias.
+		for k, j := range removed {
+			old := a.Config.Outputs[j]
+			if old.Config.Name == output.Config.Name && old.Config.Alias == output.Config.Alias {
+				r.replaced[output] = old
+				removed = append(removed[:k], removed[k+1:]...)
+				output.AdoptBuffer(old)
+				break
+			}
+		}
+
+		if err := output.Init(); err != nil {
+			return fmt.Errorf("could not initialize output %s: %v", output.LogName(), err)
+		}
+		if a.Config.Router == nil && len(output.Config.Routes) > 0 {
+			return fmt.Errorf("output %s subscribes to routes but no router is configured",
+				output.LogName())
+		}
+		r.initOutputs = append(r.initOutputs, output)
+	}
+	for _, i := range removed {
+		r.removedOutputs = append(r.removedOutputs, a.Config.Outputs[i])
+	}
+
+	// Processors and aggregators
+	if !samePipeline(a.Config, r.config) {
+		if err := r.initPipeline(); err != nil {
+			return err
+		}
+	}
+
+	for _, output := range r.initOutputs {
+		if err := a.connectOutput(r.state.ctx, output); err != nil {
+			if _, ok := r.replaced[output]; ok {
+				// The old output may hold on to resources the new one
+				// needs, such as a listening port.
+				return fmt.Errorf("%w: connecting output %s: %v",
+					ErrRestartRequired, output.LogName(), err)
+			}
+			return fmt.Errorf("connecting output %s: %w", output.LogName(), err)
+		}
+		r.connectedOutputs = append(r.connectedOutputs, output)
+	}
+
+	if r.pipeline != nil {
+		pipeline, err := a.startPipeline(r.config.Processors, r.config.AggProcessors, r.config.Aggregators)
+		if err != nil {
+			return err
+		}
+		r.pipeline = pipeline
+	}
+
+	for _, input := range r.addedInputs {
+		if err := startServiceInput(r.state.inputs.dst, input); err != nil {
+			return err
+		}
+		r.serviceInputs = append(r.serviceInputs, input)
+	}
+	return nil
+}
+
+// initPipeline initializes the processors and aggregators of the new
+// configuration.
+func (r *reload) initPipeline() error {
+	for _, processor := range r.config.Processors {
+		if err := processor.Init(); err != nil {
+			return fmt.Errorf("could not initialize processor %s: %v",
+				processor.Config.Name, err)
+		}
+	}
+	for _, aggregator := range r.config.Aggregators {
+		if err := aggregator.Init(); err != nil {
+			return fmt.Errorf("could not initialize aggregator %s: %v",
+				aggregator.Config.Name, err)
+		}
+	}
+	for _, processor := range r.config.AggProcessors {
+		if err := processor.Init(); err != nil {
+			return fmt.Errorf("could not initialize processor %s: %v",
+				processor.Config.Name, err)
+		}
+	}
+
+	// Marks the pipeline for replacement, it is started after the outputs
+	// are connected.
+	r.pipeline = &pipelineUnit{}
+	return nil
+}
+
+// rollback stops the plugins started by start.
+func (r *reload) rollback() {
+	stopServiceInputs(r.serviceInputs)
+
+	if r.pipeline != nil {
+		stopProcessors(r.pipeline.apu)
+		stopProcessors(r.pipeline.pu)
+	}
+
+	for _, output := range r.connectedOutputs {
+		if err := output.Output.Close(); err != nil {
+			log.Printf("E! [agent] Error closing output %s: %v", output.LogName(), err)
+		}
+	}
+	for _, output := range r.initOutputs {
+		output.Discard()
+	}
+}
+
+// apply switches the running agent over to the new plugins.
+func (r *reload) apply() {
+	a := r.agent
+	iu := r.state.inputs
+	ou := r.state.outputs
+
+	r.config.RegisterSecretStores()
+	a.Config.SecretStores = r.config.SecretStores
+
+	// Add new outputs first, so that no metrics are lost while the pipeline
+	// is switched.
+	for _, output := range r.connectedOutputs {
+		old, ok := r.replaced[output]
+		if !ok {
+			ou.mu.Lock()
+			ou.outputs = append(ou.outputs, output)
+			a.startFlushLoop(ou, output)
+			ou.mu.Unlock()
+			r.started++
+			continue
+		}
+
+		// The old output keeps receiving metrics until it is replaced, but
+		// is no longer written to.
+		ou.mu.Lock()
+		loop := ou.loops[old]
+		delete(ou.loops, old)
+		ou.mu.Unlock()
+		close(loop.stop)
+		<-loop.done
+
+		ou.mu.Lock()
+		for i := range ou.outputs {
+			if ou.outputs[i] == old {
+				ou.outputs[i] = output
+			}
+		}
+		old.MoveBuffer(output)
+		a.startFlushLoop(ou, output)
+		ou.mu.Unlock()
+
+		old.Close()
+		r.started++
+		r.stopped++
+	}
+
+	if r.pipeline != nil {
+		r.state.swap <- r.pipeline
+		r.started += len(r.config.Processors) + len(r.config.Aggregators)
+		r.stopped += len(a.Config.Processors) + len(a.Config.Aggregators)
+		a.Config.Processors = r.config.Processors
+		a.Config.AggProcessors = r.config.AggProcessors
+		a.Config.Aggregators = r.config.Aggregators
+	}
+
+	iu.mu.Lock()
+	for _, input := range r.removedInputs {
+		if loop, ok := iu.loops[input]; ok {
+			delete(iu.loops, input)
+			loop.cancel()
+			<-loop.done
+		}
+		stopServiceInputs([]*models.RunningInput{input})
+		r.stopped++
+	}
+	for _, input := range r.addedInputs {
+		a.startGatherLoop(iu, input)
+		r.started++
+	}
+	iu.inputs = r.inputs
+	iu.mu.Unlock()
+
+	// Outputs removed get a final flush.
+	for _, output := range r.removedOutputs {
+		ou.mu.Lock()
+		loop := ou.loops[output]
+		delete(ou.loops, output)
+		for i := range ou.outputs {
+			if ou.outputs[i] == output {
+				ou.outputs = append(ou.outputs[:i], ou.outputs[i+1:]...)
+				break
+			}
+		}
+		ou.mu.Unlock()
+
+		loop.cancel()
+		<-loop.done
+		output.Close()
+		r.stopped++
+	}
+
+	a.Config.Inputs = r.inputs
+	a.Config.Outputs = r.outputs
+}
+
+// samePipeline returns true if both configurations have the same processors
+// and aggregators in the same order.
+func samePipeline(a, b *config.Config) bool {
+	return reflect.DeepEqual(processorIDs(a.Processors), processorIDs(b.Processors)) &&
+		reflect.DeepEqual(processorIDs(a.AggProcessors), processorIDs(b.AggProcessors)) &&
+		reflect.DeepEqual(aggregatorIDs(a.Aggregators), aggregatorIDs(b.Aggregators))
+}
+
+// sameSecretStores returns true if both configurations define secret stores
+// with the same ids.
+func sameSecretStores(a, b *config.Config) bool {
+	if len(a.SecretStores) != len(b.SecretStores) {
+		return false
+	}
+	for id := range a.SecretStores {
+		if _, ok := b.SecretStores[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sameRouter returns true if the routers have the same settings and routes.
+func sameRouter(a, b *models.Router) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ca, cb := a.Config, b.Config
+	if ca.RouteTag != cb.RouteTag || ca.RemoveRouteTag != cb.RemoveRouteTag ||
+		ca.Default != cb.Default || len(ca.Routes) != len(cb.Routes) {
+		return false
+	}
+	for i, ra := range ca.Routes {
+		rb := cb.Routes[i]
+		if ra.Name != rb.Name || ra.Source != rb.Source || ra.Script != rb.Script ||
+			!reflect.DeepEqual(ra.NamePass, rb.NamePass) || len(ra.Tags) != len(rb.Tags) {
+			return false
+		}
+		for j := range ra.Tags {
+			if ra.Tags[j].Name != rb.Tags[j].Name ||
+				!reflect.DeepEqual(ra.Tags[j].Filter, rb.Tags[j].Filter) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchIDs pairs plugins with the same ID.  For each new plugin the index of
+// the matching old plugin is returned, or -1 if there is none, along with the
+// indexes of the old plugins left without a match.
+func matchIDs(oldIDs, newIDs []string) ([]int, []int) {
+	unmatched := make(map[string][]int)
+	for i, id := range oldIDs {
+		unmatched[id] = append(unmatched[id], i)
+	}
+
+	match := make([]int, len(newIDs))
+	for i, id := range newIDs {
+		match[i] = -1
+		if idx := unmatched[id]; len(idx) > 0 {
+			match[i] = idx[0]
+			unmatched[id] = idx[1:]
+		}
+	}
+
+	var removed []int
+	for _, idx := range unmatched {
+		removed = append(removed, idx...)
+	}
+	sort.Ints(removed)
+	return match, removed
+}
+
+func inputIDs(inputs []*models.RunningInput) []string {
+	ids := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		ids = append(ids, input.ID)
+	}
+	return ids
+}
+
+func outputIDs(outputs []*models.RunningOutput) []string {
+	ids := make([]string, 0, len(outputs))
+	for _, output := range outputs {
+		ids = append(ids, output.ID)
+	}
+	return ids
+}
+
+func processorIDs(processors []*models.RunningProcessor) []string {
+	ids := make([]string, 0, len(processors))
+	for _, processor := range processors {
+		ids = append(ids, processor.ID)
+	}
+	return ids
+}
+
+func aggregatorIDs(aggregators []*models.RunningAggregator) []string {
+	ids := make([]string, 0, len(aggregators))
+	for _, aggregator := range aggregators {
+		ids = append(ids, aggregator.ID)
+	}
+	return ids
+}
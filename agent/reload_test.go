@@ -0,0 +1,307 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadInput struct {
+	Value int64 `toml:"value"`
+}
+
+func (i *reloadInput) SampleConfig() string { return "" }
+func (i *reloadInput) Description() string  { return "" }
+func (i *reloadInput) Gather(acc telegraf.Accumulator) error {
+	acc.AddFields("reload", map[string]interface{}{"value": i.Value}, nil)
+	return nil
+}
+
+type reloadOutput struct {
+	Tag string `toml:"tag"`
+
+	sync.Mutex
+	metrics []telegraf.Metric
+}
+
+func (o *reloadOutput) SampleConfig() string { return "" }
+func (o *reloadOutput) Description() string  { return "" }
+func (o *reloadOutput) Connect() error       { return nil }
+func (o *reloadOutput) Close() error         { return nil }
+func (o *reloadOutput) Write(metrics []telegraf.Metric) error {
+	o.Lock()
+	defer o.Unlock()
+	o.metrics = append(o.metrics, metrics...)
+	return nil
+}
+
+func (o *reloadOutput) count() int {
+	o.Lock()
+	defer o.Unlock()
+	return len(o.metrics)
+}
+
+type reloadSecretStore struct {
+	Value string `toml:"value"`
+}
+
+func (s *reloadSecretStore) SampleConfig() string               { return "" }
+func (s *reloadSecretStore) Description() string                { return "" }
+func (s *reloadSecretStore) List() ([]string, error)            { return []string{"value"}, nil }
+func (s *reloadSecretStore) Set(key string, value []byte) error { return nil }
+func (s *reloadSecretStore) Get(key string) ([]byte, error) {
+	return []byte(s.Value), nil
+}
+
+func init() {
+	inputs.Add("reload_test", func() telegraf.Input { return &reloadInput{} })
+	outputs.Add("reload_test", func() telegraf.Output { return &reloadOutput{} })
+	secretstores.Add("reload_test", func() telegraf.SecretStore { return &reloadSecretStore{} })
+}
+
+func loadReloadConfig(t *testing.T, data string) *config.Config {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[agent]
+  interval = "10ms"
+  round_interval = false
+  flush_interval = "1h"
+  omit_hostname = true
+` + data))
+	require.NoError(t, err)
+	return c
+}
+
+func runReloadAgent(t *testing.T, c *config.Config) (*Agent, func()) {
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.running != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return a, func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestAgent_ReloadMovesBufferedMetrics(t *testing.T) {
+	a, stop := runReloadAgent(t, loadReloadConfig(t, `
+[[inputs.reload_test]]
+  value = 1
+[[outputs.reload_test]]
+  tag = "a"
+`))
+
+	input := a.Config.Inputs[0]
+	oldOutput := a.Config.Outputs[0]
+	require.Eventually(t, func() bool {
+		return oldOutput.BufferLength() >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	err := a.Reload(loadReloadConfig(t, `
+[[inputs.reload_test]]
+  value = 1
+[[outputs.reload_test]]
+  tag = "b"
+`))
+	require.NoError(t, err)
+
+	require.Len(t, a.Config.Inputs, 1)
+	require.Same(t, input, a.Config.Inputs[0])
+	require.Len(t, a.Config.Outputs, 1)
+	newOutput := a.Config.Outputs[0]
+	require.NotSame(t, oldOutput, newOutput)
+	require.Equal(t, "b", newOutput.Output.(*reloadOutput).Tag)
+	require.GreaterOrEqual(t, newOutput.BufferLength(), 3)
+
+	stop()
+
+	require.Equal(t, 0, oldOutput.Output.(*reloadOutput).count())
+	require.GreaterOrEqual(t, newOutput.Output.(*reloadOutput).count(), 3)
+}
+
+func TestAgent_ReloadInputs(t *testing.T) {
+	a, stop := runReloadAgent(t, loadReloadConfig(t, `
+[[inputs.reload_test]]
+  value = 1
+[[inputs.reload_test]]
+  value = 2
+[[outputs.reload_test]]
+`))
+	defer stop()
+
+	unchanged := a.Config.Inputs[1]
+	output := a.Config.Outputs[0]
+
+	err := a.Reload(loadReloadConfig(t, `
+[[inputs.reload_test]]
+  value = 2
+[[inputs.reload_test]]
+  value = 3
+[[outputs.reload_test]]
+`))
+	require.NoError(t, err)
+
+	require.Len(t, a.Config.Inputs, 2)
+	require.Same(t, unchanged, a.Config.Inputs[0])
+	require.Equal(t, int64(3), a.Config.Inputs[1].Input.(*reloadInput).Value)
+	require.Same(t, output, a.Config.Outputs[0])
+
+	a.running.inputs.mu.Lock()
+	require.Len(t, a.running.inputs.loops, 2)
+	require.Contains(t, a.running.inputs.loops, a.Config.Inputs[1])
+	a.running.inputs.mu.Unlock()
+}
+
+func TestAgent_ReloadAgentSettingsRequireRestart(t *testing.T) {
+	a, stop := runReloadAgent(t, loadReloadConfig(t, `
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+`))
+	defer stop()
+
+	c := loadReloadConfig(t, `
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+`)
+	c.Agent.FlushInterval.Duration = time.Minute
+
+	err := a.Reload(c)
+	require.Equal(t, ErrRestartRequired, err)
+}
+
+func TestAgent_ReloadPipelinesRequireRestart(t *testing.T) {
+	const data = `
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+[pipelines.infra]
+  [[pipelines.infra.inputs.reload_test]]
+  [[pipelines.infra.outputs.reload_test]]
+`
+	a, stop := runReloadAgent(t, loadReloadConfig(t, data))
+	defer stop()
+
+	err := a.Reload(loadReloadConfig(t, data))
+	require.True(t, errors.Is(err, ErrRestartRequired))
+	require.Contains(t, err.Error(), "named pipelines")
+}
+
+func TestAgent_ReloadNotRunning(t *testing.T) {
+	a, err := NewAgent(config.NewConfig())
+	require.NoError(t, err)
+	require.Error(t, a.Reload(config.NewConfig()))
+}
+
+func TestMatchIDs(t *testing.T) {
+	match, removed := matchIDs(
+		[]string{"cpu-1", "mem-1", "cpu-1", "disk-1"},
+		[]string{"cpu-1", "disk-2", "cpu-1", "cpu-1"},
+	)
+	require.Equal(t, []int{0, -1, 2, -1}, match)
+	require.Equal(t, []int{1, 3}, removed)
+}
+
+func TestAgent_ReloadRouterRequiresRestart(t *testing.T) {
+	const data = `
+[router]
+  [[router.routes]]
+    name = "reload"
+    namepass = ["reload"]
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+  routes = ["reload"]
+`
+	a, stop := runReloadAgent(t, loadReloadConfig(t, data))
+	defer stop()
+
+	require.NoError(t, a.Reload(loadReloadConfig(t, data)))
+
+	err := a.Reload(loadReloadConfig(t, `
+[router]
+  [[router.routes]]
+    name = "reload"
+    namepass = ["other"]
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+  routes = ["reload"]
+`))
+	require.Equal(t, ErrRestartRequired, err)
+}
+
+func TestSamePipeline(t *testing.T) {
+	processors := func(ids ...string) []*models.RunningProcessor {
+		var processors []*models.RunningProcessor
+		for _, id := range ids {
+			processors = append(processors, &models.RunningProcessor{ID: id})
+		}
+		return processors
+	}
+
+	a := &config.Config{Processors: processors("a", "b")}
+	require.True(t, samePipeline(a, &config.Config{Processors: processors("a", "b")}))
+	require.False(t, samePipeline(a, &config.Config{Processors: processors("b", "a")}))
+	require.False(t, samePipeline(a, &config.Config{
+		Processors:    processors("a", "b"),
+		AggProcessors: processors("a"),
+	}))
+}
+
+func TestAgent_ReloadSecretStores(t *testing.T) {
+	a, stop := runReloadAgent(t, loadReloadConfig(t, `
+[[secretstores.reload_test]]
+  id = "reload_a"
+  value = "1"
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+`))
+	defer stop()
+
+	value, err := internal.ResolveSecrets("@{reload_a:value}")
+	require.NoError(t, err)
+	require.Equal(t, "1", value)
+
+	// Loading a configuration does not register its secret stores.
+	c := loadReloadConfig(t, `
+[[secretstores.reload_test]]
+  id = "reload_b"
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+`)
+	err = a.Reload(c)
+	require.True(t, errors.Is(err, ErrRestartRequired))
+	_, err = internal.ResolveSecrets("@{reload_b:value}")
+	require.Error(t, err)
+
+	err = a.Reload(loadReloadConfig(t, `
+[[secretstores.reload_test]]
+  id = "reload_a"
+  value = "2"
+[[inputs.reload_test]]
+[[outputs.reload_test]]
+`))
+	require.NoError(t, err)
+	value, err = internal.ResolveSecrets("@{reload_a:value}")
+	require.NoError(t, err)
+	require.Equal(t, "2", value)
+}
@@ -0,0 +1,294 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// ErrInputNotFound is returned by Gather when no input matches the name.
+var ErrInputNotFound = errors.New("input not found")
+
+// Plugin states reported by Plugins.
+const (
+	StateRunning = "running"
+	StateStopped = "stopped"
+	// StateFailing is an output whose last write failed.
+	StateFailing = "failing"
+	// StateCircuitOpen is an output whose circuit breaker is open.
+	StateCircuitOpen = "circuit_open"
+)
+
+// PluginStatus describes a configured plugin.
+type PluginStatus struct {
+	Pipeline string `json:"pipeline,omitempty"`
+
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	ID    string `json:"id"`
+	State string `json:"state"`
+}
+
+// OutputStatus describes the buffer and writes of an output.
+type OutputStatus struct {
+	Pipeline string `json:"pipeline,omitempty"`
+
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	State string `json:"state"`
+
+	BufferSize  int     `json:"buffer_size"`
+	BufferLimit int     `json:"buffer_limit"`
+	BufferFill  float64 `json:"buffer_fill"`
+
+	MetricsAdded   int64 `json:"metrics_added"`
+	MetricsWritten int64 `json:"metrics_written"`
+	MetricsDropped int64 `json:"metrics_dropped"`
+	WriteErrors    int64 `json:"errors"`
+
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastWrite           *time.Time `json:"last_write,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorTime       *time.Time `json:"last_error_time,omitempty"`
+}
+
+// Ready returns true once all plugins are started and until shutdown begins.
+func (a *Agent) Ready() bool {
+	for _, ag := range a.agents() {
+		ag.mu.Lock()
+		running := ag.running != nil
+		ag.mu.Unlock()
+		if !running {
+			return false
+		}
+	}
+	return true
+}
+
+// Plugins returns the status of all configured plugins, including the
+// plugins of the named pipelines.
+func (a *Agent) Plugins() []PluginStatus {
+	var plugins []PluginStatus
+	for _, ag := range a.agents() {
+		plugins = append(plugins, ag.plugins()...)
+	}
+	return plugins
+}
+
+func (a *Agent) plugins() []PluginStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state := StateStopped
+	if a.running != nil {
+		state = StateRunning
+	}
+
+	var plugins []PluginStatus
+	for _, input := range a.Config.Inputs {
+		plugins = append(plugins, PluginStatus{
+			Pipeline: a.Config.Name,
+			Type:     "input",
+			Name:     input.Config.Name,
+			Alias:    input.Config.Alias,
+			ID:       input.ID,
+			State:    state,
+		})
+	}
+	for _, processor := range a.Config.Processors {
+		plugins = append(plugins, PluginStatus{
+			Pipeline: a.Config.Name,
+			Type:     "processor",
+			Name:     processor.Config.Name,
+			Alias:    processor.Config.Alias,
+			ID:       processor.ID,
+			State:    state,
+		})
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		plugins = append(plugins, PluginStatus{
+			Pipeline: a.Config.Name,
+			Type:     "aggregator",
+			Name:     aggregator.Config.Name,
+			Alias:    aggregator.Config.Alias,
+			ID:       aggregator.ID,
+			State:    state,
+		})
+	}
+	for _, output := range a.Config.Outputs {
+		plugins = append(plugins, PluginStatus{
+			Pipeline: a.Config.Name,
+			Type:     "output",
+			Name:     output.Config.Name,
+			Alias:    output.Config.Alias,
+			ID:       output.ID,
+			State:    outputState(state, output.Status()),
+		})
+	}
+	return plugins
+}
+
+// Outputs returns the status of the buffer and the writes of all outputs,
+// including the outputs of the named pipelines.  The counters are taken from
+// the internal metrics of the outputs.
+func (a *Agent) Outputs() []OutputStatus {
+	outputs := make([]OutputStatus, 0)
+	for _, ag := range a.agents() {
+		outputs = append(outputs, ag.outputs()...)
+	}
+	return outputs
+}
+
+func (a *Agent) outputs() []OutputStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state := StateStopped
+	if a.running != nil {
+		state = StateRunning
+	}
+
+	outputs := make([]OutputStatus, 0, len(a.Config.Outputs))
+	for _, output := range a.Config.Outputs {
+		status := output.Status()
+		s := OutputStatus{
+			Pipeline:            a.Config.Name,
+			Name:                output.Config.Name,
+			Alias:               output.Config.Alias,
+			State:               outputState(state, status),
+			BufferSize:          output.BufferLength(),
+			BufferLimit:         output.BufferLimit(),
+			BufferFill:          output.BufferFill(),
+			MetricsAdded:        output.MetricsAdded(),
+			MetricsWritten:      output.MetricsWritten(),
+			MetricsDropped:      output.MetricsDropped(),
+			WriteErrors:         output.WriteErrors.Get(),
+			ConsecutiveFailures: status.Failures,
+		}
+		if !status.LastWrite.IsZero() {
+			s.LastWrite = &status.LastWrite
+		}
+		if status.LastError != nil {
+			s.LastError = status.LastError.Error()
+			s.LastErrorTime = &status.LastErrorTime
+		}
+		outputs = append(outputs, s)
+	}
+	return outputs
+}
+
+func outputState(state string, status models.OutputStatus) string {
+	switch {
+	case state != StateRunning:
+		return state
+	case status.CircuitOpen:
+		return StateCircuitOpen
+	case status.Failures > 0:
+		return StateFailing
+	}
+	return state
+}
+
+// Flush writes the buffered metrics of all outputs now, ignoring any delay
+// before retrying failed writes.  It returns after all writes completed.
+func (a *Agent) Flush(ctx context.Context) error {
+	loops := make(map[*loopTask]string)
+	for _, ag := range a.agents() {
+		ag.mu.Lock()
+		if ag.running == nil {
+			ag.mu.Unlock()
+			return errors.New("agent is not running")
+		}
+		unit := ag.running.outputs
+		unit.mu.Lock()
+		for output, loop := range unit.loops {
+			loops[loop] = ag.pluginName(output.LogName())
+		}
+		unit.mu.Unlock()
+		ag.mu.Unlock()
+	}
+
+	return triggerAll(ctx, loops)
+}
+
+// Gather runs one gather of all inputs with the name or alias given, in all
+// pipelines.  It returns after the inputs completed the gather.
+func (a *Agent) Gather(ctx context.Context, name string) error {
+	loops := make(map[*loopTask]string)
+	for _, ag := range a.agents() {
+		ag.mu.Lock()
+		if ag.running == nil {
+			ag.mu.Unlock()
+			return errors.New("agent is not running")
+		}
+		unit := ag.running.inputs
+		unit.mu.Lock()
+		for input, loop := range unit.loops {
+			if input.Config.Name == name || input.Config.Alias == name {
+				loops[loop] = ag.pluginName(input.LogName())
+			}
+		}
+		unit.mu.Unlock()
+		ag.mu.Unlock()
+	}
+
+	if len(loops) == 0 {
+		return fmt.Errorf("%w: %s", ErrInputNotFound, name)
+	}
+
+	return triggerAll(ctx, loops)
+}
+
+// triggerAll triggers all loops concurrently and waits for the results.  The
+// errors are reported along with the name of the plugin of the loop.
+func triggerAll(ctx context.Context, loops map[*loopTask]string) error {
+	var mu sync.Mutex
+	var failed []string
+
+	var wg sync.WaitGroup
+	for loop, name := range loops {
+		wg.Add(1)
+		go func(name string, loop *loopTask) {
+			defer wg.Done()
+			if err := trigger(ctx, loop); err != nil {
+				mu.Lock()
+				failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+				mu.Unlock()
+			}
+		}(name, loop)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// trigger requests an immediate gather or flush from the loop and waits for
+// its result.
+func trigger(ctx context.Context, loop *loopTask) error {
+	result := make(chan error, 1)
+	select {
+	case loop.trigger <- result:
+	case <-loop.done:
+		return errors.New("plugin stopped")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// TestPipeline runs the inputs for a single gather through the processors
+// and aggregators and writes the metrics each output would receive to w,
+// serialized with the data format of the output.  If metrics is not nil they
+// are sent through the pipeline instead of running the inputs.  The outputs
+// are not connected.  The named pipelines are run one after the other, each
+// receiving a copy of the metrics.
+func (a *Agent) TestPipeline(
+	ctx context.Context,
+	wait time.Duration,
+	metrics []telegraf.Metric,
+	w io.Writer,
+) error {
+	for _, ag := range a.agents() {
+		var pipelineMetrics []telegraf.Metric
+		if metrics != nil {
+			pipelineMetrics = make([]telegraf.Metric, 0, len(metrics))
+			for _, metric := range metrics {
+				pipelineMetrics = append(pipelineMetrics, metric.Copy())
+			}
+		}
+
+		if ag.Config.Name != "" {
+			fmt.Fprintf(w, "# pipeline %s\n", ag.Config.Name)
+		}
+		if err := ag.testPipeline(ctx, wait, pipelineMetrics, w); err != nil {
+			return err
+		}
+	}
+
+	if models.GlobalGatherErrors.Get() != 0 {
+		return fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
+	}
+	return nil
+}
+
+func (a *Agent) testPipeline(
+	ctx context.Context,
+	wait time.Duration,
+	metrics []telegraf.Metric,
+	w io.Writer,
+) error {
+	log.Printf("D! [agent] Initializing plugins")
+	err := a.initPipelinePlugins()
+	if err != nil {
+		return err
+	}
+	for _, output := range a.Config.Outputs {
+		// Only the plugin is initialized, as the buffer of the running
+		// output may be stored on disk.
+		if p, ok := output.Output.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return fmt.Errorf("could not initialize output %s: %v",
+					output.Config.Name, err)
+			}
+		}
+	}
+	if err := a.initRouter(); err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+
+	unit, err := a.startPipeline(a.Config.Processors, a.Config.AggProcessors, a.Config.Aggregators)
+	if err != nil {
+		return err
+	}
+	if metrics != nil && unit.au != nil {
+		unit.au.replay = true
+	}
+
+	var iu *inputUnit
+	if metrics == nil {
+		iu, err = a.testStartInputs(unit.src, a.Config.Inputs)
+		if err != nil {
+			return err
+		}
+	}
+
+	received := make(map[*models.RunningOutput][]telegraf.Metric)
+	outputC := make(chan telegraf.Metric, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for metric := range outputC {
+			outputs := a.Config.Outputs
+			if a.Config.Router != nil {
+				outputs = a.Config.Router.Outputs(metric, outputs)
+			}
+			for _, output := range outputs {
+				m := metric.Copy()
+				if output.PrepareMetric(m) {
+					received[output] = append(received[output], m)
+				}
+			}
+			metric.Drop()
+		}
+	}()
+
+	var pwg sync.WaitGroup
+	pwg.Add(1)
+	go func() {
+		defer pwg.Done()
+		a.runPipeline(startTime, unit, outputC)
+	}()
+
+	if metrics == nil {
+		err := a.testRunInputs(ctx, wait, iu)
+		if err != nil {
+			log.Printf("E! [agent] Error running inputs: %v", err)
+		}
+	} else {
+		for _, metric := range metrics {
+			unit.src <- metric
+		}
+		close(unit.src)
+	}
+
+	pwg.Wait()
+	close(outputC)
+	wg.Wait()
+
+	for _, output := range a.Config.Outputs {
+		writeOutputMetrics(w, output, received[output])
+	}
+	return nil
+}
+
+// writeOutputMetrics writes the metrics received by the output to w,
+// serialized as one batch like the output would write them.  Outputs without
+// a data format are shown using line protocol, binary data formats as a hex
+// dump.
+func writeOutputMetrics(w io.Writer, output *models.RunningOutput, metrics []telegraf.Metric) {
+	var s serializers.Serializer = output.Serializer
+	if s == nil {
+		is := influx.NewSerializer()
+		is.SetFieldSortOrder(influx.SortFields)
+		s = is
+		fmt.Fprintf(w, "# %s: %d metrics (no data format, shown as line protocol)\n",
+			output.LogName(), len(metrics))
+	} else {
+		fmt.Fprintf(w, "# %s: %d metrics\n", output.LogName(), len(metrics))
+	}
+	if len(metrics) == 0 {
+		return
+	}
+
+	octets, err := s.SerializeBatch(metrics)
+	if err != nil {
+		fmt.Fprintf(w, "# error serializing metrics: %v\n", err)
+		return
+	}
+	if !isText(octets) {
+		fmt.Fprintf(w, "# binary data, shown as hex dump\n")
+		io.WriteString(w, hex.Dump(octets))
+		return
+	}
+	w.Write(octets)
+	if len(octets) > 0 && octets[len(octets)-1] != '\n' {
+		fmt.Fprintln(w)
+	}
+}
+
+// isText returns true if the data is printable UTF-8 text.
+func isText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// replayAggregators is a variation of runAggregators for --test-pipeline mode
+// with recorded metrics.  The aggregation periods start at the time of the
+// first metric and are pushed when a metric past their end arrives, so the
+// metrics are expected to be ordered by time.
+func (a *Agent) replayAggregators(
+	_ time.Time,
+	unit *aggregatorUnit,
+) error {
+	interval := a.Config.Agent.Interval.Duration
+	precision := a.Config.Agent.Precision.Duration
+
+	accs := make([]telegraf.Accumulator, 0, len(unit.aggregators))
+	for _, agg := range unit.aggregators {
+		acc := NewAccumulator(agg, unit.aggC)
+		acc.SetPrecision(getPrecision(precision, interval))
+		accs = append(accs, acc)
+	}
+
+	started := false
+	for metric := range unit.src {
+		if !started {
+			for _, agg := range unit.aggregators {
+				since, until := updateWindow(metric.Time(), a.Config.Agent.RoundInterval, agg.Period())
+				agg.UpdateWindow(since, until)
+			}
+			started = true
+		}
+
+		var dropOriginal bool
+		for i, agg := range unit.aggregators {
+			for agg.Period() > 0 && metric.Time().After(agg.EndPeriod().Add(agg.Config.Delay)) {
+				agg.Push(accs[i])
+			}
+			if ok := agg.Add(metric); ok {
+				dropOriginal = true
+			}
+		}
+
+		if !dropOriginal {
+			unit.outputC <- metric // keep original.
+		} else {
+			metric.Drop()
+		}
+	}
+
+	if started {
+		for i, agg := range unit.aggregators {
+			agg.Push(accs[i])
+		}
+	}
+
+	close(unit.aggC)
+	log.Printf("D! [agent] Aggregator channel closed")
+
+	return nil
+}
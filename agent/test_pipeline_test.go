@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	_ "github.com/influxdata/telegraf/plugins/aggregators/minmax"
+	_ "github.com/influxdata/telegraf/plugins/outputs/file"
+	_ "github.com/influxdata/telegraf/plugins/processors/rename"
+	"github.com/stretchr/testify/require"
+)
+
+const testPipelineConfig = `
+[[processors.rename]]
+  [[processors.rename.replace]]
+    measurement = "reload"
+    dest = "renamed"
+
+[[aggregators.minmax]]
+  period = "10s"
+  drop_original = true
+
+[[outputs.file]]
+  files = ["/nonexistent/file"]
+  data_format = "json"
+  json_timestamp_units = "1s"
+
+[[outputs.reload_test]]
+  fieldpass = ["value_max"]
+`
+
+func TestAgent_TestPipeline(t *testing.T) {
+	c := loadReloadConfig(t, `
+[[inputs.reload_test]]
+  value = 42
+`+testPipelineConfig)
+	// Align the aggregation period, so that it includes the metric even
+	// though its time is rounded down.
+	c.Agent.RoundInterval = true
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.TestPipeline(context.Background(), 0, nil, &buf))
+
+	// The outputs are listed in the order of the config, which is not fixed
+	// for plugins of different types.
+	require.Regexp(t, `(?m)^# outputs.file: 1 metrics
+\{"metrics":\[\{"fields":\{"value_max":42,"value_min":42\},"name":"renamed","tags":\{\},"timestamp":\d+\}\]\}$`, buf.String())
+	require.Regexp(t, `(?m)^# outputs.reload_test: 1 metrics \(no data format, shown as line protocol\)
+renamed value_max=42 \d+$`, buf.String())
+
+	// The outputs are not written to.
+	for _, output := range c.Outputs {
+		if o, ok := output.Output.(*reloadOutput); ok {
+			require.Equal(t, 0, o.count())
+		}
+	}
+}
+
+func TestAgent_TestPipelineRecordedMetrics(t *testing.T) {
+	c := loadReloadConfig(t, testPipelineConfig)
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	start := time.Unix(1600000000, 0)
+	var metrics []telegraf.Metric
+	for i, value := range []int64{1, 3, 10, 7} {
+		m, err := metric.New("reload", nil, map[string]interface{}{"value": value},
+			start.Add(time.Duration(i)*4*time.Second))
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, a.TestPipeline(context.Background(), 0, metrics, &buf))
+
+	// The aggregation periods follow the time of the metrics, the first one
+	// holds the metrics at 0s, 4s and 8s, the second one the metric at 12s.
+	require.Regexp(t, `(?m)^# outputs.file: 2 metrics
+\{"metrics":\[\{"fields":\{"value_max":10,"value_min":1\},"name":"renamed","tags":\{\},"timestamp":\d+\},\{"fields":\{"value_max":7,"value_min":7\},"name":"renamed","tags":\{\},"timestamp":\d+\}\]\}$`, buf.String())
+	require.Regexp(t, `(?m)^# outputs.reload_test: 2 metrics \(no data format, shown as line protocol\)
+renamed value_max=10 \d+
+renamed value_max=7 \d+$`, buf.String())
+}
+
+func TestAgent_TestPipelineRouter(t *testing.T) {
+	c := loadReloadConfig(t, `
+[router]
+  route_tag = "route"
+  remove_route_tag = true
+
+  [[router.routes]]
+    name = "cpu"
+    namepass = ["cpu"]
+
+[[outputs.file]]
+  files = ["/nonexistent/file"]
+  routes = ["cpu"]
+
+[[outputs.reload_test]]
+  routes = ["tenant"]
+`)
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	var metrics []telegraf.Metric
+	for _, name := range []string{"cpu", "mem"} {
+		m, err := metric.New(name, nil, map[string]interface{}{"value": 1}, time.Unix(1600000000, 0))
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+	m, err := metric.New("disk", map[string]string{"route": "tenant"},
+		map[string]interface{}{"value": 1}, time.Unix(1600000000, 0))
+	require.NoError(t, err)
+	metrics = append(metrics, m)
+
+	unrouted := c.Router.MetricsUnrouted.Get()
+
+	var buf bytes.Buffer
+	require.NoError(t, a.TestPipeline(context.Background(), 0, metrics, &buf))
+	require.Regexp(t, `(?m)^# outputs.file: 1 metrics
+cpu value=1i 1600000000000000000$`, buf.String())
+	require.Regexp(t, `(?m)^# outputs.reload_test: 1 metrics \(no data format, shown as line protocol\)
+disk value=1i 1600000000000000000$`, buf.String())
+	require.Equal(t, unrouted+1, c.Router.MetricsUnrouted.Get())
+}
+
+func TestAgent_TestPipelineBinaryFormat(t *testing.T) {
+	c := loadReloadConfig(t, `
+[[outputs.file]]
+  files = ["/nonexistent/file"]
+  data_format = "msgpack"
+`)
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	m, err := metric.New("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(1600000000, 0))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.TestPipeline(context.Background(), 0, []telegraf.Metric{m}, &buf))
+	require.Regexp(t, `(?m)^# outputs.file: 1 metrics
+# binary data, shown as hex dump
+00000000  [0-9a-f]{2} `, buf.String())
+}
+
+func TestAgent_RoutesWithoutRouter(t *testing.T) {
+	c := loadReloadConfig(t, `
+[[outputs.reload_test]]
+  routes = ["tenant"]
+`)
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	require.Error(t, a.initPlugins())
+}
@@ -273,3 +273,154 @@ func (t *RollingTicker) Stop() {
 	t.cancel()
 	t.wg.Wait()
 }
+
+// Schedule gives the times at which a ScheduleTicker delivers ticks.
+type Schedule interface {
+	// Next returns the first time after t, or the zero time if there is
+	// none.
+	Next(t time.Time) time.Time
+}
+
+// ScheduleTicker delivers ticks at the times of a schedule, such as a cron
+// expression, plus an optional jitter.  The next tick is calculated from the
+// clock after each tick, to handle changes to the system clock.
+//
+// The first tick is emitted at the next time of the schedule.
+//
+// Ticks are dropped for slow consumers.
+type ScheduleTicker struct {
+	schedule Schedule
+	jitter   time.Duration
+	ch       chan time.Time
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewScheduleTicker(schedule Schedule, jitter time.Duration) *ScheduleTicker {
+	return newScheduleTicker(schedule, jitter, clock.New())
+}
+
+func newScheduleTicker(schedule Schedule, jitter time.Duration, clock clock.Clock) *ScheduleTicker {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &ScheduleTicker{
+		schedule: schedule,
+		jitter:   jitter,
+		ch:       make(chan time.Time, 1),
+		cancel:   cancel,
+	}
+
+	d, ok := t.next(clock.Now())
+	timer := clock.Timer(d)
+	if !ok {
+		timer.Stop()
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.run(ctx, timer, clock)
+	}()
+
+	return t
+}
+
+func (t *ScheduleTicker) next(now time.Time) (time.Duration, bool) {
+	next := t.schedule.Next(now)
+	if next.IsZero() {
+		return 0, false
+	}
+	return next.Sub(now) + internal.RandomDuration(t.jitter), true
+}
+
+func (t *ScheduleTicker) run(ctx context.Context, timer *clock.Timer, clock clock.Clock) {
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			select {
+			case t.ch <- now:
+			default:
+			}
+
+			// Continue from the current time of the clock, as the timer
+			// may have fired late.
+			d, ok := t.next(clock.Now())
+			if !ok {
+				<-ctx.Done()
+				return
+			}
+			timer.Reset(d)
+		}
+	}
+}
+
+func (t *ScheduleTicker) Elapsed() <-chan time.Time {
+	return t.ch
+}
+
+func (t *ScheduleTicker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
+
+// Window limits the times at which a WindowTicker delivers ticks.
+type Window interface {
+	Contains(t time.Time) bool
+}
+
+// WindowTicker delivers the ticks of another ticker that occur within a time
+// window, and drops the ticks outside of it.
+type WindowTicker struct {
+	ticker Ticker
+	window Window
+	ch     chan time.Time
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWindowTicker(ticker Ticker, window Window) *WindowTicker {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &WindowTicker{
+		ticker: ticker,
+		window: window,
+		ch:     make(chan time.Time, 1),
+		cancel: cancel,
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.run(ctx)
+	}()
+
+	return t
+}
+
+func (t *WindowTicker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.ticker.Elapsed():
+			if !t.window.Contains(now) {
+				continue
+			}
+			select {
+			case t.ch <- now:
+			default:
+			}
+		}
+	}
+}
+
+func (t *WindowTicker) Elapsed() <-chan time.Time {
+	return t.ch
+}
+
+func (t *WindowTicker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+	t.ticker.Stop()
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -160,6 +161,61 @@ func TestRollingTicker(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestScheduleTicker(t *testing.T) {
+	schedule, err := internal.ParseSchedule("CRON_TZ=UTC */15 * * * *")
+	require.NoError(t, err)
+
+	clock := clock.NewMock()
+	clock.Add(1 * time.Minute)
+
+	ticker := newScheduleTicker(schedule, 0, clock)
+	defer ticker.Stop()
+
+	expected := []time.Time{
+		time.Unix(15*60, 0).UTC(),
+		time.Unix(30*60, 0).UTC(),
+		time.Unix(45*60, 0).UTC(),
+		time.Unix(60*60, 0).UTC(),
+	}
+
+	actual := []time.Time{}
+	for range expected {
+		clock.Add(15*time.Minute - time.Duration(clock.Now().Unix()%(15*60))*time.Second)
+		select {
+		case tm := <-ticker.Elapsed():
+			actual = append(actual, tm.UTC())
+		}
+	}
+
+	require.Equal(t, expected, actual)
+}
+
+type testTicker chan time.Time
+
+func (t testTicker) Elapsed() <-chan time.Time { return t }
+func (t testTicker) Stop()                     {}
+
+type testWindow func(time.Time) bool
+
+func (w testWindow) Contains(t time.Time) bool { return w(t) }
+
+func TestWindowTicker(t *testing.T) {
+	inner := make(testTicker)
+	ticker := NewWindowTicker(inner, testWindow(func(t time.Time) bool {
+		return t.Unix()%20 < 10
+	}))
+	defer ticker.Stop()
+
+	// Ticks outside of the window are dropped, the next tick received is the
+	// next one inside.
+	for _, sec := range []int64{0, 5, 10, 15, 20} {
+		inner <- time.Unix(sec, 0).UTC()
+		if sec%20 < 10 {
+			require.Equal(t, time.Unix(sec, 0).UTC(), (<-ticker.Elapsed()).UTC())
+		}
+	}
+}
+
 // Simulates running the Ticker for an hour and displays stats about the
 // operation.
 func TestAlignedTickerDistribution(t *testing.T) {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// checkConfig loads the config files in check mode and prints the problems
+// found, one per line.  It returns the number of errors found.
+func checkConfig(w io.Writer, inputFilters []string, outputFilters []string) (int, error) {
+	c := config.NewConfig()
+	c.CheckMode = true
+	c.InputFilters = inputFilters
+	c.OutputFilters = outputFilters
+
+	if err := c.LoadConfig(*fConfig); err != nil {
+		return 0, err
+	}
+	if *fConfigDirectory != "" {
+		if err := c.LoadDirectory(*fConfigDirectory); err != nil {
+			return 0, err
+		}
+	}
+
+	problems := c.Problems
+	if len(c.AllInputs()) == 0 {
+		problems = append(problems, config.Problem{
+			Severity: config.SeverityWarning,
+			Message:  "no inputs configured",
+		})
+	}
+	if len(c.AllOutputs()) == 0 {
+		problems = append(problems, config.Problem{
+			Severity: config.SeverityWarning,
+			Message:  "no outputs configured",
+		})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		return problems[i].Line < problems[j].Line
+	})
+
+	var errors, warnings int
+	for _, p := range problems {
+		fmt.Fprintln(w, p.String())
+		if p.Severity == config.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "configuration OK: %d files checked\n", len(c.Files))
+	} else {
+		fmt.Fprintf(w, "%d errors, %d warnings in %d files checked\n", errors, warnings, len(c.Files))
+	}
+	return errors, nil
+}
+
+func runCheckConfig(inputFilters []string, outputFilters []string) {
+	errors, err := checkConfig(os.Stdout, inputFilters, outputFilters)
+	if err != nil {
+		log.Fatal("E! " + err.Error())
+	}
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
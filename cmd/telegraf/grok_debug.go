@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/influxdata/telegraf/plugins/parsers/grok"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// stringList is a flag which can be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// runGrokDebug implements the "grok-debug" command, showing the pattern
+// matching each line read from stdin and the values it captures.
+func runGrokDebug(args []string) error {
+	fs := flag.NewFlagSet("grok-debug", flag.ContinueOnError)
+	var patternFiles stringList
+	fs.Var(&patternFiles, "custom-pattern-file", "file of custom patterns, can be given several times")
+	customPatterns := fs.String("custom-patterns", "", "custom patterns, one per line")
+	timezone := fs.String("timezone", "", "timezone of the timestamps")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: telegraf grok-debug [--custom-pattern-file <file>] [--custom-patterns <patterns>] [--timezone <tz>] <pattern>... < sample.log")
+	}
+
+	parser := &grok.Parser{
+		Measurement:        "grok",
+		Patterns:           fs.Args(),
+		CustomPatterns:     *customPatterns,
+		CustomPatternFiles: patternFiles,
+		Timezone:           *timezone,
+		UniqueTimestamp:    "disable",
+	}
+	if err := parser.Compile(); err != nil {
+		return err
+	}
+	return grokDebug(parser, os.Stdin, os.Stdout)
+}
+
+func grokDebug(parser *grok.Parser, r io.Reader, w io.Writer) error {
+	serializer := influx.NewSerializer()
+	serializer.SetFieldSortOrder(influx.SortFields)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintf(w, "line:    %s\n", line)
+
+		match, err := parser.Match(line)
+		if err != nil {
+			return err
+		}
+		if match == nil {
+			fmt.Fprintf(w, "no pattern matched\n\n")
+			continue
+		}
+
+		fmt.Fprintf(w, "pattern: %s\n", match.Pattern)
+		for _, c := range match.Captures {
+			fmt.Fprintf(w, "  %s = %q (%s)\n", c.Name, c.Value, c.Modifier)
+		}
+
+		m, err := parser.ParseLine(line)
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			octets, err := serializer.Serialize(m)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "metric:  %s", octets)
+		}
+		fmt.Fprintln(w)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// runSecrets implements the "secrets" command to manage the secret stores
+// configured in the config files.
+func runSecrets(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: telegraf secrets list <store id> | set <store id> <key> [value]")
+	}
+
+	c := config.NewConfig()
+	if err := c.LoadConfig(*fConfig); err != nil {
+		return err
+	}
+	if *fConfigDirectory != "" {
+		if err := c.LoadDirectory(*fConfigDirectory); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 2:
+		store, err := secretStore(c, args[1])
+		if err != nil {
+			return err
+		}
+		keys, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+		return nil
+	case args[0] == "set" && (len(args) == 3 || len(args) == 4):
+		store, err := secretStore(c, args[1])
+		if err != nil {
+			return err
+		}
+
+		// Prefer reading the value from stdin, so it does not end up in the
+		// shell history.
+		var value []byte
+		if len(args) == 4 {
+			value = []byte(args[3])
+		} else {
+			value, err = ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			value = []byte(strings.TrimRight(string(value), "\r\n"))
+		}
+		return store.Set(args[2], value)
+	}
+	return fmt.Errorf("invalid secrets command %q", strings.Join(args, " "))
+}
+
+func secretStore(c *config.Config, id string) (telegraf.SecretStore, error) {
+	store, ok := c.SecretStores[id]
+	if !ok {
+		return nil, fmt.Errorf("secret store %q not found in the configuration", id)
+	}
+	return store, nil
+}
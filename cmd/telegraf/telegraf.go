@@ -12,6 +12,7 @@ import (
 	"os/signal"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/outputs"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/all"
 )
 
 // If you update these, update usage.go and usage_windows.go
@@ -35,8 +37,12 @@ var pprofAddr = flag.String("pprof-addr", "",
 	"pprof address to listen on, not activate pprof if empty")
 var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
-var fTest = flag.Bool("test", false, "enable test mode: gather metrics, print them out, and exit. Note: Test mode only runs inputs, not processors, aggregators, or outputs")
+var fTest = flag.Bool("test", false, "enable test mode: gather metrics, print them out, and exit. Note: Test mode only runs inputs, not processors, aggregators, or outputs, see --test-pipeline")
 var fTestWait = flag.Int("test-wait", 0, "wait up to this many seconds for service inputs to complete in test mode")
+var fTestPipeline = flag.Bool("test-pipeline", false,
+	"enable pipeline test mode: gather metrics once, run them through the processors and aggregators, print what each output would receive, and exit")
+var fTestPipelineInput = flag.String("test-pipeline-input", "",
+	"file with metrics in line protocol to use instead of the inputs in pipeline test mode, '-' reads stdin")
 var fConfig = flag.String("config", "", "configuration file to load")
 var fConfigDirectory = flag.String("config-directory", "",
 	"directory containing additional *.conf files")
@@ -68,6 +74,10 @@ var fRunAsConsole = flag.Bool("console", false, "run as console application (win
 var fPlugins = flag.String("plugin-directory", "",
 	"path to directory containing external plugins")
 var fRunOnce = flag.Bool("once", false, "run one gather and exit")
+var fCheckConfig = flag.Bool("check-config", false,
+	"check the configuration files for errors and deprecated options, and exit")
+var fWatchConfig = flag.Bool("watch-config", false,
+	"reload the configuration when the config files change")
 
 var (
 	version string
@@ -77,6 +87,13 @@ var (
 
 var stop chan struct{}
 
+// errRestart is returned by runAgent when the configuration could not be
+// reloaded in place and the agent must be started again.
+var errRestart = errors.New("restart required")
+
+// How often the config files are checked for changes with --watch-config.
+const watchInterval = 5 * time.Second
+
 func reloadLoop(
 	inputFilters []string,
 	outputFilters []string,
@@ -93,64 +110,89 @@ func reloadLoop(
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
+		hangup := make(chan struct{}, 1)
 		go func() {
-			select {
-			case sig := <-signals:
-				if sig == syscall.SIGHUP {
-					log.Printf("I! Reloading Telegraf config")
-					<-reload
-					reload <- true
+			for {
+				select {
+				case sig := <-signals:
+					if sig == syscall.SIGHUP {
+						log.Printf("I! Reloading Telegraf config")
+						select {
+						case hangup <- struct{}{}:
+						default:
+						}
+						continue
+					}
+					cancel()
+				case <-stop:
+					cancel()
+				case <-ctx.Done():
 				}
-				cancel()
-			case <-stop:
-				cancel()
+				signal.Stop(signals)
+				return
 			}
 		}()
 
-		err := runAgent(ctx, inputFilters, outputFilters)
+		err := runAgent(ctx, hangup, inputFilters, outputFilters)
+		cancel()
+		if err == errRestart {
+			<-reload
+			reload <- true
+			continue
+		}
 		if err != nil && err != context.Canceled {
 			log.Fatalf("E! [telegraf] Error running agent: %v", err)
 		}
 	}
 }
 
-func runAgent(ctx context.Context,
-	inputFilters []string,
-	outputFilters []string,
-) error {
-	log.Printf("I! Starting Telegraf %s", version)
-
-	// If no other options are specified, load the config file and run.
+// loadConfig loads and checks the configuration files.
+func loadConfig(inputFilters []string, outputFilters []string) (*config.Config, error) {
 	c := config.NewConfig()
 	c.OutputFilters = outputFilters
 	c.InputFilters = inputFilters
 	err := c.LoadConfig(*fConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if *fConfigDirectory != "" {
 		err = c.LoadDirectory(*fConfigDirectory)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	if !*fTest && len(c.Outputs) == 0 {
-		return errors.New("Error: no outputs found, did you provide a valid config file?")
+	if !*fTest && len(c.AllOutputs()) == 0 {
+		return nil, errors.New("Error: no outputs found, did you provide a valid config file?")
 	}
-	if *fPlugins == "" && len(c.Inputs) == 0 {
-		return errors.New("Error: no inputs found, did you provide a valid config file?")
+	if *fPlugins == "" && *fTestPipelineInput == "" && len(c.AllInputs()) == 0 {
+		return nil, errors.New("Error: no inputs found, did you provide a valid config file?")
 	}
 
 	if int64(c.Agent.Interval.Duration) <= 0 {
-		return fmt.Errorf("Agent interval must be positive, found %s",
+		return nil, fmt.Errorf("Agent interval must be positive, found %s",
 			c.Agent.Interval.Duration)
 	}
 
 	if int64(c.Agent.FlushInterval.Duration) <= 0 {
-		return fmt.Errorf("Agent flush_interval must be positive; found %s",
+		return nil, fmt.Errorf("Agent flush_interval must be positive; found %s",
 			c.Agent.Interval.Duration)
 	}
+	return c, nil
+}
+
+func runAgent(ctx context.Context,
+	hangup <-chan struct{},
+	inputFilters []string,
+	outputFilters []string,
+) error {
+	log.Printf("I! Starting Telegraf %s", version)
+
+	// If no other options are specified, load the config file and run.
+	c, err := loadConfig(inputFilters, outputFilters)
+	if err != nil {
+		return err
+	}
 
 	ag, err := agent.NewAgent(c)
 	if err != nil {
@@ -175,6 +217,11 @@ func runAgent(ctx context.Context,
 		return ag.Once(ctx, wait)
 	}
 
+	if *fTestPipeline || *fTestPipelineInput != "" {
+		wait := time.Duration(*fTestWait) * time.Second
+		return runTestPipeline(ctx, ag, wait)
+	}
+
 	if *fTest || *fTestWait != 0 {
 		wait := time.Duration(*fTestWait) * time.Second
 		return ag.Test(ctx, wait)
@@ -204,11 +251,71 @@ func runAgent(ctx context.Context,
 		}
 	}
 
-	return ag.Run(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Plugins that changed are replaced in the running agent, if that is not
+	// possible the agent is restarted.
+	var restart int32
+	reloadConfig := func() error {
+		c, err := loadConfig(inputFilters, outputFilters)
+		if err != nil {
+			log.Printf("E! [telegraf] Error reloading config: %v", err)
+			return err
+		}
+
+		err = ag.Reload(c)
+		if errors.Is(err, agent.ErrRestartRequired) {
+			log.Printf("I! [telegraf] Restarting agent: %v", err)
+			atomic.StoreInt32(&restart, 1)
+			cancel()
+			return err
+		}
+		if err != nil {
+			log.Printf("E! [telegraf] Error reloading config: %v", err)
+		}
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case <-hangup:
+				reloadConfig()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if *fWatchConfig {
+		go watchConfig(ctx, c.Files, *fConfigDirectory, watchInterval, func() {
+			reloadConfig()
+		})
+	}
+
+	if c.Agent.APIListen != "" {
+		api := &agent.APIServer{
+			Address: c.Agent.APIListen,
+			Agent:   ag,
+			Token:   c.Agent.APIToken,
+			Reload:  reloadConfig,
+		}
+		if err := api.Start(); err != nil {
+			return fmt.Errorf("starting API: %w", err)
+		}
+		defer api.Stop()
+	}
+
+	err = ag.Run(ctx)
+	if atomic.LoadInt32(&restart) == 1 {
+		return errRestart
+	}
+	return err
 }
 
 func usageExit(rc int) {
-	fmt.Println(internal.Usage)
+	fmt.Print(internal.Usage)
 	os.Exit(rc)
 }
 
@@ -292,6 +399,10 @@ func main() {
 			fmt.Println(formatFullVersion())
 			return
 		case "config":
+			if len(args) > 1 && args[1] == "check" {
+				runCheckConfig(inputFilters, outputFilters)
+				return
+			}
 			config.PrintSampleConfig(
 				sectionFilters,
 				inputFilters,
@@ -300,11 +411,24 @@ func main() {
 				processorFilters,
 			)
 			return
+		case "secrets":
+			if err := runSecrets(args[1:]); err != nil {
+				log.Fatal("E! " + err.Error())
+			}
+			return
+		case "grok-debug":
+			if err := runGrokDebug(args[1:]); err != nil {
+				log.Fatal("E! " + err.Error())
+			}
+			return
 		}
 	}
 
 	// switch for flags which just do something and exit immediately
 	switch {
+	case *fCheckConfig:
+		runCheckConfig(inputFilters, outputFilters)
+		return
 	case *fOutputList:
 		fmt.Println("Available Output Plugins: ")
 		names := make([]string, 0, len(outputs.Outputs))
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+// runTestPipeline runs the agent in pipeline test mode, using the recorded
+// metrics instead of the inputs if a file is given.
+func runTestPipeline(ctx context.Context, ag *agent.Agent, wait time.Duration) error {
+	var metrics []telegraf.Metric
+	if *fTestPipelineInput != "" {
+		var err error
+		metrics, err = readMetrics(*fTestPipelineInput)
+		if err != nil {
+			return err
+		}
+	}
+	return ag.TestPipeline(ctx, wait, metrics, os.Stdout)
+}
+
+// readMetrics reads recorded metrics in line protocol from the file, or from
+// stdin if the path is "-".
+func readMetrics(path string) ([]telegraf.Metric, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	metrics := []telegraf.Metric{}
+	parser := influx.NewStreamParser(r)
+	for {
+		m, err := parser.Next()
+		if err == influx.EOF {
+			return metrics, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading metrics from %s: %w", path, err)
+		}
+		metrics = append(metrics, m)
+	}
+}
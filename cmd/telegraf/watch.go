@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"hash/fnv"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchConfig checks the config files and the config directory for changes
+// every interval and calls reload when any of them changed.
+func watchConfig(
+	ctx context.Context,
+	files []string,
+	directory string,
+	interval time.Duration,
+	reload func(),
+) {
+	last := configChecksum(files, directory)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sum := configChecksum(files, directory)
+			if sum == last {
+				continue
+			}
+			last = sum
+
+			log.Printf("I! Config files changed, reloading Telegraf config")
+			reload()
+		}
+	}
+}
+
+// configChecksum returns a checksum over the content of the config files and
+// all *.conf files in the config directory.  Remote config files are skipped.
+func configChecksum(files []string, directory string) uint64 {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if strings.Contains(file, "://") {
+			continue
+		}
+		paths = append(paths, file)
+	}
+
+	if directory != "" {
+		filepath.Walk(directory, func(path string, info os.FileInfo, _ error) error {
+			if info == nil {
+				return nil
+			}
+			if info.IsDir() {
+				if strings.HasPrefix(info.Name(), "..") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(info.Name(), ".conf") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+	}
+
+	h := fnv.New64a()
+	for _, path := range paths {
+		h.Write([]byte(path))
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			h.Write([]byte(err.Error()))
+			continue
+		}
+		h.Write(data)
+	}
+	return h.Sum64()
+}
@@ -1,21 +1,24 @@
 package aws
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/influxdata/telegraf/internal"
 )
 
 type CredentialConfig struct {
 	Region      string
 	AccessKey   string
-	SecretKey   string
+	SecretKey   internal.Secret
 	RoleARN     string
 	Profile     string
 	Filename    string
-	Token       string
+	Token       internal.Secret
 	EndpointURL string
 }
 
@@ -34,8 +37,12 @@ func (c *CredentialConfig) rootCredentials() client.ConfigProvider {
 	if c.EndpointURL != "" {
 		config.Endpoint = &c.EndpointURL
 	}
-	if c.AccessKey != "" || c.SecretKey != "" {
-		config.Credentials = credentials.NewStaticCredentials(c.AccessKey, c.SecretKey, c.Token)
+	if c.AccessKey != "" || !c.SecretKey.Empty() {
+		config.Credentials = credentials.NewCredentials(&secretProvider{
+			accessKey: c.AccessKey,
+			secretKey: c.SecretKey,
+			token:     c.Token,
+		})
 	} else if c.Profile != "" || c.Filename != "" {
 		config.Credentials = credentials.NewSharedCredentials(c.Filename, c.Profile)
 	}
@@ -52,3 +59,35 @@ func (c *CredentialConfig) assumeCredentials() client.ConfigProvider {
 	config.Credentials = stscreds.NewCredentials(rootCredentials, c.RoleARN)
 	return session.New(config)
 }
+
+// secretProvider provides static credentials whose secret key and token are
+// resolved each time the credentials expire, so that rotated secrets are
+// picked up.
+type secretProvider struct {
+	accessKey string
+	secretKey internal.Secret
+	token     internal.Secret
+}
+
+func (p *secretProvider) Retrieve() (credentials.Value, error) {
+	secretKey, err := p.secretKey.Get()
+	if err != nil {
+		return credentials.Value{}, fmt.Errorf("getting secret_key: %w", err)
+	}
+	token, err := p.token.Get()
+	if err != nil {
+		return credentials.Value{}, fmt.Errorf("getting token: %w", err)
+	}
+	return credentials.Value{
+		AccessKeyID:     p.accessKey,
+		SecretAccessKey: secretKey,
+		SessionToken:    token,
+		ProviderName:    credentials.StaticProviderName,
+	}, nil
+}
+
+// IsExpired always returns true, the secrets are cheap to resolve compared
+// to the requests using them.
+func (p *secretProvider) IsExpired() bool {
+	return true
+}
@@ -0,0 +1,431 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+)
+
+// Severities of the problems found in check mode.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is an issue found in a configuration file in check mode.
+type Problem struct {
+	File     string
+	Line     int
+	Plugin   string
+	Severity string
+	Message  string
+}
+
+// String formats the problem as "file:line: severity: [plugin] message".
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.File != "" {
+		b.WriteString(p.File)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+		}
+		b.WriteString(": ")
+	} else if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	b.WriteString(p.Severity)
+	b.WriteString(": ")
+	if p.Plugin != "" {
+		fmt.Fprintf(&b, "[%s] ", p.Plugin)
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// Keys of the general plugin options holding durations and sizes.
+var (
+	durationKeys = map[string][]string{
+		"inputs":      {"interval", "precision", "collection_jitter"},
+		"outputs":     {"flush_interval", "flush_jitter", "retry_initial_delay", "retry_max_delay", "retry_jitter"},
+		"aggregators": {"period", "delay", "grace"},
+	}
+	sizeKeys = map[string][]string{
+		"outputs": {"buffer_max_size"},
+	}
+	filterKeys = []string{"namepass", "namedrop", "fieldpass", "fielddrop",
+		"pass", "drop", "taginclude", "tagexclude"}
+	deprecatedKeys = map[string]string{
+		"pass": "fieldpass",
+		"drop": "fielddrop",
+	}
+)
+
+func (c *Config) problem(line int, severity string, format string, args ...interface{}) {
+	c.Problems = append(c.Problems, Problem{
+		File:     c.file,
+		Line:     line,
+		Plugin:   c.plugin,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// add adds the plugin of the given kind defined by the table.  In check mode
+// errors are recorded as problems instead of returned, so that the remaining
+// plugins are checked as well, and the plugin is initialized.
+func (c *Config) add(kind, name string, tbl *ast.Table, add func(string, *ast.Table) error) error {
+	if !c.CheckMode {
+		return add(name, tbl)
+	}
+
+	c.plugin = kind + "." + name
+	defer func() { c.plugin = "" }()
+
+	c.checkPluginTable(kind, tbl)
+
+	n := c.pluginCount(kind)
+	if err := add(name, tbl); err != nil {
+		c.problem(tbl.Line, SeverityError, "%v", err)
+		return nil
+	}
+	if c.pluginCount(kind) > n {
+		c.checkPlugin(kind, tbl.Line)
+	}
+	return nil
+}
+
+func (c *Config) pluginCount(kind string) int {
+	switch kind {
+	case "inputs":
+		return len(c.Inputs)
+	case "outputs":
+		return len(c.Outputs)
+	case "processors":
+		return len(c.Processors)
+	case "aggregators":
+		return len(c.Aggregators)
+	}
+	return 0
+}
+
+// checkPlugin checks the filters of the plugin added last and calls its Init
+// function.
+func (c *Config) checkPlugin(kind string, line int) {
+	var plugin interface{}
+	var f models.Filter
+	switch kind {
+	case "inputs":
+		ri := c.Inputs[len(c.Inputs)-1]
+		plugin, f = ri.Input, ri.Config.Filter
+	case "outputs":
+		ro := c.Outputs[len(c.Outputs)-1]
+		plugin, f = ro.Output, ro.Config.Filter
+	case "processors":
+		rp := c.Processors[len(c.Processors)-1]
+		plugin, f = rp.Processor, rp.Config.Filter
+	case "aggregators":
+		ra := c.Aggregators[len(c.Aggregators)-1]
+		plugin, f = ra.Aggregator, ra.Config.Filter
+	default:
+		return
+	}
+
+	c.checkFilter(line, f)
+
+	if p, ok := plugin.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			c.problem(line, SeverityError, "initializing plugin: %v", err)
+		}
+	}
+}
+
+// checkConfig reports the deprecated sections of the configuration.
+func (c *Config) checkConfig(tbl *ast.Table) {
+	for name, val := range tbl.Fields {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "agent", "global_tags", "tags", "secretstores", "router", "pipelines", "processors", "aggregators":
+		case "plugins":
+			c.problem(subTable.Line, SeverityWarning, "section [plugins] is deprecated, use [[inputs.*]]")
+		case "inputs", "outputs":
+			for pluginName, pluginVal := range subTable.Fields {
+				if t, ok := pluginVal.(*ast.Table); ok {
+					c.problem(t.Line, SeverityWarning,
+						"table [%s.%s] is deprecated, use [[%s.%s]]", name, pluginName, name, pluginName)
+				}
+			}
+		default:
+			c.problem(subTable.Line, SeverityWarning,
+				"table [%s] is deprecated, use [[inputs.%s]]", name, name)
+		}
+	}
+}
+
+// checkPluginTable checks the general plugin options, which are silently
+// ignored when they have the wrong type.
+func (c *Config) checkPluginTable(kind string, tbl *ast.Table) {
+	for _, key := range durationKeys[kind] {
+		kv, ok := tbl.Fields[key].(*ast.KeyValue)
+		if !ok {
+			continue
+		}
+		str, ok := kv.Value.(*ast.String)
+		if !ok {
+			c.problem(kv.Line, SeverityError, "invalid duration for %q, use a string such as \"10s\"", key)
+			delete(tbl.Fields, key)
+			continue
+		}
+		if _, err := time.ParseDuration(str.Value); err != nil {
+			c.problem(kv.Line, SeverityError, "invalid duration for %q: %v", key, err)
+			delete(tbl.Fields, key)
+		}
+	}
+
+	for _, key := range sizeKeys[kind] {
+		kv, ok := tbl.Fields[key].(*ast.KeyValue)
+		if !ok {
+			continue
+		}
+		var size internal.Size
+		var err error
+		switch v := kv.Value.(type) {
+		case *ast.String:
+			err = size.UnmarshalTOML([]byte(fmt.Sprintf("%q", v.Value)))
+		case *ast.Integer:
+			err = size.UnmarshalTOML([]byte(v.Value))
+		default:
+			err = errors.New(`use a string such as "16MiB" or an integer`)
+		}
+		if err != nil {
+			c.problem(kv.Line, SeverityError, "invalid size for %q: %v", key, err)
+			delete(tbl.Fields, key)
+		}
+	}
+
+	for _, key := range filterKeys {
+		kv, ok := tbl.Fields[key].(*ast.KeyValue)
+		if !ok {
+			continue
+		}
+		if replacement, ok := deprecatedKeys[key]; ok {
+			c.problem(kv.Line, SeverityWarning, "option %q is deprecated, use %q", key, replacement)
+		}
+		if !isStringArray(kv.Value) {
+			c.problem(kv.Line, SeverityError, "%q must be an array of strings, the setting is ignored", key)
+		}
+	}
+
+	for _, key := range []string{"tagpass", "tagdrop"} {
+		node, ok := tbl.Fields[key]
+		if !ok {
+			continue
+		}
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			c.problem(fieldLine(node), SeverityError, "%q must be a table, the setting is ignored", key)
+			continue
+		}
+		for tag, val := range subtbl.Fields {
+			if kv, ok := val.(*ast.KeyValue); !ok || !isStringArray(kv.Value) {
+				c.problem(fieldLine(val), SeverityError,
+					"%s value for tag %q must be an array of strings", key, tag)
+			}
+		}
+	}
+}
+
+// checkFilter reports filters that can never match.
+func (c *Config) checkFilter(line int, f models.Filter) {
+	checkPassDrop := func(passName string, pass []string, dropName string, drop []string) {
+		for _, pattern := range drop {
+			if pattern == "*" {
+				c.problem(line, SeverityWarning, "%s %q matches everything, nothing passes", dropName, pattern)
+				return
+			}
+		}
+		dropFilter, err := filter.Compile(drop)
+		if err != nil || dropFilter == nil {
+			return
+		}
+		for _, pattern := range pass {
+			if isLiteral(pattern) && dropFilter.Match(pattern) {
+				c.problem(line, SeverityWarning, "%s %q never matches, it is dropped by %s", passName, pattern, dropName)
+			}
+		}
+	}
+
+	checkPassDrop("namepass", f.NamePass, "namedrop", f.NameDrop)
+	checkPassDrop("fieldpass", f.FieldPass, "fielddrop", f.FieldDrop)
+	checkPassDrop("taginclude", f.TagInclude, "tagexclude", f.TagExclude)
+
+	for _, pass := range f.TagPass {
+		if len(pass.Filter) == 0 {
+			c.problem(line, SeverityWarning, "tagpass for tag %q has no values and never matches", pass.Name)
+			continue
+		}
+		for _, drop := range f.TagDrop {
+			if drop.Name == pass.Name {
+				checkPassDrop("tagpass "+pass.Name, pass.Filter, "tagdrop "+drop.Name, drop.Filter)
+			}
+		}
+	}
+}
+
+// unmarshalTable sets the plugin options from the table.  In check mode all
+// unknown keys and invalid values are recorded as problems, and keys of
+// deprecated options are reported.
+func (c *Config) unmarshalTable(tbl *ast.Table, v interface{}) error {
+	if !c.CheckMode {
+		return toml.UnmarshalTable(tbl, v)
+	}
+
+	c.checkDeprecated(tbl, v)
+
+	for {
+		err := toml.UnmarshalTable(tbl, v)
+		if err == nil {
+			return nil
+		}
+
+		var lerr *toml.LineError
+		if !errors.As(err, &lerr) {
+			return err
+		}
+		key, ok := removeLine(tbl, lerr.Line)
+		if !ok {
+			return err
+		}
+		if strings.HasPrefix(lerr.Err.Error(), "field corresponding to") {
+			c.problem(lerr.Line, SeverityError, "unknown option %q", key)
+		} else {
+			c.problem(lerr.Line, SeverityError, "invalid value for %q: %v", key, lerr.Err)
+		}
+	}
+}
+
+// checkDeprecated reports the options of the table set on struct fields with
+// a "deprecated" tag.  The tag has the form "<version>;<notice>".
+func (c *Config) checkDeprecated(tbl *ast.Table, v interface{}) {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return
+	}
+
+	for key, node := range tbl.Fields {
+		field, ok := structField(typ, key)
+		if !ok {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("deprecated")
+		if !ok {
+			continue
+		}
+		parts := strings.SplitN(tag, ";", 2)
+		msg := fmt.Sprintf("option %q is deprecated since %s", key, parts[0])
+		if len(parts) == 2 && parts[1] != "" {
+			msg += ", " + parts[1]
+		}
+		c.problem(fieldLine(node), SeverityWarning, "%s", msg)
+	}
+}
+
+// structField finds the field set by the key, matching keys the same way as
+// the TOML decoder.
+func structField(typ reflect.Type, key string) (reflect.StructField, bool) {
+	norm := func(s string) string {
+		return strings.Replace(strings.ToLower(s), "_", "", -1)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name != "" {
+			if name == key {
+				return field, true
+			}
+			continue
+		}
+		if norm(field.Name) == norm(key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// removeLine removes the key defined on the line from the table or any of
+// its subtables, preferring the innermost.  Returns the name of the key.
+func removeLine(tbl *ast.Table, line int) (string, bool) {
+	for _, node := range tbl.Fields {
+		switch t := node.(type) {
+		case *ast.Table:
+			if key, ok := removeLine(t, line); ok {
+				return key, true
+			}
+		case []*ast.Table:
+			for _, sub := range t {
+				if key, ok := removeLine(sub, line); ok {
+					return key, true
+				}
+			}
+		}
+	}
+	for key, node := range tbl.Fields {
+		if fieldLine(node) == line {
+			delete(tbl.Fields, key)
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func fieldLine(node interface{}) int {
+	switch v := node.(type) {
+	case *ast.KeyValue:
+		return v.Line
+	case *ast.Table:
+		return v.Line
+	case []*ast.Table:
+		if len(v) > 0 {
+			return v[0].Line
+		}
+	}
+	return 0
+}
+
+func isStringArray(value ast.Value) bool {
+	ary, ok := value.(*ast.Array)
+	if !ok {
+		return false
+	}
+	for _, elem := range ary.Value {
+		if _, ok := elem.(*ast.String); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isLiteral(pattern string) bool {
+	return !strings.ContainsAny(pattern, "*?[")
+}
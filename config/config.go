@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"io/ioutil"
 	"log"
 	"math"
@@ -25,6 +27,8 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
+	"github.com/influxdata/telegraf/plugins/parsers/xml"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/toml"
@@ -56,6 +60,10 @@ var (
 // will be logging to, as well as all the plugins that the user has
 // specified
 type Config struct {
+	// Name is the name of a pipeline, it is empty for the top-level
+	// configuration.
+	Name string
+
 	Tags          map[string]string
 	InputFilters  []string
 	OutputFilters []string
@@ -67,6 +75,30 @@ type Config struct {
 	// Processors have a slice wrapper type because they need to be sorted
 	Processors    models.RunningProcessors
 	AggProcessors models.RunningProcessors
+
+	// Router assigns the metrics to the routes subscribed by the outputs, it
+	// is nil without a [router] section.
+	Router *models.Router
+
+	// Pipelines are the named pipelines defined in [pipelines.<name>]
+	// sections, run alongside the plugins of the configuration.
+	Pipelines []*Config
+
+	// SecretStores maps the id of each secret store to the store.
+	SecretStores map[string]telegraf.SecretStore
+
+	// Files lists the configuration files loaded, in order.
+	Files []string
+
+	// CheckMode makes loading continue after errors in plugin tables and
+	// collects all errors and warnings in Problems.  The plugins are
+	// initialized as they are loaded.
+	CheckMode bool
+	Problems  []Problem
+
+	file   string  // file being loaded
+	plugin string  // plugin being loaded
+	parent *Config // configuration a pipeline is defined in
 }
 
 func NewConfig() *Config {
@@ -87,6 +119,7 @@ func NewConfig() *Config {
 		AggProcessors: make([]*models.RunningProcessor, 0),
 		InputFilters:  make([]string, 0),
 		OutputFilters: make([]string, 0),
+		SecretStores:  make(map[string]telegraf.SecretStore),
 	}
 	return c
 }
@@ -133,15 +166,21 @@ type AgentConfig struct {
 	// not be less than 2 times MetricBatchSize.
 	MetricBufferLimit int
 
+	// BufferStrategy is the default buffer used by outputs, either "memory"
+	// or "disk".  When using "disk" the unsent metrics are stored below
+	// BufferDirectory and survive a restart of the agent.
+	BufferStrategy  string `toml:"buffer_strategy"`
+	BufferDirectory string `toml:"buffer_directory"`
+
 	// FlushBufferWhenFull tells Telegraf to flush the metric buffer whenever
 	// it fills up, regardless of FlushInterval. Setting this option to true
 	// does _not_ deactivate FlushInterval.
-	FlushBufferWhenFull bool // deprecated in 0.13; has no effect
+	FlushBufferWhenFull bool `deprecated:"0.13.0;option is ignored"`
 
 	// TODO(cam): Remove UTC and parameter, they are no longer
 	// valid for the agent config. Leaving them here for now for backwards-
 	// compatibility
-	UTC bool `toml:"utc"` // deprecated in 1.0.0; has no effect
+	UTC bool `toml:"utc" deprecated:"1.0.0;option is ignored"`
 
 	// Debug is the option for running in debug mode
 	Debug bool `toml:"debug"`
@@ -172,6 +211,14 @@ type AgentConfig struct {
 
 	Hostname     string
 	OmitHostname bool
+
+	// APIListen is the address of the HTTP API of the agent, when empty the
+	// API is disabled.
+	APIListen string `toml:"api_listen"`
+
+	// APIToken is the bearer token required by the endpoints of the API
+	// changing the state of the agent.
+	APIToken internal.Secret `toml:"api_token"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -288,6 +335,12 @@ var agentConfig = `
   ## cost of higher maximum memory usage.
   metric_buffer_limit = 10000
 
+  ## Buffer used by the outputs for unwritten metrics, either "memory" or
+  ## "disk".  The disk buffer keeps the metrics in buffer_directory so they
+  ## are not lost when Telegraf restarts.  Can be overridden per output.
+  # buffer_strategy = "memory"
+  # buffer_directory = ""
+
   ## Collection jitter is used to jitter the collection by a random amount.
   ## Each plugin will sleep for a random time within jitter before collecting.
   ## This can be used to avoid many plugins querying things like sysfs at the
@@ -343,6 +396,15 @@ var agentConfig = `
   ## If set to true, do no set the "host" tag in the telegraf agent.
   omit_hostname = false
 
+  ## Address of the HTTP API of the agent, serving health checks, the status
+  ## of the plugins and endpoints to reload, flush and gather.  The API is
+  ## disabled when empty.
+  # api_listen = "localhost:8099"
+
+  ## Bearer token required to reload, flush and gather through the API.  When
+  ## not set these endpoints only accept requests from localhost.
+  # api_token = "@{secrets:api_token}"
+
 `
 
 var outputHeader = `
@@ -706,8 +768,21 @@ func (c *Config) LoadConfig(path string) error {
 	if err != nil {
 		return fmt.Errorf("Error loading config file %s: %w", path, err)
 	}
+	c.Files = append(c.Files, path)
+
+	c.file = path
+	defer func() { c.file = "" }()
 
 	if err = c.LoadConfigData(data); err != nil {
+		if c.CheckMode {
+			var lerr *toml.LineError
+			if errors.As(err, &lerr) {
+				c.problem(lerr.Line, SeverityError, "%v", lerr.Err)
+			} else {
+				c.problem(0, SeverityError, "%v", err)
+			}
+			return nil
+		}
 		return fmt.Errorf("Error loading config file %s: %w", path, err)
 	}
 	return nil
@@ -717,7 +792,11 @@ func (c *Config) LoadConfig(path string) error {
 func (c *Config) LoadConfigData(data []byte) error {
 	tbl, err := parseConfig(data)
 	if err != nil {
-		return fmt.Errorf("Error parsing data: %s", err)
+		return fmt.Errorf("Error parsing data: %w", err)
+	}
+
+	if c.CheckMode {
+		c.checkConfig(tbl)
 	}
 
 	// Parse tags tables first:
@@ -739,7 +818,10 @@ func (c *Config) LoadConfigData(data []byte) error {
 		if !ok {
 			return fmt.Errorf("invalid configuration, error parsing agent table")
 		}
-		if err = toml.UnmarshalTable(subTable, c.Agent); err != nil {
+		c.plugin = "agent"
+		err = c.unmarshalTable(subTable, c.Agent)
+		c.plugin = ""
+		if err != nil {
 			return fmt.Errorf("error parsing agent table: %w", err)
 		}
 	}
@@ -757,6 +839,27 @@ func (c *Config) LoadConfigData(data []byte) error {
 		c.Tags["host"] = c.Agent.Hostname
 	}
 
+	// Parse the secret stores before the plugins referencing them:
+	if val, ok := tbl.Fields["secretstores"]; ok {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing secretstores table")
+		}
+		for pluginName, pluginVal := range subTable.Fields {
+			switch pluginSubTable := pluginVal.(type) {
+			case []*ast.Table:
+				for _, t := range pluginSubTable {
+					if err = c.add("secretstores", pluginName, t, c.addSecretStore); err != nil {
+						return fmt.Errorf("Error parsing %s, %s", pluginName, err)
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s",
+					pluginName)
+			}
+		}
+	}
+
 	// Parse all the rest of the plugins:
 	for name, val := range tbl.Fields {
 		subTable, ok := val.(*ast.Table)
@@ -765,78 +868,33 @@ func (c *Config) LoadConfigData(data []byte) error {
 		}
 
 		switch name {
-		case "agent", "global_tags", "tags":
-		case "outputs":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				// legacy [outputs.influxdb] support
-				case *ast.Table:
-					if err = c.addOutput(pluginName, pluginSubTable); err != nil {
-						return fmt.Errorf("Error parsing %s, %s", pluginName, err)
-					}
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addOutput(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s array, %s", pluginName, err)
-						}
-					}
-				default:
-					return fmt.Errorf("Unsupported config format: %s",
-						pluginName)
+		case "agent", "global_tags", "tags", "secretstores":
+		case "router":
+			c.plugin = "router"
+			err = c.addRouter(subTable)
+			c.plugin = ""
+			if err != nil {
+				if !c.CheckMode {
+					return fmt.Errorf("error parsing router table: %w", err)
 				}
+				c.problem(subTable.Line, SeverityError, "%v", err)
 			}
-		case "inputs", "plugins":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				// legacy [inputs.cpu] support
-				case *ast.Table:
-					if err = c.addInput(pluginName, pluginSubTable); err != nil {
-						return fmt.Errorf("Error parsing %s, %s", pluginName, err)
-					}
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addInput(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", pluginName, err)
-						}
-					}
-				default:
-					return fmt.Errorf("Unsupported config format: %s",
-						pluginName)
-				}
-			}
-		case "processors":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addProcessor(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", pluginName, err)
-						}
-					}
-				default:
-					return fmt.Errorf("Unsupported config format: %s",
-						pluginName)
-				}
-			}
-		case "aggregators":
-			for pluginName, pluginVal := range subTable.Fields {
-				switch pluginSubTable := pluginVal.(type) {
-				case []*ast.Table:
-					for _, t := range pluginSubTable {
-						if err = c.addAggregator(pluginName, t); err != nil {
-							return fmt.Errorf("Error parsing %s, %s", pluginName, err)
-						}
+		case "pipelines":
+			for pipelineName, pipelineVal := range subTable.Fields {
+				pipelineTable, ok := pipelineVal.(*ast.Table)
+				if !ok {
+					return fmt.Errorf("invalid configuration, error parsing pipeline %q", pipelineName)
+				}
+				if err = c.addPipeline(pipelineName, pipelineTable); err != nil {
+					if !c.CheckMode {
+						return fmt.Errorf("Error parsing pipeline %s, %s", pipelineName, err)
 					}
-				default:
-					return fmt.Errorf("Unsupported config format: %s",
-						pluginName)
+					c.problem(pipelineTable.Line, SeverityError, "pipeline %s: %v", pipelineName, err)
 				}
 			}
-		// Assume it's an input input for legacy config file support if no other
-		// identifiers are present
 		default:
-			if err = c.addInput(name, subTable); err != nil {
-				return fmt.Errorf("Error parsing %s, %s", name, err)
+			if err = c.addPlugins(name, subTable); err != nil {
+				return err
 			}
 		}
 	}
@@ -848,6 +906,86 @@ func (c *Config) LoadConfigData(data []byte) error {
 	return nil
 }
 
+// addPlugins adds the plugins of the section with the given name.
+func (c *Config) addPlugins(name string, subTable *ast.Table) error {
+	var err error
+	switch name {
+	case "outputs":
+		for pluginName, pluginVal := range subTable.Fields {
+			switch pluginSubTable := pluginVal.(type) {
+			// legacy [outputs.influxdb] support
+			case *ast.Table:
+				if err = c.add("outputs", pluginName, pluginSubTable, c.addOutput); err != nil {
+					return fmt.Errorf("Error parsing %s, %s", pluginName, err)
+				}
+			case []*ast.Table:
+				for _, t := range pluginSubTable {
+					if err = c.add("outputs", pluginName, t, c.addOutput); err != nil {
+						return fmt.Errorf("Error parsing %s array, %s", pluginName, err)
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s",
+					pluginName)
+			}
+		}
+	case "inputs", "plugins":
+		for pluginName, pluginVal := range subTable.Fields {
+			switch pluginSubTable := pluginVal.(type) {
+			// legacy [inputs.cpu] support
+			case *ast.Table:
+				if err = c.add("inputs", pluginName, pluginSubTable, c.addInput); err != nil {
+					return fmt.Errorf("Error parsing %s, %s", pluginName, err)
+				}
+			case []*ast.Table:
+				for _, t := range pluginSubTable {
+					if err = c.add("inputs", pluginName, t, c.addInput); err != nil {
+						return fmt.Errorf("Error parsing %s, %s", pluginName, err)
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s",
+					pluginName)
+			}
+		}
+	case "processors":
+		for pluginName, pluginVal := range subTable.Fields {
+			switch pluginSubTable := pluginVal.(type) {
+			case []*ast.Table:
+				for _, t := range pluginSubTable {
+					if err = c.add("processors", pluginName, t, c.addProcessor); err != nil {
+						return fmt.Errorf("Error parsing %s, %s", pluginName, err)
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s",
+					pluginName)
+			}
+		}
+	case "aggregators":
+		for pluginName, pluginVal := range subTable.Fields {
+			switch pluginSubTable := pluginVal.(type) {
+			case []*ast.Table:
+				for _, t := range pluginSubTable {
+					if err = c.add("aggregators", pluginName, t, c.addAggregator); err != nil {
+						return fmt.Errorf("Error parsing %s, %s", pluginName, err)
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s",
+					pluginName)
+			}
+		}
+	// Assume it's an input input for legacy config file support if no other
+	// identifiers are present
+	default:
+		if err = c.add("inputs", name, subTable, c.addInput); err != nil {
+			return fmt.Errorf("Error parsing %s, %s", name, err)
+		}
+	}
+	return nil
+}
+
 // trimBOM trims the Byte-Order-Marks from the beginning of the file.
 // this is for Windows compatibility only.
 // see https://github.com/influxdata/telegraf/issues/1378
@@ -938,16 +1076,22 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 	}
 	aggregator := creator()
 
+	id := pluginID(name, table)
 	conf, err := buildAggregator(name, table)
 	if err != nil {
 		return err
 	}
 
-	if err := toml.UnmarshalTable(table, aggregator); err != nil {
+	if err := c.unmarshalTable(table, aggregator); err != nil {
+		return err
+	}
+	if err := c.checkSecrets("aggregators."+name, aggregator); err != nil {
 		return err
 	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	ra := models.NewRunningAggregator(aggregator, conf)
+	ra.ID = id
+	c.Aggregators = append(c.Aggregators, ra)
 	return nil
 }
 
@@ -957,6 +1101,7 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested processor: %s", name)
 	}
 
+	id := pluginID(name, table)
 	processorConfig, err := buildProcessor(name, table)
 	if err != nil {
 		return err
@@ -966,6 +1111,7 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	rf.ID = id
 	c.Processors = append(c.Processors, rf)
 
 	// save a copy for the aggregator
@@ -973,6 +1119,7 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	rf.ID = id
 	c.AggProcessors = append(c.AggProcessors, rf)
 
 	return nil
@@ -987,15 +1134,30 @@ func (c *Config) newRunningProcessor(
 	processor := creator()
 
 	if p, ok := processor.(unwrappable); ok {
-		if err := toml.UnmarshalTable(table, p.Unwrap()); err != nil {
+		if err := c.unmarshalTable(table, p.Unwrap()); err != nil {
+			return nil, err
+		}
+		if err := c.checkSecrets("processors."+name, p.Unwrap()); err != nil {
 			return nil, err
 		}
 	} else {
-		if err := toml.UnmarshalTable(table, processor); err != nil {
+		if err := c.unmarshalTable(table, processor); err != nil {
+			return nil, err
+		}
+		if err := c.checkSecrets("processors."+name, processor); err != nil {
 			return nil, err
 		}
 	}
 
+	// Processors reporting internal metrics tag them with the alias.
+	var plugin interface{} = processor
+	if p, ok := processor.(unwrappable); ok {
+		plugin = p.Unwrap()
+	}
+	if t, ok := plugin.(interface{ SetAlias(string) }); ok {
+		t.SetAlias(processorConfig.Alias)
+	}
+
 	rf := models.NewRunningProcessor(processor, processorConfig)
 	return rf, nil
 }
@@ -1009,12 +1171,15 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested output: %s", name)
 	}
 	output := creator()
+	id := pluginID(name, table)
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
+	var serializer serializers.Serializer
 	switch t := output.(type) {
 	case serializers.SerializerOutput:
-		serializer, err := buildSerializer(name, table)
+		var err error
+		serializer, err = buildSerializer(name, table)
 		if err != nil {
 			return err
 		}
@@ -1025,17 +1190,70 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	outputConfig.Pipeline = c.Name
+
+	if err := c.setBufferConfig(outputConfig); err != nil {
+		return err
+	}
 
-	if err := toml.UnmarshalTable(table, output); err != nil {
+	if err := c.unmarshalTable(table, output); err != nil {
+		return err
+	}
+	if err := c.checkSecrets("outputs."+name, output); err != nil {
 		return err
 	}
 
+	// Outputs batching their requests tag the internal metrics of the
+	// batcher with the alias.
+	if t, ok := output.(interface{ SetAlias(string) }); ok {
+		t.SetAlias(outputConfig.Alias)
+	}
+
 	ro := models.NewRunningOutput(name, output, outputConfig,
 		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+	ro.ID = id
+	ro.Serializer = serializer
 	c.Outputs = append(c.Outputs, ro)
 	return nil
 }
 
+// setBufferConfig applies the agent buffer defaults to the output and checks
+// the buffer settings.
+func (c *Config) setBufferConfig(oc *models.OutputConfig) error {
+	if oc.BufferStrategy == "" {
+		oc.BufferStrategy = c.Agent.BufferStrategy
+	}
+	if oc.BufferDirectory == "" {
+		oc.BufferDirectory = c.Agent.BufferDirectory
+	}
+
+	switch oc.BufferStrategy {
+	case "", "memory":
+		oc.BufferStrategy = "memory"
+		return nil
+	case "disk":
+	default:
+		return fmt.Errorf("invalid buffer_strategy %q", oc.BufferStrategy)
+	}
+
+	if oc.BufferDirectory == "" {
+		return fmt.Errorf("buffer_directory is required for the disk buffer")
+	}
+
+	// The buffers of the outputs of all pipelines share the buffer directory.
+	root := c
+	if c.parent != nil {
+		root = c.parent
+	}
+	path := models.BufferPath(oc)
+	for _, ro := range root.AllOutputs() {
+		if ro.Config.BufferStrategy == "disk" && models.BufferPath(ro.Config) == path {
+			return fmt.Errorf("disk buffer %q already in use, set an alias on the output", path)
+		}
+	}
+	return nil
+}
+
 func (c *Config) addInput(name string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
@@ -1050,6 +1268,7 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested input: %s", name)
 	}
 	input := creator()
+	id := pluginID(name, table)
 
 	// If the input has a SetParser function, then this means it can accept
 	// arbitrary types of input, so build the parser and set it.
@@ -1076,16 +1295,72 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return err
 	}
 
-	if err := toml.UnmarshalTable(table, input); err != nil {
+	if err := c.unmarshalTable(table, input); err != nil {
+		return err
+	}
+	if err := c.checkSecrets("inputs."+name, input); err != nil {
 		return err
 	}
 
 	rp := models.NewRunningInput(input, pluginConfig)
+	rp.ID = id
 	rp.SetDefaultTags(c.Tags)
 	c.Inputs = append(c.Inputs, rp)
 	return nil
 }
 
+// pluginID returns an identifier made up of the plugin name and a hash of its
+// configuration table.  The hash does not depend on the order of the keys or
+// on formatting, so a plugin keeps its ID as long as its settings are the same.
+func pluginID(name string, tbl *ast.Table) string {
+	h := fnv.New64a()
+	hashTable(h, tbl)
+	return fmt.Sprintf("%s-%016x", name, h.Sum64())
+}
+
+func hashTable(w io.Writer, tbl *ast.Table) {
+	keys := make([]string, 0, len(tbl.Fields))
+	for key := range tbl.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch node := tbl.Fields[key].(type) {
+		case *ast.KeyValue:
+			fmt.Fprintf(w, "%q=", key)
+			hashValue(w, node.Value)
+			fmt.Fprint(w, "\n")
+		case *ast.Table:
+			fmt.Fprintf(w, "[%q]\n", key)
+			hashTable(w, node)
+			fmt.Fprint(w, "[]\n")
+		case []*ast.Table:
+			for _, t := range node {
+				fmt.Fprintf(w, "[[%q]]\n", key)
+				hashTable(w, t)
+				fmt.Fprint(w, "[]\n")
+			}
+		}
+	}
+}
+
+func hashValue(w io.Writer, value ast.Value) {
+	switch v := value.(type) {
+	case *ast.Array:
+		fmt.Fprint(w, "[")
+		for _, elem := range v.Value {
+			hashValue(w, elem)
+			fmt.Fprint(w, ",")
+		}
+		fmt.Fprint(w, "]")
+	case *ast.String:
+		fmt.Fprintf(w, "%q", v.Value)
+	default:
+		fmt.Fprint(w, value.Source())
+	}
+}
+
 // buildAggregator parses Aggregator specific items from the ast.Table,
 // builds the filter and returns a
 // models.AggregatorConfig to be inserted into models.RunningAggregator
@@ -1367,6 +1642,32 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 		return nil, err
 	}
 
+	if node, ok := tbl.Fields["schedule"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				schedule, err := internal.ParseSchedule(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				cp.Schedule = schedule
+				delete(tbl.Fields, "schedule")
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["active_window"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				window, err := internal.ParseTimeWindow(str.Value)
+				if err != nil {
+					return nil, err
+				}
+				cp.ActiveWindow = window
+				delete(tbl.Fields, "active_window")
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["name_prefix"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1828,6 +2129,239 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["prometheus_metric_version"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				c.PrometheusMetricVersion = int(v)
+			}
+		}
+	}
+
+	// The alias is left for the input settings.
+	if node, ok := tbl.Fields["alias"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.Alias = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["parse_error_mode"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ParseErrorMode = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["prometheus_ignore_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.PrometheusIgnoreTimestamp, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema_file"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchemaFile = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_schema_registry"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroSchemaRegistry = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_measurement"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroMeasurement = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_measurement_field"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroMeasurementField = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.AvroTags = append(c.AvroTags, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.AvroFields = append(c.AvroFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroTimestamp = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["avro_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.AvroTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_file"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufFile = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_import_paths"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.ProtobufImportPaths = append(c.ProtobufImportPaths, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_message_type"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufMessageType = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_confluent_wire_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.ProtobufConfluent, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_measurement"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufMeasurement = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_measurement_field"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufMeasurementField = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.ProtobufTags = append(c.ProtobufTags, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.ProtobufFields = append(c.ProtobufFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufTimestamp = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufTimestampFormat = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_v2"]; ok {
+		if subtbls, ok := node.([]*ast.Table); ok {
+			for _, subtbl := range subtbls {
+				var v2 json_v2.Config
+				if err := toml.UnmarshalTable(subtbl, &v2); err != nil {
+					return nil, fmt.Errorf("error parsing json_v2: %w", err)
+				}
+				c.JSONV2Config = append(c.JSONV2Config, v2)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["xml"]; ok {
+		if subtbls, ok := node.([]*ast.Table); ok {
+			for _, subtbl := range subtbls {
+				var xc xml.Config
+				if err := toml.UnmarshalTable(subtbl, &xc); err != nil {
+					return nil, fmt.Errorf("error parsing xml: %w", err)
+				}
+				c.XMLConfig = append(c.XMLConfig, xc)
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["form_urlencoded_tag_keys"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if ary, ok := kv.Value.(*ast.Array); ok {
@@ -1853,6 +2387,8 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	delete(tbl.Fields, "json_time_key")
 	delete(tbl.Fields, "json_timezone")
 	delete(tbl.Fields, "json_strict")
+	delete(tbl.Fields, "json_v2")
+	delete(tbl.Fields, "xml")
 	delete(tbl.Fields, "data_type")
 	delete(tbl.Fields, "collectd_auth_file")
 	delete(tbl.Fields, "collectd_security_level")
@@ -1884,6 +2420,27 @@ func getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	delete(tbl.Fields, "csv_timezone")
 	delete(tbl.Fields, "csv_trim_space")
 	delete(tbl.Fields, "form_urlencoded_tag_keys")
+	delete(tbl.Fields, "prometheus_metric_version")
+	delete(tbl.Fields, "prometheus_ignore_timestamp")
+	delete(tbl.Fields, "parse_error_mode")
+	delete(tbl.Fields, "avro_schema_file")
+	delete(tbl.Fields, "avro_schema_registry")
+	delete(tbl.Fields, "avro_measurement")
+	delete(tbl.Fields, "avro_measurement_field")
+	delete(tbl.Fields, "avro_tags")
+	delete(tbl.Fields, "avro_fields")
+	delete(tbl.Fields, "avro_timestamp")
+	delete(tbl.Fields, "avro_timestamp_format")
+	delete(tbl.Fields, "protobuf_file")
+	delete(tbl.Fields, "protobuf_import_paths")
+	delete(tbl.Fields, "protobuf_message_type")
+	delete(tbl.Fields, "protobuf_confluent_wire_format")
+	delete(tbl.Fields, "protobuf_measurement")
+	delete(tbl.Fields, "protobuf_measurement_field")
+	delete(tbl.Fields, "protobuf_tags")
+	delete(tbl.Fields, "protobuf_fields")
+	delete(tbl.Fields, "protobuf_timestamp")
+	delete(tbl.Fields, "protobuf_timestamp_format")
 
 	return c, nil
 }
@@ -2014,6 +2571,22 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["json_template"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONTemplate = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["json_batch_template"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.JSONBatchTemplate = str.Value
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["splunkmetric_hec_routing"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if b, ok := kv.Value.(*ast.Boolean); ok {
@@ -2098,6 +2671,46 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["csv_columns"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.CSVColumns = append(c.CSVColumns, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_delimiter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVDelimiter = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_header"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.CSVHeader, err = b.Boolean()
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["csv_timestamp_format"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.CSVTimestampFormat = str.Value
+			}
+		}
+	}
+
 	delete(tbl.Fields, "carbon2_format")
 	delete(tbl.Fields, "influx_max_line_bytes")
 	delete(tbl.Fields, "influx_sort_fields")
@@ -2109,6 +2722,8 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 	delete(tbl.Fields, "template")
 	delete(tbl.Fields, "templates")
 	delete(tbl.Fields, "json_timestamp_units")
+	delete(tbl.Fields, "json_template")
+	delete(tbl.Fields, "json_batch_template")
 	delete(tbl.Fields, "splunkmetric_hec_routing")
 	delete(tbl.Fields, "splunkmetric_multimetric")
 	delete(tbl.Fields, "wavefront_source_override")
@@ -2116,6 +2731,10 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 	delete(tbl.Fields, "prometheus_export_timestamp")
 	delete(tbl.Fields, "prometheus_sort_metrics")
 	delete(tbl.Fields, "prometheus_string_as_label")
+	delete(tbl.Fields, "csv_columns")
+	delete(tbl.Fields, "csv_delimiter")
+	delete(tbl.Fields, "csv_header")
+	delete(tbl.Fields, "csv_timestamp_format")
 	return serializers.NewSerializer(c)
 }
 
@@ -2142,6 +2761,18 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 		oc.Filter.NamePass = oc.Filter.FieldPass
 	}
 
+	if node, ok := tbl.Fields["routes"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						oc.Routes = append(oc.Routes, str.Value)
+					}
+				}
+			}
+		}
+	}
+
 	if err := getConfigDuration(tbl, "flush_interval", &oc.FlushInterval); err != nil {
 		return nil, err
 	}
@@ -2174,6 +2805,81 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["buffer_strategy"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.BufferStrategy = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["buffer_directory"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				oc.BufferDirectory = str.Value
+			}
+		}
+	}
+
+	if err := getConfigSize(tbl, "buffer_max_size", &oc.BufferMaxSize); err != nil {
+		return nil, err
+	}
+
+	if err := getConfigDuration(tbl, "retry_initial_delay", &oc.Retry.InitialDelay); err != nil {
+		return nil, err
+	}
+
+	if err := getConfigDuration(tbl, "retry_max_delay", &oc.Retry.MaxDelay); err != nil {
+		return nil, err
+	}
+
+	if err := getConfigDuration(tbl, "retry_jitter", &oc.Retry.Jitter); err != nil {
+		return nil, err
+	}
+
+	if node, ok := tbl.Fields["retry_multiplier"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			switch v := kv.Value.(type) {
+			case *ast.Float:
+				f, err := v.Float()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Multiplier = f
+			case *ast.Integer:
+				i, err := v.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.Multiplier = float64(i)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_threshold"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.CircuitBreakerThreshold = int(v)
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["circuit_breaker_probe_size"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := integer.Int()
+				if err != nil {
+					return nil, err
+				}
+				oc.Retry.CircuitBreakerProbeSize = int(v)
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["alias"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -2208,10 +2914,16 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 
 	delete(tbl.Fields, "metric_buffer_limit")
 	delete(tbl.Fields, "metric_batch_size")
+	delete(tbl.Fields, "buffer_strategy")
+	delete(tbl.Fields, "buffer_directory")
+	delete(tbl.Fields, "retry_multiplier")
+	delete(tbl.Fields, "circuit_breaker_threshold")
+	delete(tbl.Fields, "circuit_breaker_probe_size")
 	delete(tbl.Fields, "alias")
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "name_suffix")
 	delete(tbl.Fields, "name_prefix")
+	delete(tbl.Fields, "routes")
 
 	return oc, nil
 }
@@ -2238,3 +2950,26 @@ func getConfigDuration(tbl *ast.Table, key string, target *time.Duration) error
 	}
 	return nil
 }
+
+func getConfigSize(tbl *ast.Table, key string, target *int64) error {
+	if node, ok := tbl.Fields[key]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			var size internal.Size
+			switch v := kv.Value.(type) {
+			case *ast.String:
+				if err := size.UnmarshalTOML([]byte(strconv.Quote(v.Value))); err != nil {
+					return fmt.Errorf("invalid size for %s: %w", key, err)
+				}
+			case *ast.Integer:
+				if err := size.UnmarshalTOML([]byte(v.Value)); err != nil {
+					return fmt.Errorf("invalid size for %s: %w", key, err)
+				}
+			default:
+				return nil
+			}
+			delete(tbl.Fields, key)
+			*target = size.Size
+		}
+	}
+	return nil
+}
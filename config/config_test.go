@@ -1,20 +1,31 @@
 package config
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	_ "github.com/influxdata/telegraf/plugins/aggregators/minmax"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/exec"
 	"github.com/influxdata/telegraf/plugins/inputs/http_listener_v2"
 	"github.com/influxdata/telegraf/plugins/inputs/memcached"
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
 	"github.com/influxdata/telegraf/plugins/outputs/azure_monitor"
-	httpOut "github.com/influxdata/telegraf/plugins/outputs/http"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
+	"github.com/influxdata/telegraf/plugins/parsers/xml"
+	"github.com/influxdata/telegraf/plugins/processors"
+	_ "github.com/influxdata/telegraf/plugins/processors/rename"
+	"github.com/influxdata/toml"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -224,32 +235,6 @@ func TestConfig_WrongFieldType(t *testing.T) {
 	assert.Equal(t, "Error loading config file ./testdata/wrong_field_type2.toml: Error parsing http_listener_v2, line 2: (http_listener_v2.HTTPListenerV2.Methods) cannot unmarshal TOML string into []string", err.Error())
 }
 
-func TestConfig_InlineTables(t *testing.T) {
-	// #4098
-	c := NewConfig()
-	err := c.LoadConfig("./testdata/inline_table.toml")
-	assert.NoError(t, err)
-	require.Equal(t, 2, len(c.Outputs))
-
-	outputHTTP, ok := c.Outputs[1].Output.(*httpOut.HTTP)
-	assert.Equal(t, true, ok)
-	assert.Equal(t, map[string]string{"Authorization": "Token $TOKEN", "Content-Type": "application/json"}, outputHTTP.Headers)
-	assert.Equal(t, []string{"org_id"}, c.Outputs[0].Config.Filter.TagInclude)
-}
-
-func TestConfig_SliceComment(t *testing.T) {
-	t.Skipf("Skipping until #3642 is resolved")
-
-	c := NewConfig()
-	err := c.LoadConfig("./testdata/slice_comment.toml")
-	assert.NoError(t, err)
-	require.Equal(t, 1, len(c.Outputs))
-
-	outputHTTP, ok := c.Outputs[0].Output.(*httpOut.HTTP)
-	assert.Equal(t, []string{"test"}, outputHTTP.Scopes)
-	assert.Equal(t, true, ok)
-}
-
 func TestConfig_BadOrdering(t *testing.T) {
 	// #3444: when not using inline tables, care has to be taken so subsequent configuration
 	// doesn't become part of the table. This is not a bug, but TOML syntax.
@@ -278,3 +263,645 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	assert.Equal(t, "", azureMonitor.NamespacePrefix)
 	assert.Equal(t, true, ok)
 }
+
+func TestConfig_OutputDiskBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-buffer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	err = c.LoadConfigData([]byte(`
+[agent]
+  buffer_directory = "` + dir + `"
+
+[[outputs.http]]
+  buffer_strategy = "disk"
+  buffer_max_size = "16MiB"
+
+[[outputs.http]]
+  alias = "memory"
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Outputs, 2)
+
+	assert.Equal(t, "disk", c.Outputs[0].Config.BufferStrategy)
+	assert.Equal(t, dir, c.Outputs[0].Config.BufferDirectory)
+	assert.Equal(t, int64(16*1024*1024), c.Outputs[0].Config.BufferMaxSize)
+	assert.Equal(t, "memory", c.Outputs[1].Config.BufferStrategy)
+
+	c = NewConfig()
+	err = c.LoadConfigData([]byte(`
+[[outputs.http]]
+  buffer_strategy = "disk"
+  buffer_directory = "` + dir + `"
+
+[[outputs.http]]
+  buffer_strategy = "disk"
+  buffer_directory = "` + dir + `"
+`))
+	require.Error(t, err)
+}
+
+func TestConfig_PipelineDiskBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-buffer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	err = c.LoadConfigData([]byte(`
+[agent]
+  buffer_directory = "` + dir + `"
+
+[[outputs.http]]
+  buffer_strategy = "disk"
+
+[pipelines.infra]
+  [[pipelines.infra.outputs.http]]
+    buffer_strategy = "disk"
+
+[pipelines.app]
+  [[pipelines.app.outputs.http]]
+    buffer_strategy = "disk"
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Pipelines, 2)
+
+	assert.Equal(t, filepath.Join(dir, "http"), models.BufferPath(c.Outputs[0].Config))
+	for _, p := range c.Pipelines {
+		require.Len(t, p.Outputs, 1)
+		assert.Equal(t, p.Name, p.Outputs[0].Config.Pipeline)
+		assert.Equal(t, filepath.Join(dir, "pipelines", p.Name, "http"),
+			models.BufferPath(p.Outputs[0].Config))
+	}
+
+	c = NewConfig()
+	err = c.LoadConfigData([]byte(`
+[agent]
+  buffer_directory = "` + dir + `"
+
+[pipelines.infra]
+  [[pipelines.infra.outputs.http]]
+    buffer_strategy = "disk"
+`))
+	require.NoError(t, err)
+	err = c.LoadConfigData([]byte(`
+[pipelines.infra]
+  [[pipelines.infra.outputs.http]]
+    buffer_strategy = "disk"
+`))
+	require.Error(t, err)
+}
+
+func TestConfig_PluginID(t *testing.T) {
+	load := func(data string) *Config {
+		c := NewConfig()
+		require.NoError(t, c.LoadConfigData([]byte(data)))
+		return c
+	}
+
+	c := load(`
+[[inputs.memcached]]
+  servers = ["localhost", "otherhost"]
+  namepass = ["metricname1"]
+  [inputs.memcached.tags]
+    a = "b"
+
+[[inputs.memcached]]
+  namepass = ["metricname1"]
+  servers = [ "localhost",
+              "otherhost" ]
+  [inputs.memcached.tags]
+    a = "b"
+
+[[inputs.memcached]]
+  servers = ["localhost"]
+  namepass = ["metricname1"]
+  [inputs.memcached.tags]
+    a = "b"
+
+[[inputs.memcached]]
+  servers = ["localhost", "otherhost"]
+  namepass = ["metricname1"]
+  [inputs.memcached.tags]
+    a = "c"
+`)
+	require.Len(t, c.Inputs, 4)
+	assert.True(t, strings.HasPrefix(c.Inputs[0].ID, "memcached-"))
+	assert.Equal(t, c.Inputs[0].ID, c.Inputs[1].ID)
+	assert.NotEqual(t, c.Inputs[0].ID, c.Inputs[2].ID)
+	assert.NotEqual(t, c.Inputs[0].ID, c.Inputs[3].ID)
+
+	c = load(`
+[[processors.rename]]
+  order = 1
+
+[[aggregators.minmax]]
+  period = "30s"
+
+[[outputs.http]]
+  url = "http://localhost"
+`)
+	other := load(`
+[[processors.rename]]
+  order = 2
+
+[[aggregators.minmax]]
+  period = "30s"
+
+[[outputs.http]]
+  url = "http://localhost"
+`)
+	assert.NotEqual(t, c.Processors[0].ID, other.Processors[0].ID)
+	assert.Equal(t, c.Processors[0].ID, c.AggProcessors[0].ID)
+	assert.Equal(t, c.Aggregators[0].ID, other.Aggregators[0].ID)
+	assert.Equal(t, c.Outputs[0].ID, other.Outputs[0].ID)
+}
+
+type aliasTestProcessor struct {
+	alias string
+}
+
+func (*aliasTestProcessor) SampleConfig() string { return "" }
+func (*aliasTestProcessor) Description() string  { return "" }
+func (*aliasTestProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	return in
+}
+func (p *aliasTestProcessor) SetAlias(alias string) { p.alias = alias }
+
+func TestConfig_ProcessorAlias(t *testing.T) {
+	processors.Add("alias_test", func() telegraf.Processor { return &aliasTestProcessor{} })
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[processors.alias_test]]
+  alias = "hosts"
+`)))
+	require.Len(t, c.Processors, 1)
+	require.Len(t, c.AggProcessors, 1)
+
+	for _, rp := range []*models.RunningProcessor{c.Processors[0], c.AggProcessors[0]} {
+		p := rp.Processor.(unwrappable).Unwrap().(*aliasTestProcessor)
+		assert.Equal(t, "hosts", p.alias)
+	}
+}
+
+type checkTestInput struct {
+	Servers []string `toml:"servers"`
+	Address string   `toml:"address" deprecated:"1.2.0;use 'servers' instead"`
+}
+
+func (*checkTestInput) SampleConfig() string              { return "" }
+func (*checkTestInput) Description() string               { return "" }
+func (*checkTestInput) Gather(telegraf.Accumulator) error { return nil }
+func (i *checkTestInput) Init() error {
+	if len(i.Servers) == 0 {
+		return errors.New("no servers configured")
+	}
+	return nil
+}
+
+func TestConfig_Check(t *testing.T) {
+	inputs.Add("check_test", func() telegraf.Input { return &checkTestInput{} })
+
+	f, err := ioutil.TempFile("", "check")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`[agent]
+  interval = "10s"
+  unknown = true
+
+[[inputs.check_test]]
+  servers = ["a"]
+  timout = "5s"
+  address = "b"
+  interval = 10
+  namepass = ["cpu"]
+  namedrop = ["cpu*"]
+  [inputs.check_test.tagpass]
+    host = "a"
+
+[[inputs.check_test]]
+
+[[outputs.http]]
+  flush_interval = "10 seconds"
+  buffer_max_size = "lots"
+`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c := NewConfig()
+	c.CheckMode = true
+	require.NoError(t, c.LoadConfig(f.Name()))
+	require.Len(t, c.Inputs, 2)
+	require.Len(t, c.Outputs, 1)
+
+	var problems []string
+	for _, p := range c.Problems {
+		require.Equal(t, f.Name(), p.File)
+		problems = append(problems, strings.TrimPrefix(p.String(), f.Name()+":"))
+	}
+	require.ElementsMatch(t, []string{
+		`3: error: [agent] unknown option "unknown"`,
+		`7: error: [inputs.check_test] unknown option "timout"`,
+		`8: warning: [inputs.check_test] option "address" is deprecated since 1.2.0, use 'servers' instead`,
+		`9: error: [inputs.check_test] invalid duration for "interval", use a string such as "10s"`,
+		`13: error: [inputs.check_test] tagpass value for tag "host" must be an array of strings`,
+		`5: warning: [inputs.check_test] namepass "cpu" never matches, it is dropped by namedrop`,
+		`5: warning: [inputs.check_test] tagpass for tag "host" has no values and never matches`,
+		`15: error: [inputs.check_test] initializing plugin: no servers configured`,
+		`18: error: [outputs.http] invalid duration for "flush_interval": time: unknown unit " seconds" in duration "10 seconds"`,
+		`19: error: [outputs.http] invalid size for "buffer_max_size": units: invalid lots`,
+	}, problems)
+
+	// Without check mode loading stops at the first error.
+	c = NewConfig()
+	require.Error(t, c.LoadConfig(f.Name()))
+}
+
+func TestConfig_InputSchedule(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  schedule = "*/15 8-17 * * Mon-Fri"
+  active_window = "Mon-Fri 08:00-18:00"
+[[inputs.memcached]]
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Inputs, 2)
+	require.NotNil(t, c.Inputs[0].Config.Schedule)
+	require.NotNil(t, c.Inputs[0].Config.ActiveWindow)
+	require.Nil(t, c.Inputs[1].Config.Schedule)
+	require.Nil(t, c.Inputs[1].Config.ActiveWindow)
+
+	for _, data := range []string{
+		`[[inputs.memcached]]
+  schedule = "* * *"`,
+		`[[inputs.memcached]]
+  active_window = "18:00"`,
+	} {
+		c := NewConfig()
+		require.Error(t, c.LoadConfigData([]byte(data)), data)
+	}
+}
+
+func TestConfig_Router(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfigData([]byte(`
+[router]
+  route_tag = "_sumo_route"
+  remove_route_tag = true
+  default = "other"
+
+  [[router.routes]]
+    name = "cpu"
+    namepass = ["cpu*"]
+
+  [[router.routes]]
+    name = "tenant"
+    [router.routes.tags]
+      tenant = ["a", "b"]
+
+[[outputs.http]]
+  routes = ["cpu", "tenant"]
+[[outputs.http]]
+`))
+	require.NoError(t, err)
+	require.NotNil(t, c.Router)
+	require.Equal(t, &models.RouterConfig{
+		RouteTag:       "_sumo_route",
+		RemoveRouteTag: true,
+		Default:        "other",
+		Routes: []*models.RouteConfig{
+			{Name: "cpu", NamePass: []string{"cpu*"}},
+			{Name: "tenant", Tags: []models.TagFilter{{Name: "tenant", Filter: []string{"a", "b"}}}},
+		},
+	}, c.Router.Config)
+
+	require.Len(t, c.Outputs, 2)
+	require.Equal(t, []string{"cpu", "tenant"}, c.Outputs[0].Config.Routes)
+	require.Nil(t, c.Outputs[1].Config.Routes)
+
+	for _, data := range []string{
+		`[router]
+  unknown = 1`,
+		`[router]
+  [[router.routes]]
+    name = "a"
+  [[router.routes]]
+    name = "a"`,
+	} {
+		c := NewConfig()
+		require.Error(t, c.LoadConfigData([]byte(data)), data)
+	}
+}
+
+func TestConfig_Pipelines(t *testing.T) {
+	c := NewConfig()
+	c.Agent.OmitHostname = true
+	err := c.LoadConfigData([]byte(`
+[global_tags]
+  dc = "us-east-1"
+
+[[inputs.memcached]]
+
+[pipelines.infra]
+  interval = "30s"
+  flush_interval = "1m"
+  [pipelines.infra.tags]
+    team = "infra"
+  [[pipelines.infra.inputs.memcached]]
+  [[pipelines.infra.outputs.http]]
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Inputs, 1)
+	require.Len(t, c.Pipelines, 1)
+
+	p := c.Pipelines[0]
+	require.Equal(t, "infra", p.Name)
+	require.Equal(t, 30*time.Second, p.Agent.Interval.Duration)
+	require.Equal(t, time.Minute, p.Agent.FlushInterval.Duration)
+	require.Equal(t, 10*time.Second, c.Agent.Interval.Duration)
+	require.Equal(t, map[string]string{"dc": "us-east-1", "team": "infra"}, p.Tags)
+	require.Equal(t, map[string]string{"dc": "us-east-1"}, c.Tags)
+	require.Len(t, p.Inputs, 1)
+	require.Len(t, p.Outputs, 1)
+	require.Len(t, c.AllInputs(), 2)
+	require.Len(t, c.AllOutputs(), 1)
+
+	// A pipeline defined in several files is merged.
+	err = c.LoadConfigData([]byte(`
+[pipelines.infra]
+  [[pipelines.infra.inputs.memcached]]
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Pipelines, 1)
+	require.Len(t, p.Inputs, 2)
+
+	for _, data := range []string{
+		`[pipelines.infra]
+  interval = "0s"`,
+		`[pipelines.infra]
+  [pipelines.infra.agent]
+    debug = true`,
+	} {
+		c := NewConfig()
+		require.Error(t, c.LoadConfigData([]byte(data)), data)
+	}
+}
+
+func TestConfig_ParserJSONV2(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "json_v2"
+[[json_v2]]
+  measurement_name = "books"
+  timestamp_path = "updated"
+  timestamp_format = "unix"
+  [[json_v2.field]]
+    path = "stats.visitors"
+    type = "int"
+  [[json_v2.tag]]
+    path = "library"
+    rename = "name"
+  [[json_v2.object]]
+    path = "books"
+    tags = ["title"]
+    excluded_keys = ["author_born"]
+    [json_v2.object.fields]
+      published = "int"
+    [json_v2.object.renames]
+      author_name = "author"
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("file", tbl)
+	require.NoError(t, err)
+	require.Equal(t, []json_v2.Config{{
+		MeasurementName: "books",
+		TimestampPath:   "updated",
+		TimestampFormat: "unix",
+		Fields:          []json_v2.DataSet{{Path: "stats.visitors", Type: "int"}},
+		Tags:            []json_v2.DataSet{{Path: "library", Rename: "name"}},
+		Objects: []json_v2.Object{{
+			Path:         "books",
+			Tags:         []string{"title"},
+			ExcludedKeys: []string{"author_born"},
+			Fields:       map[string]string{"published": "int"},
+			Renames:      map[string]string{"author_name": "author"},
+		}},
+	}}, c.JSONV2Config)
+	require.NotContains(t, tbl.Fields, "json_v2")
+
+	_, err = parsers.NewParser(c)
+	require.NoError(t, err)
+}
+
+func TestConfig_ParserXML(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "xml"
+[[xml]]
+  metric_selection = "//host"
+  timestamp = "@updated"
+  timestamp_format = "unix"
+  [xml.tags]
+    name = "@name"
+  [xml.fields]
+    load = "load"
+  [xml.field_types]
+    load = "float"
+  [xml.namespaces]
+    ns = "urn:example"
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("file", tbl)
+	require.NoError(t, err)
+	require.Equal(t, []xml.Config{{
+		Selection:       "//host",
+		Timestamp:       "@updated",
+		TimestampFormat: "unix",
+		Tags:            map[string]string{"name": "@name"},
+		Fields:          map[string]string{"load": "load"},
+		FieldTypes:      map[string]string{"load": "float"},
+		Namespaces:      map[string]string{"ns": "urn:example"},
+	}}, c.XMLConfig)
+	require.NotContains(t, tbl.Fields, "xml")
+
+	_, err = parsers.NewParser(c)
+	require.NoError(t, err)
+}
+
+func TestConfig_ParserPrometheus(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "prometheus"
+prometheus_metric_version = 2
+prometheus_ignore_timestamp = true
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("file", tbl)
+	require.NoError(t, err)
+	require.Equal(t, 2, c.PrometheusMetricVersion)
+	require.True(t, c.PrometheusIgnoreTimestamp)
+	require.Empty(t, tbl.Fields)
+
+	_, err = parsers.NewParser(c)
+	require.NoError(t, err)
+
+	c.PrometheusMetricVersion = 3
+	_, err = parsers.NewParser(c)
+	require.Error(t, err)
+}
+
+func TestConfig_ParserAvro(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "avro"
+avro_schema_file = "../plugins/parsers/avro/testdata/reading.avsc"
+avro_measurement = "reading"
+avro_tags = ["sensor"]
+avro_fields = ["temperature", "humidity"]
+avro_timestamp = "time"
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("file", tbl)
+	require.NoError(t, err)
+	require.Equal(t, "../plugins/parsers/avro/testdata/reading.avsc", c.AvroSchemaFile)
+	require.Equal(t, "reading", c.AvroMeasurement)
+	require.Equal(t, []string{"sensor"}, c.AvroTags)
+	require.Equal(t, []string{"temperature", "humidity"}, c.AvroFields)
+	require.Equal(t, "time", c.AvroTimestamp)
+	require.Empty(t, tbl.Fields)
+
+	_, err = parsers.NewParser(c)
+	require.NoError(t, err)
+
+	c.AvroSchemaRegistry = "http://localhost:8081"
+	_, err = parsers.NewParser(c)
+	require.Error(t, err)
+}
+
+func TestConfig_ParserProtobuf(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "protobuf"
+protobuf_file = "../plugins/parsers/protobuf/testdata/reading.proto"
+protobuf_import_paths = ["/usr/include"]
+protobuf_message_type = "example.Reading"
+protobuf_confluent_wire_format = true
+protobuf_measurement_field = "sensor"
+protobuf_tags = ["location_site"]
+protobuf_timestamp = "time_ms"
+protobuf_timestamp_format = "unix_ms"
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("file", tbl)
+	require.NoError(t, err)
+	require.Equal(t, "../plugins/parsers/protobuf/testdata/reading.proto", c.ProtobufFile)
+	require.Equal(t, []string{"/usr/include"}, c.ProtobufImportPaths)
+	require.Equal(t, "example.Reading", c.ProtobufMessageType)
+	require.True(t, c.ProtobufConfluent)
+	require.Equal(t, "sensor", c.ProtobufMeasurementField)
+	require.Equal(t, []string{"location_site"}, c.ProtobufTags)
+	require.Equal(t, "time_ms", c.ProtobufTimestamp)
+	require.Equal(t, "unix_ms", c.ProtobufTimestampFormat)
+	require.Empty(t, tbl.Fields)
+
+	_, err = parsers.NewParser(c)
+	require.NoError(t, err)
+}
+
+func TestConfig_SerializerCSV(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "csv"
+csv_columns = ["timestamp", "measurement", "tag.host", "field.value"]
+csv_delimiter = ";"
+csv_header = true
+csv_timestamp_format = "unix_ms"
+`))
+	require.NoError(t, err)
+
+	s, err := buildSerializer("file", tbl)
+	require.NoError(t, err)
+	require.Empty(t, tbl.Fields)
+
+	m, err := metric.New("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(1600000000, 0))
+	require.NoError(t, err)
+
+	buf, err := s.SerializeBatch([]telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Equal(t, "timestamp;measurement;host;value\n1600000000000;cpu;a;42\n", string(buf))
+}
+
+func TestConfig_ParserMsgpack(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "msgpack"
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("file", tbl)
+	require.NoError(t, err)
+	require.Empty(t, tbl.Fields)
+
+	_, err = parsers.NewParser(c)
+	require.NoError(t, err)
+}
+
+func TestConfig_ParseErrorMode(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "influx"
+parse_error_mode = "route"
+`))
+	require.NoError(t, err)
+
+	c, err := getParserConfig("tail", tbl)
+	require.NoError(t, err)
+	require.Empty(t, tbl.Fields)
+	require.Equal(t, "route", c.ParseErrorMode)
+
+	p, err := parsers.NewParser(c)
+	require.NoError(t, err)
+	metrics, err := p.Parse([]byte("not line protocol"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, "tail", metrics[0].Name())
+	require.Equal(t, map[string]string{"parse_error": "influx"}, metrics[0].Tags())
+
+	c.ParseErrorMode = "ignore"
+	_, err = parsers.NewParser(c)
+	require.Error(t, err)
+}
+
+func TestConfig_SerializerJSONTemplate(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "json"
+json_timestamp_units = "1ms"
+json_template = '''
+{{- range .FieldList }}
+{"metric": {{ json (printf "%s.%s" $.Name .Key) }}, "value": {{ json .Value }}, "timestamp": {{ $.Timestamp }}}
+{{- end }}
+'''
+json_batch_template = '{"count": {{ len .Metrics }}}'
+`))
+	require.NoError(t, err)
+
+	s, err := buildSerializer("http", tbl)
+	require.NoError(t, err)
+	require.Empty(t, tbl.Fields)
+
+	m, err := metric.New("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 91.5},
+		time.Unix(1600000000, 0))
+	require.NoError(t, err)
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, `{"metric":"cpu.usage_idle","value":91.5,"timestamp":1600000000000}`+"\n", string(buf))
+
+	buf, err = s.SerializeBatch([]telegraf.Metric{m, m})
+	require.NoError(t, err)
+	require.Equal(t, `{"count":2}`+"\n", string(buf))
+}
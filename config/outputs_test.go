@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/inputs/memcached"
+	httpOut "github.com/influxdata/telegraf/plugins/outputs/http"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests of the http output are kept out of package config, the output
+// depends on it.
+
+func TestConfig_InlineTables(t *testing.T) {
+	// #4098
+	c := config.NewConfig()
+	err := c.LoadConfig("./testdata/inline_table.toml")
+	assert.NoError(t, err)
+	require.Equal(t, 2, len(c.Outputs))
+
+	outputHTTP, ok := c.Outputs[1].Output.(*httpOut.HTTP)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, map[string]string{"Authorization": "Token $TOKEN", "Content-Type": "application/json"}, outputHTTP.Headers)
+	assert.Equal(t, []string{"org_id"}, c.Outputs[0].Config.Filter.TagInclude)
+}
+
+func TestConfig_SliceComment(t *testing.T) {
+	t.Skipf("Skipping until #3642 is resolved")
+
+	c := config.NewConfig()
+	err := c.LoadConfig("./testdata/slice_comment.toml")
+	assert.NoError(t, err)
+	require.Equal(t, 1, len(c.Outputs))
+
+	outputHTTP, ok := c.Outputs[0].Output.(*httpOut.HTTP)
+	assert.Equal(t, []string{"test"}, outputHTTP.Scopes)
+	assert.Equal(t, true, ok)
+}
+
+func TestConfig_OutputBatchingAlias(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[outputs.http]]
+  alias = "metrics"
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Outputs, 1)
+	assert.Equal(t, "metrics", c.Outputs[0].Output.(*httpOut.HTTP).Alias)
+}
+
+func TestConfig_OutputRetry(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[outputs.http]]
+  retry_initial_delay = "1s"
+  retry_max_delay = "1m"
+  retry_multiplier = 1.5
+  retry_jitter = "100ms"
+  circuit_breaker_threshold = 5
+  circuit_breaker_probe_size = 20
+  non_retryable_status_codes = [400, 404]
+
+[[outputs.http]]
+  alias = "defaults"
+  retry_multiplier = 3
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Outputs, 2)
+
+	assert.Equal(t, models.RetryConfig{
+		InitialDelay:            time.Second,
+		MaxDelay:                time.Minute,
+		Multiplier:              1.5,
+		Jitter:                  100 * time.Millisecond,
+		CircuitBreakerThreshold: 5,
+		CircuitBreakerProbeSize: 20,
+	}, c.Outputs[0].Config.Retry)
+	assert.Equal(t, []int{400, 404}, c.Outputs[0].Output.(*httpOut.HTTP).NonRetryableStatusCodes)
+
+	assert.Equal(t, models.RetryConfig{Multiplier: 3}, c.Outputs[1].Config.Retry)
+	assert.Equal(t, []int{400, 413, 422}, c.Outputs[1].Output.(*httpOut.HTTP).NonRetryableStatusCodes)
+
+	c = config.NewConfig()
+	err = c.LoadConfigData([]byte(`
+[[outputs.http]]
+  retry_initial_delay = "soon"
+`))
+	require.Error(t, err)
+}
+
+type testSecretStore map[string]string
+
+func (s testSecretStore) SampleConfig() string { return "" }
+func (s testSecretStore) Description() string  { return "" }
+func (s testSecretStore) List() ([]string, error) {
+	return nil, nil
+}
+func (s testSecretStore) Set(key string, value []byte) error {
+	s[key] = string(value)
+	return nil
+}
+func (s testSecretStore) Get(key string) ([]byte, error) {
+	value, ok := s[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(value), nil
+}
+
+func TestConfig_SecretStores(t *testing.T) {
+	store := testSecretStore{"host": "localhost:11211", "password": "secret"}
+	secretstores.Add("config_test", func() telegraf.SecretStore { return store })
+
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[secretstores.config_test]]
+  id = "test"
+
+[[outputs.http]]
+  password = "@{test:password}"
+`))
+	require.NoError(t, err)
+	require.Contains(t, c.SecretStores, "test")
+	c.RegisterSecretStores()
+
+	// Secrets are resolved when used, so they can change without a reload.
+	output := c.Outputs[0].Output.(*httpOut.HTTP)
+	require.Equal(t, "<secret>", output.Password.String())
+	store["password"] = "rotated"
+	password, err := output.Password.Get()
+	require.NoError(t, err)
+	require.Equal(t, "rotated", password)
+
+	for _, data := range []string{
+		`[[secretstores.config_test]]`,
+		`[[secretstores.config_test]]
+  id = "a:b"`,
+		`[[secretstores.config_test]]
+  id = "dup"
+[[secretstores.config_test]]
+  id = "dup"`,
+		`[[outputs.http]]
+  password = "@{unknown:password}"`,
+	} {
+		c := config.NewConfig()
+		require.Error(t, c.LoadConfigData([]byte(data)), data)
+	}
+
+	// References in other settings are used as is.
+	c = config.NewConfig()
+	err = c.LoadConfigData([]byte(`
+[[secretstores.config_test]]
+  id = "test"
+
+[[inputs.memcached]]
+  servers = ["@{test:host}"]
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"@{test:host}"},
+		c.Inputs[0].Input.(*memcached.Memcached).Servers)
+}
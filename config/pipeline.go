@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+)
+
+// pipeline returns the pipeline with the given name, a new pipeline starts
+// with a copy of the agent settings and the global tags.
+func (c *Config) pipeline(name string) *Config {
+	for _, p := range c.Pipelines {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	agent := *c.Agent
+	p := &Config{
+		Name:          name,
+		Agent:         &agent,
+		Tags:          make(map[string]string, len(c.Tags)),
+		Inputs:        make([]*models.RunningInput, 0),
+		Outputs:       make([]*models.RunningOutput, 0),
+		Processors:    make([]*models.RunningProcessor, 0),
+		AggProcessors: make([]*models.RunningProcessor, 0),
+		InputFilters:  c.InputFilters,
+		OutputFilters: c.OutputFilters,
+		SecretStores:  c.SecretStores,
+		CheckMode:     c.CheckMode,
+		parent:        c,
+	}
+	for k, v := range c.Tags {
+		p.Tags[k] = v
+	}
+	c.Pipelines = append(c.Pipelines, p)
+	return p
+}
+
+// addPipeline adds the plugins and settings of a [pipelines.<name>] section.
+// A pipeline may be defined in several files.
+func (c *Config) addPipeline(name string, tbl *ast.Table) error {
+	p := c.pipeline(name)
+	p.file = c.file
+	defer func() {
+		c.Problems = append(c.Problems, p.Problems...)
+		p.Problems = nil
+	}()
+
+	if p.CheckMode {
+		p.checkConfig(tbl)
+	}
+
+	if val, ok := tbl.Fields["tags"]; ok {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, bad table name %q", "tags")
+		}
+		if err := toml.UnmarshalTable(subTable, p.Tags); err != nil {
+			return fmt.Errorf("error parsing table name %q: %w", "tags", err)
+		}
+		delete(tbl.Fields, "tags")
+	}
+
+	if err := getConfigDuration(tbl, "interval", &p.Agent.Interval.Duration); err != nil {
+		return err
+	}
+	if err := getConfigDuration(tbl, "flush_interval", &p.Agent.FlushInterval.Duration); err != nil {
+		return err
+	}
+	if p.Agent.Interval.Duration <= 0 {
+		return fmt.Errorf("interval must be positive, found %s", p.Agent.Interval.Duration)
+	}
+	if p.Agent.FlushInterval.Duration <= 0 {
+		return fmt.Errorf("flush_interval must be positive, found %s", p.Agent.FlushInterval.Duration)
+	}
+
+	for name, val := range tbl.Fields {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing field %q as table", name)
+		}
+
+		switch name {
+		case "inputs", "outputs", "processors", "aggregators":
+			if err := p.addPlugins(name, subTable); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported section %q in pipeline", name)
+		}
+	}
+
+	if len(p.Processors) > 1 {
+		sort.Sort(p.Processors)
+	}
+	return nil
+}
+
+// AllInputs returns the inputs of the configuration and of its pipelines.
+func (c *Config) AllInputs() []*models.RunningInput {
+	inputs := c.Inputs
+	for _, p := range c.Pipelines {
+		inputs = append(inputs[:len(inputs):len(inputs)], p.Inputs...)
+	}
+	return inputs
+}
+
+// AllOutputs returns the outputs of the configuration and of its pipelines.
+func (c *Config) AllOutputs() []*models.RunningOutput {
+	outputs := c.Outputs
+	for _, p := range c.Pipelines {
+		outputs = append(outputs[:len(outputs):len(outputs)], p.Outputs...)
+	}
+	return outputs
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/toml/ast"
+)
+
+// routerTable is the [router] section.
+type routerTable struct {
+	RouteTag       string       `toml:"route_tag"`
+	RemoveRouteTag bool         `toml:"remove_route_tag"`
+	Default        string       `toml:"default"`
+	Routes         []routeTable `toml:"routes"`
+}
+
+type routeTable struct {
+	Name     string              `toml:"name"`
+	NamePass []string            `toml:"namepass"`
+	Tags     map[string][]string `toml:"tags"`
+	Source   string              `toml:"source"`
+	Script   string              `toml:"script"`
+}
+
+// addRouter sets the router defined by the [router] section.  Only one
+// router can be configured.
+func (c *Config) addRouter(tbl *ast.Table) error {
+	if c.Router != nil {
+		return fmt.Errorf("router already configured")
+	}
+
+	var rt routerTable
+	if err := c.unmarshalTable(tbl, &rt); err != nil {
+		return err
+	}
+
+	rc := &models.RouterConfig{
+		RouteTag:       rt.RouteTag,
+		RemoveRouteTag: rt.RemoveRouteTag,
+		Default:        rt.Default,
+	}
+	names := make(map[string]bool)
+	for _, route := range rt.Routes {
+		if names[route.Name] {
+			return fmt.Errorf("duplicate route %q", route.Name)
+		}
+		names[route.Name] = true
+
+		config := &models.RouteConfig{
+			Name:     route.Name,
+			NamePass: route.NamePass,
+			Source:   route.Source,
+			Script:   route.Script,
+		}
+		for name, values := range route.Tags {
+			config.Tags = append(config.Tags, models.TagFilter{Name: name, Filter: values})
+		}
+		sort.Slice(config.Tags, func(i, j int) bool {
+			return config.Tags[i].Name < config.Tags[j].Name
+		})
+		rc.Routes = append(rc.Routes, config)
+	}
+
+	router := models.NewRouter(rc)
+	if c.CheckMode {
+		if err := router.Init(); err != nil {
+			return err
+		}
+	}
+	c.Router = router
+	return nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"github.com/influxdata/toml/ast"
+)
+
+// secretStoreIDRe matches the valid secret store identifiers.
+var secretStoreIDRe = regexp.MustCompile(`^\w[\w.-]*$`)
+
+func (c *Config) addSecretStore(name string, table *ast.Table) error {
+	creator, ok := secretstores.SecretStores[name]
+	if !ok {
+		return fmt.Errorf("Undefined but requested secretstore: %s", name)
+	}
+	store := creator()
+
+	var id string
+	if node, ok := table.Fields["id"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				id = str.Value
+			}
+		}
+	}
+	delete(table.Fields, "id")
+	if !secretStoreIDRe.MatchString(id) {
+		return fmt.Errorf("invalid or missing id %q", id)
+	}
+	if _, ok := c.SecretStores[id]; ok {
+		return fmt.Errorf("duplicate secret store id %q", id)
+	}
+
+	if err := c.unmarshalTable(table, store); err != nil {
+		return err
+	}
+	if err := c.checkSecrets("secretstores."+name, store); err != nil {
+		return err
+	}
+
+	if t, ok := store.(telegraf.Initializer); ok {
+		if err := t.Init(); err != nil {
+			return err
+		}
+	}
+
+	c.SecretStores[id] = store
+	if c.CheckMode {
+		// The plugins are initialized while checking the configuration,
+		// they may resolve secrets.
+		internal.RegisterSecretStore(id, store)
+	}
+	return nil
+}
+
+// RegisterSecretStores makes the secret stores of the configuration
+// available to resolve the secrets of the plugins.  The stores are not
+// registered when the configuration is loaded, so that loading a
+// configuration does not affect the running plugins.
+func (c *Config) RegisterSecretStores() {
+	for id, store := range c.SecretStores {
+		internal.RegisterSecretStore(id, store)
+	}
+}
+
+var secretType = reflect.TypeOf(internal.Secret{})
+
+// checkSecrets checks the secret references in the settings of a plugin.
+// Only settings of type internal.Secret support references, they are
+// resolved by the plugin each time they are used.  References to secret
+// stores not defined in the configuration are rejected.  A warning is logged
+// for references in any other string setting, which are used as is.
+func (c *Config) checkSecrets(name string, plugin interface{}) error {
+	return c.checkValue(reflect.ValueOf(plugin), name, make(map[uintptr]bool))
+}
+
+func (c *Config) checkValue(v reflect.Value, path string, seen map[uintptr]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return nil
+		}
+		seen[v.Pointer()] = true
+		return c.checkValue(v.Elem(), path, seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return c.checkValue(v.Elem(), path, seen)
+	case reflect.Struct:
+		if v.Type() == secretType {
+			for _, id := range v.Interface().(internal.Secret).StoreIDs() {
+				if _, ok := c.SecretStores[id]; !ok {
+					return fmt.Errorf("unknown secret store %q", id)
+				}
+			}
+			return nil
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			fieldPath := path
+			if !field.Anonymous {
+				fieldPath = path + "." + settingName(field)
+			}
+			if err := c.checkValue(v.Field(i), fieldPath, seen); err != nil {
+				if field.Anonymous {
+					return err
+				}
+				return fmt.Errorf("%s: %w", settingName(field), err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := c.checkValue(v.Index(i), path, seen); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if err := c.checkValue(v.MapIndex(key), path, seen); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if internal.HasSecretRefs(v.String()) {
+			log.Printf("W! [config] Setting %s does not support secret references, "+
+				"the value is used as is", path)
+		}
+	}
+	return nil
+}
+
+// settingName returns the name of the setting of a struct field.
+func settingName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("toml"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
@@ -0,0 +1,48 @@
+package internal
+
+import "errors"
+
+// NonRetryableError is returned by an output when writing a batch failed in a
+// way that sending the same batch again cannot fix, for example when the
+// server rejected the data as invalid.  The batch is dropped instead of being
+// kept in the buffer.
+type NonRetryableError struct {
+	Err error
+}
+
+// NewNonRetryableError wraps err to mark it as not retryable.
+func NewNonRetryableError(err error) error {
+	return &NonRetryableError{Err: err}
+}
+
+func (e *NonRetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsNonRetryable returns true if err or any error it wraps is a
+// NonRetryableError.
+func IsNonRetryable(err error) bool {
+	var nre *NonRetryableError
+	return errors.As(err, &nre)
+}
+
+// DefaultNonRetryableStatusCodes are the HTTP status codes of responses
+// refusing the payload itself, sending the same request again fails the same
+// way.  Other client errors, such as an authentication failure during a
+// credential rotation, can be fixed on the server side and are retried.
+var DefaultNonRetryableStatusCodes = []int{400, 413, 422}
+
+// HTTPStatusError marks err, returned for a response with the given status
+// code, as not retryable if the code is one of nonRetryable.
+func HTTPStatusError(err error, code int, nonRetryable []int) error {
+	for _, c := range nonRetryable {
+		if c == code {
+			return NewNonRetryableError(err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPStatusError(t *testing.T) {
+	err := errors.New("received status code")
+
+	for _, code := range []int{400, 413, 422} {
+		require.True(t, IsNonRetryable(HTTPStatusError(err, code, DefaultNonRetryableStatusCodes)), code)
+	}
+	for _, code := range []int{401, 403, 404, 408, 429, 500, 503} {
+		require.False(t, IsNonRetryable(HTTPStatusError(err, code, DefaultNonRetryableStatusCodes)), code)
+	}
+
+	require.True(t, IsNonRetryable(fmt.Errorf("writing: %w", HTTPStatusError(err, 404, []int{404}))))
+	require.Equal(t, err, HTTPStatusError(err, 400, nil))
+}
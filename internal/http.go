@@ -11,7 +11,7 @@ type BasicAuthErrorFunc func(rw http.ResponseWriter)
 
 // AuthHandler returns a http handler that requires HTTP basic auth
 // credentials to match the given username and password.
-func AuthHandler(username, password, realm string, onError BasicAuthErrorFunc) func(h http.Handler) http.Handler {
+func AuthHandler(username string, password Secret, realm string, onError BasicAuthErrorFunc) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return &basicAuthHandler{
 			username: username,
@@ -25,18 +25,24 @@ func AuthHandler(username, password, realm string, onError BasicAuthErrorFunc) f
 
 type basicAuthHandler struct {
 	username string
-	password string
+	password Secret
 	realm    string
 	onError  BasicAuthErrorFunc
 	next     http.Handler
 }
 
 func (h *basicAuthHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	if h.username != "" || h.password != "" {
+	if h.username != "" || !h.password.Empty() {
+		password, err := h.password.Get()
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
 		reqUsername, reqPassword, ok := req.BasicAuth()
 		if !ok ||
 			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(h.username)) != 1 ||
-			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(h.password)) != 1 {
+			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(password)) != 1 {
 
 			rw.Header().Set("WWW-Authenticate", "Basic realm=\""+h.realm+"\"")
 			h.onError(rw)
@@ -50,10 +56,11 @@ func (h *basicAuthHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 
 type GenericAuthErrorFunc func(rw http.ResponseWriter)
 
-// GenericAuthHandler returns a http handler that requires `Authorization: <credentials>`
-func GenericAuthHandler(credentials string, onError GenericAuthErrorFunc) func(h http.Handler) http.Handler {
+// GenericAuthHandler returns a http handler that requires `Authorization: <scheme> <credentials>`
+func GenericAuthHandler(scheme string, credentials Secret, onError GenericAuthErrorFunc) func(h http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return &genericAuthHandler{
+			scheme:      scheme,
 			credentials: credentials,
 			onError:     onError,
 			next:        h,
@@ -61,18 +68,25 @@ func GenericAuthHandler(credentials string, onError GenericAuthErrorFunc) func(h
 	}
 }
 
-// Generic auth scheme handler - exact match on `Authorization: <credentials>`
+// Generic auth scheme handler - exact match on `Authorization: <scheme> <credentials>`
 type genericAuthHandler struct {
-	credentials string
+	scheme      string
+	credentials Secret
 	onError     GenericAuthErrorFunc
 	next        http.Handler
 }
 
 func (h *genericAuthHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	if h.credentials != "" {
+	if !h.credentials.Empty() {
+		credentials, err := h.credentials.Get()
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
 		// Scheme checking
 		authorization := req.Header.Get("Authorization")
-		if subtle.ConstantTimeCompare([]byte(authorization), []byte(h.credentials)) != 1 {
+		if subtle.ConstantTimeCompare([]byte(authorization), []byte(h.scheme+" "+credentials)) != 1 {
 
 			h.onError(rw)
 			http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
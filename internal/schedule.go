@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a cron schedule in the standard five field format:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Fields are lists of values, ranges and steps such as "1,15", "8-17",
+// "*/15" or "0-30/10".  Months and days of the week may be given by their
+// three letter English names, Sunday is both 0 and 7.  When both the day of
+// the month and the day of the week are restricted, a day matching either one
+// matches.  The descriptors @yearly, @annually, @monthly, @weekly, @daily,
+// @midnight and @hourly are supported as well.  Times are in the local time
+// zone unless the expression is prefixed with "CRON_TZ=<zone> ".
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	domRestricted bool
+	dowRestricted bool
+	location      *time.Location
+}
+
+type cronField struct {
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	dowField = cronField{min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression.
+func ParseSchedule(expr string) (*Schedule, error) {
+	s := &Schedule{location: time.Local}
+
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid schedule %q: missing fields", expr)
+		}
+		loc, err := time.LoadLocation(spec[len("CRON_TZ="):i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", expr, err)
+		}
+		s.location = loc
+		spec = strings.TrimSpace(spec[i:])
+	}
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, found %d", expr, len(fields))
+	}
+
+	var err error
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: minute: %v", expr, err)
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: hour: %v", expr, err)
+	}
+	if s.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of month: %v", expr, err)
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: month: %v", expr, err)
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: day of week: %v", expr, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domRestricted = fields[2] != "*" && fields[2] != "?"
+	s.dowRestricted = fields[4] != "*" && fields[4] != "?"
+
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: never matches", expr)
+	}
+	return s, nil
+}
+
+// parse returns the bit set of the values of the field.
+func (f cronField) parse(spec string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(spec, ",") {
+		rng, stepSpec := item, ""
+		if i := strings.Index(item, "/"); i != -1 {
+			rng, stepSpec = item[:i], item[i+1:]
+		}
+
+		var low, high int
+		switch {
+		case rng == "*" || rng == "?":
+			low, high = f.min, f.max
+		case strings.Contains(rng, "-"):
+			parts := strings.SplitN(rng, "-", 2)
+			var err error
+			if low, err = f.value(parts[0]); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(parts[1]); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			var err error
+			if low, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			high = low
+			if stepSpec != "" {
+				high = f.max
+			}
+		}
+
+		step := 1
+		if stepSpec != "" {
+			var err error
+			step, err = strconv.Atoi(stepSpec)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepSpec)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			if f.min == 1 {
+				return i + 1, nil
+			}
+			return i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t matching the schedule, or the zero
+// time if there is none within the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// TimeWindow is a daily time range, optionally limited to some days of the
+// week, in the form "[days ]HH:MM-HH:MM" such as "08:00-18:00" or
+// "Mon-Fri 08:00-18:00".  The days use the syntax of the day of week field of
+// a Schedule.  A window ending before it starts spans midnight and the end
+// belongs to the following day.  Times are in the local time zone.
+type TimeWindow struct {
+	days       uint64
+	start, end int // minutes since midnight
+}
+
+// ParseTimeWindow parses a time window.
+func ParseTimeWindow(spec string) (*TimeWindow, error) {
+	w := &TimeWindow{}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+		w.days = 1<<7 - 1
+	case 2:
+		days, err := dowField.parse(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid time window %q: days: %v", spec, err)
+		}
+		if days&(1<<7) != 0 {
+			days |= 1
+		}
+		w.days = days
+	default:
+		return nil, fmt.Errorf("invalid time window %q", spec)
+	}
+
+	times := strings.SplitN(fields[len(fields)-1], "-", 2)
+	if len(times) != 2 {
+		return nil, fmt.Errorf("invalid time window %q: expected HH:MM-HH:MM", spec)
+	}
+	var err error
+	if w.start, err = parseClock(times[0]); err != nil {
+		return nil, fmt.Errorf("invalid time window %q: %v", spec, err)
+	}
+	if w.end, err = parseClock(times[1]); err != nil {
+		return nil, fmt.Errorf("invalid time window %q: %v", spec, err)
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("invalid time window %q: empty", spec)
+	}
+	return w, nil
+}
+
+func parseClock(s string) (int, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// Contains returns true if t is within the window.
+func (w *TimeWindow) Contains(t time.Time) bool {
+	t = t.In(time.Local)
+	minute := t.Hour()*60 + t.Minute()
+	day := uint(t.Weekday())
+
+	if w.start < w.end {
+		return w.days&(1<<day) != 0 && minute >= w.start && minute < w.end
+	}
+
+	// The window spans midnight.
+	if minute >= w.start {
+		return w.days&(1<<day) != 0
+	}
+	previous := (day + 6) % 7
+	return minute < w.end && w.days&(1<<previous) != 0
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// Friday
+	start := time.Date(2021, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{
+			expr:     "*/15 * * * *",
+			expected: []string{"2021-01-15T10:15:00Z", "2021-01-15T10:30:00Z", "2021-01-15T10:45:00Z"},
+		},
+		{
+			expr:     "0 2 * * *",
+			expected: []string{"2021-01-16T02:00:00Z", "2021-01-17T02:00:00Z"},
+		},
+		{
+			expr:     "@hourly",
+			expected: []string{"2021-01-15T11:00:00Z", "2021-01-15T12:00:00Z"},
+		},
+		{
+			expr:     "*/30 8-17 * * Mon-Fri",
+			expected: []string{"2021-01-15T10:30:00Z", "2021-01-15T11:00:00Z"},
+		},
+		{
+			expr:     "0 17 * * mon-fri",
+			expected: []string{"2021-01-15T17:00:00Z", "2021-01-18T17:00:00Z"},
+		},
+		{
+			expr:     "0 0 * * 7",
+			expected: []string{"2021-01-17T00:00:00Z", "2021-01-24T00:00:00Z"},
+		},
+		{
+			// Either the day of the month or the day of the week matches.
+			expr:     "0 0 1 * Sat",
+			expected: []string{"2021-01-16T00:00:00Z", "2021-01-23T00:00:00Z", "2021-01-30T00:00:00Z", "2021-02-01T00:00:00Z"},
+		},
+		{
+			expr:     "5,10-12/2 3 29 Feb *",
+			expected: []string{"2024-02-29T03:05:00Z", "2024-02-29T03:10:00Z", "2024-02-29T03:12:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule("CRON_TZ=UTC " + tt.expr)
+			require.NoError(t, err)
+
+			var actual []string
+			next := start
+			for range tt.expected {
+				next = s.Next(next)
+				actual = append(actual, next.UTC().Format(time.RFC3339))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSchedule_TimeZone(t *testing.T) {
+	s, err := ParseSchedule("CRON_TZ=America/New_York 0 9 * * *")
+	require.NoError(t, err)
+
+	next := s.Next(time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2021, 1, 15, 14, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestSchedule_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"0 0 30 Feb *",
+		"CRON_TZ=Nowhere/Invalid * * * * *",
+	} {
+		_, err := ParseSchedule(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestTimeWindow_Contains(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		// 2021-01-11 is a Monday.
+		return time.Date(2021, 1, 10+day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		window  string
+		inside  []time.Time
+		outside []time.Time
+	}{
+		{
+			window:  "08:00-18:00",
+			inside:  []time.Time{at(1, 8, 0), at(1, 17, 59), at(6, 12, 0)},
+			outside: []time.Time{at(1, 7, 59), at(1, 18, 0), at(1, 0, 0)},
+		},
+		{
+			window:  "Mon-Fri 08:00-18:00",
+			inside:  []time.Time{at(1, 8, 0), at(5, 17, 59)},
+			outside: []time.Time{at(6, 12, 0), at(7, 12, 0), at(1, 18, 0)},
+		},
+		{
+			window:  "Fri 22:00-02:00",
+			inside:  []time.Time{at(5, 22, 0), at(5, 23, 59), at(6, 1, 59)},
+			outside: []time.Time{at(5, 21, 59), at(6, 2, 0), at(5, 1, 0), at(6, 22, 0)},
+		},
+		{
+			window:  "sat,sun 00:00-24:00",
+			inside:  []time.Time{at(6, 0, 0), at(7, 23, 59)},
+			outside: []time.Time{at(1, 0, 0), at(5, 23, 59)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			w, err := ParseTimeWindow(tt.window)
+			require.NoError(t, err)
+			for _, ts := range tt.inside {
+				require.True(t, w.Contains(ts), ts.String())
+			}
+			for _, ts := range tt.outside {
+				require.False(t, w.Contains(ts), ts.String())
+			}
+		})
+	}
+}
+
+func TestTimeWindow_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"08:00",
+		"8-18",
+		"08:00-08:00",
+		"25:00-26:00",
+		"08:60-09:00",
+		"Someday 08:00-18:00",
+		"Mon Fri 08:00-18:00",
+	} {
+		_, err := ParseTimeWindow(spec)
+		require.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/influxdata/toml"
+)
+
+// secretRefRe matches references to a secret store in the form @{id:key}.
+var secretRefRe = regexp.MustCompile(`@\{(\w[\w.-]*):([^{}]+)\}`)
+
+// SecretGetter is the part of a secret store used to resolve references.
+type SecretGetter interface {
+	Get(key string) ([]byte, error)
+}
+
+var (
+	secretStoresMu sync.RWMutex
+	secretStores   = make(map[string]SecretGetter)
+)
+
+// RegisterSecretStore makes the store available to resolve references with
+// the given id, replacing any store previously registered with the id.
+func RegisterSecretStore(id string, store SecretGetter) {
+	secretStoresMu.Lock()
+	defer secretStoresMu.Unlock()
+	secretStores[id] = store
+}
+
+func secretStore(id string) (SecretGetter, bool) {
+	secretStoresMu.RLock()
+	defer secretStoresMu.RUnlock()
+	store, ok := secretStores[id]
+	return store, ok
+}
+
+// Secret is a configuration value that may reference secret stores using
+// @{id:key}.  References are resolved on every call to Get, so a rotated
+// secret is picked up without restarting.  The value is never printed.
+type Secret struct {
+	value string
+}
+
+// NewSecret returns a secret with the given value.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// UnmarshalTOML parses the secret from a TOML string of any kind.
+func (s *Secret) UnmarshalTOML(b []byte) error {
+	var v struct {
+		Value string `toml:"value"`
+	}
+	if err := toml.Unmarshal(append([]byte("value = "), b...), &v); err != nil {
+		return fmt.Errorf("invalid secret: %w", err)
+	}
+	s.value = v.Value
+	return nil
+}
+
+// Get returns the value with all references resolved.
+func (s Secret) Get() (string, error) {
+	return ResolveSecrets(s.value)
+}
+
+// Empty returns true if no value is set.
+func (s Secret) Empty() bool {
+	return s.value == ""
+}
+
+// String hides the value, so that it does not end up in logs.
+func (s Secret) String() string {
+	if s.value == "" {
+		return ""
+	}
+	return "<secret>"
+}
+
+// GoString hides the value when printed using %#v.
+func (s Secret) GoString() string {
+	return "internal.Secret{" + s.String() + "}"
+}
+
+// StoreIDs returns the ids of the secret stores referenced by the value.
+func (s Secret) StoreIDs() []string {
+	var ids []string
+	for _, match := range secretRefRe.FindAllStringSubmatch(s.value, -1) {
+		ids = append(ids, match[1])
+	}
+	return ids
+}
+
+// HasSecretRefs returns true if value references a secret store.
+func HasSecretRefs(value string) bool {
+	return secretRefRe.MatchString(value)
+}
+
+// ResolveSecrets replaces all references to secret stores in value by the
+// secrets.
+func ResolveSecrets(value string) (string, error) {
+	var err error
+	resolved := secretRefRe.ReplaceAllStringFunc(value, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+
+		match := secretRefRe.FindStringSubmatch(ref)
+		store, ok := secretStore(match[1])
+		if !ok {
+			err = fmt.Errorf("unknown secret store %q", match[1])
+			return ""
+		}
+
+		secret, gerr := store.Get(match[2])
+		if gerr != nil {
+			err = fmt.Errorf("getting secret %q from store %q: %w", match[2], match[1], gerr)
+			return ""
+		}
+		return string(secret)
+	})
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
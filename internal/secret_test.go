@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapSecretStore map[string]string
+
+func (s mapSecretStore) Get(key string) ([]byte, error) {
+	value, ok := s[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(value), nil
+}
+
+func TestSecret(t *testing.T) {
+	store := mapSecretStore{"user": "admin", "password": "pa$$word"}
+	RegisterSecretStore("secret_test", store)
+
+	var s Secret
+	require.NoError(t, s.UnmarshalTOML([]byte(`"@{secret_test:user}:@{secret_test:password}"`)))
+	require.Equal(t, []string{"secret_test", "secret_test"}, s.StoreIDs())
+
+	value, err := s.Get()
+	require.NoError(t, err)
+	require.Equal(t, "admin:pa$$word", value)
+
+	store["password"] = "rotated"
+	value, err = s.Get()
+	require.NoError(t, err)
+	require.Equal(t, "admin:rotated", value)
+
+	require.Equal(t, "<secret>", s.String())
+	require.Equal(t, "<secret>", fmt.Sprintf("%v", s))
+	require.NotContains(t, fmt.Sprintf("%#v", s), "secret_test")
+}
+
+func TestSecret_Literal(t *testing.T) {
+	var s Secret
+	require.NoError(t, s.UnmarshalTOML([]byte(`'C:\secret'`)))
+
+	value, err := s.Get()
+	require.NoError(t, err)
+	require.Equal(t, `C:\secret`, value)
+
+	require.NoError(t, s.UnmarshalTOML([]byte(`'''it's'''`)))
+	value, err = s.Get()
+	require.NoError(t, err)
+	require.Equal(t, "it's", value)
+
+	require.NoError(t, s.UnmarshalTOML([]byte("\"\"\"\nline1\\\n  line2\"\"\"")))
+	value, err = s.Get()
+	require.NoError(t, err)
+	require.Equal(t, "line1line2", value)
+
+	require.NoError(t, s.UnmarshalTOML([]byte("'''\n'quoted'\n'''")))
+	value, err = s.Get()
+	require.NoError(t, err)
+	require.Equal(t, "'quoted'\n", value)
+	require.True(t, NewSecret("").Empty())
+	require.Equal(t, "", NewSecret("").String())
+}
+
+func TestSecret_Errors(t *testing.T) {
+	RegisterSecretStore("secret_test_errors", mapSecretStore{})
+
+	s := NewSecret("@{unknown_store:password}")
+	_, err := s.Get()
+	require.Error(t, err)
+
+	s = NewSecret("@{secret_test_errors:password}")
+	_, err = s.Get()
+	require.EqualError(t, err, `getting secret "password" from store "secret_test_errors": not found`)
+}
@@ -11,9 +11,16 @@ Usage:
 The commands & flags are:
 
   config              print out full sample configuration to stdout
+  config check        check the configuration files and print the problems found
+  grok-debug <pattern>...
+                      print the grok pattern matching each line of stdin and its captures
+  secrets list <id>   list the keys of the secrets in a secret store
+  secrets set <id> <key> [value]
+                      store a secret, the value is read from stdin if omitted
   version             print the version to stdout
 
   --aggregator-filter <filter>   filter the aggregators to enable, separator is :
+  --check-config                 check the configuration files and exit, same as 'config check'
   --config <file>                configuration file to load
   --config-directory <directory> directory containing additional *.conf files
   --debug                        turn on debug logging
@@ -31,10 +38,16 @@ The commands & flags are:
                                  'processors', 'aggregators' and 'inputs'
   --once                         enable once mode: gather metrics once, write them, and exit
   --test                         enable test mode: gather metrics once and print them
+  --test-pipeline                enable pipeline test mode: gather metrics once, run them
+                                 through the processors and aggregators and print what
+                                 each output would receive, without connecting the outputs
+  --test-pipeline-input <file>   run the metrics in line protocol from the file, or stdin
+                                 if '-', through the pipeline instead of the inputs
   --test-wait                    wait up to this many seconds for service
-                                 inputs to complete in test or once mode
+                                 inputs to complete in test, pipeline test or once mode
   --usage <plugin>               print usage for a plugin, ie, 'telegraf --usage mysql'
   --version                      display the version and exit
+  --watch-config                 reload the configuration when the config files change
 
   --console                      run as console application (windows only)
   --service <service>            operate on the service (windows only)
@@ -52,12 +65,25 @@ Examples:
   # run a single telegraf collection, outputting metrics to stdout
   telegraf --config telegraf.conf --test
 
+  # run a single collection through the processors and aggregators, printing
+  # what each output would receive
+  telegraf --config telegraf.conf --test-pipeline
+
   # run telegraf with all plugins defined in config file
   telegraf --config telegraf.conf
 
   # run telegraf, enabling the cpu & memory input, and influxdb output plugins
   telegraf --config telegraf.conf --input-filter cpu:mem --output-filter influxdb
 
+  # check the configuration files, exits with status 1 on errors
+  telegraf --config telegraf.conf --config-directory telegraf.d config check
+
+  # store a secret in the secret store with the id "keyring"
+  telegraf --config telegraf.conf secrets set keyring http_password
+
+  # show which pattern matches the lines of a log file
+  telegraf grok-debug '%{COMBINED_LOG_FORMAT}' '%{COMMON_LOG_FORMAT}' < access.log
+
   # run telegraf with pprof
   telegraf --config telegraf.conf --pprof-addr localhost:6060
 
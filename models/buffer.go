@@ -12,18 +12,45 @@ var (
 	AgentMetricsDropped = selfstat.Register("agent", "metrics_dropped", map[string]string{})
 )
 
-// Buffer stores metrics in a circular buffer.
-type Buffer struct {
-	sync.Mutex
-	buf   []telegraf.Metric
-	first int // index of the first/oldest metric
-	last  int // one after the index of the last/newest metric
-	size  int // number of metrics currently in the buffer
-	cap   int // the capacity of the buffer
+// MetricBuffer is the storage used by a RunningOutput for metrics waiting to
+// be written.  Metrics are taken from the buffer with Batch and must be
+// returned using either Accept or Reject.
+type MetricBuffer interface {
+	// Len returns the number of metrics currently in the buffer.
+	Len() int
 
-	batchFirst int // index of the first metric in the batch
-	batchSize  int // number of metrics currently in the batch
+	// Add adds metrics to the buffer and returns number of dropped metrics.
+	Add(metrics ...telegraf.Metric) int
+
+	// Batch returns a slice containing up to batchSize of the oldest metrics
+	// not yet dropped.
+	Batch(batchSize int) []telegraf.Metric
+
+	// Accept marks the batch, acquired from Batch(), as successfully written.
+	Accept(batch []telegraf.Metric)
+
+	// Reject returns the batch, acquired from Batch(), to the buffer and
+	// marks it as unsent.
+	Reject(batch []telegraf.Metric)
 
+	// Drop removes the batch, acquired from Batch(), from the buffer without
+	// writing it.
+	Drop(batch []telegraf.Metric)
+
+	// Close releases any resources held by the buffer.
+	Close() error
+
+	// release removes the batch, acquired from Batch(), from the buffer
+	// without marking it as written or dropped.  It is used when moving the
+	// metrics into another buffer, which counts them as added again.
+	release(batch []telegraf.Metric)
+
+	// stats returns the internal metrics of the buffer.
+	stats() *bufferStats
+}
+
+// bufferStats holds the internal metrics shared by all buffer types.
+type bufferStats struct {
 	MetricsAdded   selfstat.Stat
 	MetricsWritten selfstat.Stat
 	MetricsDropped selfstat.Stat
@@ -31,20 +58,11 @@ type Buffer struct {
 	BufferLimit    selfstat.Stat
 }
 
-// NewBuffer returns a new empty Buffer with the given capacity.
-func NewBuffer(name string, alias string, capacity int) *Buffer {
-	tags := map[string]string{"output": name}
-	if alias != "" {
-		tags["alias"] = alias
-	}
-
-	b := &Buffer{
-		buf:   make([]telegraf.Metric, capacity),
-		first: 0,
-		last:  0,
-		size:  0,
-		cap:   capacity,
-
+// newBufferStats registers the internal metrics of the buffer of the output,
+// its limit is reported in the limit field.
+func newBufferStats(config *OutputConfig, limitField string, limit int64) bufferStats {
+	tags := OutputTags(config)
+	bs := bufferStats{
 		MetricsAdded: selfstat.Register(
 			"write",
 			"metrics_added",
@@ -67,43 +85,76 @@ func NewBuffer(name string, alias string, capacity int) *Buffer {
 		),
 		BufferLimit: selfstat.Register(
 			"write",
-			"buffer_limit",
+			limitField,
 			tags,
 		),
 	}
-	b.BufferSize.Set(int64(0))
-	b.BufferLimit.Set(int64(capacity))
-	return b
-}
-
-// Len returns the number of metrics currently in the buffer.
-func (b *Buffer) Len() int {
-	b.Lock()
-	defer b.Unlock()
-
-	return b.length()
-}
-
-func (b *Buffer) length() int {
-	return min(b.size+b.batchSize, b.cap)
+	bs.BufferSize.Set(int64(0))
+	bs.BufferLimit.Set(limit)
+	return bs
 }
 
-func (b *Buffer) metricAdded() {
+func (b *bufferStats) metricAdded() {
 	b.MetricsAdded.Incr(1)
 }
 
-func (b *Buffer) metricWritten(metric telegraf.Metric) {
+func (b *bufferStats) metricWritten(metric telegraf.Metric) {
 	AgentMetricsWritten.Incr(1)
 	b.MetricsWritten.Incr(1)
 	metric.Accept()
 }
 
-func (b *Buffer) metricDropped(metric telegraf.Metric) {
+func (b *bufferStats) metricDropped(metric telegraf.Metric) {
 	AgentMetricsDropped.Incr(1)
 	b.MetricsDropped.Incr(1)
 	metric.Reject()
 }
 
+// Buffer stores metrics in a circular buffer.
+type Buffer struct {
+	sync.Mutex
+	buf   []telegraf.Metric
+	first int // index of the first/oldest metric
+	last  int // one after the index of the last/newest metric
+	size  int // number of metrics currently in the buffer
+	cap   int // the capacity of the buffer
+
+	batchFirst int // index of the first metric in the batch
+	batchSize  int // number of metrics currently in the batch
+
+	bufferStats
+}
+
+func (bs *bufferStats) stats() *bufferStats {
+	return bs
+}
+
+// NewBuffer returns a new empty Buffer of the output with the given capacity.
+func NewBuffer(config *OutputConfig, capacity int) *Buffer {
+	b := &Buffer{
+		buf:   make([]telegraf.Metric, capacity),
+		first: 0,
+		last:  0,
+		size:  0,
+		cap:   capacity,
+
+		bufferStats: newBufferStats(config, "buffer_limit", int64(capacity)),
+	}
+	return b
+}
+
+// Len returns the number of metrics currently in the buffer.
+func (b *Buffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.length()
+}
+
+func (b *Buffer) length() int {
+	return min(b.size+b.batchSize, b.cap)
+}
+
 func (b *Buffer) add(m telegraf.Metric) int {
 	dropped := 0
 	// Check if Buffer is full
@@ -187,6 +238,20 @@ func (b *Buffer) Accept(batch []telegraf.Metric) {
 	b.BufferSize.Set(int64(b.length()))
 }
 
+// Drop removes the batch, acquired from Batch(), from the buffer and marks it
+// as dropped.
+func (b *Buffer) Drop(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, m := range batch {
+		b.metricDropped(m)
+	}
+
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
 // Reject returns the batch, acquired from Batch(), to the buffer and marks it
 // as unsent.
 func (b *Buffer) Reject(batch []telegraf.Metric) {
@@ -220,6 +285,20 @@ func (b *Buffer) Reject(batch []telegraf.Metric) {
 	b.BufferSize.Set(int64(b.length()))
 }
 
+func (b *Buffer) release(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.MetricsAdded.Incr(-int64(len(batch)))
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// Close is a no-op for the memory buffer.
+func (b *Buffer) Close() error {
+	return nil
+}
+
 // dist returns the distance between two indexes.  Because this data structure
 // uses a half open range the arguments must both either left side or right
 // side pairs.
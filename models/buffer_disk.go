@@ -0,0 +1,835 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	// Size after which a new segment file is started.
+	diskBufferSegmentSize = 8 * 1024 * 1024
+
+	// Number of metrics added after which the current segment is synced to
+	// disk, the segment is also synced before each batch.
+	diskBufferSyncCount = 1000
+
+	// Default maximum size of the data kept on disk.
+	DEFAULT_BUFFER_MAX_SIZE = 256 * 1024 * 1024
+
+	segmentExt     = ".wal"
+	checkpointFile = "checkpoint"
+
+	// Each record is prefixed by the length and CRC32 of the payload.
+	recordHeaderSize = 8
+)
+
+// Field type markers used in the on-disk metric encoding.
+const (
+	fieldInt64 byte = iota + 1
+	fieldUint64
+	fieldFloat64
+	fieldString
+	fieldBool
+)
+
+var errCorruptRecord = errors.New("corrupt record")
+
+// segment is a single write-ahead-log file holding consecutive entries.
+type segment struct {
+	path  string
+	first uint64 // index of the first entry in the segment
+	// offsets of each entry in the file followed by the end of the last entry
+	offsets []int64
+}
+
+func (s *segment) count() uint64 {
+	return uint64(len(s.offsets) - 1)
+}
+
+func (s *segment) size() int64 {
+	return s.offsets[len(s.offsets)-1]
+}
+
+// entrySize returns the on-disk size of the entry with the given index.
+func (s *segment) entrySize(index uint64) int64 {
+	i := index - s.first
+	return s.offsets[i+1] - s.offsets[i]
+}
+
+// DiskBuffer stores metrics in a write-ahead-log on disk so that metrics not
+// yet written survive a restart of the agent.  Metrics are appended to
+// segment files and the index of the oldest unacknowledged metric is kept in
+// a checkpoint file.  Segments containing only acknowledged metrics are
+// removed.
+//
+// Metrics added to the buffer are accepted once they are synced to disk, at
+// the latest before the next batch, the metrics returned by Batch are new
+// metrics read back from disk.
+type DiskBuffer struct {
+	sync.Mutex
+	path    string
+	maxSize int64
+
+	segments []*segment
+	writer   *os.File // last segment, opened for appending
+
+	first uint64 // index of the oldest unacknowledged entry
+	read  uint64 // index of the next entry to be returned by Batch
+	last  uint64 // one after the index of the newest entry
+	size  int64  // bytes used by the entries from first to last
+
+	batchFirst   uint64 // index of the first metric in the batch
+	batchDropped int    // number of metrics of the batch already dropped
+
+	unsynced []unsyncedMetric // metrics added but not yet synced to disk
+
+	bufferStats
+}
+
+// unsyncedMetric is a metric added to the buffer, it is accepted once its
+// entry is synced to disk.
+type unsyncedMetric struct {
+	index  uint64
+	metric telegraf.Metric
+}
+
+// NewDiskBuffer opens the disk buffer stored in the given directory, creating
+// it if needed.  Any metrics left from a previous run are replayed.  When
+// maxSize is exceeded the oldest metrics are dropped.
+func NewDiskBuffer(config *OutputConfig, path string, maxSize int64) (*DiskBuffer, error) {
+	if maxSize <= 0 {
+		maxSize = DEFAULT_BUFFER_MAX_SIZE
+	}
+
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, fmt.Errorf("creating buffer directory: %w", err)
+	}
+
+	b := &DiskBuffer{
+		path:    path,
+		maxSize: maxSize,
+
+		bufferStats: newBufferStats(config, "buffer_max_size", maxSize),
+	}
+
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+
+	if n := b.length(); n > 0 {
+		log.Printf("I! [%s] Replaying %d unsent metrics from disk buffer",
+			logName("outputs", config.Name, config.Alias), n)
+	}
+	b.BufferSize.Set(int64(b.length()))
+	return b, nil
+}
+
+// load reads the existing segments and the checkpoint from disk.
+func (b *DiskBuffer) load() error {
+	files, err := filepath.Glob(filepath.Join(b.path, "*"+segmentExt))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		first, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(file), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		seg, err := loadSegment(file, first)
+		if err != nil {
+			return fmt.Errorf("loading buffer segment %q: %w", file, err)
+		}
+		b.segments = append(b.segments, seg)
+	}
+
+	sort.Slice(b.segments, func(i, j int) bool {
+		return b.segments[i].first < b.segments[j].first
+	})
+
+	// Discard segments not following on from the previous one, the entries
+	// in them can no longer be addressed.
+	for i := 1; i < len(b.segments); i++ {
+		prev := b.segments[i-1]
+		if b.segments[i].first != prev.first+prev.count() {
+			for _, seg := range b.segments[i:] {
+				os.Remove(seg.path)
+			}
+			b.segments = b.segments[:i]
+			break
+		}
+	}
+
+	if len(b.segments) > 0 {
+		b.first = b.segments[0].first
+		tail := b.segments[len(b.segments)-1]
+		b.last = tail.first + tail.count()
+	}
+
+	checkpoint, err := b.readCheckpoint()
+	if err != nil {
+		return err
+	}
+	if checkpoint > b.first {
+		b.first = checkpoint
+	}
+	if b.first > b.last {
+		b.first = b.last
+	}
+	b.read = b.first
+
+	for i := b.first; i < b.last; i++ {
+		b.size += b.segmentFor(i).entrySize(i)
+	}
+
+	b.removeAcknowledged()
+	return nil
+}
+
+// loadSegment scans a segment file, truncating it after the last complete
+// record.
+func loadSegment(path string, first uint64) (*segment, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seg := &segment{path: path, first: first, offsets: []int64{0}}
+	var offset int64
+	for {
+		n, err := checkRecord(data[offset:])
+		if err != nil {
+			break
+		}
+		offset += n
+		seg.offsets = append(seg.offsets, offset)
+	}
+
+	if offset != int64(len(data)) {
+		if err := os.Truncate(path, offset); err != nil {
+			return nil, err
+		}
+	}
+	return seg, nil
+}
+
+// checkRecord validates the record at the beginning of data and returns its
+// full length.
+func checkRecord(data []byte) (int64, error) {
+	if len(data) < recordHeaderSize {
+		return 0, errCorruptRecord
+	}
+	length := int64(binary.BigEndian.Uint32(data[0:4]))
+	if int64(len(data)) < recordHeaderSize+length {
+		return 0, errCorruptRecord
+	}
+	payload := data[recordHeaderSize : recordHeaderSize+length]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(data[4:8]) {
+		return 0, errCorruptRecord
+	}
+	return recordHeaderSize + length, nil
+}
+
+func (b *DiskBuffer) readCheckpoint() (uint64, error) {
+	data, err := ioutil.ReadFile(filepath.Join(b.path, checkpointFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+func (b *DiskBuffer) writeCheckpoint() error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, b.first)
+
+	tmp := filepath.Join(b.path, checkpointFile+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(b.path, checkpointFile))
+}
+
+// segmentFor returns the segment holding the entry with the given index.
+func (b *DiskBuffer) segmentFor(index uint64) *segment {
+	i := sort.Search(len(b.segments), func(i int) bool {
+		return b.segments[i].first+b.segments[i].count() > index
+	})
+	return b.segments[i]
+}
+
+// Len returns the number of metrics currently in the buffer.
+func (b *DiskBuffer) Len() int {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.length()
+}
+
+func (b *DiskBuffer) length() int {
+	return int(b.last - b.first)
+}
+
+// usage returns the bytes used by the metrics in the buffer and the maximum
+// size of the buffer.
+func (b *DiskBuffer) usage() (int64, int64) {
+	b.Lock()
+	defer b.Unlock()
+
+	return b.size, b.maxSize
+}
+
+// Add adds metrics to the buffer and returns number of dropped metrics.
+func (b *DiskBuffer) Add(metrics ...telegraf.Metric) int {
+	b.Lock()
+	defer b.Unlock()
+
+	dropped := 0
+	for _, m := range metrics {
+		if err := b.append(m); err != nil {
+			log.Printf("E! [outputs] Writing metric to disk buffer %q: %v", b.path, err)
+			b.metricDropped(m)
+			dropped++
+			continue
+		}
+		b.metricAdded()
+		b.unsynced = append(b.unsynced, unsyncedMetric{index: b.last - 1, metric: m})
+	}
+
+	dropped += b.trim()
+	if len(b.unsynced) >= diskBufferSyncCount {
+		b.sync()
+	}
+	b.BufferSize.Set(int64(b.length()))
+	return dropped
+}
+
+// sync flushes the current segment to disk and accepts the metrics added
+// since the last sync, so that the inputs tracking them consider them
+// delivered.  Metrics already dropped from the buffer are rejected.  If the
+// segment cannot be synced the metrics are rejected but kept in the buffer,
+// they may be delivered twice.
+func (b *DiskBuffer) sync() {
+	if len(b.unsynced) == 0 {
+		return
+	}
+
+	var err error
+	if b.writer != nil {
+		if err = b.writer.Sync(); err != nil {
+			log.Printf("E! [outputs] Syncing disk buffer %q: %v", b.path, err)
+		}
+	}
+	for _, u := range b.unsynced {
+		if err != nil || u.index < b.first {
+			u.metric.Reject()
+		} else {
+			u.metric.Accept()
+		}
+	}
+	b.unsynced = b.unsynced[:0]
+}
+
+// append writes a single metric to the current segment.
+func (b *DiskBuffer) append(m telegraf.Metric) error {
+	payload := encodeMetric(m)
+	record := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[recordHeaderSize:], payload)
+
+	var seg *segment
+	if len(b.segments) > 0 {
+		seg = b.segments[len(b.segments)-1]
+	}
+	if seg == nil || b.writer == nil || seg.size() >= diskBufferSegmentSize {
+		var err error
+		if seg, err = b.openSegment(seg); err != nil {
+			return err
+		}
+	}
+
+	if _, err := b.writer.Write(record); err != nil {
+		// Remove any partial write so the segment stays readable.
+		b.writer.Truncate(seg.size())
+		return err
+	}
+
+	seg.offsets = append(seg.offsets, seg.size()+int64(len(record)))
+	b.size += int64(len(record))
+	b.last++
+	return nil
+}
+
+// openSegment opens the segment for appending.  A new segment is started
+// when the current one is full.
+func (b *DiskBuffer) openSegment(seg *segment) (*segment, error) {
+	if b.writer != nil {
+		// The metrics appended to the segment are accepted with the next
+		// sync, which only syncs the new segment.
+		if err := b.writer.Sync(); err != nil {
+			log.Printf("E! [outputs] Syncing disk buffer %q: %v", b.path, err)
+		}
+		b.writer.Close()
+		b.writer = nil
+	}
+
+	if seg == nil || seg.size() >= diskBufferSegmentSize {
+		seg = &segment{
+			path:    filepath.Join(b.path, fmt.Sprintf("%020d%s", b.last, segmentExt)),
+			first:   b.last,
+			offsets: []int64{0},
+		}
+		b.segments = append(b.segments, seg)
+	}
+
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	b.writer = f
+	return seg, nil
+}
+
+// trim drops the oldest metrics until the buffer fits into its maximum size.
+// The newest metric is always kept.
+func (b *DiskBuffer) trim() int {
+	dropped := 0
+	for b.size > b.maxSize && b.last-b.first > 1 {
+		b.size -= b.segmentFor(b.first).entrySize(b.first)
+
+		// Metrics of the batch currently being written are only counted
+		// once the batch is finished.
+		if b.first < b.read {
+			b.batchDropped++
+		} else {
+			AgentMetricsDropped.Incr(1)
+			b.MetricsDropped.Incr(1)
+			b.read++
+		}
+		b.first++
+		dropped++
+	}
+
+	if dropped > 0 {
+		b.removeAcknowledged()
+		if err := b.writeCheckpoint(); err != nil {
+			log.Printf("E! [outputs] Writing disk buffer checkpoint %q: %v", b.path, err)
+		}
+	}
+	return dropped
+}
+
+// Batch returns a slice containing up to batchSize of the oldest metrics not
+// yet dropped.  Metrics are ordered from oldest to newest in the batch.  The
+// batch must not be modified by the client.
+func (b *DiskBuffer) Batch(batchSize int) []telegraf.Metric {
+	b.Lock()
+	defer b.Unlock()
+
+	b.sync()
+
+	outLen := min(int(b.last-b.read), batchSize)
+	out := make([]telegraf.Metric, 0, outLen)
+	if outLen == 0 {
+		return out
+	}
+
+	b.batchFirst = b.read
+	b.batchDropped = 0
+
+	var f *os.File
+	var current *segment
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	for index := b.read; index < b.last && len(out) < batchSize; index++ {
+		seg := b.segmentFor(index)
+		if seg != current {
+			if f != nil {
+				f.Close()
+			}
+			var err error
+			f, err = os.Open(seg.path)
+			if err != nil {
+				log.Printf("E! [outputs] Reading disk buffer %q: %v", b.path, err)
+				break
+			}
+			current = seg
+		}
+
+		m, err := readMetric(f, seg, index)
+		if err != nil {
+			// The batch must hold consecutive entries, an unreadable entry
+			// after the first one is skipped by the next batch.
+			if len(out) > 0 {
+				break
+			}
+			log.Printf("E! [outputs] Dropping unreadable metric from disk buffer %q: %v", b.path, err)
+			b.skip(index)
+			continue
+		}
+		out = append(out, m)
+	}
+
+	b.read += uint64(len(out))
+	b.BufferSize.Set(int64(b.length()))
+	return out
+}
+
+// skip drops the unreadable entry at the beginning of the batch.
+func (b *DiskBuffer) skip(index uint64) {
+	AgentMetricsDropped.Incr(1)
+	b.MetricsDropped.Incr(1)
+
+	b.read = index + 1
+	b.batchFirst = b.read
+	if b.first == index {
+		b.advance(index + 1)
+	}
+}
+
+func readMetric(f *os.File, seg *segment, index uint64) (telegraf.Metric, error) {
+	i := index - seg.first
+	record := make([]byte, seg.offsets[i+1]-seg.offsets[i])
+	if _, err := f.ReadAt(record, seg.offsets[i]); err != nil {
+		return nil, err
+	}
+	if _, err := checkRecord(record); err != nil {
+		return nil, err
+	}
+	return decodeMetric(record[recordHeaderSize:])
+}
+
+// Accept marks the batch, acquired from Batch(), as successfully written.
+func (b *DiskBuffer) Accept(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	for i, m := range batch {
+		if i < b.batchDropped {
+			b.metricDropped(m)
+			continue
+		}
+		b.metricWritten(m)
+	}
+
+	b.advance(b.batchFirst + uint64(len(batch)))
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// Drop removes the batch, acquired from Batch(), from the buffer and marks it
+// as dropped.
+func (b *DiskBuffer) Drop(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, m := range batch {
+		b.metricDropped(m)
+	}
+
+	b.advance(b.batchFirst + uint64(len(batch)))
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// Reject returns the batch, acquired from Batch(), to the buffer and marks it
+// as unsent.
+func (b *DiskBuffer) Reject(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	for i := 0; i < b.batchDropped && i < len(batch); i++ {
+		b.metricDropped(batch[i])
+	}
+
+	// The metrics are still on disk, start reading again at the oldest one
+	// not yet dropped.
+	b.read = b.first
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+func (b *DiskBuffer) release(batch []telegraf.Metric) {
+	b.Lock()
+	defer b.Unlock()
+
+	for i, m := range batch {
+		if i < b.batchDropped {
+			b.metricDropped(m)
+			continue
+		}
+		b.MetricsAdded.Incr(-1)
+	}
+
+	b.advance(b.batchFirst + uint64(len(batch)))
+	b.resetBatch()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// setMaxSize changes the maximum size of the data kept on disk.
+func (b *DiskBuffer) setMaxSize(maxSize int64) {
+	b.Lock()
+	defer b.Unlock()
+
+	if maxSize <= 0 {
+		maxSize = DEFAULT_BUFFER_MAX_SIZE
+	}
+	b.maxSize = maxSize
+	b.BufferLimit.Set(maxSize)
+	b.trim()
+	b.BufferSize.Set(int64(b.length()))
+}
+
+// advance acknowledges all entries before the given index.
+func (b *DiskBuffer) advance(index uint64) {
+	for b.first < index && b.first < b.last {
+		b.size -= b.segmentFor(b.first).entrySize(b.first)
+		b.first++
+	}
+	if b.read < b.first {
+		b.read = b.first
+	}
+
+	if err := b.writeCheckpoint(); err != nil {
+		log.Printf("E! [outputs] Writing disk buffer checkpoint %q: %v", b.path, err)
+	}
+	b.removeAcknowledged()
+}
+
+// removeAcknowledged deletes all segments holding only acknowledged entries.
+// The newest segment is kept so it can be appended to.
+func (b *DiskBuffer) removeAcknowledged() {
+	for len(b.segments) > 1 {
+		seg := b.segments[0]
+		if seg.first+seg.count() > b.first {
+			break
+		}
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			log.Printf("E! [outputs] Removing disk buffer segment %q: %v", seg.path, err)
+			break
+		}
+		b.segments = b.segments[1:]
+	}
+}
+
+func (b *DiskBuffer) resetBatch() {
+	b.batchFirst = 0
+	b.batchDropped = 0
+}
+
+// Close flushes the current segment to disk and closes it.
+func (b *DiskBuffer) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.sync()
+	if b.writer == nil {
+		return nil
+	}
+
+	err := b.writer.Close()
+	b.writer = nil
+	return err
+}
+
+// encodeMetric serializes a metric to the binary format used in the segment
+// files.
+func encodeMetric(m telegraf.Metric) []byte {
+	var buf bytes.Buffer
+	scratch := make([]byte, binary.MaxVarintLen64)
+
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch, v)
+		buf.Write(scratch[:n])
+	}
+	putString := func(s string) {
+		putUvarint(uint64(len(s)))
+		buf.WriteString(s)
+	}
+
+	putString(m.Name())
+	buf.WriteByte(byte(m.Type()))
+
+	n := binary.PutVarint(scratch, m.Time().UnixNano())
+	buf.Write(scratch[:n])
+
+	tags := m.TagList()
+	putUvarint(uint64(len(tags)))
+	for _, tag := range tags {
+		putString(tag.Key)
+		putString(tag.Value)
+	}
+
+	fields := m.FieldList()
+	putUvarint(uint64(len(fields)))
+	for _, field := range fields {
+		putString(field.Key)
+		switch v := field.Value.(type) {
+		case int64:
+			buf.WriteByte(fieldInt64)
+			n := binary.PutVarint(scratch, v)
+			buf.Write(scratch[:n])
+		case uint64:
+			buf.WriteByte(fieldUint64)
+			putUvarint(v)
+		case float64:
+			buf.WriteByte(fieldFloat64)
+			putUvarint(math.Float64bits(v))
+		case string:
+			buf.WriteByte(fieldString)
+			putString(v)
+		case bool:
+			buf.WriteByte(fieldBool)
+			if v {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+		default:
+			// Metric fields are always converted to one of the types
+			// above, anything else cannot be stored.
+			buf.WriteByte(fieldString)
+			putString(fmt.Sprintf("%v", v))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// decodeMetric creates a metric from its binary encoding.
+func decodeMetric(data []byte) (telegraf.Metric, error) {
+	r := bytes.NewReader(data)
+
+	getString := func() (string, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return "", err
+		}
+		if n > uint64(r.Len()) {
+			return "", errCorruptRecord
+		}
+		s := make([]byte, n)
+		if _, err := io.ReadFull(r, s); err != nil {
+			return "", err
+		}
+		return string(s), nil
+	}
+
+	name, err := getString()
+	if err != nil {
+		return nil, err
+	}
+
+	tp, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := binary.ReadVarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ntags, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, ntags)
+	for i := uint64(0); i < ntags; i++ {
+		key, err := getString()
+		if err != nil {
+			return nil, err
+		}
+		value, err := getString()
+		if err != nil {
+			return nil, err
+		}
+		tags[key] = value
+	}
+
+	nfields, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, nfields)
+	for i := uint64(0); i < nfields; i++ {
+		key, err := getString()
+		if err != nil {
+			return nil, err
+		}
+
+		kind, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case fieldInt64:
+			v, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = v
+		case fieldUint64:
+			v, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = v
+		case fieldFloat64:
+			v, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = math.Float64frombits(v)
+		case fieldString:
+			v, err := getString()
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = v
+		case fieldBool:
+			v, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			fields[key] = v == 1
+		default:
+			return nil, errCorruptRecord
+		}
+	}
+
+	return metric.New(name, tags, fields, time.Unix(0, ts), telegraf.ValueType(tp))
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskBuffer(t *testing.T, path string, maxSize int64) *DiskBuffer {
+	b, err := NewDiskBuffer(&OutputConfig{Name: "test"}, path, maxSize)
+	require.NoError(t, err)
+	b.MetricsAdded.Set(0)
+	b.MetricsWritten.Set(0)
+	b.MetricsDropped.Set(0)
+	return b
+}
+
+func tempBufferDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "telegraf-buffer")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestDiskBuffer_AddBatchAccept(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 0)
+	defer b.Close()
+
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3))
+	require.Equal(t, 3, b.Len())
+
+	batch := b.Batch(2)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(1), MetricTime(2)}, batch)
+	require.Equal(t, 3, b.Len())
+
+	b.Accept(batch)
+	require.Equal(t, 1, b.Len())
+	require.Equal(t, int64(2), b.MetricsWritten.Get())
+
+	batch = b.Batch(2)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(3)}, batch)
+}
+
+func TestDiskBuffer_Reject(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 0)
+	defer b.Close()
+
+	b.Add(MetricTime(1), MetricTime(2))
+	batch := b.Batch(2)
+	b.Reject(batch)
+
+	require.Equal(t, 2, b.Len())
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(1), MetricTime(2)}, batch)
+}
+
+func TestDiskBuffer_ReplayAfterRestart(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 0)
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3))
+	b.Accept(b.Batch(1))
+
+	// Unacknowledged batch is replayed as well.
+	b.Batch(1)
+	require.NoError(t, b.Close())
+
+	b = newTestDiskBuffer(t, dir, 0)
+	defer b.Close()
+	require.Equal(t, 2, b.Len())
+
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(2), MetricTime(3)}, batch)
+}
+
+func TestDiskBuffer_DropOldestWhenFull(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	record := int64(recordHeaderSize + len(encodeMetric(MetricTime(1))))
+	b := newTestDiskBuffer(t, dir, 3*record)
+	defer b.Close()
+
+	dropped := b.Add(MetricTime(1), MetricTime(2), MetricTime(3), MetricTime(4), MetricTime(5))
+	require.Equal(t, 2, dropped)
+	require.Equal(t, 3, b.Len())
+	require.Equal(t, int64(2), b.MetricsDropped.Get())
+
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(3), MetricTime(4), MetricTime(5)}, batch)
+}
+
+func TestDiskBuffer_DropFromBatchInFlight(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	record := int64(recordHeaderSize + len(encodeMetric(MetricTime(1))))
+	b := newTestDiskBuffer(t, dir, 2*record)
+	defer b.Close()
+
+	b.Add(MetricTime(1), MetricTime(2))
+	batch := b.Batch(2)
+
+	b.Add(MetricTime(3))
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+
+	b.Reject(batch)
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+	require.Equal(t, 2, b.Len())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{MetricTime(2), MetricTime(3)}, batch)
+}
+
+func TestDiskBuffer_AcceptsTrackingMetricWhenPersisted(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 0)
+	defer b.Close()
+
+	var delivered bool
+	m, _ := metric.WithTracking(MetricTime(1), func(info telegraf.DeliveryInfo) {
+		delivered = info.Delivered()
+	})
+	b.Add(m)
+	require.False(t, delivered)
+
+	b.Batch(1)
+	require.True(t, delivered)
+}
+
+func TestDiskBuffer_AcceptsTrackingMetricOnClose(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 0)
+
+	var delivered bool
+	m, _ := metric.WithTracking(MetricTime(1), func(info telegraf.DeliveryInfo) {
+		delivered = info.Delivered()
+	})
+	b.Add(m)
+	require.NoError(t, b.Close())
+	require.True(t, delivered)
+}
+
+func TestDiskBuffer_RejectsTrackingMetricDroppedBeforeSync(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 1)
+	defer b.Close()
+
+	var delivered, notified bool
+	m, _ := metric.WithTracking(MetricTime(1), func(info telegraf.DeliveryInfo) {
+		notified = true
+		delivered = info.Delivered()
+	})
+	b.Add(m, MetricTime(2))
+
+	b.Batch(1)
+	require.True(t, notified)
+	require.False(t, delivered)
+}
+
+func TestDiskBuffer_SkipCorruptRecord(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 0)
+	defer b.Close()
+
+	b.Add(MetricTime(1), MetricTime(2), MetricTime(3))
+
+	// Corrupt the payload of the second record.
+	seg := b.segments[0]
+	f, err := os.OpenFile(seg.path, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff}, seg.offsets[1]+recordHeaderSize)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	batch := b.Batch(3)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(1)}, batch)
+	b.Accept(batch)
+
+	batch = b.Batch(3)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{MetricTime(3)}, batch)
+	b.Accept(batch)
+
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, int64(2), b.MetricsWritten.Get())
+	require.Equal(t, int64(1), b.MetricsDropped.Get())
+}
+
+func TestDiskBuffer_BufferLimit(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	b := newTestDiskBuffer(t, dir, 1024)
+	defer b.Close()
+	require.Equal(t, int64(1024), b.BufferLimit.Get())
+	require.Equal(t, "buffer_max_size", b.BufferLimit.FieldName())
+
+	b.setMaxSize(2048)
+	require.Equal(t, int64(2048), b.BufferLimit.Get())
+}
+
+func TestDiskBuffer_EncodeDecode(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "localhost", "cpu": "cpu0"},
+		map[string]interface{}{
+			"int":    int64(-42),
+			"uint":   uint64(42),
+			"float":  42.5,
+			"string": "howdy",
+			"bool":   true,
+		},
+		time.Unix(42, 123),
+		telegraf.Counter,
+	)
+
+	actual, err := decodeMetric(encodeMetric(m))
+	require.NoError(t, err)
+	testutil.RequireMetricEqual(t, m, actual)
+	require.Equal(t, telegraf.Counter, actual.Type())
+}
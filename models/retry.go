@@ -0,0 +1,137 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	// Default factor the retry delay grows by after each failed write.
+	DEFAULT_RETRY_MULTIPLIER = 2.0
+
+	// Default number of metrics sent to probe an output while the circuit
+	// breaker is open.
+	DEFAULT_CIRCUIT_BREAKER_PROBE_SIZE = 10
+)
+
+// RetryConfig controls how a RunningOutput retries failed writes.
+type RetryConfig struct {
+	// InitialDelay is the delay after the first failed write.  When zero
+	// writes are retried on the next flush.
+	InitialDelay time.Duration
+	// MaxDelay is the upper limit of the delay between retries.
+	MaxDelay time.Duration
+	// Multiplier is the factor the delay grows by with each failure.
+	Multiplier float64
+	// Jitter adds a random amount of up to Jitter to each delay.
+	Jitter time.Duration
+
+	// CircuitBreakerThreshold is the number of consecutive failed writes
+	// after which the circuit breaker opens.  When zero the circuit breaker
+	// is disabled.
+	CircuitBreakerThreshold int
+	// CircuitBreakerProbeSize is the size of the batch sent to probe the
+	// output while the circuit breaker is open.
+	CircuitBreakerProbeSize int
+}
+
+// retryState tracks the failed writes of an output.
+type retryState struct {
+	sync.Mutex
+	config RetryConfig
+
+	failures int           // number of consecutive failed writes
+	delay    time.Duration // current delay between retries
+	next     time.Time     // earliest time of the next write
+	open     bool          // circuit breaker is open
+
+	now func() time.Time
+}
+
+func newRetryState(config RetryConfig) *retryState {
+	if config.Multiplier <= 1 {
+		config.Multiplier = DEFAULT_RETRY_MULTIPLIER
+	}
+	if config.CircuitBreakerProbeSize <= 0 {
+		config.CircuitBreakerProbeSize = DEFAULT_CIRCUIT_BREAKER_PROBE_SIZE
+	}
+	return &retryState{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// ready returns true if the output may be written to now and otherwise the
+// time remaining until the next attempt.
+func (s *retryState) ready() (bool, time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	wait := s.next.Sub(s.now())
+	return wait <= 0, wait
+}
+
+// batchSize returns the size of the next batch.  While the circuit breaker
+// is open only a small probe batch is sent.
+func (s *retryState) batchSize(size int) int {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.open {
+		return min(size, s.config.CircuitBreakerProbeSize)
+	}
+	return size
+}
+
+// success resets the state after a successful write.  Returns true if the
+// circuit breaker was closed.
+func (s *retryState) success() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	closed := s.open
+	s.failures = 0
+	s.delay = 0
+	s.next = time.Time{}
+	s.open = false
+	return closed
+}
+
+// status returns the number of consecutive failed writes and whether the
+// circuit breaker is open.
+func (s *retryState) status() (int, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.failures, s.open
+}
+
+// failure records a failed write and schedules the next attempt.  Returns
+// true if the circuit breaker was opened.
+func (s *retryState) failure() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	s.failures++
+
+	if s.config.InitialDelay > 0 {
+		if s.delay == 0 {
+			s.delay = s.config.InitialDelay
+		} else {
+			s.delay = time.Duration(float64(s.delay) * s.config.Multiplier)
+		}
+		if s.config.MaxDelay > 0 && s.delay > s.config.MaxDelay {
+			s.delay = s.config.MaxDelay
+		}
+		s.next = s.now().Add(s.delay + internal.RandomDuration(s.config.Jitter))
+	}
+
+	threshold := s.config.CircuitBreakerThreshold
+	if threshold > 0 && s.failures >= threshold && !s.open {
+		s.open = true
+		return true
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	common "github.com/influxdata/telegraf/plugins/common/starlark"
+	"github.com/influxdata/telegraf/selfstat"
+	"go.starlark.net/starlark"
+)
+
+// RouterConfig is the configuration of the [router] section.
+type RouterConfig struct {
+	// RouteTag is the tag whose value is the name of the route of a metric.
+	// Metrics with the tag are not matched against the routes.
+	RouteTag string
+	// RemoveRouteTag removes the route tag from the metrics once routed.
+	RemoveRouteTag bool
+	// Default is the route of metrics matching no route.
+	Default string
+
+	Routes []*RouteConfig
+}
+
+// RouteConfig is the configuration of a route.  A metric matches the route
+// when it matches all of the conditions given.
+type RouteConfig struct {
+	Name     string
+	NamePass []string
+	Tags     []TagFilter
+
+	// Source or Script is a Starlark program defining the predicate
+	// match(metric), returning true for metrics on the route.
+	Source string
+	Script string
+}
+
+// Router assigns metrics to named routes.  Outputs subscribe to routes and
+// receive only the metrics on them, outputs without routes receive all
+// metrics.
+type Router struct {
+	Config *RouterConfig
+	routes []*route
+
+	MetricsRouted   selfstat.Stat
+	MetricsUnrouted selfstat.Stat
+	// MetricsUnmatched counts the metrics without route tag matching none of
+	// the routes, whether or not an output receives them.
+	MetricsUnmatched selfstat.Stat
+}
+
+type route struct {
+	config   *RouteConfig
+	namePass filter.Filter
+	log      telegraf.Logger
+
+	script    *common.Script
+	matchFunc *starlark.Function
+	args      starlark.Tuple
+}
+
+func NewRouter(config *RouterConfig) *Router {
+	return &Router{
+		Config: config,
+		MetricsRouted: selfstat.Register(
+			"router",
+			"metrics_routed",
+			map[string]string{},
+		),
+		MetricsUnrouted: selfstat.Register(
+			"router",
+			"metrics_unrouted",
+			map[string]string{},
+		),
+		MetricsUnmatched: selfstat.Register(
+			"router",
+			"metrics_unmatched",
+			map[string]string{},
+		),
+	}
+}
+
+// Init compiles the route conditions.
+func (r *Router) Init() error {
+	r.routes = make([]*route, 0, len(r.Config.Routes))
+	for _, rc := range r.Config.Routes {
+		if rc.Name == "" {
+			return fmt.Errorf("route without name")
+		}
+
+		rt := &route{
+			config: rc,
+			log:    NewLogger("router", rc.Name, ""),
+		}
+
+		var err error
+		rt.namePass, err = filter.Compile(rc.NamePass)
+		if err != nil {
+			return fmt.Errorf("route %s: Error compiling 'namepass', %s", rc.Name, err)
+		}
+		for i := range rc.Tags {
+			rc.Tags[i].filter, err = filter.Compile(rc.Tags[i].Filter)
+			if err != nil {
+				return fmt.Errorf("route %s: Error compiling 'tags', %s", rc.Name, err)
+			}
+		}
+
+		if rc.Source != "" || rc.Script != "" {
+			rt.script, err = common.NewScript("route."+rc.Name, rc.Source, rc.Script, common.Options{}, rt.log)
+			if err != nil {
+				return fmt.Errorf("route %s: %v", rc.Name, err)
+			}
+			rt.matchFunc, err = rt.script.Function("match", 1)
+			if err != nil {
+				return fmt.Errorf("route %s: %v", rc.Name, err)
+			}
+			rt.args = starlark.Tuple{&common.Metric{}}
+		}
+
+		r.routes = append(r.routes, rt)
+	}
+	return nil
+}
+
+// Route returns the names of the routes of the metric.  It is either the
+// value of the route tag, the routes matching the metric, or the default
+// route.
+func (r *Router) Route(metric telegraf.Metric) []string {
+	if r.Config.RouteTag != "" {
+		if name, ok := metric.GetTag(r.Config.RouteTag); ok {
+			if r.Config.RemoveRouteTag {
+				metric.RemoveTag(r.Config.RouteTag)
+			}
+			return []string{name}
+		}
+	}
+
+	var routes []string
+	for _, rt := range r.routes {
+		if rt.match(metric) {
+			routes = append(routes, rt.config.Name)
+		}
+	}
+	if len(routes) == 0 {
+		r.MetricsUnmatched.Incr(1)
+		if r.Config.Default != "" {
+			routes = append(routes, r.Config.Default)
+		}
+	}
+	return routes
+}
+
+// Outputs returns the outputs subscribed to the routes of the metric.
+// Metrics not received by any output are counted as unrouted.
+func (r *Router) Outputs(metric telegraf.Metric, outputs []*RunningOutput) []*RunningOutput {
+	routes := r.Route(metric)
+
+	var subscribed []*RunningOutput
+	for _, output := range outputs {
+		if output.Subscribed(routes) {
+			subscribed = append(subscribed, output)
+		}
+	}
+
+	if len(subscribed) == 0 {
+		r.MetricsUnrouted.Incr(1)
+	} else {
+		r.MetricsRouted.Incr(1)
+	}
+	return subscribed
+}
+
+func (rt *route) match(metric telegraf.Metric) bool {
+	if rt.namePass != nil && !rt.namePass.Match(metric.Name()) {
+		return false
+	}
+
+	for _, tf := range rt.config.Tags {
+		value, ok := metric.GetTag(tf.Name)
+		if !ok || (tf.filter != nil && !tf.filter.Match(value)) {
+			return false
+		}
+	}
+
+	if rt.matchFunc == nil {
+		return true
+	}
+
+	// The metric is frozen, as it is shared by all the routes.
+	m := rt.args[0].(*common.Metric)
+	m.Wrap(metric)
+	m.Freeze()
+	rv, err := rt.script.Call(rt.matchFunc, rt.args)
+	if err != nil {
+		rt.log.Errorf("Error calling match: %v", err)
+		return false
+	}
+	return bool(rv.Truth())
+}
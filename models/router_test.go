@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Route(t *testing.T) {
+	r := NewRouter(&RouterConfig{
+		RouteTag:       "_route",
+		RemoveRouteTag: true,
+		Default:        "other",
+		Routes: []*RouteConfig{
+			{Name: "cpu", NamePass: []string{"cpu*"}},
+			{Name: "tenant", Tags: []TagFilter{{Name: "tenant", Filter: []string{"a", "b"}}}},
+			{Name: "large", Source: `
+def match(metric):
+    return metric.fields.get("value", 0) > 100
+`},
+		},
+	})
+	require.NoError(t, r.Init())
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		fields   map[string]interface{}
+		expected []string
+	}{
+		{"cpu", nil, map[string]interface{}{"value": 1}, []string{"cpu"}},
+		{"cpu", map[string]string{"tenant": "a"}, map[string]interface{}{"value": 1}, []string{"cpu", "tenant"}},
+		{"mem", map[string]string{"tenant": "c"}, map[string]interface{}{"value": 1}, []string{"other"}},
+		{"mem", nil, map[string]interface{}{"value": 101}, []string{"large"}},
+		{"cpu", map[string]string{"_route": "custom"}, map[string]interface{}{"value": 101}, []string{"custom"}},
+	}
+	for _, tt := range tests {
+		m := testutil.MustMetric(tt.name, tt.tags, tt.fields, time.Unix(0, 0))
+		require.Equal(t, tt.expected, r.Route(m))
+		require.False(t, m.HasTag("_route"))
+	}
+}
+
+func TestRouter_Outputs(t *testing.T) {
+	r := NewRouter(&RouterConfig{
+		Routes: []*RouteConfig{
+			{Name: "cpu", NamePass: []string{"cpu"}},
+		},
+	})
+	require.NoError(t, r.Init())
+
+	all := NewRunningOutput("all", &mockOutput{}, &OutputConfig{}, 0, 0)
+	cpu := NewRunningOutput("cpu", &mockOutput{}, &OutputConfig{Routes: []string{"cpu"}}, 0, 0)
+	outputs := []*RunningOutput{all, cpu}
+
+	m := testutil.MustMetric("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.Equal(t, outputs, r.Outputs(m, outputs))
+
+	unmatched := r.MetricsUnmatched.Get()
+	m = testutil.MustMetric("mem", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.Equal(t, []*RunningOutput{all}, r.Outputs(m, outputs))
+	require.Equal(t, unmatched+1, r.MetricsUnmatched.Get())
+
+	unrouted := r.MetricsUnrouted.Get()
+	require.Empty(t, r.Outputs(m, []*RunningOutput{cpu}))
+	require.Equal(t, unrouted+1, r.MetricsUnrouted.Get())
+}
+
+func TestRouter_InitErrors(t *testing.T) {
+	for _, routes := range [][]*RouteConfig{
+		{{}},
+		{{Name: "a", NamePass: []string{"["}}},
+		{{Name: "a", Source: "def apply(metric):\n    return True"}},
+		{{Name: "a", Source: "def match():\n    return True"}},
+	} {
+		r := NewRouter(&RouterConfig{Routes: routes})
+		require.Error(t, r.Init())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -15,6 +16,7 @@ var (
 type RunningInput struct {
 	Input  telegraf.Input
 	Config *InputConfig
+	ID     string // name and hash of the configuration
 
 	log         telegraf.Logger
 	defaultTags map[string]string
@@ -62,6 +64,11 @@ type InputConfig struct {
 	CollectionJitter time.Duration
 	Precision        time.Duration
 
+	// Schedule replaces the interval by a cron schedule if set.
+	Schedule *internal.Schedule
+	// ActiveWindow limits the collections to a time window if set.
+	ActiveWindow *internal.TimeWindow
+
 	NameOverride      string
 	MeasurementPrefix string
 	MeasurementSuffix string
@@ -1,11 +1,15 @@
 package models
 
 import (
+	"fmt"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -23,11 +27,27 @@ type OutputConfig struct {
 	Alias  string
 	Filter Filter
 
+	// Pipeline is the name of the pipeline of the output, it is empty for
+	// the outputs outside of the named pipelines.
+	Pipeline string
+
 	FlushInterval     time.Duration
 	FlushJitter       time.Duration
 	MetricBufferLimit int
 	MetricBatchSize   int
 
+	// BufferStrategy selects the buffer implementation, either "memory" or
+	// "disk".
+	BufferStrategy  string
+	BufferDirectory string
+	BufferMaxSize   int64
+
+	Retry RetryConfig
+
+	// Routes are the routes of the router the output subscribes to.  Outputs
+	// without routes receive all metrics.
+	Routes []string
+
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
@@ -41,18 +61,40 @@ type RunningOutput struct {
 
 	Output            telegraf.Output
 	Config            *OutputConfig
+	ID                string                 // name and hash of the configuration
+	Serializer        serializers.Serializer // set on outputs with a data format
 	MetricBufferLimit int
 	MetricBatchSize   int
 
 	MetricsFiltered selfstat.Stat
 	WriteTime       selfstat.Stat
+	WriteErrors     selfstat.Stat
 
 	BatchReady chan time.Time
 
-	buffer *Buffer
-	log    telegraf.Logger
+	buffer        MetricBuffer
+	bufferAdopted bool // buffer taken over from a replaced output
+	bufferMoved   bool // buffer handed over to the replacing output
+	retry         *retryState
+	log           telegraf.Logger
 
 	aggMutex sync.Mutex
+
+	statusMutex   sync.Mutex
+	lastWrite     time.Time
+	lastError     error
+	lastErrorTime time.Time
+}
+
+// OutputStatus describes the outcome of the recent writes to an output.
+type OutputStatus struct {
+	LastWrite     time.Time
+	LastError     error
+	LastErrorTime time.Time
+
+	// Failures is the number of consecutive failed writes.
+	Failures    int
+	CircuitOpen bool
 }
 
 func NewRunningOutput(
@@ -62,15 +104,11 @@ func NewRunningOutput(
 	batchSize int,
 	bufferLimit int,
 ) *RunningOutput {
-	tags := map[string]string{"output": config.Name}
-	if config.Alias != "" {
-		tags["alias"] = config.Alias
-	}
-
-	writeErrorsRegister := selfstat.Register("write", "errors", tags)
+	tags := OutputTags(config)
+	writeErrors := selfstat.Register("write", "errors", tags)
 	logger := NewLogger("outputs", config.Name, config.Alias)
 	logger.OnErr(func() {
-		writeErrorsRegister.Incr(1)
+		writeErrors.Incr(1)
 	})
 	SetLoggerOnPlugin(output, logger)
 
@@ -88,7 +126,6 @@ func NewRunningOutput(
 	}
 
 	ro := &RunningOutput{
-		buffer:            NewBuffer(config.Name, config.Alias, bufferLimit),
 		BatchReady:        make(chan time.Time, 1),
 		Output:            output,
 		Config:            config,
@@ -104,12 +141,44 @@ func NewRunningOutput(
 			"write_time_ns",
 			tags,
 		),
-		log: logger,
+		WriteErrors: writeErrors,
+		retry:       newRetryState(config.Retry),
+		log:         logger,
 	}
 
+	// The disk buffer is opened by Init, so that an output replaced on
+	// reload can hand its buffer over first.
+	ro.buffer = NewBuffer(config, bufferLimit)
+
 	return ro
 }
 
+// OutputTags returns the tags of the internal metrics of the output.
+func OutputTags(config *OutputConfig) map[string]string {
+	tags := map[string]string{"output": config.Name}
+	if config.Alias != "" {
+		tags["alias"] = config.Alias
+	}
+	if config.Pipeline != "" {
+		tags["pipeline"] = config.Pipeline
+	}
+	return tags
+}
+
+// BufferPath returns the directory used by the disk buffer of the output.
+// The buffers of the outputs of a named pipeline are kept in a directory
+// named after the pipeline.
+func BufferPath(config *OutputConfig) string {
+	dir := config.Name
+	if config.Alias != "" {
+		dir += "-" + config.Alias
+	}
+	if config.Pipeline != "" {
+		return filepath.Join(config.BufferDirectory, "pipelines", config.Pipeline, dir)
+	}
+	return filepath.Join(config.BufferDirectory, dir)
+}
+
 func (r *RunningOutput) LogName() string {
 	return logName("outputs", r.Config.Name, r.Config.Alias)
 }
@@ -119,7 +188,16 @@ func (ro *RunningOutput) metricFiltered(metric telegraf.Metric) {
 	metric.Drop()
 }
 
+// Init opens the buffer of the output and initializes the plugin.
 func (r *RunningOutput) Init() error {
+	if r.Config.BufferStrategy == "disk" && !r.bufferAdopted {
+		buffer, err := NewDiskBuffer(r.Config, BufferPath(r.Config), r.Config.BufferMaxSize)
+		if err != nil {
+			return fmt.Errorf("opening disk buffer: %w", err)
+		}
+		r.buffer = buffer
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -130,6 +208,53 @@ func (r *RunningOutput) Init() error {
 	return nil
 }
 
+// Subscribed returns true if the output receives metrics on any of the
+// routes.
+func (r *RunningOutput) Subscribed(routes []string) bool {
+	if len(r.Config.Routes) == 0 {
+		return true
+	}
+	for _, route := range routes {
+		for _, name := range r.Config.Routes {
+			if route == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PrepareMetric applies the filters and name modifications of the output to
+// the metric, as AddMetric does, without adding it to the buffer.  It returns
+// false if the metric is filtered out.
+func (ro *RunningOutput) PrepareMetric(metric telegraf.Metric) bool {
+	if ok := ro.Config.Filter.Select(metric); !ok {
+		return false
+	}
+
+	ro.Config.Filter.Modify(metric)
+	if len(metric.FieldList()) == 0 {
+		return false
+	}
+
+	ro.rename(metric)
+	return true
+}
+
+func (ro *RunningOutput) rename(metric telegraf.Metric) {
+	if len(ro.Config.NameOverride) > 0 {
+		metric.SetName(ro.Config.NameOverride)
+	}
+
+	if len(ro.Config.NamePrefix) > 0 {
+		metric.AddPrefix(ro.Config.NamePrefix)
+	}
+
+	if len(ro.Config.NameSuffix) > 0 {
+		metric.AddSuffix(ro.Config.NameSuffix)
+	}
+}
+
 // AddMetric adds a metric to the output.
 //
 // Takes ownership of metric
@@ -152,17 +277,7 @@ func (ro *RunningOutput) AddMetric(metric telegraf.Metric) {
 		return
 	}
 
-	if len(ro.Config.NameOverride) > 0 {
-		metric.SetName(ro.Config.NameOverride)
-	}
-
-	if len(ro.Config.NamePrefix) > 0 {
-		metric.AddPrefix(ro.Config.NamePrefix)
-	}
-
-	if len(ro.Config.NameSuffix) > 0 {
-		metric.AddSuffix(ro.Config.NameSuffix)
-	}
+	ro.rename(metric)
 
 	dropped := ro.buffer.Add(metric)
 	atomic.AddInt64(&ro.droppedMetrics, int64(dropped))
@@ -178,8 +293,19 @@ func (ro *RunningOutput) AddMetric(metric telegraf.Metric) {
 }
 
 // Write writes all metrics to the output, stopping when all have been sent on
-// or error.
+// or error.  Nothing is written while waiting to retry a failed write.
 func (ro *RunningOutput) Write() error {
+	return ro.writeAll(false)
+}
+
+// Flush writes all metrics to the output like Write, but ignores any delay
+// before retrying failed writes.  This is used for the final write before
+// shutdown.
+func (ro *RunningOutput) Flush() error {
+	return ro.writeAll(true)
+}
+
+func (ro *RunningOutput) writeAll(force bool) error {
 	if output, ok := ro.Output.(telegraf.AggregatingOutput); ok {
 		ro.aggMutex.Lock()
 		metrics := output.Push()
@@ -190,41 +316,76 @@ func (ro *RunningOutput) Write() error {
 
 	atomic.StoreInt64(&ro.newMetricsCount, 0)
 
+	if !force && !ro.retryReady() {
+		return nil
+	}
+
 	// Only process the metrics in the buffer now.  Metrics added while we are
 	// writing will be sent on the next call.
-	nBuffer := ro.buffer.Len()
-	nBatches := nBuffer/ro.MetricBatchSize + 1
-	for i := 0; i < nBatches; i++ {
-		batch := ro.buffer.Batch(ro.MetricBatchSize)
-		if len(batch) == 0 {
-			break
-		}
-
-		err := ro.write(batch)
+	remaining := ro.buffer.Len()
+	for remaining > 0 {
+		n, err := ro.writeBatch(ro.retry.batchSize(ro.MetricBatchSize))
 		if err != nil {
-			ro.buffer.Reject(batch)
 			return err
 		}
-		ro.buffer.Accept(batch)
+		if n == 0 {
+			break
+		}
+		remaining -= n
 	}
 	return nil
 }
 
 // WriteBatch writes a single batch of metrics to the output.
 func (ro *RunningOutput) WriteBatch() error {
-	batch := ro.buffer.Batch(ro.MetricBatchSize)
-	if len(batch) == 0 {
+	if !ro.retryReady() {
 		return nil
 	}
 
+	_, err := ro.writeBatch(ro.retry.batchSize(ro.MetricBatchSize))
+	return err
+}
+
+// retryReady returns true if the output can be written to now.
+func (ro *RunningOutput) retryReady() bool {
+	ready, wait := ro.retry.ready()
+	if !ready {
+		ro.log.Debugf("Waiting %s before retrying write", wait.Round(time.Millisecond))
+	}
+	return ready
+}
+
+// writeBatch takes a batch of up to size metrics from the buffer and writes
+// it to the output.  Returns the number of metrics taken from the buffer.
+func (ro *RunningOutput) writeBatch(size int) (int, error) {
+	batch := ro.buffer.Batch(size)
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
 	err := ro.write(batch)
-	if err != nil {
+	ro.setStatus(err)
+	switch {
+	case err == nil:
+		ro.buffer.Accept(batch)
+	case internal.IsNonRetryable(err):
+		// The output is reachable but refused the data, sending it again
+		// would block the metrics behind it.
+		ro.log.Errorf("Dropping batch of %d metrics: %v", len(batch), err)
+		ro.buffer.Drop(batch)
+	default:
 		ro.buffer.Reject(batch)
-		return err
+		if ro.retry.failure() {
+			ro.log.Warnf("Circuit breaker opened after %d consecutive failed writes",
+				ro.Config.Retry.CircuitBreakerThreshold)
+		}
+		return len(batch), err
 	}
-	ro.buffer.Accept(batch)
 
-	return nil
+	if ro.retry.success() {
+		ro.log.Infof("Circuit breaker closed, write succeeded")
+	}
+	return len(batch), nil
 }
 
 // Close closes the output
@@ -233,6 +394,80 @@ func (r *RunningOutput) Close() {
 	if err != nil {
 		r.log.Errorf("Error closing output: %v", err)
 	}
+
+	if r.bufferMoved {
+		return
+	}
+	err = r.buffer.Close()
+	if err != nil {
+		r.log.Errorf("Error closing buffer: %v", err)
+	}
+}
+
+// AdoptBuffer makes the output share the buffer of old, which it is going to
+// replace.  It must be called before Init.  Returns false if the buffers are
+// not compatible, the metrics are then copied over by MoveBuffer instead.
+func (r *RunningOutput) AdoptBuffer(old *RunningOutput) bool {
+	if r.Config.BufferStrategy != old.Config.BufferStrategy {
+		return false
+	}
+
+	switch r.Config.BufferStrategy {
+	case "disk":
+		// The maximum size of the output is only applied by MoveBuffer,
+		// once the replacement is committed.
+		if _, ok := old.buffer.(*DiskBuffer); !ok || BufferPath(r.Config) != BufferPath(old.Config) {
+			return false
+		}
+	default:
+		if r.MetricBufferLimit != old.MetricBufferLimit {
+			return false
+		}
+	}
+
+	r.buffer = old.buffer
+	r.bufferAdopted = true
+	return true
+}
+
+// MoveBuffer hands the metrics waiting in the buffer over to dst, which
+// replaces this output.  A buffer adopted by dst gets the limits of dst.
+// Nothing must be added to or written from the output while the metrics are
+// moved.
+func (r *RunningOutput) MoveBuffer(dst *RunningOutput) {
+	r.bufferMoved = true
+	if dst.buffer == r.buffer {
+		if buffer, ok := dst.buffer.(*DiskBuffer); ok {
+			buffer.setMaxSize(dst.Config.BufferMaxSize)
+		}
+		return
+	}
+
+	for {
+		batch := r.buffer.Batch(r.MetricBatchSize)
+		if len(batch) == 0 {
+			break
+		}
+		r.buffer.release(batch)
+		dst.buffer.Add(batch...)
+	}
+
+	err := r.buffer.Close()
+	if err != nil {
+		r.log.Errorf("Error closing buffer: %v", err)
+	}
+}
+
+// Discard releases the buffer of an output that was initialized but never
+// started.  A buffer adopted from another output is left open.
+func (r *RunningOutput) Discard() {
+	if r.bufferAdopted {
+		return
+	}
+	err := r.buffer.Close()
+	if err != nil {
+		r.log.Errorf("Error closing buffer: %v", err)
+	}
 }
 
 func (r *RunningOutput) write(metrics []telegraf.Metric) error {
@@ -265,3 +500,66 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// MetricsAdded returns the number of metrics added to the buffer.
+func (r *RunningOutput) MetricsAdded() int64 {
+	return r.buffer.stats().MetricsAdded.Get()
+}
+
+// MetricsWritten returns the number of metrics written from the buffer.
+func (r *RunningOutput) MetricsWritten() int64 {
+	return r.buffer.stats().MetricsWritten.Get()
+}
+
+// MetricsDropped returns the number of metrics dropped from the buffer.
+func (r *RunningOutput) MetricsDropped() int64 {
+	return r.buffer.stats().MetricsDropped.Get()
+}
+
+// BufferLimit returns the limit of the buffer, in metrics for the memory
+// buffer and in bytes for the disk buffer.
+func (r *RunningOutput) BufferLimit() int {
+	if b, ok := r.buffer.(*DiskBuffer); ok {
+		_, limit := b.usage()
+		return int(limit)
+	}
+	return r.MetricBufferLimit
+}
+
+// BufferFill returns the fraction of the limit of the buffer in use.
+func (r *RunningOutput) BufferFill() float64 {
+	if b, ok := r.buffer.(*DiskBuffer); ok {
+		size, limit := b.usage()
+		return float64(size) / float64(limit)
+	}
+	if r.MetricBufferLimit <= 0 {
+		return 0
+	}
+	return float64(r.buffer.Len()) / float64(r.MetricBufferLimit)
+}
+
+// Status returns the outcome of the recent writes.
+func (r *RunningOutput) Status() OutputStatus {
+	r.statusMutex.Lock()
+	status := OutputStatus{
+		LastWrite:     r.lastWrite,
+		LastError:     r.lastError,
+		LastErrorTime: r.lastErrorTime,
+	}
+	r.statusMutex.Unlock()
+
+	status.Failures, status.CircuitOpen = r.retry.status()
+	return status
+}
+
+func (r *RunningOutput) setStatus(err error) {
+	r.statusMutex.Lock()
+	defer r.statusMutex.Unlock()
+
+	if err != nil {
+		r.lastError = err
+		r.lastErrorTime = time.Now()
+		return
+	}
+	r.lastWrite = time.Now()
+}
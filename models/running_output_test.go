@@ -2,11 +2,13 @@ package models
 
 import (
 	"fmt"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
@@ -272,6 +274,26 @@ func TestRunningOutput_NameSuffix(t *testing.T) {
 	assert.Equal(t, "metric1_suffix", m.Metrics()[0].Name())
 }
 
+// Test that PrepareMetric filters and renames without buffering.
+func TestRunningOutput_PrepareMetric(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{
+			NameDrop: []string{"metric2"},
+		},
+		NamePrefix: "prefix_",
+	}
+	assert.NoError(t, conf.Filter.Compile())
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 1000, 10000)
+
+	metric := testutil.TestMetric(101, "metric1")
+	assert.True(t, ro.PrepareMetric(metric))
+	assert.Equal(t, "prefix_metric1", metric.Name())
+	assert.False(t, ro.PrepareMetric(testutil.TestMetric(101, "metric2")))
+	assert.Equal(t, 0, ro.BufferLength())
+}
+
 // Test that we can write metrics with simple default setup.
 func TestRunningOutputDefault(t *testing.T) {
 	conf := &OutputConfig{
@@ -468,6 +490,227 @@ func TestRunningOutputWriteFailOrder3(t *testing.T) {
 	assert.Equal(t, expected, m.Metrics())
 }
 
+func TestRunningOutputRetryBackoff(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: RetryConfig{
+			InitialDelay: time.Minute,
+			MaxDelay:     3 * time.Minute,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+	now := time.Unix(0, 0)
+	ro.retry.now = func() time.Time { return now }
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.Error(t, ro.Write())
+	require.Equal(t, []int{5}, m.batches)
+
+	// Not retried before the delay elapsed
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.Equal(t, []int{5}, m.batches)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, ro.Write())
+	require.Equal(t, []int{5, 5}, m.batches)
+	require.Len(t, m.Metrics(), 5)
+}
+
+func TestRunningOutputRetryDelayGrows(t *testing.T) {
+	s := newRetryState(RetryConfig{
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+	})
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		s.failure()
+		_, wait := s.ready()
+		delays = append(delays, wait)
+	}
+	require.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+	}, delays)
+
+	s.success()
+	ready, _ := s.ready()
+	require.True(t, ready)
+}
+
+func TestRunningOutputCircuitBreaker(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: RetryConfig{
+			CircuitBreakerThreshold: 2,
+			CircuitBreakerProbeSize: 1,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.Error(t, ro.Write())
+	require.Error(t, ro.Write())
+	require.Equal(t, []int{4, 4}, m.batches)
+
+	// Circuit is open, only a probe is sent
+	require.Error(t, ro.Write())
+	require.Equal(t, []int{4, 4, 1}, m.batches)
+
+	// Probe succeeds and closes the circuit, remaining metrics follow
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	require.Equal(t, []int{4, 4, 1, 1, 4}, m.batches)
+	require.Len(t, m.Metrics(), 5)
+}
+
+func TestRunningOutputStatus(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		Retry: RetryConfig{
+			CircuitBreakerThreshold: 1,
+		},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	status := ro.Status()
+	require.True(t, status.LastWrite.IsZero())
+	require.NoError(t, status.LastError)
+
+	ro.AddMetric(first5[0])
+	require.Error(t, ro.Write())
+
+	status = ro.Status()
+	require.Error(t, status.LastError)
+	require.False(t, status.LastErrorTime.IsZero())
+	require.Equal(t, 1, status.Failures)
+	require.True(t, status.CircuitOpen)
+
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+
+	status = ro.Status()
+	require.False(t, status.LastWrite.IsZero())
+	require.Error(t, status.LastError)
+	require.Equal(t, 0, status.Failures)
+	require.False(t, status.CircuitOpen)
+}
+
+func TestRunningOutputNonRetryableError(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.writeErr = internal.NewNonRetryableError(fmt.Errorf("bad request"))
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	require.NoError(t, ro.Write())
+	require.Equal(t, 0, ro.BufferLength())
+	require.Len(t, m.Metrics(), 0)
+}
+
+func TestRunningOutputAdoptBuffer(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	old := NewRunningOutput("test", &mockOutput{}, conf, 5, 10)
+	for _, metric := range first5 {
+		old.AddMetric(metric)
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 5, 10)
+	require.True(t, ro.AdoptBuffer(old))
+	require.NoError(t, ro.Init())
+	require.Equal(t, 5, ro.BufferLength())
+
+	old.MoveBuffer(ro)
+	require.Equal(t, 5, ro.BufferLength())
+
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 5)
+}
+
+func TestRunningOutputAdoptDiskBufferMaxSize(t *testing.T) {
+	dir := tempBufferDir(t)
+	defer os.RemoveAll(dir)
+
+	conf := &OutputConfig{
+		Filter:          Filter{},
+		BufferStrategy:  "disk",
+		BufferDirectory: dir,
+	}
+	old := NewRunningOutput("test", &mockOutput{}, conf, 5, 10)
+	require.NoError(t, old.Init())
+	for _, metric := range first5 {
+		old.AddMetric(metric)
+	}
+
+	// The smaller maximum size is not applied to the live buffer until the
+	// replacement is committed.
+	small := *conf
+	small.BufferMaxSize = 1
+	ro := NewRunningOutput("test", &mockOutput{}, &small, 5, 10)
+	require.True(t, ro.AdoptBuffer(old))
+	require.NoError(t, ro.Init())
+	ro.Discard()
+	require.Equal(t, 5, old.BufferLength())
+
+	ro = NewRunningOutput("test", &mockOutput{}, &small, 5, 10)
+	require.True(t, ro.AdoptBuffer(old))
+	require.NoError(t, ro.Init())
+	old.MoveBuffer(ro)
+	require.Equal(t, 1, ro.BufferLength())
+	require.NoError(t, ro.buffer.Close())
+}
+
+func TestRunningOutputMoveBuffer(t *testing.T) {
+	old := NewRunningOutput("test", &mockOutput{}, &OutputConfig{
+		Filter: Filter{},
+	}, 2, 10)
+	for _, metric := range first5 {
+		old.AddMetric(metric)
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, &OutputConfig{
+		Filter: Filter{},
+	}, 2, 20)
+	require.False(t, ro.AdoptBuffer(old))
+	require.NoError(t, ro.Init())
+	require.Equal(t, 0, ro.BufferLength())
+
+	old.MoveBuffer(ro)
+	require.Equal(t, 0, old.BufferLength())
+	require.Equal(t, 5, ro.BufferLength())
+
+	require.NoError(t, ro.Write())
+	testutil.RequireMetricsEqual(t, first5, m.Metrics())
+}
+
 func TestInternalMetrics(t *testing.T) {
 	_ = NewRunningOutput(
 		"test_internal",
@@ -512,6 +755,49 @@ func TestInternalMetrics(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
 }
 
+func TestInternalMetricsPipeline(t *testing.T) {
+	_ = NewRunningOutput(
+		"test_internal",
+		&mockOutput{},
+		&OutputConfig{
+			Name:     "test_pipeline",
+			Pipeline: "infra",
+		},
+		5,
+		10)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"internal_write",
+			map[string]string{
+				"output":   "test_pipeline",
+				"pipeline": "infra",
+			},
+			map[string]interface{}{
+				"buffer_limit":     10,
+				"buffer_size":      0,
+				"errors":           0,
+				"metrics_added":    0,
+				"metrics_dropped":  0,
+				"metrics_filtered": 0,
+				"metrics_written":  0,
+				"write_time_ns":    0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range selfstat.Metrics() {
+		output, _ := m.GetTag("output")
+		if m.Name() == "internal_write" && output == "test_pipeline" {
+			actual = append(actual, m)
+		}
+	}
+
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -519,6 +805,12 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+
+	// if set, returned as the write error
+	writeErr error
+
+	// sizes of the batches written or attempted
+	batches []int
 }
 
 func (m *mockOutput) Connect() error {
@@ -540,9 +832,13 @@ func (m *mockOutput) SampleConfig() string {
 func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	m.Lock()
 	defer m.Unlock()
+	m.batches = append(m.batches, len(metrics))
 	if m.failWrite {
 		return fmt.Errorf("Failed Write!")
 	}
+	if m.writeErr != nil {
+		return m.writeErr
+	}
 
 	if m.metrics == nil {
 		m.metrics = []telegraf.Metric{}
@@ -12,6 +12,7 @@ type RunningProcessor struct {
 	log       telegraf.Logger
 	Processor telegraf.StreamingProcessor
 	Config    *ProcessorConfig
+	ID        string // name and hash of the configuration
 }
 
 type RunningProcessors []*RunningProcessor
@@ -0,0 +1,249 @@
+package quantile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ddSketch is a DDSketch: values are counted in buckets growing
+// exponentially, so that the quantiles are estimated within the relative
+// accuracy.  The number of buckets is bounded, when reached the buckets of
+// the values closest to zero are collapsed.
+type ddSketch struct {
+	accuracy float64
+	maxBins  int
+
+	gamma    float64
+	logGamma float64
+
+	positive store
+	negative store
+	zero     uint64
+}
+
+func newDDSketch(accuracy float64, maxBins int) *ddSketch {
+	gamma := (1 + accuracy) / (1 - accuracy)
+	return &ddSketch{
+		accuracy: accuracy,
+		maxBins:  maxBins,
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		positive: store{maxBins: maxBins},
+		negative: store{maxBins: maxBins},
+	}
+}
+
+// minIndexable is the smallest magnitude counted apart from zero.
+const minIndexable = 1e-300
+
+func (s *ddSketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logGamma))
+}
+
+func (s *ddSketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+func (s *ddSketch) Add(v float64) {
+	switch {
+	case v > minIndexable:
+		s.positive.add(s.index(v), 1)
+	case v < -minIndexable:
+		s.negative.add(s.index(-v), 1)
+	default:
+		s.zero++
+	}
+}
+
+func (s *ddSketch) count() uint64 {
+	return s.negative.count + s.zero + s.positive.count
+}
+
+func (s *ddSketch) Quantile(q float64) float64 {
+	n := s.count()
+	if n == 0 {
+		return math.NaN()
+	}
+
+	rank := math.Round(q * float64(n-1))
+	switch {
+	case rank < float64(s.negative.count):
+		return -s.value(s.negative.indexAtRank(float64(s.negative.count-1) - rank))
+	case rank < float64(s.negative.count+s.zero):
+		return 0
+	default:
+		return s.value(s.positive.indexAtRank(rank - float64(s.negative.count+s.zero)))
+	}
+}
+
+func (s *ddSketch) Merge(other sketch) error {
+	o, ok := other.(*ddSketch)
+	if !ok {
+		return fmt.Errorf("can not merge %T into a DDSketch", other)
+	}
+	if o.accuracy != s.accuracy {
+		return fmt.Errorf("can not merge DDSketch of relative accuracy %v into %v", o.accuracy, s.accuracy)
+	}
+
+	s.positive.merge(&o.positive)
+	s.negative.merge(&o.negative)
+	s.zero += o.zero
+	return nil
+}
+
+// MarshalBinary encodes the sketch as the relative accuracy, the maximum
+// number of buckets, the zero count and the positive and negative stores,
+// in big endian.
+func (s *ddSketch) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(algorithmDDSketch)
+	for _, v := range []interface{}{s.accuracy, uint32(s.maxBins), s.zero} {
+		if err := binary.Write(&buf, binary.BigEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, st := range []*store{&s.positive, &s.negative} {
+		if err := st.write(&buf); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalDDSketch(b []byte) (*ddSketch, error) {
+	r := bytes.NewReader(b)
+	var (
+		accuracy float64
+		maxBins  uint32
+		zero     uint64
+	)
+	for _, v := range []interface{}{&accuracy, &maxBins, &zero} {
+		if err := binary.Read(r, binary.BigEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	if accuracy <= 0 || accuracy >= 1 || maxBins == 0 {
+		return nil, fmt.Errorf("invalid DDSketch parameters")
+	}
+
+	s := newDDSketch(accuracy, int(maxBins))
+	s.zero = zero
+	for _, st := range []*store{&s.positive, &s.negative} {
+		if err := st.read(r); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// store counts values in contiguous buckets starting at the index offset.
+type store struct {
+	maxBins int
+
+	bins   []uint64
+	offset int
+	count  uint64
+}
+
+func (s *store) add(index int, n uint64) {
+	if s.count == 0 {
+		s.bins = []uint64{n}
+		s.offset = index
+		s.count = n
+		return
+	}
+
+	low, high := s.offset, s.offset+len(s.bins)-1
+	if index < low {
+		low = index
+	}
+	if index > high {
+		high = index
+	}
+	if high-low+1 > s.maxBins {
+		low = high - s.maxBins + 1
+	}
+	if index < low {
+		index = low
+	}
+
+	s.resize(low, high)
+	s.bins[index-s.offset] += n
+	s.count += n
+}
+
+// resize changes the range of the buckets, buckets below the new range are
+// collapsed into the lowest one.
+func (s *store) resize(low, high int) {
+	if low == s.offset && high == s.offset+len(s.bins)-1 {
+		return
+	}
+
+	bins := make([]uint64, high-low+1)
+	for i, n := range s.bins {
+		index := s.offset + i
+		if index < low {
+			index = low
+		}
+		bins[index-low] += n
+	}
+	s.bins = bins
+	s.offset = low
+}
+
+func (s *store) indexAtRank(rank float64) int {
+	var n uint64
+	for i, c := range s.bins {
+		n += c
+		if float64(n) > rank {
+			return s.offset + i
+		}
+	}
+	return s.offset + len(s.bins) - 1
+}
+
+func (s *store) merge(o *store) {
+	for i, n := range o.bins {
+		if n != 0 {
+			s.add(o.offset+i, n)
+		}
+	}
+}
+
+func (s *store) write(buf *bytes.Buffer) error {
+	for _, v := range []interface{}{int64(s.offset), uint32(len(s.bins)), s.bins} {
+		if err := binary.Write(buf, binary.BigEndian, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) read(r *bytes.Reader) error {
+	var (
+		offset int64
+		n      uint32
+	)
+	if err := binary.Read(r, binary.BigEndian, &offset); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	if int(n) > r.Len()/8 {
+		return fmt.Errorf("invalid DDSketch store of %d buckets", n)
+	}
+
+	bins := make([]uint64, n)
+	if err := binary.Read(r, binary.BigEndian, bins); err != nil {
+		return err
+	}
+	for i, c := range bins {
+		if c != 0 {
+			s.add(int(offset)+i, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package quantile
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+// sketchSuffix is the suffix of the fields holding serialized sketches.
+const sketchSuffix = "_sketch"
+
+// Defaults of the settings of the sketches.
+const (
+	defaultCompression      = 100
+	defaultRelativeAccuracy = 0.01
+	defaultMaxBins          = 2048
+)
+
+type Quantile struct {
+	Quantiles        []float64 `toml:"quantiles"`
+	Algorithm        string    `toml:"algorithm"`
+	Compression      float64   `toml:"compression"`
+	RelativeAccuracy float64   `toml:"relative_accuracy"`
+	MaxBins          int       `toml:"max_bins"`
+	EmitSketch       bool      `toml:"emit_sketch"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	suffixes []string
+	cache    map[uint64]aggregate
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]sketch
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## Quantiles to output in the range (0,1), as fields suffixed with "_p"
+  ## and the decimals of the quantile, ie, "_p50" for 0.5.
+  # quantiles = [0.5, 0.9, 0.99, 0.999]
+
+  ## Sketch used to estimate the quantiles, "t-digest" or "ddsketch".
+  # algorithm = "t-digest"
+
+  ## Compression of the t-digest, larger values are more accurate and use
+  ## more memory.
+  # compression = 100.0
+
+  ## Relative accuracy of the quantiles of the DDSketch, and maximum number of
+  ## buckets it uses.
+  # relative_accuracy = 0.01
+  # max_bins = 2048
+
+  ## If true, output the serialized sketch of each field, base64 encoded,
+  ## as a field suffixed with "_sketch".  Such fields passing through the
+  ## aggregator are merged, to compute quantiles over many hosts.
+  # emit_sketch = false
+`
+
+func (q *Quantile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (q *Quantile) Description() string {
+	return "Keep the aggregate quantiles of each metric passing through."
+}
+
+func (q *Quantile) Init() error {
+	switch q.Algorithm {
+	case "t-digest":
+		if q.Compression <= 0 {
+			return fmt.Errorf("compression must be positive")
+		}
+		if q.RelativeAccuracy != defaultRelativeAccuracy || q.MaxBins != defaultMaxBins {
+			return fmt.Errorf("relative_accuracy and max_bins only apply to the ddsketch algorithm")
+		}
+	case "ddsketch":
+		if q.RelativeAccuracy <= 0 || q.RelativeAccuracy >= 1 {
+			return fmt.Errorf("relative_accuracy must be in the range (0,1)")
+		}
+		if q.MaxBins <= 0 {
+			return fmt.Errorf("max_bins must be positive")
+		}
+		if q.Compression != defaultCompression {
+			return fmt.Errorf("compression only applies to the t-digest algorithm")
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q", q.Algorithm)
+	}
+
+	q.suffixes = make([]string, 0, len(q.Quantiles))
+	for _, v := range q.Quantiles {
+		if v <= 0 || v >= 1 {
+			return fmt.Errorf("quantile %v is not in the range (0,1)", v)
+		}
+		q.suffixes = append(q.suffixes, suffix(v))
+	}
+	return nil
+}
+
+// suffix returns the field suffix of a quantile, the decimals of the
+// quantile, with at least two digits.
+func suffix(q float64) string {
+	digits := strings.TrimPrefix(strconv.FormatFloat(q, 'f', -1, 64), "0.")
+	if len(digits) < 2 {
+		digits += "0"
+	}
+	return "_p" + digits
+}
+
+func (q *Quantile) newSketch() sketch {
+	if q.Algorithm == "ddsketch" {
+		return newDDSketch(q.RelativeAccuracy, q.MaxBins)
+	}
+	// The compression was checked by Init.
+	s, _ := newTDigest(q.Compression)
+	return s
+}
+
+func (q *Quantile) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, ok := q.cache[id]
+	if !ok {
+		a = aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]sketch),
+		}
+		q.cache[id] = a
+	}
+
+	for _, field := range in.FieldList() {
+		if s, ok := field.Value.(string); ok && strings.HasSuffix(field.Key, sketchSuffix) {
+			q.merge(a, strings.TrimSuffix(field.Key, sketchSuffix), s)
+			continue
+		}
+
+		fv, ok := convert(field.Value)
+		if !ok || math.IsNaN(fv) || math.IsInf(fv, 0) {
+			continue
+		}
+		s, ok := a.fields[field.Key]
+		if !ok {
+			s = q.newSketch()
+			a.fields[field.Key] = s
+		}
+		s.Add(fv)
+	}
+}
+
+// merge merges a serialized sketch into the sketch of the field.
+func (q *Quantile) merge(a aggregate, key, encoded string) {
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		q.Log.Errorf("Dropping sketch of field %q, decoding failed: %v", key, err)
+		return
+	}
+	other, err := unmarshalSketch(buf)
+	if err != nil {
+		q.Log.Errorf("Dropping sketch of field %q, decoding failed: %v", key, err)
+		return
+	}
+
+	s, ok := a.fields[key]
+	if !ok {
+		s = q.newSketch()
+	}
+	if err := s.Merge(other); err != nil {
+		q.Log.Errorf("Dropping sketch of field %q, merging failed: %v", key, err)
+		return
+	}
+	a.fields[key] = s
+}
+
+func (q *Quantile) Push(acc telegraf.Accumulator) {
+	for _, a := range q.cache {
+		fields := make(map[string]interface{}, len(a.fields)*(len(q.Quantiles)+1))
+		for key, s := range a.fields {
+			for i, v := range q.Quantiles {
+				fields[key+q.suffixes[i]] = s.Quantile(v)
+			}
+
+			if q.EmitSketch {
+				buf, err := s.MarshalBinary()
+				if err != nil {
+					q.Log.Errorf("Serializing sketch of field %q: %v", key, err)
+					continue
+				}
+				fields[key+sketchSuffix] = base64.StdEncoding.EncodeToString(buf)
+			}
+		}
+		if len(fields) > 0 {
+			acc.AddFields(a.name, fields, a.tags)
+		}
+	}
+}
+
+func (q *Quantile) Reset() {
+	q.cache = make(map[uint64]aggregate)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func NewQuantile() *Quantile {
+	q := &Quantile{
+		Quantiles:        []float64{0.5, 0.9, 0.99, 0.999},
+		Algorithm:        "t-digest",
+		Compression:      defaultCompression,
+		RelativeAccuracy: defaultRelativeAccuracy,
+		MaxBins:          defaultMaxBins,
+	}
+	q.Reset()
+	return q
+}
+
+func init() {
+	aggregators.Add("quantile", func() telegraf.Aggregator {
+		return NewQuantile()
+	})
+}
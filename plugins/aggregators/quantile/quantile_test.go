@@ -0,0 +1,199 @@
+package quantile
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuantile(t *testing.T, algorithm string) *Quantile {
+	q := NewQuantile()
+	q.Algorithm = algorithm
+	q.Log = testutil.Logger{}
+	require.NoError(t, q.Init())
+	return q
+}
+
+// errorLogger counts the logged errors.
+type errorLogger struct {
+	testutil.Logger
+	errors int
+}
+
+func (l *errorLogger) Errorf(format string, args ...interface{}) {
+	l.errors++
+	l.Logger.Errorf(format, args...)
+}
+
+func addValues(q *Quantile, from, to int) {
+	for i := from; i <= to; i++ {
+		q.Add(testutil.MustMetric("latency",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": float64(i), "status": "ok"},
+			time.Unix(0, 0)))
+	}
+}
+
+func requireWithin(t *testing.T, expected, actual, accuracy float64) {
+	t.Helper()
+	require.Truef(t, math.Abs(actual-expected) <= accuracy*math.Abs(expected),
+		"%v not within %v of %v", actual, accuracy, expected)
+}
+
+func pushed(t *testing.T, q *Quantile) telegraf.Metric {
+	var acc testutil.Accumulator
+	q.Push(&acc)
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	return metrics[0]
+}
+
+func TestQuantiles(t *testing.T) {
+	for _, algorithm := range []string{"t-digest", "ddsketch"} {
+		t.Run(algorithm, func(t *testing.T) {
+			q := newQuantile(t, algorithm)
+			addValues(q, 1, 1000)
+
+			m := pushed(t, q)
+			require.Equal(t, "latency", m.Name())
+			require.Equal(t, map[string]string{"host": "a"}, m.Tags())
+			require.Len(t, m.FieldList(), 4)
+
+			expected := map[string]float64{
+				"value_p50":  500,
+				"value_p90":  900,
+				"value_p99":  990,
+				"value_p999": 999,
+			}
+			for key, v := range expected {
+				actual, ok := m.GetField(key)
+				require.True(t, ok, key)
+				requireWithin(t, v, actual.(float64), 0.02)
+			}
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	q := newQuantile(t, "t-digest")
+	addValues(q, 1, 10)
+	q.Reset()
+
+	var acc testutil.Accumulator
+	q.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestMergeSketches(t *testing.T) {
+	for _, algorithm := range []string{"t-digest", "ddsketch"} {
+		t.Run(algorithm, func(t *testing.T) {
+			// Two hosts each see half of the values.
+			var hosts []telegraf.Metric
+			for _, r := range [][2]int{{1, 500}, {501, 1000}} {
+				q := newQuantile(t, algorithm)
+				q.EmitSketch = true
+				addValues(q, r[0], r[1])
+				hosts = append(hosts, pushed(t, q))
+			}
+
+			q := newQuantile(t, algorithm)
+			for _, m := range hosts {
+				q.Add(m)
+			}
+
+			m := pushed(t, q)
+			p50, _ := m.GetField("value_p50")
+			requireWithin(t, 500, p50.(float64), 0.02)
+			p99, _ := m.GetField("value_p99")
+			requireWithin(t, 990, p99.(float64), 0.02)
+		})
+	}
+}
+
+func TestMergeMismatch(t *testing.T) {
+	q := newQuantile(t, "ddsketch")
+	q.EmitSketch = true
+	addValues(q, 1, 10)
+	m := pushed(t, q)
+
+	digest := newQuantile(t, "t-digest")
+	log := &errorLogger{}
+	digest.Log = log
+	sketch, _ := m.GetField("value_sketch")
+	digest.Add(testutil.MustMetric("latency",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value_sketch": sketch, "other_sketch": "not base64!"},
+		time.Unix(0, 0)))
+
+	var acc testutil.Accumulator
+	digest.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Equal(t, 2, log.errors)
+}
+
+func TestSuffix(t *testing.T) {
+	tests := map[float64]string{
+		0.5:   "_p50",
+		0.05:  "_p05",
+		0.9:   "_p90",
+		0.99:  "_p99",
+		0.999: "_p999",
+		0.25:  "_p25",
+	}
+	for q, expected := range tests {
+		require.Equal(t, expected, suffix(q))
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []func(q *Quantile){
+		func(q *Quantile) { q.Algorithm = "hdr" },
+		func(q *Quantile) { q.Quantiles = []float64{1} },
+		func(q *Quantile) { q.Compression = 0 },
+		func(q *Quantile) { q.Algorithm = "ddsketch"; q.RelativeAccuracy = 1 },
+		func(q *Quantile) { q.Algorithm = "ddsketch"; q.MaxBins = 0 },
+		func(q *Quantile) { q.RelativeAccuracy = 0.001 },
+		func(q *Quantile) { q.MaxBins = 4096 },
+		func(q *Quantile) { q.Algorithm = "ddsketch"; q.Compression = 200 },
+	}
+	for _, tt := range tests {
+		q := NewQuantile()
+		tt(q)
+		require.Error(t, q.Init())
+	}
+}
+
+func TestDDSketch(t *testing.T) {
+	s := newDDSketch(0.01, 2048)
+	for _, v := range []float64{-100, -10, 0, 10, 100} {
+		s.Add(v)
+	}
+	requireWithin(t, -100, s.Quantile(0.01), 0.01)
+	require.Equal(t, 0.0, s.Quantile(0.5))
+	requireWithin(t, 100, s.Quantile(0.99), 0.01)
+
+	buf, err := s.MarshalBinary()
+	require.NoError(t, err)
+	decoded, err := unmarshalSketch(buf)
+	require.NoError(t, err)
+	require.Equal(t, s, decoded)
+
+	_, err = unmarshalSketch(buf[:len(buf)-1])
+	require.Error(t, err)
+}
+
+func TestDDSketchMaxBins(t *testing.T) {
+	s := newDDSketch(0.01, 64)
+	for i := 0; i < 1000; i++ {
+		s.Add(math.Pow(1.1, float64(i%200)))
+	}
+	require.LessOrEqual(t, len(s.positive.bins), 64)
+	require.Equal(t, uint64(1000), s.positive.count)
+
+	// The highest values keep their accuracy.
+	requireWithin(t, math.Pow(1.1, 199), s.Quantile(1), 0.01)
+}
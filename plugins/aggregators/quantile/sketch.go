@@ -0,0 +1,79 @@
+package quantile
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/caio/go-tdigest"
+)
+
+// The first byte of a serialized sketch is its algorithm.
+const (
+	algorithmTDigest  byte = 1
+	algorithmDDSketch byte = 2
+)
+
+// sketch estimates the quantiles of the values added to it.
+type sketch interface {
+	Add(v float64)
+	Quantile(q float64) float64
+	Merge(other sketch) error
+	MarshalBinary() ([]byte, error)
+}
+
+type tDigest struct {
+	digest *tdigest.TDigest
+}
+
+func newTDigest(compression float64) (*tDigest, error) {
+	digest, err := tdigest.New(tdigest.Compression(uint32(compression)))
+	if err != nil {
+		return nil, err
+	}
+	return &tDigest{digest: digest}, nil
+}
+
+func (t *tDigest) Add(v float64) {
+	// Errors are only returned for a zero count.
+	_ = t.digest.Add(v)
+}
+
+func (t *tDigest) Quantile(q float64) float64 {
+	return t.digest.Quantile(q)
+}
+
+func (t *tDigest) Merge(other sketch) error {
+	o, ok := other.(*tDigest)
+	if !ok {
+		return fmt.Errorf("can not merge %T into a t-digest", other)
+	}
+	return t.digest.Merge(o.digest)
+}
+
+func (t *tDigest) MarshalBinary() ([]byte, error) {
+	b, err := t.digest.AsBytes()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{algorithmTDigest}, b...), nil
+}
+
+// unmarshalSketch decodes a sketch serialized by MarshalBinary.
+func unmarshalSketch(b []byte) (sketch, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty sketch")
+	}
+
+	switch b[0] {
+	case algorithmTDigest:
+		digest, err := tdigest.FromBytes(bytes.NewReader(b[1:]))
+		if err != nil {
+			return nil, err
+		}
+		return &tDigest{digest: digest}, nil
+	case algorithmDDSketch:
+		return unmarshalDDSketch(b[1:])
+	default:
+		return nil, fmt.Errorf("unknown sketch algorithm %d", b[0])
+	}
+}
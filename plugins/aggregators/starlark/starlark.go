@@ -0,0 +1,149 @@
+package starlark
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	common "github.com/influxdata/telegraf/plugins/common/starlark"
+	"go.starlark.net/starlark"
+)
+
+const (
+	description  = "Aggregate metrics using a Starlark script"
+	sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## The Starlark source can be set as a string in this configuration file, or
+  ## by referencing a file containing the script.  Only one source or script
+  ## should be set at once.
+  ##
+  ## Source of the Starlark script.
+  ## The script must define the add, push and reset functions.  The state
+  ## dict is kept between the calls, unlike global variables which can not be
+  ## modified.
+  source = '''
+def add(metric):
+  state["last"] = metric
+
+def push():
+  return state.get("last")
+
+def reset():
+  state.clear()
+'''
+
+  ## File containing a Starlark script.
+  # script = "/usr/local/bin/myscript.star"
+
+  ## Directory of the .star libraries the script can load, in addition to
+  ## the bundled json, math, time and logging modules.
+  # library_dir = "/usr/local/lib/telegraf/starlark"
+`
+)
+
+type Starlark struct {
+	Source     string `toml:"source"`
+	Script     string `toml:"script"`
+	LibraryDir string `toml:"library_dir"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	script    *common.Script
+	addFunc   *starlark.Function
+	pushFunc  *starlark.Function
+	resetFunc *starlark.Function
+}
+
+func (s *Starlark) Init() error {
+	// The state dict is kept between calls, unlike the frozen globals of the
+	// script.
+	predeclared := starlark.StringDict{
+		"state": starlark.NewDict(0),
+	}
+
+	var err error
+	s.script, err = common.NewScript("aggregator.starlark", s.Source, s.Script, common.Options{Predeclared: predeclared, LibraryDir: s.LibraryDir}, s.Log)
+	if err != nil {
+		return err
+	}
+
+	// The source should define the add, push and reset functions.
+	if s.addFunc, err = s.script.Function("add", 1); err != nil {
+		return err
+	}
+	if s.pushFunc, err = s.script.Function("push", 0); err != nil {
+		return err
+	}
+	if s.resetFunc, err = s.script.Function("reset", 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Starlark) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Starlark) Description() string {
+	return description
+}
+
+func (s *Starlark) Add(metric telegraf.Metric) {
+	// Scripts may keep the metric in their state, each gets its own wrapper.
+	m := &common.Metric{}
+	m.Wrap(metric)
+
+	if _, err := s.script.Call(s.addFunc, starlark.Tuple{m}); err != nil {
+		s.Log.Errorf("Error calling add: %v", err)
+	}
+}
+
+func (s *Starlark) Push(acc telegraf.Accumulator) {
+	rv, err := s.script.Call(s.pushFunc, nil)
+	if err != nil {
+		s.Log.Errorf("Error calling push: %v", err)
+		return
+	}
+
+	switch rv := rv.(type) {
+	case *starlark.List:
+		iter := rv.Iterate()
+		defer iter.Done()
+		var v starlark.Value
+		for iter.Next(&v) {
+			switch v := v.(type) {
+			case *common.Metric:
+				s.addMetric(v, acc)
+			default:
+				s.Log.Errorf("Invalid type returned in list: %s", v.Type())
+			}
+		}
+	case *common.Metric:
+		s.addMetric(rv, acc)
+	case starlark.NoneType:
+	default:
+		s.Log.Errorf("Invalid type returned: %T", rv)
+	}
+}
+
+// addMetric adds a copy of the metric, scripts may still hold and modify the
+// metric afterwards.
+func (s *Starlark) addMetric(m *common.Metric, acc telegraf.Accumulator) {
+	acc.AddMetric(m.Unwrap().Copy())
+}
+
+func (s *Starlark) Reset() {
+	if _, err := s.script.Call(s.resetFunc, nil); err != nil {
+		s.Log.Errorf("Error calling reset: %v", err)
+	}
+}
+
+func init() {
+	aggregators.Add("starlark", func() telegraf.Aggregator {
+		return &Starlark{}
+	})
+}
@@ -0,0 +1,235 @@
+package starlark
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newStarlark(t *testing.T, source, script string) *Starlark {
+	s := &Starlark{
+		Source: source,
+		Script: script,
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+	return s
+}
+
+func TestInitError(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{
+			name: "add must be defined",
+			source: `
+def push():
+  return None
+
+def reset():
+  pass
+`,
+		},
+		{
+			name: "push must take no parameters",
+			source: `
+def add(metric):
+  pass
+
+def push(x):
+  return None
+
+def reset():
+  pass
+`,
+		},
+		{
+			name: "reset must be defined",
+			source: `
+def add(metric):
+  pass
+
+def push():
+  return None
+`,
+		},
+		{
+			name: "no source no script",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Starlark{Source: tt.source, Log: testutil.Logger{}}
+			require.Error(t, s.Init())
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	s := newStarlark(t, `
+def add(metric):
+  state["count"] = state.get("count", 0) + 1
+
+def push():
+  m = Metric("count")
+  m.fields["count"] = state.get("count", 0)
+  m.time = 0
+  return m
+
+def reset():
+  state.clear()
+`, "")
+
+	for i := 0; i < 3; i++ {
+		s.Add(testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0)))
+	}
+
+	var acc testutil.Accumulator
+	s.Push(&acc)
+	s.Reset()
+	s.Push(&acc)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("count",
+			map[string]string{},
+			map[string]interface{}{"count": 3},
+			time.Unix(0, 0)),
+		testutil.MustMetric("count",
+			map[string]string{},
+			map[string]interface{}{"count": 0},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestScriptErrors(t *testing.T) {
+	s := newStarlark(t, `
+def add(metric):
+  fail("add")
+
+def push():
+  return 42
+
+def reset():
+  fail("reset")
+`, "")
+
+	var acc testutil.Accumulator
+	s.Add(testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0)))
+	s.Push(&acc)
+	s.Reset()
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestWeightedAverage(t *testing.T) {
+	s := newStarlark(t, "", filepath.Join("testdata", "weighted_average.star"))
+
+	s.Add(testutil.MustMetric("latency",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": 10.0, "count": 1},
+		time.Unix(1600000000, 0)))
+	s.Add(testutil.MustMetric("latency",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": 20.0, "count": 3},
+		time.Unix(1600000001, 0)))
+
+	var acc testutil.Accumulator
+	s.Push(&acc)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("latency",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value_weighted_average": 17.5},
+			time.Unix(1600000001, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestJoin(t *testing.T) {
+	s := newStarlark(t, "", filepath.Join("testdata", "join.star"))
+
+	s.Add(testutil.MustMetric("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage": 50.0},
+		time.Unix(1600000000, 0)))
+	s.Add(testutil.MustMetric("mem",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"used": 1000},
+		time.Unix(1600000000, 0)))
+
+	var acc testutil.Accumulator
+	s.Push(&acc)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("host",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"cpu_usage": 50.0, "mem_used": 1000, "mem_per_cpu": 20.0},
+			time.Unix(1600000000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+// The running aggregator drops metrics outside of the period and grace, and
+// resets the script after each push.
+func TestRunningAggregator(t *testing.T) {
+	s := newStarlark(t, `
+def add(metric):
+  state["sum"] = state.get("sum", 0) + metric.fields["value"]
+
+def push():
+  m = Metric("sum")
+  m.fields["sum"] = state.get("sum", 0)
+  return m
+
+def reset():
+  state.clear()
+`, "")
+
+	ra := models.NewRunningAggregator(s, &models.AggregatorConfig{
+		Name: "starlark",
+		Filter: models.Filter{
+			NamePass: []string{"*"},
+		},
+		Period: time.Minute,
+		Grace:  time.Second * 10,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+	require.NoError(t, ra.Init())
+
+	now := time.Now()
+	ra.UpdateWindow(now, now.Add(ra.Config.Period))
+
+	for _, tm := range []time.Time{
+		now.Add(time.Second),
+		now.Add(-time.Second * 5), // within the grace
+		now.Add(-time.Hour),       // dropped
+	} {
+		ra.Add(testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 1},
+			tm))
+	}
+
+	var acc testutil.Accumulator
+	ra.Push(&acc)
+	ra.Push(&acc)
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	sum, _ := metrics[0].GetField("sum")
+	require.Equal(t, int64(2), sum)
+	sum, _ = metrics[1].GetField("sum")
+	require.Equal(t, int64(0), sum)
+}
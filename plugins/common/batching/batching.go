@@ -0,0 +1,292 @@
+// Package batching splits the metrics written by outputs using a serializer
+// into requests limited in size and number of metrics, and sends them.
+package batching
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Config is the batching configuration, to embed in output plugins.  A zero
+// limit means no limit.
+type Config struct {
+	MaxRequestBodySize    config.Size `toml:"max_request_body_size"`
+	MaxMetricsPerRequest  int         `toml:"max_metrics_per_request"`
+	MaxConcurrentRequests int         `toml:"max_concurrent_requests"`
+
+	// Alias is the alias of the output, set with SetAlias.
+	Alias string `toml:"-"`
+}
+
+// SetAlias sets the alias of the output, added to the tags of the internal
+// metrics of the batcher.
+func (c *Config) SetAlias(alias string) {
+	c.Alias = alias
+}
+
+// SendFunc sends the body of a request, encoded with the content encoding
+// of the batcher.
+type SendFunc func(body []byte) error
+
+// Batcher splits metrics into requests and sends them.
+type Batcher struct {
+	Config
+
+	name            string
+	serializer      serializers.Serializer
+	contentEncoding string
+
+	chunks       selfstat.Stat
+	chunkBytes   selfstat.Stat
+	chunkMetrics selfstat.Stat
+}
+
+// NewBatcher returns a batcher serializing metrics of the output with the
+// serializer, and encoding requests with the content encoding, "identity" or
+// "gzip".
+func (c *Config) NewBatcher(output string, serializer serializers.Serializer, contentEncoding string) (*Batcher, error) {
+	if serializer == nil {
+		return nil, fmt.Errorf("%s: serializer unset", output)
+	}
+	if _, err := internal.NewContentEncoder(contentEncoding); err != nil {
+		return nil, err
+	}
+	if c.MaxRequestBodySize < 0 || c.MaxMetricsPerRequest < 0 || c.MaxConcurrentRequests < 0 {
+		return nil, fmt.Errorf("%s: batching limits must not be negative", output)
+	}
+
+	tags := map[string]string{"output": output}
+	if c.Alias != "" {
+		tags["alias"] = c.Alias
+	}
+	return &Batcher{
+		Config:          *c,
+		name:            output,
+		serializer:      serializer,
+		contentEncoding: contentEncoding,
+		chunks:          selfstat.Register("batching", "chunks", tags),
+		chunkBytes:      selfstat.Register("batching", "chunk_bytes", tags),
+		chunkMetrics:    selfstat.Register("batching", "chunk_metrics", tags),
+	}, nil
+}
+
+// chunk is the serialized body of a request and the number of metrics in it.
+type chunk struct {
+	body    []byte
+	metrics int
+}
+
+// Split serializes the metrics into the bodies of the requests, before
+// encoding.
+func (b *Batcher) Split(metrics []telegraf.Metric) ([][]byte, error) {
+	chunks, err := b.split(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make([][]byte, 0, len(chunks))
+	for _, c := range chunks {
+		bodies = append(bodies, c.body)
+	}
+	return bodies, nil
+}
+
+func (b *Batcher) split(metrics []telegraf.Metric) ([]chunk, error) {
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	if b.MaxRequestBodySize == 0 && (b.MaxMetricsPerRequest == 0 || len(metrics) <= b.MaxMetricsPerRequest) {
+		body, err := b.serializer.SerializeBatch(metrics)
+		if err != nil {
+			return nil, err
+		}
+		return []chunk{{body: body, metrics: len(metrics)}}, nil
+	}
+
+	groups, err := b.group(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []chunk
+	for _, group := range groups {
+		c, err := b.serializeGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, c...)
+	}
+	return chunks, nil
+}
+
+// group packs the metrics into groups within the limits, using the size of
+// each metric serialized on its own.
+func (b *Batcher) group(metrics []telegraf.Metric) ([][]telegraf.Metric, error) {
+	var (
+		groups [][]telegraf.Metric
+		start  int
+		size   int
+	)
+	for i, m := range metrics {
+		var l int
+		if b.MaxRequestBodySize > 0 {
+			buf, err := b.serializer.Serialize(m)
+			if err != nil {
+				return nil, err
+			}
+			l = len(buf)
+		}
+
+		full := b.MaxMetricsPerRequest > 0 && i-start >= b.MaxMetricsPerRequest
+		tooBig := b.MaxRequestBodySize > 0 && size+l > int(b.MaxRequestBodySize)
+		if i > start && (full || tooBig) {
+			groups = append(groups, metrics[start:i])
+			start, size = i, 0
+		}
+		size += l
+	}
+	return append(groups, metrics[start:]), nil
+}
+
+// serializeGroup serializes the group as a batch, splitting it in halves
+// while the batch is larger than the single metrics predicted.  A metric too
+// large on its own is sent anyway.
+func (b *Batcher) serializeGroup(metrics []telegraf.Metric) ([]chunk, error) {
+	body, err := b.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.MaxRequestBodySize == 0 || len(body) <= int(b.MaxRequestBodySize) {
+		return []chunk{{body: body, metrics: len(metrics)}}, nil
+	}
+	if len(metrics) == 1 {
+		log.Printf("W! [outputs.%s] max_request_body_size set to %d which is too small even for a single metric (len: %d), sending without split",
+			b.name, b.MaxRequestBodySize, len(body))
+		return []chunk{{body: body, metrics: 1}}, nil
+	}
+
+	half := len(metrics) / 2
+	first, err := b.serializeGroup(metrics[:half])
+	if err != nil {
+		return nil, err
+	}
+	second, err := b.serializeGroup(metrics[half:])
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
+
+// WriteError is returned by Write when some of the requests failed.  It
+// wraps a retryable error when any request failed with one, so that the
+// batch is only dropped when all failed requests are not retryable.
+type WriteError struct {
+	// Requests is the number of requests of the batch.
+	Requests int
+	// Failed maps the index of each failed request to its error.
+	Failed map[int]error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("%d of %d requests failed: %v", len(e.Failed), e.Requests, e.Unwrap())
+}
+
+// Unwrap returns the error of the first failed request, preferring
+// retryable errors.
+func (e *WriteError) Unwrap() error {
+	var first error
+	for i := 0; i < e.Requests; i++ {
+		err, ok := e.Failed[i]
+		if !ok {
+			continue
+		}
+		if !internal.IsNonRetryable(err) {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Succeeded returns the indexes of the requests sent successfully.
+func (e *WriteError) Succeeded() []int {
+	var sent []int
+	for i := 0; i < e.Requests; i++ {
+		if _, ok := e.Failed[i]; !ok {
+			sent = append(sent, i)
+		}
+	}
+	return sent
+}
+
+// Write splits the metrics into requests, encodes them and sends them with
+// up to MaxConcurrentRequests requests in flight.  All requests are tried,
+// when any of them fails a *WriteError is returned.
+func (b *Batcher) Write(metrics []telegraf.Metric, send SendFunc) error {
+	chunks, err := b.split(metrics)
+	if err != nil {
+		return err
+	}
+
+	concurrency := b.MaxConcurrentRequests
+	if concurrency == 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = make(map[int]error)
+		sem    = make(chan struct{}, concurrency)
+	)
+	for i, c := range chunks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, c chunk) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := b.send(c, send); err != nil {
+				mu.Lock()
+				failed[i] = err
+				mu.Unlock()
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return &WriteError{Requests: len(chunks), Failed: failed}
+}
+
+func (b *Batcher) send(c chunk, send SendFunc) error {
+	// Encoders reuse their buffer, each request gets its own.
+	encoder, err := internal.NewContentEncoder(b.contentEncoding)
+	if err != nil {
+		return err
+	}
+	body, err := encoder.Encode(c.body)
+	if err != nil {
+		return err
+	}
+
+	b.chunks.Incr(1)
+	b.chunkBytes.Incr(int64(len(body)))
+	b.chunkMetrics.Incr(int64(c.metrics))
+	return send(body)
+}
@@ -0,0 +1,237 @@
+package batching
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/influxdata/toml"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetrics(count int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		metrics = append(metrics, testutil.MustMetric("cpu",
+			map[string]string{"cpu": fmt.Sprintf("cpu%d", i)},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0)))
+	}
+	return metrics
+}
+
+func TestSplitUnlimited(t *testing.T) {
+	c := &Config{}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	chunks, err := b.Split(testMetrics(10))
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+}
+
+func TestSplitMaxMetrics(t *testing.T) {
+	c := &Config{MaxMetricsPerRequest: 4}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	chunks, err := b.Split(testMetrics(10))
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	require.Equal(t, 4, bytes.Count(chunks[0], []byte("\n")))
+	require.Equal(t, 2, bytes.Count(chunks[2], []byte("\n")))
+}
+
+func TestSplitMaxBytes(t *testing.T) {
+	// Each metric is "cpu,cpu=cpuN value=42 0\n", 24 bytes.
+	c := &Config{MaxRequestBodySize: config.Size(50)}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	chunks, err := b.Split(testMetrics(5))
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+	for _, chunk := range chunks {
+		require.LessOrEqual(t, len(chunk), 50)
+	}
+}
+
+func TestSplitBatchFormat(t *testing.T) {
+	// The json batch adds its envelope, groups are split again when the
+	// batch does not fit.
+	s, err := json.NewSerializer(0)
+	require.NoError(t, err)
+	single, err := s.Serialize(testMetrics(1)[0])
+	require.NoError(t, err)
+
+	c := &Config{MaxRequestBodySize: config.Size(int64(2 * len(single)))}
+	b, err := c.NewBatcher("test", s, "")
+	require.NoError(t, err)
+
+	chunks, err := b.Split(testMetrics(4))
+	require.NoError(t, err)
+	require.Len(t, chunks, 4)
+	for _, chunk := range chunks {
+		require.True(t, bytes.HasPrefix(chunk, []byte(`{"metrics":[`)))
+	}
+}
+
+func TestSplitTooLarge(t *testing.T) {
+	c := &Config{MaxRequestBodySize: config.Size(10)}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	chunks, err := b.Split(testMetrics(3))
+	require.NoError(t, err)
+	require.Len(t, chunks, 3)
+}
+
+func TestWriteGzip(t *testing.T) {
+	c := &Config{MaxMetricsPerRequest: 2, MaxConcurrentRequests: 2}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "gzip")
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		lines int
+	)
+	// The stats are shared by the batchers of the same output
+	chunks := b.chunks.Get()
+	err = b.Write(testMetrics(5), func(body []byte) error {
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		buf, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		lines += bytes.Count(buf, []byte("\n"))
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, lines)
+	require.Equal(t, chunks+3, b.chunks.Get())
+}
+
+func TestWriteConcurrency(t *testing.T) {
+	c := &Config{MaxMetricsPerRequest: 1, MaxConcurrentRequests: 3}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	var inFlight, maxInFlight int32
+	err = b.Write(testMetrics(10), func(body []byte) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil
+	})
+	require.NoError(t, err)
+	require.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+}
+
+func TestWriteError(t *testing.T) {
+	c := &Config{MaxMetricsPerRequest: 1}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	var sent int
+	err = b.Write(testMetrics(3), func(body []byte) error {
+		sent++
+		return errors.New("unavailable")
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, sent)
+}
+
+func TestWriteErrorRetryable(t *testing.T) {
+	c := &Config{MaxMetricsPerRequest: 1}
+	b, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	// The requests are sent in order without concurrency
+	var sent int
+	results := []error{
+		internal.NewNonRetryableError(errors.New("bad request")),
+		nil,
+		errors.New("unavailable"),
+	}
+	err = b.Write(testMetrics(3), func(body []byte) error {
+		err := results[sent]
+		sent++
+		return err
+	})
+	require.Error(t, err)
+	require.False(t, internal.IsNonRetryable(err))
+	require.Contains(t, err.Error(), "2 of 3 requests failed: unavailable")
+
+	var werr *WriteError
+	require.True(t, errors.As(err, &werr))
+	require.Equal(t, []int{1}, werr.Succeeded())
+
+	sent = 0
+	results = []error{nil, internal.NewNonRetryableError(errors.New("bad request")), nil}
+	err = b.Write(testMetrics(3), func(body []byte) error {
+		err := results[sent]
+		sent++
+		return err
+	})
+	require.True(t, internal.IsNonRetryable(err))
+}
+
+func TestNewBatcherAlias(t *testing.T) {
+	c := &Config{}
+	c.SetAlias("batching_alias")
+	_, err := c.NewBatcher("test", influx.NewSerializer(), "")
+	require.NoError(t, err)
+
+	var found bool
+	for _, m := range selfstat.Metrics() {
+		if alias, _ := m.GetTag("alias"); m.Name() == "internal_batching" && alias == "batching_alias" {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestNewBatcherErrors(t *testing.T) {
+	c := &Config{}
+	_, err := c.NewBatcher("test", influx.NewSerializer(), "br")
+	require.Error(t, err)
+
+	_, err = c.NewBatcher("test", nil, "")
+	require.Error(t, err)
+
+	c = &Config{MaxMetricsPerRequest: -1}
+	_, err = c.NewBatcher("test", influx.NewSerializer(), "")
+	require.Error(t, err)
+}
+
+func TestConfigMaxRequestBodySize(t *testing.T) {
+	for _, value := range []string{`1000`, `"1000"`, `"1KB"`} {
+		var c Config
+		require.NoError(t, toml.Unmarshal([]byte("max_request_body_size = "+value), &c), value)
+		require.Equal(t, config.Size(1000), c.MaxRequestBodySize, value)
+	}
+}
@@ -2,15 +2,17 @@ package kafka
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/Shopify/sarama"
+	"github.com/influxdata/telegraf/internal"
 )
 
 type SASLAuth struct {
-	SASLUsername  string `toml:"sasl_username"`
-	SASLPassword  string `toml:"sasl_password"`
-	SASLMechanism string `toml:"sasl_mechanism"`
-	SASLVersion   *int   `toml:"sasl_version"`
+	SASLUsername  string          `toml:"sasl_username"`
+	SASLPassword  internal.Secret `toml:"sasl_password"`
+	SASLMechanism string          `toml:"sasl_mechanism"`
+	SASLVersion   *int            `toml:"sasl_version"`
 
 	// GSSAPI config
 	SASLGSSAPIServiceName        string `toml:"sasl_gssapi_service_name"`
@@ -21,13 +23,17 @@ type SASLAuth struct {
 	SASLGSSAPIRealm              string `toml:"sasl_gssapi_realm"`
 
 	// OAUTHBEARER config. experimental. undoubtedly this is not good enough.
-	SASLAccessToken string `toml:"sasl_access_token"`
+	SASLAccessToken internal.Secret `toml:"sasl_access_token"`
 }
 
 // SetSASLConfig configures SASL for kafka (sarama)
 func (k *SASLAuth) SetSASLConfig(config *sarama.Config) error {
+	password, err := k.SASLPassword.Get()
+	if err != nil {
+		return fmt.Errorf("getting SASL password: %w", err)
+	}
 	config.Net.SASL.User = k.SASLUsername
-	config.Net.SASL.Password = k.SASLPassword
+	config.Net.SASL.Password = password
 
 	if k.SASLMechanism != "" {
 		config.Net.SASL.Mechanism = sarama.SASLMechanism(k.SASLMechanism)
@@ -46,7 +52,7 @@ func (k *SASLAuth) SetSASLConfig(config *sarama.Config) error {
 			config.Net.SASL.GSSAPI.ServiceName = k.SASLGSSAPIServiceName
 			config.Net.SASL.GSSAPI.AuthType = gssapiAuthType(k.SASLGSSAPIAuthType)
 			config.Net.SASL.GSSAPI.Username = k.SASLUsername
-			config.Net.SASL.GSSAPI.Password = k.SASLPassword
+			config.Net.SASL.GSSAPI.Password = password
 			config.Net.SASL.GSSAPI.DisablePAFXFAST = k.SASLGSSAPIDisablePAFXFAST
 			config.Net.SASL.GSSAPI.KerberosConfigPath = k.SASLGSSAPIKerberosConfigPath
 			config.Net.SASL.GSSAPI.KeyTabPath = k.SASLGSSAPIKeyTabPath
@@ -72,8 +78,12 @@ func (k *SASLAuth) SetSASLConfig(config *sarama.Config) error {
 
 // Token does nothing smart, it just grabs a hard-coded token from config.
 func (k *SASLAuth) Token() (*sarama.AccessToken, error) {
+	token, err := k.SASLAccessToken.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting SASL access token: %w", err)
+	}
 	return &sarama.AccessToken{
-		Token:      k.SASLAccessToken,
+		Token:      token,
 		Extensions: map[string]string{},
 	}, nil
 }
@@ -0,0 +1,210 @@
+// Package msgpack holds the MessagePack encoding of metrics shared by the
+// msgpack serializer and parser.  Each metric is a map with the keys "name",
+// "time", "tags" and "fields", the time is a MessagePack timestamp extension.
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// Metric is the encoded form of a metric.
+type Metric struct {
+	Name   string
+	Time   time.Time
+	Tags   map[string]string
+	Fields map[string]interface{}
+}
+
+// MarshalMsg appends the encoding of the metric to b.
+func (m *Metric) MarshalMsg(b []byte) ([]byte, error) {
+	b = msgp.AppendMapHeader(b, 4)
+
+	b = msgp.AppendString(b, "name")
+	b = msgp.AppendString(b, m.Name)
+
+	b = msgp.AppendString(b, "time")
+	ts := Timestamp(m.Time)
+	b, err := msgp.AppendExtension(b, &ts)
+	if err != nil {
+		return b, err
+	}
+
+	b = msgp.AppendString(b, "tags")
+	b = msgp.AppendMapStrStr(b, m.Tags)
+
+	b = msgp.AppendString(b, "fields")
+	b = msgp.AppendMapHeader(b, uint32(len(m.Fields)))
+	for k, v := range m.Fields {
+		b = msgp.AppendString(b, k)
+		switch v := v.(type) {
+		case int64:
+			b = msgp.AppendInt64(b, v)
+		case uint64:
+			b = appendUint64(b, v)
+		case float64:
+			b = msgp.AppendFloat64(b, v)
+		case string:
+			b = msgp.AppendString(b, v)
+		case bool:
+			b = msgp.AppendBool(b, v)
+		default:
+			return b, fmt.Errorf("unsupported type %T of field %q", v, k)
+		}
+	}
+	return b, nil
+}
+
+// appendUint64 appends an unsigned integer in its 64-bit form, smaller
+// values would be encoded as positive integers and decoded as int64.
+func appendUint64(b []byte, v uint64) []byte {
+	b = append(b, 0xcf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint64(b[len(b)-8:], v)
+	return b
+}
+
+// UnmarshalMsg decodes a metric from the start of b and returns the
+// remaining bytes.  Unknown keys are skipped.
+func (m *Metric) UnmarshalMsg(b []byte) ([]byte, error) {
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return b, err
+	}
+
+	for i := uint32(0); i < n; i++ {
+		var key []byte
+		key, b, err = msgp.ReadMapKeyZC(b)
+		if err != nil {
+			return b, err
+		}
+
+		switch string(key) {
+		case "name":
+			m.Name, b, err = msgp.ReadStringBytes(b)
+		case "time":
+			var ts Timestamp
+			b, err = msgp.ReadExtensionBytes(b, &ts)
+			m.Time = time.Time(ts)
+		case "tags":
+			m.Tags, b, err = readTags(b)
+		case "fields":
+			m.Fields, b, err = readFields(b)
+		default:
+			b, err = msgp.Skip(b)
+		}
+		if err != nil {
+			return b, fmt.Errorf("decoding %s: %v", key, err)
+		}
+	}
+	return b, nil
+}
+
+func readTags(b []byte) (map[string]string, []byte, error) {
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, b, err
+	}
+
+	tags := make(map[string]string, n)
+	for i := uint32(0); i < n; i++ {
+		var k, v string
+		if k, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		if v, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		tags[k] = v
+	}
+	return tags, b, nil
+}
+
+func readFields(b []byte) (map[string]interface{}, []byte, error) {
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, b, err
+	}
+
+	fields := make(map[string]interface{}, n)
+	for i := uint32(0); i < n; i++ {
+		var k string
+		var v interface{}
+		if k, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		if v, b, err = msgp.ReadIntfBytes(b); err != nil {
+			return nil, b, err
+		}
+
+		switch v := v.(type) {
+		case int64, uint64, float64, string, bool:
+			fields[k] = v
+		case float32:
+			fields[k] = float64(v)
+		case []byte:
+			fields[k] = string(v)
+		default:
+			return nil, b, fmt.Errorf("unsupported type %T of field %q", v, k)
+		}
+	}
+	return fields, b, nil
+}
+
+// Timestamp is the MessagePack timestamp extension, type -1.  It is encoded
+// in 4 bytes when the time is whole seconds fitting an unsigned 32-bit
+// integer, 8 bytes up to the year 2514, and 12 bytes otherwise.
+type Timestamp time.Time
+
+func (*Timestamp) ExtensionType() int8 {
+	return -1
+}
+
+func (t *Timestamp) Len() int {
+	sec := time.Time(*t).Unix()
+	nsec := time.Time(*t).Nanosecond()
+	switch {
+	case sec>>34 != 0:
+		return 12
+	case nsec != 0 || sec>>32 != 0:
+		return 8
+	default:
+		return 4
+	}
+}
+
+func (t *Timestamp) MarshalBinaryTo(b []byte) error {
+	sec := time.Time(*t).Unix()
+	nsec := time.Time(*t).Nanosecond()
+	switch len(b) {
+	case 4:
+		binary.BigEndian.PutUint32(b, uint32(sec))
+	case 8:
+		binary.BigEndian.PutUint64(b, uint64(nsec)<<34|uint64(sec))
+	case 12:
+		binary.BigEndian.PutUint32(b, uint32(nsec))
+		binary.BigEndian.PutUint64(b[4:], uint64(sec))
+	default:
+		return fmt.Errorf("invalid timestamp length %d", len(b))
+	}
+	return nil
+}
+
+func (t *Timestamp) UnmarshalBinary(b []byte) error {
+	switch len(b) {
+	case 4:
+		*t = Timestamp(time.Unix(int64(binary.BigEndian.Uint32(b)), 0))
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		*t = Timestamp(time.Unix(int64(v&(1<<34-1)), int64(v>>34)))
+	case 12:
+		nsec := binary.BigEndian.Uint32(b)
+		sec := binary.BigEndian.Uint64(b[4:])
+		*t = Timestamp(time.Unix(int64(sec), int64(nsec)))
+	default:
+		return fmt.Errorf("invalid timestamp length %d", len(b))
+	}
+	return nil
+}
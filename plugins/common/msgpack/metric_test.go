@@ -0,0 +1,79 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		name   string
+		time   time.Time
+		length int
+	}{
+		{"seconds", time.Unix(1600000000, 0), 4},
+		{"nanoseconds", time.Unix(1600000000, 123456789), 8},
+		{"after 2106", time.Unix(1<<33, 0), 8},
+		{"after 2514", time.Unix(1<<35, 1), 12},
+		{"before 1970", time.Unix(-1, 500), 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := Timestamp(tt.time)
+			require.Equal(t, tt.length, ts.Len())
+
+			b, err := msgp.AppendExtension(nil, &ts)
+			require.NoError(t, err)
+			require.Equal(t, byte(0xff), b[len(b)-tt.length-1], "extension type")
+
+			var decoded Timestamp
+			rest, err := msgp.ReadExtensionBytes(b, &decoded)
+			require.NoError(t, err)
+			require.Empty(t, rest)
+			require.True(t, tt.time.Equal(time.Time(decoded)))
+		})
+	}
+}
+
+func TestMetric(t *testing.T) {
+	m := Metric{
+		Name: "cpu",
+		Time: time.Unix(1600000000, 5),
+		Tags: map[string]string{"host": "a"},
+		Fields: map[string]interface{}{
+			"int":    int64(-3),
+			"uint":   uint64(3),
+			"float":  1.5,
+			"string": "x",
+			"bool":   true,
+		},
+	}
+	b, err := m.MarshalMsg(nil)
+	require.NoError(t, err)
+
+	// Unknown keys are skipped
+	b[0]++
+	b = msgp.AppendString(b, "extra")
+	b = msgp.AppendInt(b, 1)
+
+	var decoded Metric
+	rest, err := decoded.UnmarshalMsg(b)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	require.Equal(t, m.Name, decoded.Name)
+	require.True(t, m.Time.Equal(decoded.Time))
+	require.Equal(t, m.Tags, decoded.Tags)
+	require.Equal(t, m.Fields, decoded.Fields)
+}
+
+func TestMetricUnsupportedField(t *testing.T) {
+	m := Metric{
+		Name:   "cpu",
+		Fields: map[string]interface{}{"value": []int{1}},
+	}
+	_, err := m.MarshalMsg(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,74 @@
+// Package prompb holds the messages of the Prometheus remote write protocol.
+// It is the subset of the messages of the prompb package of Prometheus needed
+// to write samples, encoded with the same field numbers.
+package prompb
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+)
+
+// WriteRequest is the body of a remote write request.
+type WriteRequest struct {
+	Timeseries []*TimeSeries `protobuf:"bytes,1,rep,name=timeseries,proto3" json:"timeseries"`
+}
+
+func (m *WriteRequest) Reset()         { *m = WriteRequest{} }
+func (m *WriteRequest) String() string { return proto.CompactTextString(m) }
+func (*WriteRequest) ProtoMessage()    {}
+
+// TimeSeries is a series of samples.  The labels are sorted by name, the
+// name of the metric is the value of the "__name__" label.
+type TimeSeries struct {
+	Labels  []*Label  `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels"`
+	Samples []*Sample `protobuf:"bytes,2,rep,name=samples,proto3" json:"samples"`
+}
+
+func (m *TimeSeries) Reset()         { *m = TimeSeries{} }
+func (m *TimeSeries) String() string { return proto.CompactTextString(m) }
+func (*TimeSeries) ProtoMessage()    {}
+
+type Label struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *Label) Reset()         { *m = Label{} }
+func (m *Label) String() string { return proto.CompactTextString(m) }
+func (*Label) ProtoMessage()    {}
+
+// Sample is a value at a timestamp in milliseconds.
+type Sample struct {
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (m *Sample) Reset()         { *m = Sample{} }
+func (m *Sample) String() string { return proto.CompactTextString(m) }
+func (*Sample) ProtoMessage()    {}
+
+// MetricNameLabel is the label holding the name of the metric.
+const MetricNameLabel = "__name__"
+
+// Encode returns the request marshaled and compressed with snappy, as sent in
+// the body of remote write requests.
+func Encode(req *WriteRequest) ([]byte, error) {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+// Decode returns the request of the snappy compressed body.
+func Decode(buf []byte) (*WriteRequest, error) {
+	data, err := snappy.Decode(nil, buf)
+	if err != nil {
+		return nil, err
+	}
+	var req WriteRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
@@ -0,0 +1,189 @@
+package starlark
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// mathModule is loaded with load("math.star", "math").
+var mathModule = &starlarkstruct.Module{
+	Name: "math",
+	Members: starlark.StringDict{
+		"ceil":  roundingFunc("ceil", math.Ceil),
+		"floor": roundingFunc("floor", math.Floor),
+		"round": roundingFunc("round", math.Round),
+		"fabs":  floatFunc("fabs", math.Abs),
+		"sqrt":  floatFunc("sqrt", math.Sqrt),
+		"exp":   floatFunc("exp", math.Exp),
+		"log":   floatFunc("log", math.Log),
+		"log2":  floatFunc("log2", math.Log2),
+		"log10": floatFunc("log10", math.Log10),
+		"pow":   starlark.NewBuiltin("pow", mathPow),
+		"isinf": floatPredicate("isinf", func(f float64) bool { return math.IsInf(f, 0) }),
+		"isnan": floatPredicate("isnan", math.IsNaN),
+		"e":     starlark.Float(math.E),
+		"pi":    starlark.Float(math.Pi),
+		"inf":   starlark.Float(math.Inf(1)),
+		"nan":   starlark.Float(math.NaN()),
+	},
+}
+
+// floatArg unpacks the single int or float argument of a function.
+func floatArg(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (float64, error) {
+	var x starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &x); err != nil {
+		return 0, err
+	}
+	f, ok := starlark.AsFloat(x)
+	if !ok {
+		return 0, nameErr(b, fmt.Sprintf("got %s, want int or float", x.Type()))
+	}
+	return f, nil
+}
+
+func floatFunc(name string, fn func(float64) float64) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		f, err := floatArg(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Float(fn(f)), nil
+	})
+}
+
+// roundingFunc returns a function rounding to an int, like in Python.
+func roundingFunc(name string, fn func(float64) float64) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		f, err := floatArg(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		i, err := starlark.NumberToInt(starlark.Float(fn(f)))
+		if err != nil {
+			return nil, nameErr(b, err)
+		}
+		return i, nil
+	})
+}
+
+func floatPredicate(name string, fn func(float64) bool) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		f, err := floatArg(b, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Bool(fn(f)), nil
+	})
+}
+
+func mathPow(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var x, y starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &x, &y); err != nil {
+		return nil, err
+	}
+	fx, ok := starlark.AsFloat(x)
+	if !ok {
+		return nil, nameErr(b, fmt.Sprintf("got %s, want int or float", x.Type()))
+	}
+	fy, ok := starlark.AsFloat(y)
+	if !ok {
+		return nil, nameErr(b, fmt.Sprintf("got %s, want int or float", y.Type()))
+	}
+	return starlark.Float(math.Pow(fx, fy)), nil
+}
+
+// timeModule is loaded with load("time.star", "time").  Times are integers
+// in nanoseconds since the Unix epoch, as the time of metrics, and durations
+// integers in nanoseconds.
+var timeModule = &starlarkstruct.Module{
+	Name: "time",
+	Members: starlark.StringDict{
+		"now":            starlark.NewBuiltin("now", timeNow),
+		"parse_time":     starlark.NewBuiltin("parse_time", timeParse),
+		"format_time":    starlark.NewBuiltin("format_time", timeFormat),
+		"parse_duration": starlark.NewBuiltin("parse_duration", timeParseDuration),
+		"nanosecond":     starlark.MakeInt64(int64(time.Nanosecond)),
+		"microsecond":    starlark.MakeInt64(int64(time.Microsecond)),
+		"millisecond":    starlark.MakeInt64(int64(time.Millisecond)),
+		"second":         starlark.MakeInt64(int64(time.Second)),
+		"minute":         starlark.MakeInt64(int64(time.Minute)),
+		"hour":           starlark.MakeInt64(int64(time.Hour)),
+	},
+}
+
+func timeNow(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.MakeInt64(time.Now().UnixNano()), nil
+}
+
+// timeParse parses a time in the Go layout, RFC 3339 by default.
+func timeParse(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value string
+	layout := time.RFC3339Nano
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "value", &value, "layout?", &layout); err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return nil, nameErr(b, err)
+	}
+	return starlark.MakeInt64(t.UnixNano()), nil
+}
+
+// timeFormat formats a time in the Go layout, RFC 3339 in UTC by default.
+func timeFormat(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Int
+	layout := time.RFC3339Nano
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "value", &value, "layout?", &layout); err != nil {
+		return nil, err
+	}
+	ns, ok := value.Int64()
+	if !ok {
+		return nil, nameErr(b, "time out of range")
+	}
+	return starlark.String(time.Unix(0, ns).UTC().Format(layout)), nil
+}
+
+func timeParseDuration(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &value); err != nil {
+		return nil, err
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, nameErr(b, err)
+	}
+	return starlark.MakeInt64(int64(d)), nil
+}
+
+// newLoggingModule returns the module loaded with load("logging.star", "log"),
+// writing to the log of the plugin.
+func newLoggingModule(log telegraf.Logger) *starlarkstruct.Module {
+	logFunc := func(name string, fn func(args ...interface{})) *starlark.Builtin {
+		return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var msg string
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &msg); err != nil {
+				return nil, err
+			}
+			fn(msg)
+			return starlark.None, nil
+		})
+	}
+
+	return &starlarkstruct.Module{
+		Name: "log",
+		Members: starlark.StringDict{
+			"debug": logFunc("debug", log.Debug),
+			"info":  logFunc("info", log.Info),
+			"warn":  logFunc("warn", log.Warn),
+			"error": logFunc("error", log.Error),
+		},
+	}
+}
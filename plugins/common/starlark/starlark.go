@@ -0,0 +1,198 @@
+package starlark
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/starlarkstruct"
+)
+
+// Script is an executed Starlark script, whose functions can be called with
+// metrics.  The script has access to the builtins to create and copy metrics
+// and can load the bundled json, math, time and logging modules, and the
+// libraries of the library directory.
+type Script struct {
+	thread  *starlark.Thread
+	globals starlark.StringDict
+	log     telegraf.Logger
+
+	builtins   starlark.StringDict
+	libraryDir string
+	libraries  map[string]starlark.StringDict
+	logging    *starlarkstruct.Module
+}
+
+// Options are the optional settings of a script.
+type Options struct {
+	// Predeclared values are available to the script besides the builtins,
+	// they are not frozen.
+	Predeclared starlark.StringDict
+	// LibraryDir is the directory of the .star libraries the script can load.
+	LibraryDir string
+}
+
+// NewScript executes the script given either as source or as the path of a
+// file, exactly one of them must be set.  The name is used in error messages
+// for a script given as source.  The global state of the script is frozen,
+// predeclared values are not.
+func NewScript(name, source, path string, opts Options, log telegraf.Logger) (*Script, error) {
+	if source == "" && path == "" {
+		return nil, errors.New("one of source or script must be set")
+	}
+	if source != "" && path != "" {
+		return nil, errors.New("both source or script cannot be set")
+	}
+
+	s := &Script{
+		log:        log,
+		libraryDir: opts.LibraryDir,
+		libraries:  make(map[string]starlark.StringDict),
+		logging:    newLoggingModule(log),
+	}
+	s.thread = &starlark.Thread{
+		Print: func(_ *starlark.Thread, msg string) { s.log.Debug(msg) },
+		Load:  s.load,
+	}
+
+	builtins := starlark.StringDict{}
+	builtins["Metric"] = starlark.NewBuiltin("Metric", newMetric)
+	builtins["deepcopy"] = starlark.NewBuiltin("deepcopy", deepcopy)
+	for k, v := range opts.Predeclared {
+		builtins[k] = v
+	}
+	s.builtins = builtins
+
+	var program *starlark.Program
+	var err error
+	if source != "" {
+		_, program, err = starlark.SourceProgram(name, source, builtins.Has)
+	} else {
+		_, program, err = starlark.SourceProgram(path, nil, builtins.Has)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute source
+	s.globals, err = program.Init(s.thread, builtins)
+	if err != nil {
+		return nil, err
+	}
+
+	// Freeze the global state.  This prevents modifications to the plugin
+	// state and prevents scripts from containing errors storing tracking
+	// metrics.  Tasks that require global state will not be possible due to
+	// this, so maybe we should relax this in the future.
+	s.globals.Freeze()
+
+	return s, nil
+}
+
+// Function returns the function of the script with the given name, which
+// must take the given number of parameters.
+func (s *Script) Function(name string, params int) (*starlark.Function, error) {
+	value := s.globals[name]
+	if value == nil {
+		return nil, fmt.Errorf("%s is not defined", name)
+	}
+
+	fn, ok := value.(*starlark.Function)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a function", name)
+	}
+
+	if fn.NumParams() != params {
+		switch params {
+		case 0:
+			return nil, fmt.Errorf("%s function must take no parameters", name)
+		case 1:
+			return nil, fmt.Errorf("%s function must take one parameter", name)
+		default:
+			return nil, fmt.Errorf("%s function must take %d parameters", name, params)
+		}
+	}
+	return fn, nil
+}
+
+// Call calls the function with the arguments.  The backtrace of errors in
+// the script is logged.
+func (s *Script) Call(fn *starlark.Function, args starlark.Tuple) (starlark.Value, error) {
+	rv, err := starlark.Call(s.thread, fn, args, nil)
+	if err != nil {
+		if err, ok := err.(*starlark.EvalError); ok {
+			for _, line := range strings.Split(err.Backtrace(), "\n") {
+				s.log.Error(line)
+			}
+		}
+		return nil, err
+	}
+	return rv, nil
+}
+
+func init() {
+	// https://github.com/bazelbuild/starlark/issues/20
+	resolve.AllowNestedDef = true
+	resolve.AllowLambda = true
+	resolve.AllowFloat = true
+	resolve.AllowSet = true
+	resolve.AllowGlobalReassign = true
+	resolve.AllowRecursion = true
+}
+
+// load returns the globals of a bundled module, or of a library of the
+// library directory.  Libraries are executed once, with the same builtins as
+// the script, and their globals are frozen.
+func (s *Script) load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	switch module {
+	case "json.star":
+		return starlark.StringDict{
+			"json": starlarkjson.Module,
+		}, nil
+	case "math.star":
+		return starlark.StringDict{
+			"math": mathModule,
+		}, nil
+	case "time.star":
+		return starlark.StringDict{
+			"time": timeModule,
+		}, nil
+	case "logging.star":
+		return starlark.StringDict{
+			"log": s.logging,
+		}, nil
+	}
+
+	if s.libraryDir == "" || !strings.HasSuffix(module, ".star") {
+		return nil, errors.New("module " + module + " is not available")
+	}
+
+	// Libraries can not be loaded from outside of the library directory.
+	path := filepath.Join(s.libraryDir, filepath.Clean("/"+module))
+	if globals, ok := s.libraries[path]; ok {
+		if globals == nil {
+			return nil, fmt.Errorf("cycle in the load of %s", module)
+		}
+		return globals, nil
+	}
+
+	s.libraries[path] = nil
+	libThread := &starlark.Thread{
+		Name:  module,
+		Print: thread.Print,
+		Load:  s.load,
+	}
+	globals, err := starlark.ExecFile(libThread, path, nil, s.builtins)
+	if err != nil {
+		delete(s.libraries, path)
+		return nil, err
+	}
+	globals.Freeze()
+	s.libraries[path] = globals
+	return globals, nil
+}
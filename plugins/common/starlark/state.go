@@ -0,0 +1,95 @@
+package starlark
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/telegraf"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+)
+
+// LoadState reads a state dict saved by SaveState.  A missing file is an
+// empty state.
+func LoadState(path string) (*starlark.Dict, error) {
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return starlark.NewDict(0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decode := starlarkjson.Module.Members["decode"]
+	v, err := starlark.Call(&starlark.Thread{}, decode, starlark.Tuple{starlark.String(buf)}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decoding state file %s: %v", path, err)
+	}
+	state, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("state file %s does not hold a dict", path)
+	}
+	return state, nil
+}
+
+// SaveState writes the state dict as JSON, replacing the file atomically.
+// Only values that can be encoded to JSON can be saved, the entries of the
+// dicts with other keys or values are left out with a warning.
+func SaveState(path string, state *starlark.Dict, log telegraf.Logger) error {
+	v, err := encodeJSON(encodableDict("state", state, log))
+	if err != nil {
+		return fmt.Errorf("encoding state: %v", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(v); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// encodableDict returns a copy of the dict holding only the entries with
+// string keys and values that can be encoded to JSON, nested dicts are
+// copied the same way.  The name is the expression of the dict in the
+// warnings.
+func encodableDict(name string, dict *starlark.Dict, log telegraf.Logger) *starlark.Dict {
+	out := starlark.NewDict(dict.Len())
+	for _, item := range dict.Items() {
+		k, v := item[0], item[1]
+		entry := name + "[" + k.String() + "]"
+		if _, ok := k.(starlark.String); !ok {
+			log.Warnf("Not saving %s: key of type %s is not a string", entry, k.Type())
+			continue
+		}
+
+		if d, ok := v.(*starlark.Dict); ok {
+			v = encodableDict(entry, d, log)
+		} else if _, err := encodeJSON(v); err != nil {
+			log.Warnf("Not saving %s: %v", entry, err)
+			continue
+		}
+		// Setting a string key in a new dict cannot fail.
+		_ = out.SetKey(k, v)
+	}
+	return out
+}
+
+func encodeJSON(v starlark.Value) (string, error) {
+	encode := starlarkjson.Module.Members["encode"]
+	out, err := starlark.Call(&starlark.Thread{}, encode, starlark.Tuple{v}, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(out.(starlark.String)), nil
+}
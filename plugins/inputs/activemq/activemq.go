@@ -22,7 +22,7 @@ type ActiveMQ struct {
 	Port            int               `toml:"port"`
 	URL             string            `toml:"url"`
 	Username        string            `toml:"username"`
-	Password        string            `toml:"password"`
+	Password        internal.Secret   `toml:"password"`
 	Webadmin        string            `toml:"webadmin"`
 	ResponseTimeout internal.Duration `toml:"response_timeout"`
 	tls.ClientConfig
@@ -170,8 +170,12 @@ func (a *ActiveMQ) GetMetrics(u string) ([]byte, error) {
 		return nil, err
 	}
 
-	if a.Username != "" || a.Password != "" {
-		req.SetBasicAuth(a.Username, a.Password)
+	if a.Username != "" || !a.Password.Empty() {
+		password, err := a.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(a.Username, password)
 	}
 
 	resp, err := a.client.Do(req)
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
@@ -20,11 +21,11 @@ import (
 type Aerospike struct {
 	Servers []string `toml:"servers"`
 
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 
 	EnableTLS bool `toml:"enable_tls"`
-	EnableSSL bool `toml:"enable_ssl"` // deprecated in 1.7; use enable_tls
+	EnableSSL bool `toml:"enable_ssl" deprecated:"1.7.0;use 'enable_tls' instead"`
 	tlsint.ClientConfig
 
 	initialized bool
@@ -140,7 +141,11 @@ func (a *Aerospike) gatherServer(hostPort string, acc telegraf.Accumulator) erro
 
 	policy := as.NewClientPolicy()
 	policy.User = a.Username
-	policy.Password = a.Password
+	password, err := a.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
+	policy.Password = password
 	policy.TlsConfig = a.tlsConfig
 	c, err := as.NewClientWithPolicy(policy, host, iport)
 	if err != nil {
@@ -26,10 +26,10 @@ type semaphore chan empty
 
 // AMQPConsumer is the top level struct for this plugin
 type AMQPConsumer struct {
-	URL                    string            `toml:"url"` // deprecated in 1.7; use brokers
+	URL                    string            `toml:"url" deprecated:"1.7.0;use 'brokers' instead"`
 	Brokers                []string          `toml:"brokers"`
 	Username               string            `toml:"username"`
-	Password               string            `toml:"password"`
+	Password               internal.Secret   `toml:"password"`
 	Exchange               string            `toml:"exchange"`
 	ExchangeType           string            `toml:"exchange_type"`
 	ExchangeDurability     string            `toml:"exchange_durability"`
@@ -191,11 +191,15 @@ func (a *AMQPConsumer) createConfig() (*amqp.Config, error) {
 	var auth []amqp.Authentication
 	if strings.ToUpper(a.AuthMethod) == "EXTERNAL" {
 		auth = []amqp.Authentication{&externalAuth{}}
-	} else if a.Username != "" || a.Password != "" {
+	} else if a.Username != "" || !a.Password.Empty() {
+		password, err := a.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
 		auth = []amqp.Authentication{
 			&amqp.PlainAuth{
 				Username: a.Username,
-				Password: a.Password,
+				Password: password,
 			},
 		}
 	}
@@ -20,7 +20,7 @@ import (
 type Apache struct {
 	Urls            []string
 	Username        string
-	Password        string
+	Password        internal.Secret
 	ResponseTimeout internal.Duration
 	tls.ClientConfig
 
@@ -114,8 +114,12 @@ func (n *Apache) gatherUrl(addr *url.URL, acc telegraf.Accumulator) error {
 		return fmt.Errorf("error on new request to %s : %s\n", addr.String(), err)
 	}
 
-	if len(n.Username) != 0 && len(n.Password) != 0 {
-		req.SetBasicAuth(n.Username, n.Password)
+	if n.Username != "" && !n.Password.Empty() {
+		password, err := n.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(n.Username, password)
 	}
 
 	resp, err := n.client.Do(req)
@@ -47,7 +47,7 @@ type Aurora struct {
 	Roles      []string          `toml:"roles"`
 	Timeout    internal.Duration `toml:"timeout"`
 	Username   string            `toml:"username"`
-	Password   string            `toml:"password"`
+	Password   internal.Secret   `toml:"password"`
 	tls.ClientConfig
 
 	client *http.Client
@@ -181,8 +181,12 @@ func (a *Aurora) gatherRole(ctx context.Context, origin *url.URL) (RoleType, err
 		return Unknown, err
 	}
 
-	if a.Username != "" || a.Password != "" {
-		req.SetBasicAuth(a.Username, a.Password)
+	if a.Username != "" || !a.Password.Empty() {
+		password, err := a.Password.Get()
+		if err != nil {
+			return Unknown, fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(a.Username, password)
 	}
 	req.Header.Add("Accept", "text/plain")
 
@@ -214,8 +218,12 @@ func (a *Aurora) gatherScheduler(
 		return err
 	}
 
-	if a.Username != "" || a.Password != "" {
-		req.SetBasicAuth(a.Username, a.Password)
+	if a.Username != "" || !a.Password.Empty() {
+		password, err := a.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(a.Username, password)
 	}
 	req.Header.Add("Accept", "application/json")
 
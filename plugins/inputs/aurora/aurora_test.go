@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -251,7 +252,7 @@ func TestBasicAuth(t *testing.T) {
 			plugin := &Aurora{}
 			plugin.Schedulers = []string{u.String()}
 			plugin.Username = tt.username
-			plugin.Password = tt.password
+			plugin.Password = internal.NewSecret(tt.password)
 			err := plugin.Gather(&acc)
 			require.NoError(t, err)
 		})
@@ -3,19 +3,21 @@ package azure_storage_queue
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-storage-queue-go/azqueue"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 type AzureStorageQueue struct {
-	StorageAccountName   string `toml:"account_name"`
-	StorageAccountKey    string `toml:"account_key"`
-	PeekOldestMessageAge bool   `toml:"peek_oldest_message_age"`
+	StorageAccountName   string          `toml:"account_name"`
+	StorageAccountKey    internal.Secret `toml:"account_key"`
+	PeekOldestMessageAge bool            `toml:"peek_oldest_message_age"`
 	Log                  telegraf.Logger
 
 	serviceURL *azqueue.ServiceURL
@@ -45,7 +47,7 @@ func (a *AzureStorageQueue) Init() error {
 		return errors.New("account_name must be configured")
 	}
 
-	if a.StorageAccountKey == "" {
+	if a.StorageAccountKey.Empty() {
 		return errors.New("account_key must be configured")
 	}
 	return nil
@@ -58,7 +60,11 @@ func (a *AzureStorageQueue) GetServiceURL() (azqueue.ServiceURL, error) {
 			return azqueue.ServiceURL{}, err
 		}
 
-		credential, err := azqueue.NewSharedKeyCredential(a.StorageAccountName, a.StorageAccountKey)
+		accountKey, err := a.StorageAccountKey.Get()
+		if err != nil {
+			return azqueue.ServiceURL{}, fmt.Errorf("getting account_key: %w", err)
+		}
+		credential, err := azqueue.NewSharedKeyCredential(a.StorageAccountName, accountKey)
 		if err != nil {
 			return azqueue.ServiceURL{}, err
 		}
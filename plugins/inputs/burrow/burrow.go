@@ -72,7 +72,7 @@ type (
 
 		Servers               []string
 		Username              string
-		Password              string
+		Password              internal.Secret
 		ResponseTimeout       internal.Duration
 		ConcurrentConnections int
 
@@ -236,7 +236,11 @@ func (b *burrow) getResponse(u *url.URL) (*apiResponse, error) {
 		return nil, err
 	}
 	if b.Username != "" {
-		req.SetBasicAuth(b.Username, b.Password)
+		password, err := b.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(b.Username, password)
 	}
 
 	res, err := b.client.Do(req)
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -220,7 +221,7 @@ func TestBasicAuthConfig(t *testing.T) {
 	plugin := &burrow{
 		Servers:  []string{s.URL},
 		Username: "test",
-		Password: "test",
+		Password: internal.NewSecret("test"),
 	}
 
 	acc := &testutil.Accumulator{}
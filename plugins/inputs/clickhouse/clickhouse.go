@@ -109,7 +109,7 @@ func init() {
 // ClickHouse Telegraf Input Plugin
 type ClickHouse struct {
 	Username       string            `toml:"username"`
-	Password       string            `toml:"password"`
+	Password       internal.Secret   `toml:"password"`
 	Servers        []string          `toml:"servers"`
 	AutoDiscovery  bool              `toml:"auto_discovery"`
 	ClusterInclude []string          `toml:"cluster_include"`
@@ -576,8 +576,12 @@ func (ch *ClickHouse) execQuery(url *url.URL, query string, i interface{}) error
 	if ch.Username != "" {
 		req.Header.Add("X-ClickHouse-User", ch.Username)
 	}
-	if ch.Password != "" {
-		req.Header.Add("X-ClickHouse-Key", ch.Password)
+	if !ch.Password.Empty() {
+		password, err := ch.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.Header.Add("X-ClickHouse-Key", password)
 	}
 	resp, err := ch.HTTPClient.Do(req)
 	if err != nil {
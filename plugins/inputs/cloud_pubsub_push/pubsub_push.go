@@ -26,7 +26,7 @@ const defaultMaxUndeliveredMessages = 1000
 
 type PubSubPush struct {
 	ServiceAddress string
-	Token          string
+	Token          internal.Secret
 	Path           string
 	ReadTimeout    internal.Duration
 	WriteTimeout   internal.Duration
@@ -302,8 +302,14 @@ func (p *PubSubPush) receiveDelivered() {
 }
 
 func (p *PubSubPush) AuthenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
-	if p.Token != "" {
-		if subtle.ConstantTimeCompare([]byte(req.FormValue("token")), []byte(p.Token)) != 1 {
+	if !p.Token.Empty() {
+		token, err := p.Token.Get()
+		if err != nil {
+			p.Log.Errorf("Getting token: %v", err)
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(req.FormValue("token")), []byte(token)) != 1 {
 			http.Error(res, "Unauthorized.", http.StatusUnauthorized)
 			return
 		}
@@ -25,11 +25,11 @@ import (
 type CloudWatch struct {
 	Region           string          `toml:"region"`
 	AccessKey        string          `toml:"access_key"`
-	SecretKey        string          `toml:"secret_key"`
+	SecretKey        internal.Secret `toml:"secret_key"`
 	RoleARN          string          `toml:"role_arn"`
 	Profile          string          `toml:"profile"`
 	CredentialPath   string          `toml:"shared_credential_file"`
-	Token            string          `toml:"token"`
+	Token            internal.Secret `toml:"token"`
 	EndpointURL      string          `toml:"endpoint_url"`
 	StatisticExclude []string        `toml:"statistic_exclude"`
 	StatisticInclude []string        `toml:"statistic_include"`
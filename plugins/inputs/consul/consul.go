@@ -1,11 +1,13 @@
 package consul
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -13,10 +15,10 @@ import (
 type Consul struct {
 	Address    string
 	Scheme     string
-	Token      string
+	Token      internal.Secret
 	Username   string
-	Password   string
-	Datacentre string // deprecated in 1.10; use Datacenter
+	Password   internal.Secret
+	Datacentre string `deprecated:"1.10.0;use 'datacenter' instead"`
 	Datacenter string
 	tls.ClientConfig
 	TagDelimiter  string
@@ -99,14 +101,22 @@ func (c *Consul) createAPIClient() (*api.Client, error) {
 		config.Datacenter = c.Datacenter
 	}
 
-	if c.Token != "" {
-		config.Token = c.Token
+	if !c.Token.Empty() {
+		token, err := c.Token.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting token: %w", err)
+		}
+		config.Token = token
 	}
 
 	if c.Username != "" {
+		password, err := c.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
 		config.HttpAuth = &api.HttpBasicAuth{
 			Username: c.Username,
-			Password: c.Password,
+			Password: password,
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -80,9 +81,9 @@ type (
 	}
 
 	CouchDB struct {
-		Hosts         []string `toml:"hosts"`
-		BasicUsername string   `toml:"basic_username"`
-		BasicPassword string   `toml:"basic_password"`
+		Hosts         []string        `toml:"hosts"`
+		BasicUsername string          `toml:"basic_username"`
+		BasicPassword internal.Secret `toml:"basic_password"`
 
 		client *http.Client
 	}
@@ -136,8 +137,12 @@ func (c *CouchDB) fetchAndInsertData(accumulator telegraf.Accumulator, host stri
 		return err
 	}
 
-	if c.BasicUsername != "" || c.BasicPassword != "" {
-		req.SetBasicAuth(c.BasicUsername, c.BasicPassword)
+	if c.BasicUsername != "" || !c.BasicPassword.Empty() {
+		password, err := c.BasicPassword.Get()
+		if err != nil {
+			return fmt.Errorf("getting basic_password: %w", err)
+		}
+		req.SetBasicAuth(c.BasicUsername, password)
 	}
 
 	response, error := c.client.Do(req)
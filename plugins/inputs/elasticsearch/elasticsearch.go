@@ -151,7 +151,7 @@ type Elasticsearch struct {
 	IndicesLevel               string            `toml:"indices_level"`
 	NodeStats                  []string          `toml:"node_stats"`
 	Username                   string            `toml:"username"`
-	Password                   string            `toml:"password"`
+	Password                   internal.Secret   `toml:"password"`
 	tls.ClientConfig
 
 	client          *http.Client
@@ -600,8 +600,12 @@ func (e *Elasticsearch) getCatMaster(url string) (string, error) {
 		return "", err
 	}
 
-	if e.Username != "" || e.Password != "" {
-		req.SetBasicAuth(e.Username, e.Password)
+	if e.Username != "" || !e.Password.Empty() {
+		password, err := e.Password.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(e.Username, password)
 	}
 
 	r, err := e.client.Do(req)
@@ -632,8 +636,12 @@ func (e *Elasticsearch) gatherJSONData(url string, v interface{}) error {
 		return err
 	}
 
-	if e.Username != "" || e.Password != "" {
-		req.SetBasicAuth(e.Username, e.Password)
+	if e.Username != "" || !e.Password.Empty() {
+		password, err := e.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(e.Username, password)
 	}
 
 	r, err := e.client.Do(req)
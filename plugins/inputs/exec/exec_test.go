@@ -154,6 +154,25 @@ func TestCommandError(t *testing.T) {
 	assert.Equal(t, acc.NFields(), 0, "No new points should have been added")
 }
 
+func TestExecNagiosCommandError(t *testing.T) {
+	parser, err := parsers.NewParser(&parsers.Config{
+		DataFormat: "nagios",
+		MetricName: "exec",
+	})
+	require.NoError(t, err)
+	e := &Exec{
+		Log:      testutil.Logger{},
+		runner:   newRunnerMock([]byte("CRITICAL: load high|load1=9;5;8"), nil, fmt.Errorf("exit status code 2")),
+		Commands: []string{"check_load"},
+		parser:   parser,
+	}
+
+	// The output of a failing nagios check is still parsed
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	require.True(t, acc.HasMeasurement("nagios"))
+}
+
 func TestExecCommandWithGlob(t *testing.T) {
 	parser, _ := parsers.NewValueParser("metric", "string", nil)
 	e := NewExec()
@@ -94,7 +94,8 @@ func (e *Execd) Stop() {
 }
 
 func (e *Execd) cmdReadOut(out io.Reader) {
-	if _, isInfluxParser := e.parser.(*influx.Parser); isInfluxParser {
+	_, isInfluxParser := parsers.Unwrap(e.parser).(*influx.Parser)
+	if isInfluxParser && !parsers.EmitsRaw(e.parser) {
 		// work around the lack of built-in streaming parser. :(
 		e.cmdReadOutStream(out)
 		return
@@ -129,6 +130,7 @@ func (e *Execd) cmdReadOutStream(out io.Reader) {
 			}
 			if parseErr, isParseError := err.(*influx.ParseError); isParseError {
 				// parse error.
+				parsers.CountError(e.parser)
 				e.acc.AddError(parseErr)
 				continue
 			}
@@ -34,8 +34,8 @@ type Fibaro struct {
 	URL string `toml:"url"`
 
 	// HTTP Basic Auth Credentials
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 
 	Timeout internal.Duration `toml:"timeout"`
 
@@ -93,7 +93,11 @@ func (f *Fibaro) getJSON(path string, dataStruct interface{}) error {
 		return err
 	}
 
-	req.SetBasicAuth(f.Username, f.Password)
+	password, err := f.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
+	req.SetBasicAuth(f.Username, password)
 	resp, err := f.client.Do(req)
 	if err != nil {
 		return err
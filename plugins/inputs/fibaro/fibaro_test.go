@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -140,7 +141,7 @@ func TestUnauthorized(t *testing.T) {
 	a := Fibaro{
 		URL:      ts.URL,
 		Username: "user",
-		Password: "pass",
+		Password: internal.NewSecret("pass"),
 		client:   &http.Client{},
 	}
 
@@ -169,7 +170,7 @@ func TestJSONSuccess(t *testing.T) {
 	a := Fibaro{
 		URL:      ts.URL,
 		Username: "user",
-		Password: "pass",
+		Password: internal.NewSecret("pass"),
 		client:   &http.Client{},
 	}
 
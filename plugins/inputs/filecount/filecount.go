@@ -51,7 +51,7 @@ const sampleConfig = `
 `
 
 type FileCount struct {
-	Directory      string // deprecated in 1.9
+	Directory      string `deprecated:"1.9.0;use 'directories' instead"`
 	Directories    []string
 	Name           string
 	Recursive      bool
@@ -14,7 +14,7 @@ import (
 
 // Fireboard gathers statistics from the fireboard.io servers
 type Fireboard struct {
-	AuthToken   string            `toml:"auth_token"`
+	AuthToken   internal.Secret   `toml:"auth_token"`
 	URL         string            `toml:"url"`
 	HTTPTimeout internal.Duration `toml:"http_timeout"`
 
@@ -70,7 +70,7 @@ func (r *Fireboard) Description() string {
 // Init the things
 func (r *Fireboard) Init() error {
 
-	if len(r.AuthToken) == 0 {
+	if r.AuthToken.Empty() {
 		return fmt.Errorf("You must specify an authToken")
 	}
 	if len(r.URL) == 0 {
@@ -94,7 +94,11 @@ func (r *Fireboard) Gather(acc telegraf.Accumulator) error {
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Authorization", "Token "+r.AuthToken)
+	token, err := r.AuthToken.Get()
+	if err != nil {
+		return fmt.Errorf("getting auth_token: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+token)
 	resp, err := r.client.Do(req)
 	if err != nil {
 		return err
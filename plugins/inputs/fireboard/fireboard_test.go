@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -26,7 +27,7 @@ func TestFireboard(t *testing.T) {
 
 	// Create a new fb instance with our given test server
 	fireboard := NewFireboard()
-	fireboard.AuthToken = "b4bb6e6a7b6231acb9f71b304edb2274693d8849"
+	fireboard.AuthToken = internal.NewSecret("b4bb6e6a7b6231acb9f71b304edb2274693d8849")
 	fireboard.URL = u.String()
 
 	// Create a test accumulator
@@ -19,7 +19,7 @@ import (
 // GitHub - plugin main structure
 type GitHub struct {
 	Repositories      []string          `toml:"repositories"`
-	AccessToken       string            `toml:"access_token"`
+	AccessToken       internal.Secret   `toml:"access_token"`
 	EnterpriseBaseURL string            `toml:"enterprise_base_url"`
 	HTTPTimeout       internal.Duration `toml:"http_timeout"`
 	githubClient      *github.Client
@@ -69,14 +69,18 @@ func (g *GitHub) createGitHubClient(ctx context.Context) (*github.Client, error)
 
 	g.obfuscatedToken = "Unauthenticated"
 
-	if g.AccessToken != "" {
+	if !g.AccessToken.Empty() {
+		token, err := g.AccessToken.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting access_token: %w", err)
+		}
 		tokenSource := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: g.AccessToken},
+			&oauth2.Token{AccessToken: token},
 		)
 		oauthClient := oauth2.NewClient(ctx, tokenSource)
 		_ = context.WithValue(ctx, oauth2.HTTPClient, oauthClient)
 
-		g.obfuscatedToken = g.AccessToken[0:4] + "..." + g.AccessToken[len(g.AccessToken)-3:]
+		g.obfuscatedToken = token[0:4] + "..." + token[len(token)-3:]
 
 		return g.newGithubClient(oauthClient)
 	}
@@ -41,7 +41,7 @@ type GNMI struct {
 
 	// gNMI target credentials
 	Username string
-	Password string
+	Password internal.Secret
 
 	// Redial
 	Redial internal.Duration
@@ -98,7 +98,11 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 	}
 
 	if len(c.Username) > 0 {
-		ctx = metadata.AppendToOutgoingContext(ctx, "username", c.Username, "password", c.Password)
+		password, err := c.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", c.Username, "password", password)
 	}
 
 	// Invert explicit alias list and prefill subscription names
@@ -133,7 +133,7 @@ func TestUsernamePassword(t *testing.T) {
 		Log:       testutil.Logger{},
 		Addresses: []string{listener.Addr().String()},
 		Username:  "theusername",
-		Password:  "thepassword",
+		Password:  internal.NewSecret("thepassword"),
 		Encoding:  "proto",
 		Redial:    internal.Duration{Duration: 1 * time.Second},
 	}
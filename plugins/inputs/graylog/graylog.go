@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -34,7 +35,7 @@ type GrayLog struct {
 	Servers  []string
 	Metrics  []string
 	Username string
-	Password string
+	Password internal.Secret
 	tls.ClientConfig
 
 	client HTTPClient
@@ -230,7 +231,11 @@ func (h *GrayLog) sendRequest(serverURL string) (string, float64, error) {
 	}
 	method := "GET"
 	content := bytes.NewBufferString("")
-	headers["Authorization"] = "Basic " + base64.URLEncoding.EncodeToString([]byte(h.Username+":"+h.Password))
+	password, err := h.Password.Get()
+	if err != nil {
+		return "", -1, fmt.Errorf("getting password: %w", err)
+	}
+	headers["Authorization"] = "Basic " + base64.URLEncoding.EncodeToString([]byte(h.Username+":"+password))
 	// Prepare URL
 	requestURL, err := url.Parse(serverURL)
 	if err != nil {
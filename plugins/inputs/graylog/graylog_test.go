@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -146,7 +147,7 @@ func genMockGrayLog(response string, statusCode int) []*GrayLog {
 				"org.graylog2.shared.journal.KafkaJournal.writeTime",
 			},
 			Username: "test",
-			Password: "test",
+			Password: internal.NewSecret("test"),
 		},
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -24,7 +25,7 @@ type haproxy struct {
 	Servers        []string
 	KeepFieldNames bool
 	Username       string
-	Password       string
+	Password       internal.Secret
 	tls.ClientConfig
 
 	client *http.Client
@@ -176,8 +177,12 @@ func (g *haproxy) gatherServer(addr string, acc telegraf.Accumulator) error {
 		addr = u.String()
 	}
 
-	if g.Username != "" || g.Password != "" {
-		req.SetBasicAuth(g.Username, g.Password)
+	if g.Username != "" || !g.Password.Empty() {
+		password, err := g.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(g.Username, password)
 	}
 
 	res, err := g.client.Do(req)
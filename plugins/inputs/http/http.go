@@ -25,8 +25,8 @@ type HTTP struct {
 	Headers map[string]string `toml:"headers"`
 
 	// HTTP Basic Auth Credentials
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 	tls.ClientConfig
 
 	// Absolute path to file with Bearer token
@@ -188,8 +188,12 @@ func (h *HTTP) gatherURL(
 		}
 	}
 
-	if h.Username != "" || h.Password != "" {
-		request.SetBasicAuth(h.Username, h.Password)
+	if h.Username != "" || !h.Password.Empty() {
+		password, err := h.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		request.SetBasicAuth(h.Username, password)
 	}
 
 	resp, err := h.client.Do(request)
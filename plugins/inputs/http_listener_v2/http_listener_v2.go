@@ -43,7 +43,7 @@ type HTTPListenerV2 struct {
 	MaxBodySize    internal.Size     `toml:"max_body_size"`
 	Port           int               `toml:"port"`
 	BasicUsername  string            `toml:"basic_username"`
-	BasicPassword  string            `toml:"basic_password"`
+	BasicPassword  internal.Secret   `toml:"basic_password"`
 	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
 	tlsint.ServerConfig
 
@@ -306,11 +306,18 @@ func badRequest(res http.ResponseWriter) {
 }
 
 func (h *HTTPListenerV2) authenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
-	if h.BasicUsername != "" && h.BasicPassword != "" {
+	if h.BasicUsername != "" && !h.BasicPassword.Empty() {
+		password, err := h.BasicPassword.Get()
+		if err != nil {
+			h.Log.Errorf("Getting basic_password: %v", err)
+			http.Error(res, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
 		reqUsername, reqPassword, ok := req.BasicAuth()
 		if !ok ||
 			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(h.BasicUsername)) != 1 ||
-			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(h.BasicPassword)) != 1 {
+			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(password)) != 1 {
 
 			http.Error(res, "Unauthorized.", http.StatusUnauthorized)
 			return
@@ -61,7 +61,7 @@ func newTestHTTPListenerV2() *HTTPListenerV2 {
 func newTestHTTPAuthListener() *HTTPListenerV2 {
 	listener := newTestHTTPListenerV2()
 	listener.BasicUsername = basicUsername
-	listener.BasicPassword = basicPassword
+	listener.BasicPassword = internal.NewSecret(basicPassword)
 	return listener
 }
 
@@ -28,7 +28,7 @@ const (
 
 // HTTPResponse struct
 type HTTPResponse struct {
-	Address         string   // deprecated in 1.12
+	Address         string   `deprecated:"1.12.0;use 'urls' instead"`
 	URLs            []string `toml:"urls"`
 	HTTPProxy       string   `toml:"http_proxy"`
 	Body            string
@@ -45,8 +45,8 @@ type HTTPResponse struct {
 	ResponseStatusCode  int
 	Interface           string
 	// HTTP Basic Auth Credentials
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 	tls.ClientConfig
 
 	Log telegraf.Logger
@@ -288,8 +288,12 @@ func (h *HTTPResponse) httpGather(u string) (map[string]interface{}, map[string]
 		}
 	}
 
-	if h.Username != "" || h.Password != "" {
-		request.SetBasicAuth(h.Username, h.Password)
+	if h.Username != "" || !h.Password.Empty() {
+		password, err := h.Password.Get()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting password: %w", err)
+		}
+		request.SetBasicAuth(h.Username, password)
 	}
 
 	// Start Timer
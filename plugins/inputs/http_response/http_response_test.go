@@ -1087,7 +1087,7 @@ func TestBasicAuth(t *testing.T) {
 		Method:          "GET",
 		ResponseTimeout: internal.Duration{Duration: time.Second * 20},
 		Username:        "me",
-		Password:        "mypassword",
+		Password:        internal.NewSecret("mypassword"),
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
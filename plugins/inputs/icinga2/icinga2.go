@@ -17,7 +17,7 @@ type Icinga2 struct {
 	Server          string
 	ObjectType      string
 	Username        string
-	Password        string
+	Password        internal.Secret
 	ResponseTimeout internal.Duration
 	tls.ClientConfig
 
@@ -160,7 +160,11 @@ func (i *Icinga2) Gather(acc telegraf.Accumulator) error {
 	}
 
 	if i.Username != "" {
-		req.SetBasicAuth(i.Username, i.Password)
+		password, err := i.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(i.Username, password)
 	}
 
 	resp, err := i.client.Do(req)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"sync"
@@ -35,7 +36,7 @@ func (e *APIError) Error() string {
 type InfluxDB struct {
 	URLs     []string          `toml:"urls"`
 	Username string            `toml:"username"`
-	Password string            `toml:"password"`
+	Password internal.Secret   `toml:"password"`
 	Timeout  internal.Duration `toml:"timeout"`
 	tls.ClientConfig
 
@@ -164,8 +165,12 @@ func (i *InfluxDB) gatherURL(
 		return err
 	}
 
-	if i.Username != "" || i.Password != "" {
-		req.SetBasicAuth(i.Username, i.Password)
+	if i.Username != "" || !i.Password.Empty() {
+		password, err := i.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(i.Username, password)
 	}
 
 	req.Header.Set("User-Agent", "Telegraf/"+internal.Version())
@@ -32,9 +32,9 @@ type InfluxDBListener struct {
 	ReadTimeout        internal.Duration `toml:"read_timeout"`
 	WriteTimeout       internal.Duration `toml:"write_timeout"`
 	MaxBodySize        internal.Size     `toml:"max_body_size"`
-	MaxLineSize        internal.Size     `toml:"max_line_size"` // deprecated in 1.14; ignored
+	MaxLineSize        internal.Size     `toml:"max_line_size" deprecated:"1.14.0;option is ignored"`
 	BasicUsername      string            `toml:"basic_username"`
-	BasicPassword      string            `toml:"basic_password"`
+	BasicPassword      internal.Secret   `toml:"basic_password"`
 	DatabaseTag        string            `toml:"database_tag"`
 	RetentionPolicyTag string            `toml:"retention_policy_tag"`
 
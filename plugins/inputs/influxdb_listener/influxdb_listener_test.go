@@ -58,7 +58,7 @@ func newTestListener() *InfluxDBListener {
 func newTestAuthListener() *InfluxDBListener {
 	listener := newTestListener()
 	listener.BasicUsername = basicUsername
-	listener.BasicPassword = basicPassword
+	listener.BasicPassword = internal.NewSecret(basicPassword)
 	return listener
 }
 
@@ -39,9 +39,9 @@ type InfluxDBV2Listener struct {
 	port           int
 	tlsint.ServerConfig
 
-	MaxBodySize internal.Size `toml:"max_body_size"`
-	Token       string        `toml:"token"`
-	BucketTag   string        `toml:"bucket_tag"`
+	MaxBodySize internal.Size   `toml:"max_body_size"`
+	Token       internal.Secret `toml:"token"`
+	BucketTag   string          `toml:"bucket_tag"`
 
 	timeFunc influx.TimeFunc
 
@@ -106,11 +106,7 @@ func (h *InfluxDBV2Listener) Gather(_ telegraf.Accumulator) error {
 }
 
 func (h *InfluxDBV2Listener) routes() {
-	credentials := ""
-	if h.Token != "" {
-		credentials = fmt.Sprintf("Token %s", h.Token)
-	}
-	authHandler := internal.GenericAuthHandler(credentials,
+	authHandler := internal.GenericAuthHandler("Token", h.Token,
 		func(_ http.ResponseWriter) {
 			h.authFailures.Incr(1)
 		},
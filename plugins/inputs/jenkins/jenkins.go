@@ -21,7 +21,7 @@ import (
 type Jenkins struct {
 	URL      string
 	Username string
-	Password string
+	Password internal.Secret
 	Source   string
 	Port     string
 	// HTTP Timeout specified as a string - 3s, 1m, 1h
@@ -181,7 +181,11 @@ func (j *Jenkins) initialize(client *http.Client) error {
 
 	j.semaphore = make(chan struct{}, j.MaxConnections)
 
-	j.client = newClient(client, j.URL, j.Username, j.Password, j.MaxConnections)
+	password, err := j.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
+	j.client = newClient(client, j.URL, j.Username, password, j.MaxConnections)
 
 	return j.client.init()
 }
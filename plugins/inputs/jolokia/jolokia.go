@@ -23,7 +23,7 @@ type Server struct {
 	Name     string
 	Host     string
 	Username string
-	Password string
+	Password internal.Secret
 	Port     string
 }
 
@@ -171,6 +171,11 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 	var jolokiaUrl *url.URL
 	context := j.Context // Usually "/jolokia/"
 
+	password, err := server.Password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password: %w", err)
+	}
+
 	var bulkBodyContent []map[string]interface{}
 	for _, metric := range metrics {
 		// Create bodyContent
@@ -199,8 +204,8 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 				target["user"] = server.Username
 			}
 
-			if server.Password != "" {
-				target["password"] = server.Password
+			if password != "" {
+				target["password"] = password
 			}
 
 			bodyContent["target"] = target
@@ -212,8 +217,12 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 			if err != nil {
 				return nil, err
 			}
-			if proxy.Username != "" || proxy.Password != "" {
-				proxyUrl.User = url.UserPassword(proxy.Username, proxy.Password)
+			if proxy.Username != "" || !proxy.Password.Empty() {
+				proxyPassword, err := proxy.Password.Get()
+				if err != nil {
+					return nil, fmt.Errorf("getting proxy password: %w", err)
+				}
+				proxyUrl.User = url.UserPassword(proxy.Username, proxyPassword)
 			}
 
 			jolokiaUrl = proxyUrl
@@ -223,8 +232,8 @@ func (j *Jolokia) prepareRequest(server Server, metrics []Metric) (*http.Request
 			if err != nil {
 				return nil, err
 			}
-			if server.Username != "" || server.Password != "" {
-				serverUrl.User = url.UserPassword(server.Username, server.Password)
+			if server.Username != "" || password != "" {
+				serverUrl.User = url.UserPassword(server.Username, password)
 			}
 
 			jolokiaUrl = serverUrl
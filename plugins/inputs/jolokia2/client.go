@@ -10,6 +10,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 )
 
@@ -22,20 +23,20 @@ type Client struct {
 type ClientConfig struct {
 	ResponseTimeout time.Duration
 	Username        string
-	Password        string
+	Password        internal.Secret
 	ProxyConfig     *ProxyConfig
 	tls.ClientConfig
 }
 
 type ProxyConfig struct {
 	DefaultTargetUsername string
-	DefaultTargetPassword string
+	DefaultTargetPassword internal.Secret
 	Targets               []ProxyTargetConfig
 }
 
 type ProxyTargetConfig struct {
 	Username string
-	Password string
+	Password internal.Secret
 	URL      string
 }
 
@@ -119,13 +120,20 @@ func NewClient(url string, config *ClientConfig) (*Client, error) {
 }
 
 func (c *Client) read(requests []ReadRequest) ([]ReadResponse, error) {
-	jrequests := makeJolokiaRequests(requests, c.config.ProxyConfig)
+	jrequests, err := makeJolokiaRequests(requests, c.config.ProxyConfig)
+	if err != nil {
+		return nil, err
+	}
 	requestBody, err := json.Marshal(jrequests)
 	if err != nil {
 		return nil, err
 	}
 
-	requestUrl, err := formatReadUrl(c.URL, c.config.Username, c.config.Password)
+	password, err := c.config.Password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password: %w", err)
+	}
+	requestUrl, err := formatReadUrl(c.URL, c.config.Username, password)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +169,7 @@ func (c *Client) read(requests []ReadRequest) ([]ReadResponse, error) {
 	return makeReadResponses(jresponses), nil
 }
 
-func makeJolokiaRequests(rrequests []ReadRequest, proxyConfig *ProxyConfig) []jolokiaRequest {
+func makeJolokiaRequests(rrequests []ReadRequest, proxyConfig *ProxyConfig) ([]jolokiaRequest, error) {
 	jrequests := make([]jolokiaRequest, 0)
 	if proxyConfig == nil {
 		for _, rr := range rrequests {
@@ -172,15 +180,19 @@ func makeJolokiaRequests(rrequests []ReadRequest, proxyConfig *ProxyConfig) []jo
 			if t.Username == "" {
 				t.Username = proxyConfig.DefaultTargetUsername
 			}
-			if t.Password == "" {
+			if t.Password.Empty() {
 				t.Password = proxyConfig.DefaultTargetPassword
 			}
+			password, err := t.Password.Get()
+			if err != nil {
+				return nil, fmt.Errorf("getting password of target %s: %w", t.URL, err)
+			}
 
 			for _, rr := range rrequests {
 				jtarget := &jolokiaTarget{
 					URL:      t.URL,
 					User:     t.Username,
-					Password: t.Password,
+					Password: password,
 				}
 
 				jrequests = append(jrequests, makeJolokiaRequest(rr, jtarget))
@@ -188,7 +200,7 @@ func makeJolokiaRequests(rrequests []ReadRequest, proxyConfig *ProxyConfig) []jo
 		}
 	}
 
-	return jrequests
+	return jrequests, nil
 }
 
 func makeJolokiaRequest(rrequest ReadRequest, jtarget *jolokiaTarget) jolokiaRequest {
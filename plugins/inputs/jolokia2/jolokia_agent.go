@@ -16,7 +16,7 @@ type JolokiaAgent struct {
 
 	URLs            []string `toml:"urls"`
 	Username        string
-	Password        string
+	Password        internal.Secret
 	ResponseTimeout internal.Duration `toml:"response_timeout"`
 
 	tls.ClientConfig
@@ -12,12 +12,12 @@ type JolokiaProxy struct {
 	DefaultTagPrefix      string
 
 	URL                   string `toml:"url"`
-	DefaultTargetPassword string
+	DefaultTargetPassword internal.Secret
 	DefaultTargetUsername string
 	Targets               []JolokiaProxyTargetConfig `toml:"target"`
 
 	Username        string
-	Password        string
+	Password        internal.Secret
 	ResponseTimeout internal.Duration `toml:"response_timeout"`
 	tls.ClientConfig
 
@@ -29,7 +29,7 @@ type JolokiaProxy struct {
 type JolokiaProxyTargetConfig struct {
 	URL      string `toml:"url"`
 	Username string
-	Password string
+	Password internal.Secret
 }
 
 func (jp *JolokiaProxy) SampleConfig() string {
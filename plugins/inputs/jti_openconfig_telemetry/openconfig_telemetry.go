@@ -25,7 +25,7 @@ type OpenConfigTelemetry struct {
 	Servers         []string          `toml:"servers"`
 	Sensors         []string          `toml:"sensors"`
 	Username        string            `toml:"username"`
-	Password        string            `toml:"password"`
+	Password        internal.Secret   `toml:"password"`
 	ClientID        string            `toml:"client_id"`
 	SampleFrequency internal.Duration `toml:"sample_frequency"`
 	StrAsTags       bool              `toml:"str_as_tags"`
@@ -390,11 +390,16 @@ func (m *OpenConfigTelemetry) Start(acc telegraf.Accumulator) error {
 		// Add to the list of client connections
 		m.grpcClientConns = append(m.grpcClientConns, grpcClientConn)
 
-		if m.Username != "" && m.Password != "" && m.ClientID != "" {
+		if m.Username != "" && !m.Password.Empty() && m.ClientID != "" {
+			password, err := m.Password.Get()
+			if err != nil {
+				m.Log.Errorf("Getting password for %s: %v", server, err)
+				continue
+			}
 			lc := authentication.NewLoginClient(grpcClientConn)
 			loginReply, loginErr := lc.LoginCheck(ctx,
 				&authentication.LoginRequest{UserName: m.Username,
-					Password: m.Password, ClientId: m.ClientID})
+					Password: password, ClientId: m.ClientID})
 			if loginErr != nil {
 				m.Log.Errorf("Could not initiate login check for %s: %v", server, loginErr)
 				continue
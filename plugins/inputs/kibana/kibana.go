@@ -103,7 +103,7 @@ type Kibana struct {
 	Local    bool
 	Servers  []string
 	Username string
-	Password string
+	Password internal.Secret
 	Timeout  internal.Duration
 	tls.ClientConfig
 
@@ -243,8 +243,12 @@ func (k *Kibana) gatherJsonData(url string, v interface{}) (host string, err err
 		return "", fmt.Errorf("unable to create new request '%s': %v", url, err)
 	}
 
-	if (k.Username != "") || (k.Password != "") {
-		request.SetBasicAuth(k.Username, k.Password)
+	if k.Username != "" || !k.Password.Empty() {
+		password, err := k.Password.Get()
+		if err != nil {
+			return "", fmt.Errorf("getting password: %w", err)
+		}
+		request.SetBasicAuth(k.Username, password)
 	}
 
 	response, err := k.client.Do(request)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	internalaws "github.com/influxdata/telegraf/config/aws"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
@@ -26,18 +27,18 @@ type (
 	}
 
 	KinesisConsumer struct {
-		Region                 string    `toml:"region"`
-		AccessKey              string    `toml:"access_key"`
-		SecretKey              string    `toml:"secret_key"`
-		RoleARN                string    `toml:"role_arn"`
-		Profile                string    `toml:"profile"`
-		Filename               string    `toml:"shared_credential_file"`
-		Token                  string    `toml:"token"`
-		EndpointURL            string    `toml:"endpoint_url"`
-		StreamName             string    `toml:"streamname"`
-		ShardIteratorType      string    `toml:"shard_iterator_type"`
-		DynamoDB               *DynamoDB `toml:"checkpoint_dynamodb"`
-		MaxUndeliveredMessages int       `toml:"max_undelivered_messages"`
+		Region                 string          `toml:"region"`
+		AccessKey              string          `toml:"access_key"`
+		SecretKey              internal.Secret `toml:"secret_key"`
+		RoleARN                string          `toml:"role_arn"`
+		Profile                string          `toml:"profile"`
+		Filename               string          `toml:"shared_credential_file"`
+		Token                  internal.Secret `toml:"token"`
+		EndpointURL            string          `toml:"endpoint_url"`
+		StreamName             string          `toml:"streamname"`
+		ShardIteratorType      string          `toml:"shard_iterator_type"`
+		DynamoDB               *DynamoDB       `toml:"checkpoint_dynamodb"`
+		MaxUndeliveredMessages int             `toml:"max_undelivered_messages"`
 
 		Log telegraf.Logger
 
@@ -27,7 +27,7 @@ const (
 type KubernetesInventory struct {
 	URL               string            `toml:"url"`
 	BearerToken       string            `toml:"bearer_token"`
-	BearerTokenString string            `toml:"bearer_token_string"`
+	BearerTokenString internal.Secret   `toml:"bearer_token_string"`
 	Namespace         string            `toml:"namespace"`
 	ResponseTimeout   internal.Duration `toml:"response_timeout"` // Timeout specified as a string - 3s, 1m, 1h
 	ResourceExclude   []string          `toml:"resource_exclude"`
@@ -96,7 +96,7 @@ func (ki *KubernetesInventory) Description() string {
 
 func (ki *KubernetesInventory) Init() error {
 	// If neither are provided, use the default service account.
-	if ki.BearerToken == "" && ki.BearerTokenString == "" {
+	if ki.BearerToken == "" && ki.BearerTokenString.Empty() {
 		ki.BearerToken = defaultServiceAccountPath
 	}
 
@@ -105,11 +105,14 @@ func (ki *KubernetesInventory) Init() error {
 		if err != nil {
 			return err
 		}
-		ki.BearerTokenString = strings.TrimSpace(string(token))
+		ki.BearerTokenString = internal.NewSecret(strings.TrimSpace(string(token)))
 	}
 
-	var err error
-	ki.client, err = newClient(ki.URL, ki.Namespace, ki.BearerTokenString, ki.ResponseTimeout.Duration, ki.ClientConfig)
+	token, err := ki.BearerTokenString.Get()
+	if err != nil {
+		return fmt.Errorf("getting bearer_token_string: %w", err)
+	}
+	ki.client, err = newClient(ki.URL, ki.Namespace, token, ki.ResponseTimeout.Duration, ki.ClientConfig)
 
 	if err != nil {
 		return err
@@ -21,8 +21,8 @@ type Kubernetes struct {
 	URL string
 
 	// Bearer Token authorization file path
-	BearerToken       string `toml:"bearer_token"`
-	BearerTokenString string `toml:"bearer_token_string"`
+	BearerToken       string          `toml:"bearer_token"`
+	BearerTokenString internal.Secret `toml:"bearer_token_string"`
 
 	LabelInclude []string `toml:"label_include"`
 	LabelExclude []string `toml:"label_exclude"`
@@ -91,7 +91,7 @@ func (k *Kubernetes) Description() string {
 func (k *Kubernetes) Init() error {
 
 	// If neither are provided, use the default service account.
-	if k.BearerToken == "" && k.BearerTokenString == "" {
+	if k.BearerToken == "" && k.BearerTokenString.Empty() {
 		k.BearerToken = defaultServiceAccountPath
 	}
 
@@ -100,7 +100,7 @@ func (k *Kubernetes) Init() error {
 		if err != nil {
 			return err
 		}
-		k.BearerTokenString = strings.TrimSpace(string(token))
+		k.BearerTokenString = internal.NewSecret(strings.TrimSpace(string(token)))
 	}
 
 	labelFilter, err := filter.NewIncludeExcludeFilter(k.LabelInclude, k.LabelExclude)
@@ -225,7 +225,11 @@ func (k *Kubernetes) LoadJson(url string, v interface{}) error {
 			ResponseHeaderTimeout: k.ResponseTimeout.Duration,
 		}
 	}
-	req.Header.Set("Authorization", "Bearer "+k.BearerTokenString)
+	token, err := k.BearerTokenString.Get()
+	if err != nil {
+		return fmt.Errorf("getting bearer_token_string: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Add("Accept", "application/json")
 	resp, err = k.RoundTripper.RoundTrip(req)
 	if err != nil {
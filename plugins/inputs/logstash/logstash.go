@@ -57,7 +57,7 @@ type Logstash struct {
 	Collect        []string `toml:"collect"`
 
 	Username string            `toml:"username"`
-	Password string            `toml:"password"`
+	Password internal.Secret   `toml:"password"`
 	Headers  map[string]string `toml:"headers"`
 	Timeout  internal.Duration `toml:"timeout"`
 	tls.ClientConfig
@@ -181,8 +181,12 @@ func (logstash *Logstash) gatherJsonData(url string, value interface{}) error {
 		return err
 	}
 
-	if (logstash.Username != "") || (logstash.Password != "") {
-		request.SetBasicAuth(logstash.Username, logstash.Password)
+	if logstash.Username != "" || !logstash.Password.Empty() {
+		password, err := logstash.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		request.SetBasicAuth(logstash.Username, password)
 	}
 
 	for header, value := range logstash.Headers {
@@ -5,13 +5,14 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 type MailChimp struct {
 	api *ChimpAPI
 
-	ApiKey     string
+	ApiKey     internal.Secret
 	DaysOld    int
 	CampaignId string
 }
@@ -37,7 +38,11 @@ func (m *MailChimp) Description() string {
 
 func (m *MailChimp) Gather(acc telegraf.Accumulator) error {
 	if m.api == nil {
-		m.api = NewChimpAPI(m.ApiKey)
+		apiKey, err := m.ApiKey.Get()
+		if err != nil {
+			return fmt.Errorf("getting api_key: %w", err)
+		}
+		m.api = NewChimpAPI(apiKey)
 	}
 	m.api.Debug = false
 
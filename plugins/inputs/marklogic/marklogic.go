@@ -10,16 +10,17 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
 // Marklogic configuration toml
 type Marklogic struct {
-	URL      string   `toml:"url"`
-	Hosts    []string `toml:"hosts"`
-	Username string   `toml:"username"`
-	Password string   `toml:"password"`
+	URL      string          `toml:"url"`
+	Hosts    []string        `toml:"hosts"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 	Sources  []string
 
 	tls.ClientConfig
@@ -232,8 +233,12 @@ func (c *Marklogic) gatherJSONData(url string, v interface{}) error {
 		return err
 	}
 
-	if c.Username != "" || c.Password != "" {
-		req.SetBasicAuth(c.Username, c.Password)
+	if c.Username != "" || !c.Password.Empty() {
+		password, err := c.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(c.Username, password)
 	}
 
 	response, err := c.client.Do(req)
@@ -1,7 +1,10 @@
 package minecraft
 
 import (
+	"fmt"
+
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -33,9 +36,9 @@ type Client interface {
 
 // Minecraft is the plugin type.
 type Minecraft struct {
-	Server   string `toml:"server"`
-	Port     string `toml:"port"`
-	Password string `toml:"password"`
+	Server   string          `toml:"server"`
+	Port     string          `toml:"port"`
+	Password internal.Secret `toml:"password"`
 
 	client Client
 }
@@ -50,7 +53,11 @@ func (s *Minecraft) SampleConfig() string {
 
 func (s *Minecraft) Gather(acc telegraf.Accumulator) error {
 	if s.client == nil {
-		connector, err := NewConnector(s.Server, s.Port, s.Password)
+		password, err := s.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		connector, err := NewConnector(s.Server, s.Port, password)
 		if err != nil {
 			return err
 		}
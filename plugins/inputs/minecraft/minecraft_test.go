@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -108,7 +109,7 @@ func TestGather(t *testing.T) {
 			plugin := &Minecraft{
 				Server:   "example.org",
 				Port:     "25575",
-				Password: "xyzzy",
+				Password: internal.NewSecret("xyzzy"),
 				client:   tt.client,
 			}
 
@@ -172,9 +172,9 @@ type System struct {
 }
 
 type Monit struct {
-	Address  string `toml:"address"`
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Address  string          `toml:"address"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 	client   http.Client
 	tls.ClientConfig
 	Timeout internal.Duration `toml:"timeout"`
@@ -233,8 +233,12 @@ func (m *Monit) Gather(acc telegraf.Accumulator) error {
 	if err != nil {
 		return err
 	}
-	if len(m.Username) > 0 || len(m.Password) > 0 {
-		req.SetBasicAuth(m.Username, m.Password)
+	if m.Username != "" || !m.Password.Empty() {
+		password, err := m.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(m.Username, password)
 	}
 
 	resp, err := m.client.Do(req)
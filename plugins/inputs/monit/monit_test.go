@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -557,7 +558,7 @@ func TestAllowHosts(t *testing.T) {
 	r := &Monit{
 		Address:  "http://127.0.0.1:2812",
 		Username: "test",
-		Password: "test",
+		Password: internal.NewSecret("test"),
 	}
 
 	var acc testutil.Accumulator
@@ -576,7 +577,7 @@ func TestConnection(t *testing.T) {
 	r := &Monit{
 		Address:  "http://127.0.0.1:2812",
 		Username: "test",
-		Password: "test",
+		Password: internal.NewSecret("test"),
 	}
 
 	var acc testutil.Accumulator
@@ -612,7 +613,7 @@ func TestInvalidUsernameOrPassword(t *testing.T) {
 	r := &Monit{
 		Address:  ts.URL,
 		Username: "test",
-		Password: "test",
+		Password: internal.NewSecret("test"),
 	}
 
 	var acc testutil.Accumulator
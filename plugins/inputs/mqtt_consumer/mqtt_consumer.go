@@ -46,7 +46,7 @@ type MQTTConsumer struct {
 	Topics                 []string          `toml:"topics"`
 	TopicTag               *string           `toml:"topic_tag"`
 	Username               string            `toml:"username"`
-	Password               string            `toml:"password"`
+	Password               internal.Secret   `toml:"password"`
 	QoS                    int               `toml:"qos"`
 	ConnectionTimeout      internal.Duration `toml:"connection_timeout"`
 	MaxUndeliveredMessages int               `toml:"max_undelivered_messages"`
@@ -338,8 +338,11 @@ func (m *MQTTConsumer) createOpts() (*mqtt.ClientOptions, error) {
 	if user != "" {
 		opts.SetUsername(user)
 	}
-	password := m.Password
-	if password != "" {
+	if !m.Password.Empty() {
+		password, err := m.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
 		opts.SetPassword(password)
 	}
 
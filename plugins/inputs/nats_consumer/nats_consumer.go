@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
@@ -32,13 +33,13 @@ func (e natsError) Error() string {
 }
 
 type natsConsumer struct {
-	QueueGroup  string   `toml:"queue_group"`
-	Subjects    []string `toml:"subjects"`
-	Servers     []string `toml:"servers"`
-	Secure      bool     `toml:"secure"`
-	Username    string   `toml:"username"`
-	Password    string   `toml:"password"`
-	Credentials string   `toml:"credentials"`
+	QueueGroup  string          `toml:"queue_group"`
+	Subjects    []string        `toml:"subjects"`
+	Servers     []string        `toml:"servers"`
+	Secure      bool            `toml:"secure"`
+	Username    string          `toml:"username"`
+	Password    internal.Secret `toml:"password"`
+	Credentials string          `toml:"credentials"`
 
 	tls.ClientConfig
 
@@ -147,8 +148,12 @@ func (n *natsConsumer) Start(acc telegraf.Accumulator) error {
 	}
 
 	// override authentication, if any was specified
-	if n.Username != "" && n.Password != "" {
-		options = append(options, nats.UserInfo(n.Username, n.Password))
+	if n.Username != "" && !n.Password.Empty() {
+		password, err := n.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		options = append(options, nats.UserInfo(n.Username, password))
 	}
 
 	if n.Credentials != "" {
@@ -51,7 +51,7 @@ type NginxUpstreamCheck struct {
 	URL string `toml:"url"`
 
 	Username   string            `toml:"username"`
-	Password   string            `toml:"password"`
+	Password   internal.Secret   `toml:"password"`
 	Method     string            `toml:"method"`
 	Headers    map[string]string `toml:"headers"`
 	HostHeader string            `toml:"host_header"`
@@ -136,8 +136,12 @@ func (check *NginxUpstreamCheck) gatherJsonData(url string, value interface{}) e
 		return err
 	}
 
-	if (check.Username != "") || (check.Password != "") {
-		request.SetBasicAuth(check.Username, check.Password)
+	if check.Username != "" || !check.Password.Empty() {
+		password, err := check.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		request.SetBasicAuth(check.Username, password)
 	}
 	for header, value := range check.Headers {
 		request.Header.Add(header, value)
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -125,7 +126,7 @@ func TestNginxUpstreamCheckRequest(test *testing.T) {
 	check.Headers["X-test"] = "test-value"
 	check.HostHeader = "status.local"
 	check.Username = "user"
-	check.Password = "password"
+	check.Password = internal.NewSecret("password")
 	check.Method = "POST"
 
 	var accumulator testutil.Accumulator
@@ -12,6 +12,7 @@ import (
 	"github.com/gopcua/opcua/ua"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -24,7 +25,7 @@ type OpcUA struct {
 	Certificate    string          `toml:"certificate"`
 	PrivateKey     string          `toml:"private_key"`
 	Username       string          `toml:"username"`
-	Password       string          `toml:"password"`
+	Password       internal.Secret `toml:"password"`
 	AuthMethod     string          `toml:"auth_method"`
 	ConnectTimeout config.Duration `toml:"connect_timeout"`
 	RequestTimeout config.Duration `toml:"request_timeout"`
@@ -317,7 +318,11 @@ func (o *OpcUA) setupOptions() error {
 		}
 	}
 
-	o.opts = generateClientOpts(endpoints, o.Certificate, o.PrivateKey, o.SecurityPolicy, o.SecurityMode, o.AuthMethod, o.Username, o.Password, time.Duration(o.RequestTimeout))
+	password, err := o.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
+	o.opts = generateClientOpts(endpoints, o.Certificate, o.PrivateKey, o.SecurityPolicy, o.SecurityMode, o.AuthMethod, o.Username, password, time.Duration(o.RequestTimeout))
 
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"gopkg.in/ldap.v3"
@@ -14,13 +15,13 @@ import (
 type Openldap struct {
 	Host               string
 	Port               int
-	SSL                string `toml:"ssl"` // Deprecated in 1.7; use TLS
+	SSL                string `toml:"ssl" deprecated:"1.7.0;use 'tls_*' options instead"`
 	TLS                string `toml:"tls"`
 	InsecureSkipVerify bool
-	SSLCA              string `toml:"ssl_ca"` // Deprecated in 1.7; use TLSCA
+	SSLCA              string `toml:"ssl_ca" deprecated:"1.7.0;use 'tls_ca' instead"`
 	TLSCA              string `toml:"tls_ca"`
 	BindDn             string
-	BindPassword       string
+	BindPassword       internal.Secret
 	ReverseMetricNames bool
 }
 
@@ -83,7 +84,6 @@ func NewOpenldap() *Openldap {
 		SSLCA:              "",
 		TLSCA:              "",
 		BindDn:             "",
-		BindPassword:       "",
 		ReverseMetricNames: false,
 	}
 }
@@ -142,8 +142,13 @@ func (o *Openldap) Gather(acc telegraf.Accumulator) error {
 	defer l.Close()
 
 	// username/password bind
-	if o.BindDn != "" && o.BindPassword != "" {
-		err = l.Bind(o.BindDn, o.BindPassword)
+	if o.BindDn != "" && !o.BindPassword.Empty() {
+		password, err := o.BindPassword.Get()
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting bind_password: %w", err))
+			return nil
+		}
+		err = l.Bind(o.BindDn, password)
 		if err != nil {
 			acc.AddError(err)
 			return nil
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -132,7 +133,7 @@ func TestOpenldapBind(t *testing.T) {
 		SSL:                "",
 		InsecureSkipVerify: true,
 		BindDn:             "cn=manager,cn=config",
-		BindPassword:       "secret",
+		BindPassword:       internal.NewSecret("secret"),
 	}
 
 	var acc testutil.Accumulator
@@ -160,7 +161,7 @@ func TestOpenldapReverseMetrics(t *testing.T) {
 		SSL:                "",
 		InsecureSkipVerify: true,
 		BindDn:             "cn=manager,cn=config",
-		BindPassword:       "secret",
+		BindPassword:       internal.NewSecret("secret"),
 		ReverseMetricNames: true,
 	}
 
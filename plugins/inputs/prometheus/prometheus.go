@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	promparser "github.com/influxdata/telegraf/plugins/parsers/prometheus"
 )
 
 const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
@@ -36,12 +37,12 @@ type Prometheus struct {
 	KubernetesFieldSelector string `toml:"kubernetes_field_selector"`
 
 	// Bearer Token authorization file path
-	BearerToken       string `toml:"bearer_token"`
-	BearerTokenString string `toml:"bearer_token_string"`
+	BearerToken       string          `toml:"bearer_token"`
+	BearerTokenString internal.Secret `toml:"bearer_token_string"`
 
 	// Basic authentication credentials
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 
 	ResponseTimeout internal.Duration `toml:"response_timeout"`
 
@@ -104,7 +105,7 @@ var sampleConfig = `
 
   ## Use bearer token for authorization. ('bearer_token' takes priority)
   # bearer_token = "/path/to/bearer/token"
-  ## OR
+  ## OR, the value may reference a secret store as "@{<store id>:<key>}"
   # bearer_token_string = "abc_123"
 
   ## HTTP Basic Authentication username and password. ('bearer_token' and
@@ -302,10 +303,18 @@ func (p *Prometheus) gatherURL(u URLAndAddress, acc telegraf.Accumulator) error
 			return err
 		}
 		req.Header.Set("Authorization", "Bearer "+string(token))
-	} else if p.BearerTokenString != "" {
-		req.Header.Set("Authorization", "Bearer "+p.BearerTokenString)
-	} else if p.Username != "" || p.Password != "" {
-		req.SetBasicAuth(p.Username, p.Password)
+	} else if !p.BearerTokenString.Empty() {
+		token, err := p.BearerTokenString.Get()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if p.Username != "" || !p.Password.Empty() {
+		password, err := p.Password.Get()
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(p.Username, password)
 	}
 
 	var resp *http.Response
@@ -328,12 +337,11 @@ func (p *Prometheus) gatherURL(u URLAndAddress, acc telegraf.Accumulator) error
 		return fmt.Errorf("error reading body: %s", err)
 	}
 
-	if p.MetricVersion == 2 {
-		metrics, err = ParseV2(body, resp.Header)
-	} else {
-		metrics, err = Parse(body, resp.Header)
+	parser := promparser.Parser{
+		MetricVersion: p.MetricVersion,
+		Header:        resp.Header,
 	}
-
+	metrics, err = parser.Parse(body)
 	if err != nil {
 		return fmt.Errorf("error reading metrics for %s: %s",
 			u.URL, err)
@@ -3,6 +3,7 @@ package proxmox
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"io/ioutil"
@@ -106,7 +107,11 @@ func performRequest(px *Proxmox, apiUrl string, method string, data url.Values)
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Add("Authorization", "PVEAPIToken="+px.APIToken)
+	token, err := px.APIToken.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting api_token: %w", err)
+	}
+	request.Header.Add("Authorization", "PVEAPIToken="+token)
 
 	resp, err := px.httpClient.Do(request)
 	if err != nil {
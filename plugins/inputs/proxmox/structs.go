@@ -11,7 +11,7 @@ import (
 
 type Proxmox struct {
 	BaseURL         string            `toml:"base_url"`
-	APIToken        string            `toml:"api_token"`
+	APIToken        internal.Secret   `toml:"api_token"`
 	ResponseTimeout internal.Duration `toml:"response_timeout"`
 	NodeName        string            `toml:"node_name"`
 
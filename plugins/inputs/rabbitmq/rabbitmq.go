@@ -34,10 +34,10 @@ const DefaultClientTimeout = 4
 // RabbitMQ defines the configuration necessary for gathering metrics,
 // see the sample config for further details
 type RabbitMQ struct {
-	URL      string `toml:"url"`
-	Name     string `toml:"name"`
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	URL      string          `toml:"url"`
+	Name     string          `toml:"name"`
+	Username string          `toml:"username"`
+	Password internal.Secret `toml:"password"`
 	tls.ClientConfig
 
 	ResponseHeaderTimeout internal.Duration `toml:"header_timeout"`
@@ -382,7 +382,10 @@ func (r *RabbitMQ) requestJSON(u string, target interface{}) error {
 		username = DefaultUsername
 	}
 
-	password := r.Password
+	password, err := r.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
 	if password == "" {
 		password = DefaultPassword
 	}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -42,7 +43,7 @@ const sampleConfig = `
 type Redfish struct {
 	Address          string          `toml:"address"`
 	Username         string          `toml:"username"`
-	Password         string          `toml:"password"`
+	Password         internal.Secret `toml:"password"`
 	ComputerSystemId string          `toml:"computer_system_id"`
 	Timeout          config.Duration `toml:"timeout"`
 
@@ -142,7 +143,7 @@ func (r *Redfish) Init() error {
 		return fmt.Errorf("did not provide IP")
 	}
 
-	if r.Username == "" && r.Password == "" {
+	if r.Username == "" && r.Password.Empty() {
 		return fmt.Errorf("did not provide username and password")
 	}
 
@@ -178,7 +179,11 @@ func (r *Redfish) getData(url string, payload interface{}) error {
 		return err
 	}
 
-	req.SetBasicAuth(r.Username, r.Password)
+	password, err := r.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
+	req.SetBasicAuth(r.Username, password)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := r.client.Do(req)
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -466,7 +467,7 @@ func TestDellApis(t *testing.T) {
 	plugin := &Redfish{
 		Address:          ts.URL,
 		Username:         "test",
-		Password:         "test",
+		Password:         internal.NewSecret("test"),
 		ComputerSystemId: "System.Embedded.1",
 	}
 	plugin.Init()
@@ -621,7 +622,7 @@ func TestHPApis(t *testing.T) {
 	hp_plugin := &Redfish{
 		Address:          ts.URL,
 		Username:         "test",
-		Password:         "test",
+		Password:         internal.NewSecret("test"),
 		ComputerSystemId: "1",
 	}
 	hp_plugin.Init()
@@ -647,7 +648,7 @@ func TestConnection(t *testing.T) {
 	r := &Redfish{
 		Address:          "http://127.0.0.1",
 		Username:         "test",
-		Password:         "test",
+		Password:         internal.NewSecret("test"),
 		ComputerSystemId: "System.Embedded.1",
 	}
 
@@ -679,7 +680,7 @@ func TestInvalidUsernameorPassword(t *testing.T) {
 	r := &Redfish{
 		Address:          ts.URL,
 		Username:         "test",
-		Password:         "test",
+		Password:         internal.NewSecret("test"),
 		ComputerSystemId: "System.Embedded.1",
 	}
 
@@ -781,7 +782,7 @@ func TestInvalidDellJSON(t *testing.T) {
 		plugin := &Redfish{
 			Address:          ts.URL,
 			Username:         "test",
-			Password:         "test",
+			Password:         internal.NewSecret("test"),
 			ComputerSystemId: "System.Embedded.1",
 		}
 
@@ -852,7 +853,7 @@ func TestInvalidHPJSON(t *testing.T) {
 			plugin := &Redfish{
 				Address:          ts.URL,
 				Username:         "test",
-				Password:         "test",
+				Password:         internal.NewSecret("test"),
 				ComputerSystemId: "System.Embedded.2",
 			}
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/go-redis/redis"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -26,7 +27,7 @@ type RedisCommand struct {
 type Redis struct {
 	Commands []*RedisCommand
 	Servers  []string
-	Password string
+	Password internal.Secret
 	tls.ClientConfig
 
 	Log telegraf.Logger
@@ -147,8 +148,11 @@ func (r *Redis) init(acc telegraf.Accumulator) error {
 				password = pw
 			}
 		}
-		if len(r.Password) > 0 {
-			password = r.Password
+		if !r.Password.Empty() {
+			password, err = r.Password.Get()
+			if err != nil {
+				return fmt.Errorf("getting password: %w", err)
+			}
 		}
 
 		var address string
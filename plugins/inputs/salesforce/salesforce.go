@@ -45,8 +45,8 @@ type limits map[string]limit
 
 type Salesforce struct {
 	Username       string
-	Password       string
-	SecurityToken  string
+	Password       internal.Secret
+	SecurityToken  internal.Secret
 	Environment    string
 	SessionID      string
 	ServerURL      *url.URL
@@ -168,10 +168,19 @@ func (s *Salesforce) getLoginEndpoint() (string, error) {
 
 // Authenticate with Salesforce
 func (s *Salesforce) login() error {
-	if s.Username == "" || s.Password == "" {
+	if s.Username == "" || s.Password.Empty() {
 		return errors.New("missing username or password")
 	}
 
+	password, err := s.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password: %w", err)
+	}
+	securityToken, err := s.SecurityToken.Get()
+	if err != nil {
+		return fmt.Errorf("getting security token: %w", err)
+	}
+
 	body := fmt.Sprintf(`<?xml version="1.0" encoding="utf-8"?>
 		<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"
 		  xmlns:urn="urn:enterprise.soap.sforce.com">
@@ -182,7 +191,7 @@ func (s *Salesforce) login() error {
 			</urn:login>
 		  </soapenv:Body>
 		</soapenv:Envelope>`,
-		s.Username, s.Password, s.SecurityToken)
+		s.Username, password, securityToken)
 
 	loginEndpoint, err := s.getLoginEndpoint()
 	if err != nil {
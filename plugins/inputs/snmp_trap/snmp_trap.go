@@ -38,11 +38,11 @@ type SnmpTrap struct {
 	SecLevel string `toml:"sec_level"`
 	SecName  string `toml:"sec_name"`
 	// Values: "MD5", "SHA", "". Default: ""
-	AuthProtocol string `toml:"auth_protocol"`
-	AuthPassword string `toml:"auth_password"`
+	AuthProtocol string          `toml:"auth_protocol"`
+	AuthPassword internal.Secret `toml:"auth_password"`
 	// Values: "DES", "AES", "". Default: ""
-	PrivProtocol string `toml:"priv_protocol"`
-	PrivPassword string `toml:"priv_password"`
+	PrivProtocol string          `toml:"priv_protocol"`
+	PrivPassword internal.Secret `toml:"priv_password"`
 
 	acc      telegraf.Accumulator
 	listener *gosnmp.TrapListener
@@ -199,11 +199,19 @@ func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
 			return fmt.Errorf("unknown privacy protocol '%s'", s.PrivProtocol)
 		}
 
+		privPassword, err := s.PrivPassword.Get()
+		if err != nil {
+			return fmt.Errorf("getting priv_password: %w", err)
+		}
+		authPassword, err := s.AuthPassword.Get()
+		if err != nil {
+			return fmt.Errorf("getting auth_password: %w", err)
+		}
 		s.listener.Params.SecurityParameters = &gosnmp.UsmSecurityParameters{
 			UserName:                 s.SecName,
 			PrivacyProtocol:          privacyProtocol,
-			PrivacyPassphrase:        s.PrivPassword,
-			AuthenticationPassphrase: s.AuthPassword,
+			PrivacyPassphrase:        privPassword,
+			AuthenticationPassphrase: authPassword,
 			AuthenticationProtocol:   authenticationProtocol,
 		}
 
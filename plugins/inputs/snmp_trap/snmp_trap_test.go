@@ -1285,9 +1285,9 @@ func TestReceiveTrap(t *testing.T) {
 				SecName:      tt.secName,
 				SecLevel:     tt.secLevel,
 				AuthProtocol: tt.authProto,
-				AuthPassword: tt.authPass,
+				AuthPassword: internal.NewSecret(tt.authPass),
 				PrivProtocol: tt.privProto,
-				PrivPassword: tt.privPass,
+				PrivPassword: internal.NewSecret(tt.privPass),
 			}
 			require.Nil(t, s.Init())
 			// Don't look up oid with snmptranslate.
@@ -39,7 +39,7 @@ type Solr struct {
 	Local       bool
 	Servers     []string
 	Username    string
-	Password    string
+	Password    internal.Secret
 	HTTPTimeout internal.Duration
 	Cores       []string
 	client      *http.Client
@@ -483,7 +483,11 @@ func (s *Solr) gatherData(url string, v interface{}) error {
 	}
 
 	if s.Username != "" {
-		req.SetBasicAuth(s.Username, s.Password)
+		password, err := s.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(s.Username, password)
 	}
 
 	req.Header.Set("User-Agent", internal.ProductToken())
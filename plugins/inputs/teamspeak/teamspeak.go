@@ -1,9 +1,12 @@
 package teamspeak
 
 import (
+	"fmt"
+
 	"github.com/multiplay/go-ts3"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"strconv"
 )
@@ -11,7 +14,7 @@ import (
 type Teamspeak struct {
 	Server         string
 	Username       string
-	Password       string
+	Password       internal.Secret
 	VirtualServers []int `toml:"virtual_servers"`
 
 	client    *ts3.Client
@@ -46,7 +49,11 @@ func (ts *Teamspeak) Gather(acc telegraf.Accumulator) error {
 			return err
 		}
 
-		err = ts.client.Login(ts.Username, ts.Password)
+		password, err := ts.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		err = ts.client.Login(ts.Username, password)
 		if err != nil {
 			return err
 		}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -33,7 +34,7 @@ func TestGather(t *testing.T) {
 	testConfig := Teamspeak{
 		Server:         l.Addr().String(),
 		Username:       "serveradmin",
-		Password:       "test",
+		Password:       internal.NewSecret("test"),
 		VirtualServers: []int{1},
 	}
 	err = testConfig.Gather(&acc)
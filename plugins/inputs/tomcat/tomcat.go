@@ -62,7 +62,7 @@ type RequestInfo struct {
 type Tomcat struct {
 	URL      string
 	Username string
-	Password string
+	Password internal.Secret
 	Timeout  internal.Duration
 	tls.ClientConfig
 
@@ -115,7 +115,11 @@ func (s *Tomcat) Gather(acc telegraf.Accumulator) error {
 		if err != nil {
 			return err
 		}
-		request.SetBasicAuth(s.Username, s.Password)
+		password, err := s.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		request.SetBasicAuth(s.Username, password)
 		s.request = request
 	}
 
@@ -208,7 +212,7 @@ func init() {
 		return &Tomcat{
 			URL:      "http://127.0.0.1:8080/manager/status/all?XML=true",
 			Username: "tomcat",
-			Password: "s3cret",
+			Password: internal.NewSecret("s3cret"),
 			Timeout:  internal.Duration{Duration: 5 * time.Second},
 		}
 	})
@@ -8,6 +8,7 @@ import (
 
 	"github.com/influxdata/telegraf/testutil"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/stretchr/testify/require"
 )
 
@@ -47,7 +48,7 @@ func TestHTTPTomcat8(t *testing.T) {
 	tc := Tomcat{
 		URL:      ts.URL,
 		Username: "tomcat",
-		Password: "s3cret",
+		Password: internal.NewSecret("s3cret"),
 	}
 
 	var acc testutil.Accumulator
@@ -119,7 +120,7 @@ func TestHTTPTomcat6(t *testing.T) {
 	tc := Tomcat{
 		URL:      ts.URL,
 		Username: "tomcat",
-		Password: "s3cret",
+		Password: internal.NewSecret("s3cret"),
 	}
 
 	var acc testutil.Accumulator
@@ -78,9 +78,13 @@ func (cf *ClientFactory) GetClient(ctx context.Context) (*Client, error) {
 		defer cancel1()
 		if _, err := methods.GetCurrentTime(ctx1, cf.client.Client); err != nil {
 			cf.parent.Log.Info("Client session seems to have time out. Reauthenticating!")
+			password, err := cf.parent.Password.Get()
+			if err != nil {
+				return nil, fmt.Errorf("getting password: %w", err)
+			}
 			ctx2, cancel2 := context.WithTimeout(ctx, cf.parent.Timeout.Duration)
 			defer cancel2()
-			if err := cf.client.Client.SessionManager.Login(ctx2, url.UserPassword(cf.parent.Username, cf.parent.Password)); err != nil {
+			if err := cf.client.Client.SessionManager.Login(ctx2, url.UserPassword(cf.parent.Username, password)); err != nil {
 				if !retrying {
 					// The client went stale. Probably because someone rebooted vCenter. Clear it to
 					// force us to create a fresh one. We only get one chance at this. If we fail a second time
@@ -111,7 +115,11 @@ func NewClient(ctx context.Context, u *url.URL, vs *VSphere) (*Client, error) {
 		tlsCfg = &tls.Config{}
 	}
 	if vs.Username != "" {
-		u.User = url.UserPassword(vs.Username, vs.Password)
+		password, err := vs.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
+		u.User = url.UserPassword(vs.Username, password)
 	}
 
 	vs.Log.Debugf("Creating client: %s", u.Host)
@@ -17,7 +17,7 @@ import (
 type VSphere struct {
 	Vcenters                []string
 	Username                string
-	Password                string
+	Password                internal.Secret
 	DatacenterInstances     bool
 	DatacenterMetricInclude []string
 	DatacenterMetricExclude []string
@@ -469,7 +469,7 @@ func testCollection(t *testing.T, excludeClusters bool) {
 	if vCenter != "" {
 		v.Vcenters = []string{vCenter}
 		v.Username = username
-		v.Password = password
+		v.Password = internal.NewSecret(password)
 	} else {
 
 		// Don't run test on 32-bit machines due to bug in simulator.
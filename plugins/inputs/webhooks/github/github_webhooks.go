@@ -11,11 +11,12 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 )
 
 type GithubWebhook struct {
 	Path   string
-	Secret string
+	Secret internal.Secret
 	acc    telegraf.Accumulator
 }
 
@@ -34,10 +35,18 @@ func (gh *GithubWebhook) eventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if gh.Secret != "" && !checkSignature(gh.Secret, data, r.Header.Get("X-Hub-Signature")) {
-		log.Printf("E! Fail to check the github webhook signature\n")
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	if !gh.Secret.Empty() {
+		secret, err := gh.Secret.Get()
+		if err != nil {
+			log.Printf("E! Failed to get the github webhook secret: %v\n", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !checkSignature(secret, data, r.Header.Get("X-Hub-Signature")) {
+			log.Printf("E! Fail to check the github webhook signature\n")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
 	}
 
 	e, err := NewEvent(data, eventType)
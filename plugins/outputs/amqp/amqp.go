@@ -34,7 +34,7 @@ func (a *externalAuth) Response() string {
 }
 
 type AMQP struct {
-	URL                string            `toml:"url"` // deprecated in 1.7; use brokers
+	URL                string            `toml:"url" deprecated:"1.7.0;use 'brokers' instead"`
 	Brokers            []string          `toml:"brokers"`
 	Exchange           string            `toml:"exchange"`
 	ExchangeType       string            `toml:"exchange_type"`
@@ -42,15 +42,15 @@ type AMQP struct {
 	ExchangeDurability string            `toml:"exchange_durability"`
 	ExchangeArguments  map[string]string `toml:"exchange_arguments"`
 	Username           string            `toml:"username"`
-	Password           string            `toml:"password"`
+	Password           internal.Secret   `toml:"password"`
 	MaxMessages        int               `toml:"max_messages"`
 	AuthMethod         string            `toml:"auth_method"`
 	RoutingTag         string            `toml:"routing_tag"`
 	RoutingKey         string            `toml:"routing_key"`
 	DeliveryMode       string            `toml:"delivery_mode"`
-	Database           string            `toml:"database"`         // deprecated in 1.7; use headers
-	RetentionPolicy    string            `toml:"retention_policy"` // deprecated in 1.7; use headers
-	Precision          string            `toml:"precision"`        // deprecated; has no effect
+	Database           string            `toml:"database" deprecated:"1.7.0;use 'headers' instead"`
+	RetentionPolicy    string            `toml:"retention_policy" deprecated:"1.7.0;use 'headers' instead"`
+	Precision          string            `toml:"precision"` // deprecated; has no effect
 	Headers            map[string]string `toml:"headers"`
 	Timeout            internal.Duration `toml:"timeout"`
 	UseBatchFormat     bool              `toml:"use_batch_format"`
@@ -373,11 +373,15 @@ func (q *AMQP) makeClientConfig() (*ClientConfig, error) {
 	var auth []amqp.Authentication
 	if strings.ToUpper(q.AuthMethod) == "EXTERNAL" {
 		auth = []amqp.Authentication{&externalAuth{}}
-	} else if q.Username != "" || q.Password != "" {
+	} else if q.Username != "" || !q.Password.Empty() {
+		password, err := q.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
 		auth = []amqp.Authentication{
 			&amqp.PlainAuth{
 				Username: q.Username,
-				Password: q.Password,
+				Password: password,
 			},
 		}
 	}
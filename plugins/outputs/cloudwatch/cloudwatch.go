@@ -12,18 +12,19 @@ import (
 
 	"github.com/influxdata/telegraf"
 	internalaws "github.com/influxdata/telegraf/config/aws"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
 
 type CloudWatch struct {
-	Region      string `toml:"region"`
-	AccessKey   string `toml:"access_key"`
-	SecretKey   string `toml:"secret_key"`
-	RoleARN     string `toml:"role_arn"`
-	Profile     string `toml:"profile"`
-	Filename    string `toml:"shared_credential_file"`
-	Token       string `toml:"token"`
-	EndpointURL string `toml:"endpoint_url"`
+	Region      string          `toml:"region"`
+	AccessKey   string          `toml:"access_key"`
+	SecretKey   internal.Secret `toml:"secret_key"`
+	RoleARN     string          `toml:"role_arn"`
+	Profile     string          `toml:"profile"`
+	Filename    string          `toml:"shared_credential_file"`
+	Token       internal.Secret `toml:"token"`
+	EndpointURL string          `toml:"endpoint_url"`
 
 	Namespace             string `toml:"namespace"` // CloudWatch Metrics Namespace
 	HighResolutionMetrics bool   `toml:"high_resolution_metrics"`
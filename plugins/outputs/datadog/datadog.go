@@ -16,7 +16,7 @@ import (
 )
 
 type Datadog struct {
-	Apikey  string
+	Apikey  internal.Secret
 	Timeout internal.Duration
 
 	URL    string `toml:"url"`
@@ -50,7 +50,7 @@ type Point [2]float64
 const datadog_api = "https://app.datadoghq.com/api/v1/series"
 
 func (d *Datadog) Connect() error {
-	if d.Apikey == "" {
+	if d.Apikey.Empty() {
 		return fmt.Errorf("apikey is a required field for datadog output")
 	}
 
@@ -111,15 +111,19 @@ func (d *Datadog) Write(metrics []telegraf.Metric) error {
 	if err != nil {
 		return fmt.Errorf("unable to marshal TimeSeries, %s\n", err.Error())
 	}
-	req, err := http.NewRequest("POST", d.authenticatedUrl(), bytes.NewBuffer(tsBytes))
+	apikey, err := d.Apikey.Get()
 	if err != nil {
-		return fmt.Errorf("unable to create http.Request, %s\n", strings.Replace(err.Error(), d.Apikey, redactedApiKey, -1))
+		return fmt.Errorf("getting apikey: %w", err)
+	}
+	req, err := http.NewRequest("POST", d.authenticatedUrl(apikey), bytes.NewBuffer(tsBytes))
+	if err != nil {
+		return fmt.Errorf("unable to create http.Request, %s\n", strings.Replace(err.Error(), apikey, redactedApiKey, -1))
 	}
 	req.Header.Add("Content-Type", "application/json")
 
 	resp, err := d.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("error POSTing metrics, %s\n", strings.Replace(err.Error(), d.Apikey, redactedApiKey, -1))
+		return fmt.Errorf("error POSTing metrics, %s\n", strings.Replace(err.Error(), apikey, redactedApiKey, -1))
 	}
 	defer resp.Body.Close()
 
@@ -138,9 +142,9 @@ func (d *Datadog) Description() string {
 	return "Configuration for DataDog API to send metrics to."
 }
 
-func (d *Datadog) authenticatedUrl() string {
+func (d *Datadog) authenticatedUrl(apikey string) string {
 	q := url.Values{
-		"api_key": []string{d.Apikey},
+		"api_key": []string{apikey},
 	}
 	return fmt.Sprintf("%s?%s", d.URL, q.Encode())
 }
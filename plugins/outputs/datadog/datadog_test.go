@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,7 +30,7 @@ func NewDatadog(url string) *Datadog {
 
 func fakeDatadog() *Datadog {
 	d := NewDatadog(fakeUrl)
-	d.Apikey = fakeApiKey
+	d.Apikey = internal.NewSecret(fakeApiKey)
 	return d
 }
 
@@ -41,7 +42,7 @@ func TestUriOverride(t *testing.T) {
 	defer ts.Close()
 
 	d := NewDatadog(ts.URL)
-	d.Apikey = "123456"
+	d.Apikey = internal.NewSecret("123456")
 	err := d.Connect()
 	require.NoError(t, err)
 	err = d.Write(testutil.MockMetrics())
@@ -60,7 +61,7 @@ func TestBadStatusCode(t *testing.T) {
 	defer ts.Close()
 
 	d := NewDatadog(ts.URL)
-	d.Apikey = "123456"
+	d.Apikey = internal.NewSecret("123456")
 	err := d.Connect()
 	require.NoError(t, err)
 	err = d.Write(testutil.MockMetrics())
@@ -74,7 +75,7 @@ func TestBadStatusCode(t *testing.T) {
 func TestAuthenticatedUrl(t *testing.T) {
 	d := fakeDatadog()
 
-	authUrl := d.authenticatedUrl()
+	authUrl := d.authenticatedUrl(fakeApiKey)
 	assert.EqualValues(t, fmt.Sprintf("%s?api_key=%s", fakeUrl, fakeApiKey), authUrl)
 }
 
@@ -252,7 +253,7 @@ func TestVerifyValue(t *testing.T) {
 
 func TestNaNIsSkipped(t *testing.T) {
 	plugin := &Datadog{
-		Apikey: "testing",
+		Apikey: internal.NewSecret("testing"),
 		URL:    "", // No request will be sent because all fields are skipped
 	}
 
@@ -273,7 +274,7 @@ func TestNaNIsSkipped(t *testing.T) {
 
 func TestInfIsSkipped(t *testing.T) {
 	plugin := &Datadog{
-		Apikey: "testing",
+		Apikey: internal.NewSecret("testing"),
 		URL:    "", // No request will be sent because all fields are skipped
 	}
 
@@ -31,7 +31,7 @@ var (
 // Dynatrace Configuration for the Dynatrace output plugin
 type Dynatrace struct {
 	URL         string            `toml:"url"`
-	APIToken    string            `toml:"api_token"`
+	APIToken    internal.Secret   `toml:"api_token"`
 	Prefix      string            `toml:"prefix"`
 	Log         telegraf.Logger   `toml:"-"`
 	Timeout     internal.Duration `toml:"timeout"`
@@ -240,8 +240,12 @@ func (d *Dynatrace) send(msg []byte) error {
 	}
 	req.Header.Add("Content-Type", "text/plain; charset=UTF-8")
 
-	if len(d.APIToken) != 0 {
-		req.Header.Add("Authorization", "Api-Token "+d.APIToken)
+	if !d.APIToken.Empty() {
+		token, err := d.APIToken.Get()
+		if err != nil {
+			return fmt.Errorf("getting api_token: %w", err)
+		}
+		req.Header.Add("Authorization", "Api-Token "+token)
 	}
 	// add user-agent header to identify metric source
 	req.Header.Add("User-Agent", "telegraf")
@@ -275,7 +279,7 @@ func (d *Dynatrace) Init() error {
 		d.Log.Infof("Dynatrace URL is empty, defaulting to OneAgent metrics interface")
 		d.URL = oneAgentMetricsUrl
 	}
-	if d.URL != oneAgentMetricsUrl && len(d.APIToken) == 0 {
+	if d.URL != oneAgentMetricsUrl && d.APIToken.Empty() {
 		d.Log.Errorf("Dynatrace api_token is a required field for Dynatrace output")
 		return fmt.Errorf("api_token is a required field for Dynatrace output")
 	}
@@ -26,7 +26,7 @@ type Elasticsearch struct {
 	DefaultTagValue     string
 	TagKeys             []string
 	Username            string
-	Password            string
+	Password            internal.Secret
 	EnableSniffer       bool
 	Timeout             internal.Duration
 	HealthCheckInterval internal.Duration
@@ -199,9 +199,13 @@ func (a *Elasticsearch) Connect() error {
 		elastic.SetHealthcheckInterval(a.HealthCheckInterval.Duration),
 	)
 
-	if a.Username != "" && a.Password != "" {
+	if a.Username != "" && !a.Password.Empty() {
+		password, err := a.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
 		clientOptions = append(clientOptions,
-			elastic.SetBasicAuth(a.Username, a.Password),
+			elastic.SetBasicAuth(a.Username, password),
 		)
 	}
 
@@ -72,7 +72,7 @@ type Health struct {
 	ReadTimeout    internal.Duration `toml:"read_timeout"`
 	WriteTimeout   internal.Duration `toml:"write_timeout"`
 	BasicUsername  string            `toml:"basic_username"`
-	BasicPassword  string            `toml:"basic_password"`
+	BasicPassword  internal.Secret   `toml:"basic_password"`
 	tlsint.ServerConfig
 
 	Compares []*Compares `toml:"compares"`
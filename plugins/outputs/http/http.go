@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -12,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/batching"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -33,7 +33,8 @@ var sampleConfig = `
   ## HTTP method, one of: "POST" or "PUT"
   # method = "POST"
 
-  ## HTTP Basic Auth credentials
+  ## HTTP Basic Auth credentials, the password may reference a secret store
+  ## as "@{<store id>:<key>}"
   # username = "username"
   # password = "pa$$word"
 
@@ -56,10 +57,24 @@ var sampleConfig = `
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
   # data_format = "influx"
 
+  ## Max HTTP request body size in bytes before compression (if applied),
+  ## larger writes are split into several requests.  Unlimited by default.
+  # max_request_body_size = 0
+
+  ## Maximum number of metrics in a request, unlimited by default.
+  # max_metrics_per_request = 0
+
+  ## Maximum number of requests sent at the same time.
+  # max_concurrent_requests = 1
+
   ## HTTP Content-Encoding for write request body, can be set to "gzip" to
   ## compress body or "identity" to apply no encoding.
   # content_encoding = "identity"
 
+  ## HTTP status codes of responses refusing the data itself, the batch is
+  ## then dropped instead of being retried.
+  # non_retryable_status_codes = [400, 413, 422]
+
   ## Additional HTTP headers
   # [outputs.http.headers]
   #   # Should be set manually to "application/json" for json data_format
@@ -77,17 +92,22 @@ type HTTP struct {
 	Timeout         internal.Duration `toml:"timeout"`
 	Method          string            `toml:"method"`
 	Username        string            `toml:"username"`
-	Password        string            `toml:"password"`
+	Password        internal.Secret   `toml:"password"`
 	Headers         map[string]string `toml:"headers"`
 	ClientID        string            `toml:"client_id"`
-	ClientSecret    string            `toml:"client_secret"`
+	ClientSecret    internal.Secret   `toml:"client_secret"`
 	TokenURL        string            `toml:"token_url"`
 	Scopes          []string          `toml:"scopes"`
 	ContentEncoding string            `toml:"content_encoding"`
+
+	NonRetryableStatusCodes []int `toml:"non_retryable_status_codes"`
+
 	tls.ClientConfig
+	batching.Config
 
 	client     *http.Client
 	serializer serializers.Serializer
+	batcher    *batching.Batcher
 }
 
 func (h *HTTP) SetSerializer(serializer serializers.Serializer) {
@@ -108,10 +128,14 @@ func (h *HTTP) createClient(ctx context.Context) (*http.Client, error) {
 		Timeout: h.Timeout.Duration,
 	}
 
-	if h.ClientID != "" && h.ClientSecret != "" && h.TokenURL != "" {
+	if h.ClientID != "" && !h.ClientSecret.Empty() && h.TokenURL != "" {
+		clientSecret, err := h.ClientSecret.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting client_secret: %w", err)
+		}
 		oauthConfig := clientcredentials.Config{
 			ClientID:     h.ClientID,
-			ClientSecret: h.ClientSecret,
+			ClientSecret: clientSecret,
 			TokenURL:     h.TokenURL,
 			Scopes:       h.Scopes,
 		}
@@ -143,6 +167,12 @@ func (h *HTTP) Connect() error {
 
 	h.client = client
 
+	batcher, err := h.NewBatcher("http", h.serializer, h.ContentEncoding)
+	if err != nil {
+		return err
+	}
+	h.batcher = batcher
+
 	return nil
 }
 
@@ -159,38 +189,21 @@ func (h *HTTP) SampleConfig() string {
 }
 
 func (h *HTTP) Write(metrics []telegraf.Metric) error {
-	reqBody, err := h.serializer.SerializeBatch(metrics)
-	if err != nil {
-		return err
-	}
-
-	if err := h.write(reqBody); err != nil {
-		return err
-	}
-
-	return nil
+	return h.batcher.Write(metrics, h.write)
 }
 
 func (h *HTTP) write(reqBody []byte) error {
-	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
-
-	var err error
-	if h.ContentEncoding == "gzip" {
-		rc, err := internal.CompressWithGzip(reqBodyBuffer)
-		if err != nil {
-			return err
-		}
-		defer rc.Close()
-		reqBodyBuffer = rc
-	}
-
-	req, err := http.NewRequest(h.Method, h.URL, reqBodyBuffer)
+	req, err := http.NewRequest(h.Method, h.URL, bytes.NewReader(reqBody))
 	if err != nil {
 		return err
 	}
 
-	if h.Username != "" || h.Password != "" {
-		req.SetBasicAuth(h.Username, h.Password)
+	if h.Username != "" || !h.Password.Empty() {
+		password, err := h.Password.Get()
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(h.Username, password)
 	}
 
 	req.Header.Set("User-Agent", internal.ProductToken())
@@ -213,7 +226,8 @@ func (h *HTTP) write(reqBody []byte) error {
 	_, err = ioutil.ReadAll(resp.Body)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("when writing to [%s] received status code: %d", h.URL, resp.StatusCode)
+		err := fmt.Errorf("when writing to [%s] received status code: %d", h.URL, resp.StatusCode)
+		return internal.HTTPStatusError(err, resp.StatusCode, h.NonRetryableStatusCodes)
 	}
 
 	return nil
@@ -222,9 +236,10 @@ func (h *HTTP) write(reqBody []byte) error {
 func init() {
 	outputs.Add("http", func() telegraf.Output {
 		return &HTTP{
-			Timeout: internal.Duration{Duration: defaultClientTimeout},
-			Method:  defaultMethod,
-			URL:     defaultURL,
+			Timeout:                 internal.Duration{Duration: defaultClientTimeout},
+			Method:                  defaultMethod,
+			URL:                     defaultURL,
+			NonRetryableStatusCodes: append([]int(nil), internal.DefaultNonRetryableStatusCodes...),
 		}
 	})
 }
@@ -164,6 +164,64 @@ func TestStatusCode(t *testing.T) {
 				require.Error(t, err)
 			},
 		},
+		{
+			name: "400 status is not retryable",
+			plugin: &HTTP{
+				URL:                     u.String(),
+				NonRetryableStatusCodes: internal.DefaultNonRetryableStatusCodes,
+			},
+			statusCode: http.StatusBadRequest,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.True(t, internal.IsNonRetryable(err))
+			},
+		},
+		{
+			name: "401 status is retryable",
+			plugin: &HTTP{
+				URL:                     u.String(),
+				NonRetryableStatusCodes: internal.DefaultNonRetryableStatusCodes,
+			},
+			statusCode: http.StatusUnauthorized,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, internal.IsNonRetryable(err))
+			},
+		},
+		{
+			name: "configured status is not retryable",
+			plugin: &HTTP{
+				URL:                     u.String(),
+				NonRetryableStatusCodes: []int{http.StatusNotFound},
+			},
+			statusCode: http.StatusNotFound,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.True(t, internal.IsNonRetryable(err))
+			},
+		},
+		{
+			name: "429 status is retryable",
+			plugin: &HTTP{
+				URL: u.String(),
+			},
+			statusCode: http.StatusTooManyRequests,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, internal.IsNonRetryable(err))
+			},
+		},
+		{
+			name: "5xx status is retryable",
+			plugin: &HTTP{
+				URL: u.String(),
+			},
+			statusCode: http.StatusServiceUnavailable,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, internal.IsNonRetryable(err))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -318,7 +376,7 @@ func TestBasicAuth(t *testing.T) {
 			name: "password only",
 			plugin: &HTTP{
 				URL:      u.String(),
-				Password: "pa$$word",
+				Password: internal.NewSecret("pa$$word"),
 			},
 		},
 		{
@@ -326,7 +384,7 @@ func TestBasicAuth(t *testing.T) {
 			plugin: &HTTP{
 				URL:      u.String(),
 				Username: "username",
-				Password: "pa$$word",
+				Password: internal.NewSecret("pa$$word"),
 			},
 		},
 	}
@@ -336,7 +394,9 @@ func TestBasicAuth(t *testing.T) {
 			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				username, password, _ := r.BasicAuth()
 				require.Equal(t, tt.plugin.Username, username)
-				require.Equal(t, tt.plugin.Password, password)
+				expected, err := tt.plugin.Password.Get()
+				require.NoError(t, err)
+				require.Equal(t, expected, password)
 				w.WriteHeader(http.StatusOK)
 			})
 
@@ -383,7 +443,7 @@ func TestOAuthClientCredentialsGrant(t *testing.T) {
 			plugin: &HTTP{
 				URL:          u.String() + "/write",
 				ClientID:     "howdy",
-				ClientSecret: "secret",
+				ClientSecret: internal.NewSecret("secret"),
 				TokenURL:     u.String() + "/token",
 				Scopes:       []string{"urn:opc:idm:__myscopes__"},
 			},
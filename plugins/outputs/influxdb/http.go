@@ -87,7 +87,7 @@ type HTTPConfig struct {
 	UserAgent                 string
 	Timeout                   time.Duration
 	Username                  string
-	Password                  string
+	Password                  internal.Secret
 	TLSConfig                 *tls.Config
 	Proxy                     *url.URL
 	Headers                   map[string]string
@@ -416,7 +416,9 @@ func (c *httpClient) makeQueryRequest(query string) (*http.Request, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	c.addHeaders(req)
+	if err := c.addHeaders(req); err != nil {
+		return nil, err
+	}
 
 	return req, nil
 }
@@ -430,7 +432,9 @@ func (c *httpClient) makeWriteRequest(url string, body io.Reader) (*http.Request
 	}
 
 	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
-	c.addHeaders(req)
+	if err := c.addHeaders(req); err != nil {
+		return nil, err
+	}
 
 	if c.config.ContentEncoding == "gzip" {
 		req.Header.Set("Content-Encoding", "gzip")
@@ -456,14 +460,19 @@ func (c *httpClient) requestBodyReader(metrics []telegraf.Metric) (io.ReadCloser
 	return ioutil.NopCloser(reader), nil
 }
 
-func (c *httpClient) addHeaders(req *http.Request) {
-	if c.config.Username != "" || c.config.Password != "" {
-		req.SetBasicAuth(c.config.Username, c.config.Password)
+func (c *httpClient) addHeaders(req *http.Request) error {
+	if c.config.Username != "" || !c.config.Password.Empty() {
+		password, err := c.config.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		req.SetBasicAuth(c.config.Username, password)
 	}
 
 	for header, value := range c.config.Headers {
 		req.Header.Set(header, value)
 	}
+	return nil
 }
 
 func makeWriteURL(loc *url.URL, db, rp, consistency string) (string, error) {
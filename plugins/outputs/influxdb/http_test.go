@@ -92,7 +92,7 @@ func TestHTTP_CreateDatabase(t *testing.T) {
 			config: influxdb.HTTPConfig{
 				URL:      u,
 				Username: "guy",
-				Password: "smiley",
+				Password: internal.NewSecret("smiley"),
 				Database: "telegraf",
 			},
 			database: "telegraf",
@@ -276,7 +276,7 @@ func TestHTTP_Write(t *testing.T) {
 				URL:      u,
 				Database: "telegraf",
 				Username: "guy",
-				Password: "smiley",
+				Password: internal.NewSecret("smiley"),
 				Log:      testutil.Logger{},
 			},
 			queryHandlerFunc: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
@@ -34,7 +34,7 @@ type InfluxDB struct {
 	URL                       string            // url deprecated in 0.1.9; use urls
 	URLs                      []string          `toml:"urls"`
 	Username                  string            `toml:"username"`
-	Password                  string            `toml:"password"`
+	Password                  internal.Secret   `toml:"password"`
 	Database                  string            `toml:"database"`
 	DatabaseTag               string            `toml:"database_tag"`
 	ExcludeDatabaseTag        bool              `toml:"exclude_database_tag"`
@@ -122,7 +122,7 @@ func TestConnectHTTPConfig(t *testing.T) {
 		WriteConsistency: "any",
 		Timeout:          internal.Duration{Duration: 5 * time.Second},
 		Username:         "guy",
-		Password:         "smiley",
+		Password:         internal.NewSecret("smiley"),
 		UserAgent:        "telegraf",
 		HTTPProxy:        "http://localhost:8086",
 		HTTPHeaders: map[string]string{
@@ -42,7 +42,7 @@ const (
 
 type HTTPConfig struct {
 	URL              *url.URL
-	Token            string
+	Token            internal.Secret
 	Organization     string
 	Bucket           string
 	BucketTag        string
@@ -60,6 +60,7 @@ type HTTPConfig struct {
 type httpClient struct {
 	ContentEncoding  string
 	Timeout          time.Duration
+	Token            internal.Secret
 	Headers          map[string]string
 	Organization     string
 	Bucket           string
@@ -87,9 +88,8 @@ func NewHTTPClient(config *HTTPConfig) (*httpClient, error) {
 		userAgent = internal.ProductToken()
 	}
 
-	var headers = make(map[string]string, len(config.Headers)+2)
+	var headers = make(map[string]string, len(config.Headers)+1)
 	headers["User-Agent"] = userAgent
-	headers["Authorization"] = "Token " + config.Token
 	for k, v := range config.Headers {
 		headers[k] = v
 	}
@@ -136,6 +136,7 @@ func NewHTTPClient(config *HTTPConfig) (*httpClient, error) {
 		url:              config.URL,
 		ContentEncoding:  config.ContentEncoding,
 		Timeout:          timeout,
+		Token:            config.Token,
 		Headers:          headers,
 		Organization:     config.Organization,
 		Bucket:           config.Bucket,
@@ -296,7 +297,9 @@ func (c *httpClient) makeWriteRequest(url string, body io.Reader) (*http.Request
 	}
 
 	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
-	c.addHeaders(req)
+	if err := c.addHeaders(req); err != nil {
+		return nil, err
+	}
 
 	if c.ContentEncoding == "gzip" {
 		req.Header.Set("Content-Encoding", "gzip")
@@ -322,10 +325,17 @@ func (c *httpClient) requestBodyReader(metrics []telegraf.Metric) (io.ReadCloser
 	return ioutil.NopCloser(reader), nil
 }
 
-func (c *httpClient) addHeaders(req *http.Request) {
+func (c *httpClient) addHeaders(req *http.Request) error {
+	token, err := c.Token.Get()
+	if err != nil {
+		return fmt.Errorf("getting token: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+token)
+
 	for header, value := range c.Headers {
 		req.Header.Set(header, value)
 	}
+	return nil
 }
 
 func makeWriteURL(loc url.URL, org, bucket string) (string, error) {
@@ -23,7 +23,7 @@ var (
 
 type Instrumental struct {
 	Host       string
-	ApiToken   string
+	ApiToken   internal.Secret
 	Prefix     string
 	DataFormat string
 	Template   string
@@ -175,7 +175,11 @@ func (i *Instrumental) SampleConfig() string {
 }
 
 func (i *Instrumental) authenticate(conn net.Conn) error {
-	_, err := fmt.Fprintf(conn, HandshakeFormat, i.ApiToken)
+	token, err := i.ApiToken.Get()
+	if err != nil {
+		return fmt.Errorf("getting api_token: %w", err)
+	}
+	_, err = fmt.Fprintf(conn, HandshakeFormat, token)
 	if err != nil {
 		return err
 	}
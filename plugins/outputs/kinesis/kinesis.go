@@ -9,20 +9,21 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/influxdata/telegraf"
 	internalaws "github.com/influxdata/telegraf/config/aws"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
 type (
 	KinesisOutput struct {
-		Region      string `toml:"region"`
-		AccessKey   string `toml:"access_key"`
-		SecretKey   string `toml:"secret_key"`
-		RoleARN     string `toml:"role_arn"`
-		Profile     string `toml:"profile"`
-		Filename    string `toml:"shared_credential_file"`
-		Token       string `toml:"token"`
-		EndpointURL string `toml:"endpoint_url"`
+		Region      string          `toml:"region"`
+		AccessKey   string          `toml:"access_key"`
+		SecretKey   internal.Secret `toml:"secret_key"`
+		RoleARN     string          `toml:"role_arn"`
+		Profile     string          `toml:"profile"`
+		Filename    string          `toml:"shared_credential_file"`
+		Token       internal.Secret `toml:"token"`
+		EndpointURL string          `toml:"endpoint_url"`
 
 		StreamName         string     `toml:"streamname"`
 		PartitionKey       string     `toml:"partitionkey"`
@@ -17,8 +17,8 @@ import (
 
 // Librato structure for configuration and client
 type Librato struct {
-	APIUser   string `toml:"api_user"`
-	APIToken  string `toml:"api_token"`
+	APIUser   string          `toml:"api_user"`
+	APIToken  internal.Secret `toml:"api_token"`
 	Debug     bool
 	SourceTag string // Deprecated, keeping for backward-compatibility
 	Timeout   internal.Duration
@@ -75,7 +75,7 @@ func NewLibrato(apiURL string) *Librato {
 // Connect is the default output plugin connection function who make sure it
 // can connect to the endpoint
 func (l *Librato) Connect() error {
-	if l.APIUser == "" || l.APIToken == "" {
+	if l.APIUser == "" || l.APIToken.Empty() {
 		return fmt.Errorf(
 			"api_user and api_token are required fields for librato output")
 	}
@@ -115,6 +115,11 @@ func (l *Librato) Write(metrics []telegraf.Metric) error {
 		}
 	}
 
+	token, err := l.APIToken.Get()
+	if err != nil {
+		return fmt.Errorf("getting api_token: %w", err)
+	}
+
 	metricCounter := len(tempGauges)
 	// make sur we send a batch of maximum 300
 	sizeBatch := 300
@@ -144,7 +149,7 @@ func (l *Librato) Write(metrics []telegraf.Metric) error {
 				err.Error())
 		}
 		req.Header.Add("Content-Type", "application/json")
-		req.SetBasicAuth(l.APIUser, l.APIToken)
+		req.SetBasicAuth(l.APIUser, token)
 
 		resp, err := l.client.Do(req)
 		if err != nil {
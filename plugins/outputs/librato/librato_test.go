@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/stretchr/testify/require"
 )
@@ -22,7 +23,7 @@ var (
 func fakeLibrato() *Librato {
 	l := NewLibrato(fakeURL)
 	l.APIUser = fakeUser
-	l.APIToken = fakeToken
+	l.APIToken = internal.NewSecret(fakeToken)
 	return l
 }
 
@@ -36,7 +37,7 @@ func TestUriOverride(t *testing.T) {
 
 	l := NewLibrato(ts.URL)
 	l.APIUser = "telegraf@influxdb.com"
-	l.APIToken = "123456"
+	l.APIToken = internal.NewSecret("123456")
 	err := l.Connect()
 	require.NoError(t, err)
 	err = l.Write([]telegraf.Metric{newHostMetric(int32(0), "name", "host")})
@@ -52,7 +53,7 @@ func TestBadStatusCode(t *testing.T) {
 
 	l := NewLibrato(ts.URL)
 	l.APIUser = "telegraf@influxdb.com"
-	l.APIToken = "123456"
+	l.APIToken = internal.NewSecret("123456")
 	err := l.Connect()
 	require.NoError(t, err)
 	err = l.Write([]telegraf.Metric{newHostMetric(int32(0), "name", "host")})
@@ -41,7 +41,7 @@ var sampleConfig = `
 type Logzio struct {
 	Log     telegraf.Logger   `toml:"-"`
 	Timeout internal.Duration `toml:"timeout"`
-	Token   string            `toml:"token"`
+	Token   internal.Secret   `toml:"token"`
 	URL     string            `toml:"url"`
 
 	tls.ClientConfig
@@ -63,7 +63,10 @@ type Metric struct {
 func (l *Logzio) Connect() error {
 	l.Log.Debug("Connecting to logz.io output...")
 
-	if l.Token == "" || l.Token == "your logz.io token" {
+	if l.Token.Empty() {
+		return fmt.Errorf("token is required")
+	}
+	if token, err := l.Token.Get(); err == nil && token == "your logz.io token" {
 		return fmt.Errorf("token is required")
 	}
 
@@ -130,7 +133,11 @@ func (l *Logzio) Write(metrics []telegraf.Metric) error {
 }
 
 func (l *Logzio) send(metrics []byte) error {
-	req, err := http.NewRequest("POST", l.authUrl(), bytes.NewBuffer(metrics))
+	url, err := l.authUrl()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(metrics))
 	if err != nil {
 		return fmt.Errorf("unable to create http.Request, %s\n", err.Error())
 	}
@@ -150,8 +157,12 @@ func (l *Logzio) send(metrics []byte) error {
 	return nil
 }
 
-func (l *Logzio) authUrl() string {
-	return fmt.Sprintf("%s/?token=%s", l.URL, l.Token)
+func (l *Logzio) authUrl() (string, error) {
+	token, err := l.Token.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting token: %w", err)
+	}
+	return fmt.Sprintf("%s/?token=%s", l.URL, token), nil
 }
 
 func (l *Logzio) parseMetric(metric telegraf.Metric) *Metric {
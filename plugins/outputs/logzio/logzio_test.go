@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 	"io"
@@ -45,7 +46,7 @@ func TestBadStatusCode(t *testing.T) {
 	defer ts.Close()
 
 	l := &Logzio{
-		Token: testToken,
+		Token: internal.NewSecret(testToken),
 		URL:   ts.URL,
 		Log:   testutil.Logger{},
 	}
@@ -81,7 +82,7 @@ func TestWrite(t *testing.T) {
 	defer ts.Close()
 
 	l := &Logzio{
-		Token: testToken,
+		Token: internal.NewSecret(testToken),
 		URL:   ts.URL,
 		Log:   testutil.Logger{},
 	}
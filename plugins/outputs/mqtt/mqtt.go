@@ -64,7 +64,7 @@ var sampleConfig = `
 type MQTT struct {
 	Servers     []string `toml:"servers"`
 	Username    string
-	Password    string
+	Password    internal.Secret
 	Database    string
 	Timeout     internal.Duration
 	TopicPrefix string
@@ -217,8 +217,11 @@ func (m *MQTT) createOpts() (*paho.ClientOptions, error) {
 	if user != "" {
 		opts.SetUsername(user)
 	}
-	password := m.Password
-	if password != "" {
+	if !m.Password.Empty() {
+		password, err := m.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
 		opts.SetPassword(password)
 	}
 
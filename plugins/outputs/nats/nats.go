@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -13,12 +14,12 @@ import (
 )
 
 type NATS struct {
-	Servers     []string `toml:"servers"`
-	Secure      bool     `toml:"secure"`
-	Username    string   `toml:"username"`
-	Password    string   `toml:"password"`
-	Credentials string   `toml:"credentials"`
-	Subject     string   `toml:"subject"`
+	Servers     []string        `toml:"servers"`
+	Secure      bool            `toml:"secure"`
+	Username    string          `toml:"username"`
+	Password    internal.Secret `toml:"password"`
+	Credentials string          `toml:"credentials"`
+	Subject     string          `toml:"subject"`
 
 	tls.ClientConfig
 
@@ -70,7 +71,11 @@ func (n *NATS) Connect() error {
 
 	// override authentication, if any was specified
 	if n.Username != "" {
-		opts = append(opts, nats.UserInfo(n.Username, n.Password))
+		password, err := n.Password.Get()
+		if err != nil {
+			return fmt.Errorf("getting password: %w", err)
+		}
+		opts = append(opts, nats.UserInfo(n.Username, password))
 	}
 
 	if n.Secure {
@@ -16,7 +16,7 @@ import (
 
 // NewRelic nr structure
 type NewRelic struct {
-	InsightsKey  string            `toml:"insights_key"`
+	InsightsKey  internal.Secret   `toml:"insights_key"`
 	MetricPrefix string            `toml:"metric_prefix"`
 	Timeout      internal.Duration `toml:"timeout"`
 
@@ -48,11 +48,14 @@ func (nr *NewRelic) SampleConfig() string {
 
 // Connect to the Output
 func (nr *NewRelic) Connect() error {
-	if nr.InsightsKey == "" {
+	if nr.InsightsKey.Empty() {
 		return fmt.Errorf("InsightKey is a required for newrelic")
 	}
-	var err error
-	nr.harvestor, err = telemetry.NewHarvester(telemetry.ConfigAPIKey(nr.InsightsKey),
+	insightsKey, err := nr.InsightsKey.Get()
+	if err != nil {
+		return fmt.Errorf("getting insights_key: %w", err)
+	}
+	nr.harvestor, err = telemetry.NewHarvester(telemetry.ConfigAPIKey(insightsKey),
 		telemetry.ConfigHarvestPeriod(0),
 		func(cfg *telemetry.Config) {
 			cfg.Product = "NewRelic-Telegraf-Plugin"
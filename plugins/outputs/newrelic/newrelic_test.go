@@ -16,7 +16,7 @@ import (
 func TestBasic(t *testing.T) {
 	nr := &NewRelic{
 		MetricPrefix: "Test",
-		InsightsKey:  "12345",
+		InsightsKey:  internal.NewSecret("12345"),
 		Timeout:      internal.Duration{Duration: time.Second * 5},
 	}
 	if testing.Short() {
@@ -148,7 +148,7 @@ func TestNewRelic_Connect(t *testing.T) {
 		{
 			name: "Test: Insights key",
 			newrelic: &NewRelic{
-				InsightsKey:  "12312133",
+				InsightsKey:  internal.NewSecret("12312133"),
 				MetricPrefix: "prefix",
 			},
 			wantErr: false,
@@ -156,14 +156,14 @@ func TestNewRelic_Connect(t *testing.T) {
 		{
 			name: "Test: Only Insights key",
 			newrelic: &NewRelic{
-				InsightsKey: "12312133",
+				InsightsKey: internal.NewSecret("12312133"),
 			},
 			wantErr: false,
 		},
 		{
 			name: "Test: Insights key and Timeout",
 			newrelic: &NewRelic{
-				InsightsKey: "12312133",
+				InsightsKey: internal.NewSecret("12312133"),
 				Timeout:     internal.Duration{Duration: time.Second * 5},
 			},
 			wantErr: false,
@@ -82,7 +82,7 @@ type PrometheusClient struct {
 	Listen             string            `toml:"listen"`
 	MetricVersion      int               `toml:"metric_version"`
 	BasicUsername      string            `toml:"basic_username"`
-	BasicPassword      string            `toml:"basic_password"`
+	BasicPassword      internal.Secret   `toml:"basic_password"`
 	IPRange            []string          `toml:"ip_range"`
 	ExpirationInterval internal.Duration `toml:"expiration_interval"`
 	Path               string            `toml:"path"`
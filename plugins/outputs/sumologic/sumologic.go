@@ -2,9 +2,7 @@ package sumologic
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
-	"log"
 	"net/http"
 	"time"
 
@@ -13,6 +11,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/batching"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
@@ -52,6 +51,20 @@ const (
   ## as expected.
   # max_request_body_size = 1000000
 
+  ## Maximum number of metrics in a request, unlimited by default.
+  # max_metrics_per_request = 0
+
+  ## Maximum number of requests sent at the same time.
+  # max_concurrent_requests = 1
+
+  ## HTTP Content-Encoding for write request body, can be set to "gzip" to
+  ## compress body or "identity" to apply no encoding.
+  # content_encoding = "gzip"
+
+  ## HTTP status codes of responses refusing the data itself, the batch is
+  ## then dropped instead of being retried.
+  # non_retryable_status_codes = [400, 413, 422]
+
   ## Additional, Sumo specific options.
   ## Full list can be found here:
   ## https://help.sumologic.com/03Send-Data/Sources/02Sources-for-Hosted-Collectors/HTTP-Source/Upload-Metrics-to-an-HTTP-Source#supported-http-headers
@@ -93,9 +106,12 @@ const (
 )
 
 type SumoLogic struct {
-	URL               string            `toml:"url"`
-	Timeout           internal.Duration `toml:"timeout"`
-	MaxRequstBodySize config.Size       `toml:"max_request_body_size"`
+	URL             string            `toml:"url"`
+	Timeout         internal.Duration `toml:"timeout"`
+	ContentEncoding string            `toml:"content_encoding"`
+	batching.Config
+
+	NonRetryableStatusCodes []int `toml:"non_retryable_status_codes"`
 
 	SourceName     string `toml:"source_name"`
 	SourceHost     string `toml:"source_host"`
@@ -106,6 +122,7 @@ type SumoLogic struct {
 
 	client     *http.Client
 	serializer serializers.Serializer
+	batcher    *batching.Batcher
 
 	err     error
 	headers map[string]string
@@ -164,6 +181,12 @@ func (s *SumoLogic) Connect() error {
 
 	s.client = client
 
+	batcher, err := s.NewBatcher("sumologic", s.serializer, s.ContentEncoding)
+	if err != nil {
+		return errors.Wrap(err, "sumologic: incorrect configuration")
+	}
+	s.batcher = batcher
+
 	return nil
 }
 
@@ -190,53 +213,18 @@ func (s *SumoLogic) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	reqBody, err := s.serializer.SerializeBatch(metrics)
-	if err != nil {
-		return err
-	}
-
-	if l := len(reqBody); l > int(s.MaxRequstBodySize) {
-		chunks, err := s.splitIntoChunks(metrics)
-		if err != nil {
-			return err
-		}
-
-		return s.writeRequestChunks(chunks)
-	}
-
-	return s.write(reqBody)
-}
-
-func (s *SumoLogic) writeRequestChunks(chunks [][]byte) error {
-	for _, reqChunk := range chunks {
-		if err := s.write(reqChunk); err != nil {
-			s.Log.Errorf("Error sending chunk: %v", err)
-		}
-	}
-	return nil
+	return s.batcher.Write(metrics, s.write)
 }
 
 func (s *SumoLogic) write(reqBody []byte) error {
-	var (
-		err  error
-		buff bytes.Buffer
-		gz   = gzip.NewWriter(&buff)
-	)
-
-	if _, err = gz.Write(reqBody); err != nil {
-		return err
-	}
-
-	if err = gz.Close(); err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest(defaultMethod, s.URL, &buff)
+	req, err := http.NewRequest(defaultMethod, s.URL, bytes.NewReader(reqBody))
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-Encoding", "gzip")
+	if s.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	req.Header.Set("User-Agent", internal.ProductToken())
 
 	// Set headers coming from the configuration.
@@ -256,77 +244,16 @@ func (s *SumoLogic) write(reqBody []byte) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return errors.Errorf(
+		err := errors.Errorf(
 			"sumologic: when writing to [%s] received status code: %d",
 			s.URL, resp.StatusCode,
 		)
+		return internal.HTTPStatusError(err, resp.StatusCode, s.NonRetryableStatusCodes)
 	}
 
 	return nil
 }
 
-// splitIntoChunks splits metrics to be sent into chunks so that every request
-// is smaller than s.MaxRequstBodySize unless it was configured so small so that
-// even a single metric cannot fit.
-// In such a situation metrics will be sent one by one with a warning being logged
-// for every request sent even though they don't fit in s.MaxRequstBodySize bytes.
-func (s *SumoLogic) splitIntoChunks(metrics []telegraf.Metric) ([][]byte, error) {
-	var (
-		numMetrics = len(metrics)
-		chunks     = make([][]byte, 0)
-	)
-
-	for i := 0; i < numMetrics; {
-		var toAppend []byte
-		for i < numMetrics {
-			chunkBody, err := s.serializer.Serialize(metrics[i])
-			if err != nil {
-				return nil, err
-			}
-
-			la := len(toAppend)
-			if la != 0 {
-				// We already have something to append ...
-				if la+len(chunkBody) > int(s.MaxRequstBodySize) {
-					// ... and it's just the right size, without currently processed chunk.
-					break
-				} else {
-					// ... we can try appending more.
-					i++
-					toAppend = append(toAppend, chunkBody...)
-					continue
-				}
-			} else { // la == 0
-				i++
-				toAppend = chunkBody
-
-				if len(chunkBody) > int(s.MaxRequstBodySize) {
-					log.Printf(
-						"W! [SumoLogic] max_request_body_size set to %d which is too small even for a single metric (len: %d), sending without split",
-						s.MaxRequstBodySize, len(chunkBody),
-					)
-
-					// The serialized metric is too big but we have no choice
-					// but to send it.
-					// max_request_body_size was set so small that it wouldn't
-					// even accomodate a single metric.
-					break
-				}
-
-				continue
-			}
-		}
-
-		if toAppend == nil {
-			break
-		}
-
-		chunks = append(chunks, toAppend)
-	}
-
-	return chunks, nil
-}
-
 func setHeaderIfSetInConfig(r *http.Request, h header, value string) {
 	if value != "" {
 		r.Header.Set(string(h), value)
@@ -338,8 +265,12 @@ func Default() *SumoLogic {
 		Timeout: internal.Duration{
 			Duration: defaultClientTimeout,
 		},
-		MaxRequstBodySize: defaultMaxRequestBodySize,
-		headers:           make(map[string]string),
+		ContentEncoding:         "gzip",
+		NonRetryableStatusCodes: append([]int(nil), internal.DefaultNonRetryableStatusCodes...),
+		Config: batching.Config{
+			MaxRequestBodySize: config.Size(defaultMaxRequestBodySize),
+		},
+		headers: make(map[string]string),
 	}
 }
 
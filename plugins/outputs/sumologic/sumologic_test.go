@@ -164,6 +164,24 @@ func TestStatusCode(t *testing.T) {
 				require.Error(t, err)
 			},
 		},
+		{
+			name:       "413 status is not retryable",
+			plugin:     pluginFn(),
+			statusCode: http.StatusRequestEntityTooLarge,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.True(t, internal.IsNonRetryable(err))
+			},
+		},
+		{
+			name:       "403 status is retryable",
+			plugin:     pluginFn(),
+			statusCode: http.StatusForbidden,
+			errFunc: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, internal.IsNonRetryable(err))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -340,8 +358,8 @@ func TestDefaultUserAgent(t *testing.T) {
 		})
 
 		plugin := &SumoLogic{
-			URL:               u.String(),
-			MaxRequstBodySize: Default().MaxRequstBodySize,
+			URL:    u.String(),
+			Config: Default().Config,
 		}
 
 		serializer, err := carbon2.NewSerializer(string(carbon2.Carbon2FormatFieldSeparate))
@@ -491,7 +509,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 				s.URL = u.String()
 				// getMetrics returns metrics that serialized (using carbon2),
 				// uncompressed size is 43750B
-				s.MaxRequstBodySize = 43_749
+				s.MaxRequestBodySize = config.Size(43_749)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
@@ -504,7 +522,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 			plugin: func() *SumoLogic {
 				s := Default()
 				s.URL = u.String()
-				s.MaxRequstBodySize = 10_000
+				s.MaxRequestBodySize = config.Size(10_000)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
@@ -517,7 +535,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 			plugin: func() *SumoLogic {
 				s := Default()
 				s.URL = u.String()
-				s.MaxRequstBodySize = 5_000
+				s.MaxRequestBodySize = config.Size(5_000)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
@@ -530,7 +548,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 			plugin: func() *SumoLogic {
 				s := Default()
 				s.URL = u.String()
-				s.MaxRequstBodySize = 2_500
+				s.MaxRequestBodySize = config.Size(2_500)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
@@ -543,7 +561,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 			plugin: func() *SumoLogic {
 				s := Default()
 				s.URL = u.String()
-				s.MaxRequstBodySize = 1_000
+				s.MaxRequestBodySize = config.Size(1_000)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
@@ -556,7 +574,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 			plugin: func() *SumoLogic {
 				s := Default()
 				s.URL = u.String()
-				s.MaxRequstBodySize = 500
+				s.MaxRequestBodySize = config.Size(500)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
@@ -569,7 +587,7 @@ func TestMaxRequestBodySize(t *testing.T) {
 			plugin: func() *SumoLogic {
 				s := Default()
 				s.URL = u.String()
-				s.MaxRequstBodySize = 300
+				s.MaxRequestBodySize = config.Size(300)
 				return s
 			},
 			metrics:                  getMetrics(t, count),
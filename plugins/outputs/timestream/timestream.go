@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/outputs"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,14 +20,14 @@ import (
 
 type (
 	Timestream struct {
-		Region      string `toml:"region"`
-		AccessKey   string `toml:"access_key"`
-		SecretKey   string `toml:"secret_key"`
-		RoleARN     string `toml:"role_arn"`
-		Profile     string `toml:"profile"`
-		Filename    string `toml:"shared_credential_file"`
-		Token       string `toml:"token"`
-		EndpointURL string `toml:"endpoint_url"`
+		Region      string          `toml:"region"`
+		AccessKey   string          `toml:"access_key"`
+		SecretKey   internal.Secret `toml:"secret_key"`
+		RoleARN     string          `toml:"role_arn"`
+		Profile     string          `toml:"profile"`
+		Filename    string          `toml:"shared_credential_file"`
+		Token       internal.Secret `toml:"token"`
+		EndpointURL string          `toml:"endpoint_url"`
 
 		MappingMode             string `toml:"mapping_mode"`
 		DescribeDatabaseOnStart bool   `toml:"describe_database_on_start"`
@@ -26,7 +26,7 @@ const (
 type Warp10 struct {
 	Prefix             string            `toml:"prefix"`
 	WarpURL            string            `toml:"warp_url"`
-	Token              string            `toml:"token"`
+	Token              internal.Secret   `toml:"token"`
 	Timeout            internal.Duration `toml:"timeout"`
 	PrintErrorBody     bool              `toml:"print_error_body"`
 	MaxStringErrorSize int               `toml:"max_string_error_size"`
@@ -144,7 +144,11 @@ func (w *Warp10) Write(metrics []telegraf.Metric) error {
 		return fmt.Errorf("unable to create new request '%s': %s", addr, err)
 	}
 
-	req.Header.Set("X-Warp10-Token", w.Token)
+	token, err := w.Token.Get()
+	if err != nil {
+		return fmt.Errorf("getting token: %w", err)
+	}
+	req.Header.Set("X-Warp10-Token", token)
 	req.Header.Set("Content-Type", "text/plain")
 
 	resp, err := w.client.Do(req)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -17,7 +18,7 @@ func TestWriteWarp10(t *testing.T) {
 	w := Warp10{
 		Prefix:  "unit.test",
 		WarpURL: "http://localhost:8090",
-		Token:   "WRITE",
+		Token:   internal.NewSecret("WRITE"),
 	}
 
 	payload := w.GenWarp10Payload(testutil.MockMetrics())
@@ -28,7 +29,7 @@ func TestHandleWarp10Error(t *testing.T) {
 	w := Warp10{
 		Prefix:  "unit.test",
 		WarpURL: "http://localhost:8090",
-		Token:   "WRITE",
+		Token:   internal.NewSecret("WRITE"),
 	}
 	tests := [...]*ErrorTest{
 		{
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	wavefront "github.com/wavefronthq/wavefront-sdk-go/senders"
 )
@@ -14,7 +15,7 @@ const maxTagLength = 254
 
 type Wavefront struct {
 	Url             string
-	Token           string
+	Token           internal.Secret
 	Host            string
 	Port            int
 	Prefix          string
@@ -125,9 +126,13 @@ func (w *Wavefront) Connect() error {
 
 	if w.Url != "" {
 		w.Log.Debug("connecting over http/https using Url: %s", w.Url)
+		token, err := w.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token: %w", err)
+		}
 		sender, err := wavefront.NewDirectSender(&wavefront.DirectConfiguration{
 			Server:               w.Url,
-			Token:                w.Token,
+			Token:                token,
 			FlushIntervalSeconds: 5,
 		})
 		if err != nil {
@@ -331,7 +336,7 @@ func (w *Wavefront) Close() error {
 func init() {
 	outputs.Add("wavefront", func() telegraf.Output {
 		return &Wavefront{
-			Token:           "DUMMY_TOKEN",
+			Token:           internal.NewSecret("DUMMY_TOKEN"),
 			MetricSeparator: ".",
 			ConvertPaths:    true,
 			ConvertBool:     true,
@@ -0,0 +1,218 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/internal/record"
+	"github.com/linkedin/goavro/v2"
+)
+
+// magicByte starts the messages of the Confluent wire format, followed by the
+// ID of the schema of the message in the registry.
+const magicByte = 0
+
+// Config is the configuration of the avro data format.  The schema is read
+// from the SchemaFile, or looked up in the SchemaRegistry using the schema ID
+// prefix of the messages.
+type Config struct {
+	MetricName     string
+	SchemaFile     string
+	SchemaRegistry string
+
+	Measurement      string
+	MeasurementField string
+	Tags             []string
+	Fields           []string
+	Timestamp        string
+	TimestampFormat  string
+
+	DefaultTags map[string]string
+}
+
+type Parser struct {
+	record   record.Config
+	schema   *schema
+	registry *schemaRegistry
+}
+
+// schema is a codec and the names of the named types of its schema.
+type schema struct {
+	codec *goavro.Codec
+	names map[string]bool
+}
+
+func NewParser(config *Config) (*Parser, error) {
+	p := &Parser{
+		record: record.Config{
+			Measurement:      config.Measurement,
+			MeasurementField: config.MeasurementField,
+			Tags:             config.Tags,
+			Fields:           config.Fields,
+			Timestamp:        config.Timestamp,
+			TimestampFormat:  config.TimestampFormat,
+			DefaultTags:      config.DefaultTags,
+		},
+	}
+	if p.record.Measurement == "" {
+		p.record.Measurement = config.MetricName
+	}
+
+	switch {
+	case config.SchemaFile != "" && config.SchemaRegistry != "":
+		return nil, errors.New("both avro_schema_file and avro_schema_registry cannot be set")
+	case config.SchemaFile != "":
+		buf, err := ioutil.ReadFile(config.SchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		p.schema, err = newSchema(string(buf))
+		if err != nil {
+			return nil, fmt.Errorf("parsing schema file %s: %v", config.SchemaFile, err)
+		}
+	case config.SchemaRegistry != "":
+		p.registry = newSchemaRegistry(config.SchemaRegistry)
+	default:
+		return nil, errors.New("one of avro_schema_file or avro_schema_registry must be set")
+	}
+	return p, nil
+}
+
+func newSchema(text string) (*schema, error) {
+	codec, err := goavro.NewCodec(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var root interface{}
+	if err := json.Unmarshal([]byte(codec.Schema()), &root); err != nil {
+		return nil, err
+	}
+	s := &schema{
+		codec: codec,
+		names: make(map[string]bool),
+	}
+	s.collectNames(root)
+	return s, nil
+}
+
+// collectNames collects the names of the records, enums and fixed types.  The
+// canonical form of the schema uses their full names, as do unions.
+func (s *schema) collectNames(node interface{}) {
+	switch n := node.(type) {
+	case []interface{}:
+		for _, elem := range n {
+			s.collectNames(elem)
+		}
+	case map[string]interface{}:
+		switch n["type"] {
+		case "record", "enum", "fixed":
+			if name, ok := n["name"].(string); ok {
+				s.names[name] = true
+			}
+		}
+		for _, v := range n {
+			s.collectNames(v)
+		}
+	}
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	s := p.schema
+	if p.registry != nil {
+		if len(buf) < 5 || buf[0] != magicByte {
+			return nil, errors.New("message without schema ID")
+		}
+		var err error
+		s, err = p.registry.schema(int(binary.BigEndian.Uint32(buf[1:5])))
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[5:]
+	}
+
+	native, _, err := s.codec.NativeFromBinary(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	s.flatten("", native, values)
+	m, err := p.record.Metric(values, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return []telegraf.Metric{m}, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: avro", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.record.DefaultTags = tags
+}
+
+// flatten stores the values of the native form of a datum, the names of
+// nested values are joined with an underscore.  Unions are decoded as a map
+// holding the value under the name of its type, which is left out.
+func (s *schema) flatten(prefix string, native interface{}, values map[string]interface{}) {
+	switch v := native.(type) {
+	case nil:
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for k, elem := range v {
+				if primitives[k] || s.names[k] {
+					s.flatten(prefix, elem, values)
+					return
+				}
+			}
+		}
+		for k, elem := range v {
+			s.flatten(record.Join(prefix, k), elem, values)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			s.flatten(record.Join(prefix, fmt.Sprint(i)), elem, values)
+		}
+	case int32:
+		values[prefix] = int64(v)
+	case float32:
+		values[prefix] = float64(v)
+	case []byte:
+		values[prefix] = string(v)
+	case time.Duration:
+		values[prefix] = int64(v)
+	case *big.Rat:
+		f, _ := v.Float64()
+		values[prefix] = f
+	default:
+		values[prefix] = v
+	}
+}
+
+var primitives = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"int":     true,
+	"long":    true,
+	"float":   true,
+	"double":  true,
+	"bytes":   true,
+	"string":  true,
+}
@@ -0,0 +1,185 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+)
+
+var reading = map[string]interface{}{
+	"sensor":      "s1",
+	"time":        time.Unix(1600000000, 123000000),
+	"temperature": 21.5,
+	"humidity":    goavro.Union("float", float32(0.5)),
+	"battery":     nil,
+	"location":    map[string]interface{}{"site": "north", "floor": int32(2)},
+	"state":       "OK",
+}
+
+func encode(t *testing.T, schema string, native interface{}) []byte {
+	codec, err := goavro.NewCodec(schema)
+	require.NoError(t, err)
+	buf, err := codec.BinaryFromNative(nil, native)
+	require.NoError(t, err)
+	return buf
+}
+
+func readSchema(t *testing.T) string {
+	buf, err := ioutil.ReadFile("testdata/reading.avsc")
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestParseSchemaFile(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:      "avro",
+		SchemaFile:      "testdata/reading.avsc",
+		Measurement:     "reading",
+		Tags:            []string{"sensor", "location_site"},
+		Timestamp:       "time",
+		TimestampFormat: "unix_ms",
+		DefaultTags:     map[string]string{"source": "kafka"},
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(encode(t, readSchema(t), reading))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("reading",
+			map[string]string{"source": "kafka", "sensor": "s1", "location_site": "north"},
+			map[string]interface{}{
+				"temperature":    21.5,
+				"humidity":       0.5,
+				"location_floor": int64(2),
+				"state":          "OK",
+			},
+			time.Unix(1600000000, 123000000)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseFieldsAndMeasurementField(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:       "avro",
+		SchemaFile:       "testdata/reading.avsc",
+		MeasurementField: "sensor",
+		Fields:           []string{"temperature", "battery"},
+	})
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine(string(encode(t, readSchema(t), reading)))
+	require.NoError(t, err)
+	require.Equal(t, "s1", m.Name())
+	require.Equal(t, map[string]interface{}{"temperature": 21.5}, m.Fields())
+	require.Empty(t, m.Tags())
+}
+
+func TestParseSchemaRegistry(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/schemas/ids/42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]string{"schema": readSchema(t)}))
+	}))
+	defer ts.Close()
+
+	parser, err := NewParser(&Config{
+		MetricName:     "avro",
+		SchemaRegistry: ts.URL + "/",
+		Tags:           []string{"sensor"},
+		Fields:         []string{"temperature"},
+		Timestamp:      "time",
+	})
+	require.NoError(t, err)
+
+	message := []byte{0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(message[1:], 42)
+	message = append(message, encode(t, readSchema(t), reading)...)
+
+	for i := 0; i < 2; i++ {
+		metrics, err := parser.Parse(message)
+		require.NoError(t, err)
+		expected := []telegraf.Metric{
+			testutil.MustMetric("avro",
+				map[string]string{"sensor": "s1"},
+				map[string]interface{}{"temperature": 21.5},
+				time.Unix(1600000000, 123000000)),
+		}
+		testutil.RequireMetricsEqual(t, expected, metrics)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// Unknown schema, the failure is cached until the retry interval passed
+	binary.BigEndian.PutUint32(message[1:], 7)
+	_, err = parser.Parse(message)
+	require.Error(t, err)
+	_, err = parser.Parse(message)
+	require.Error(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	parser.registry.retryInterval = 0
+	_, err = parser.Parse(message)
+	require.Error(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	// Missing schema ID
+	_, err = parser.Parse(encode(t, readSchema(t), reading))
+	require.Error(t, err)
+}
+
+func TestParseTimestampFormat(t *testing.T) {
+	schema := `{"type": "record", "name": "Event", "fields": [
+		{"name": "ts", "type": "long"},
+		{"name": "value", "type": "long"}
+	]}`
+
+	dir, err := ioutil.TempDir("", "avro")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := dir + "/event.avsc"
+	require.NoError(t, ioutil.WriteFile(file, []byte(schema), 0644))
+
+	parser, err := NewParser(&Config{
+		MetricName:      "event",
+		SchemaFile:      file,
+		Timestamp:       "ts",
+		TimestampFormat: "unix_ms",
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(encode(t, schema, map[string]interface{}{"ts": int64(1600000000500), "value": int64(3)}))
+	require.NoError(t, err)
+	expected := []telegraf.Metric{
+		testutil.MustMetric("event",
+			map[string]string{},
+			map[string]interface{}{"value": int64(3)},
+			time.Unix(1600000000, 500000000)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestNewParserErrors(t *testing.T) {
+	_, err := NewParser(&Config{})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{SchemaFile: "testdata/reading.avsc", SchemaRegistry: "http://localhost:8081"})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{SchemaFile: "testdata/missing.avsc"})
+	require.Error(t, err)
+}
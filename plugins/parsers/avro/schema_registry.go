@@ -0,0 +1,99 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// schemaRetryInterval is how long a failed schema lookup is cached before
+// the registry is asked again.
+const schemaRetryInterval = time.Minute
+
+// schemaRegistry looks up schemas by ID in a Confluent compatible schema
+// registry.  Schemas never change once registered, so they are cached.
+// Failed lookups are cached for the retry interval so that the messages of
+// an unknown schema do not each query the registry.
+type schemaRegistry struct {
+	url           string
+	client        *http.Client
+	retryInterval time.Duration
+
+	sync.Mutex
+	cache    map[int]*schema
+	failures map[int]schemaFailure
+}
+
+type schemaFailure struct {
+	err  error
+	time time.Time
+}
+
+func newSchemaRegistry(url string) *schemaRegistry {
+	return &schemaRegistry{
+		url:           strings.TrimSuffix(url, "/"),
+		client:        &http.Client{Timeout: 10 * time.Second},
+		retryInterval: schemaRetryInterval,
+		cache:         make(map[int]*schema),
+		failures:      make(map[int]schemaFailure),
+	}
+}
+
+// schema returns the schema with the ID.  The registry is queried without
+// holding the lock, concurrent lookups of a new schema may all query it.
+func (r *schemaRegistry) schema(id int) (*schema, error) {
+	r.Lock()
+	if s, ok := r.cache[id]; ok {
+		r.Unlock()
+		return s, nil
+	}
+	if f, ok := r.failures[id]; ok && time.Since(f.time) < r.retryInterval {
+		r.Unlock()
+		return nil, f.err
+	}
+	r.Unlock()
+
+	s, err := r.fetch(id)
+
+	r.Lock()
+	defer r.Unlock()
+	if err != nil {
+		r.failures[id] = schemaFailure{err: err, time: time.Now()}
+		return nil, err
+	}
+	delete(r.failures, id)
+	r.cache[id] = s
+	return s, nil
+}
+
+func (r *schemaRegistry) fetch(id int) (*schema, error) {
+	resp, err := r.client.Get(fmt.Sprintf("%s/schemas/ids/%d", r.url, id))
+	if err != nil {
+		return nil, fmt.Errorf("getting schema %d: %v", id, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting schema %d: %s", id, resp.Status)
+	}
+
+	var body struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding schema %d: %v", id, err)
+	}
+	if body.SchemaType != "" && body.SchemaType != "AVRO" {
+		return nil, fmt.Errorf("schema %d is not an avro schema but %s", id, body.SchemaType)
+	}
+
+	s, err := newSchema(body.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %d: %v", id, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,147 @@
+package parsers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Parse error modes, selecting what happens to data the parser fails on.
+const (
+	// ParseErrorDrop returns the error, the input logs it and drops the data.
+	ParseErrorDrop = "drop"
+	// ParseErrorEmitRaw returns a metric holding the raw data and the error.
+	ParseErrorEmitRaw = "emit_raw"
+	// ParseErrorRoute is ParseErrorEmitRaw, with the metric tagged so it can
+	// be routed to a dead-letter output.
+	ParseErrorRoute = "route"
+)
+
+// ParseErrorTag is the tag added to the metrics of ParseErrorRoute, its value
+// is the data format.
+const ParseErrorTag = "parse_error"
+
+// errorModeParser wraps a parser to count its errors and handle them
+// according to the parse error mode.
+type errorModeParser struct {
+	Parser
+
+	mode        string
+	metricName  string
+	dataFormat  string
+	defaultTags map[string]string
+
+	errors selfstat.Stat
+}
+
+func newErrorModeParser(parser Parser, config *Config) (Parser, error) {
+	mode := config.ParseErrorMode
+	switch mode {
+	case "":
+		mode = ParseErrorDrop
+	case ParseErrorDrop, ParseErrorEmitRaw, ParseErrorRoute:
+	default:
+		return nil, fmt.Errorf("invalid parse_error_mode %q", config.ParseErrorMode)
+	}
+
+	tags := map[string]string{
+		"input":       config.MetricName,
+		"data_format": config.DataFormat,
+	}
+	if config.Alias != "" {
+		tags["alias"] = config.Alias
+	}
+	return &errorModeParser{
+		Parser:      parser,
+		mode:        mode,
+		metricName:  config.MetricName,
+		dataFormat:  config.DataFormat,
+		defaultTags: config.DefaultTags,
+		errors:      selfstat.Register("parser", "errors", tags),
+	}, nil
+}
+
+func (p *errorModeParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	if err == nil {
+		return metrics, nil
+	}
+
+	p.errors.Incr(1)
+	if p.mode == ParseErrorDrop {
+		return metrics, err
+	}
+
+	// The raw metric holds all of the data, the metrics parsed before the
+	// error would be emitted twice.
+	return []telegraf.Metric{p.rawMetric(string(buf), err)}, nil
+}
+
+func (p *errorModeParser) ParseLine(line string) (telegraf.Metric, error) {
+	m, err := p.Parser.ParseLine(line)
+	if err == nil {
+		return m, nil
+	}
+
+	p.errors.Incr(1)
+	if p.mode == ParseErrorDrop {
+		return m, err
+	}
+	return p.rawMetric(line, err), nil
+}
+
+// Unwrap returns the wrapped parser.
+func (p *errorModeParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *errorModeParser) SetDefaultTags(tags map[string]string) {
+	p.defaultTags = tags
+	p.Parser.SetDefaultTags(tags)
+}
+
+// rawMetric returns the metric holding the data the parser failed on.
+func (p *errorModeParser) rawMetric(raw string, err error) telegraf.Metric {
+	tags := make(map[string]string, len(p.defaultTags)+1)
+	for k, v := range p.defaultTags {
+		tags[k] = v
+	}
+	if p.mode == ParseErrorRoute {
+		tags[ParseErrorTag] = p.dataFormat
+	}
+
+	fields := map[string]interface{}{
+		"raw":   raw,
+		"error": err.Error(),
+	}
+	m, _ := metric.New(p.metricName, tags, fields, time.Now())
+	return m
+}
+
+// Unwrap returns the parser wrapped to handle the parse error mode, for
+// inputs depending on the type of the parser.
+func Unwrap(parser Parser) Parser {
+	if p, ok := parser.(*errorModeParser); ok {
+		return p.Unwrap()
+	}
+	return parser
+}
+
+// EmitsRaw reports whether the parse errors of the parser are emitted as
+// metrics holding the raw data, which inputs parsing the data without the
+// parser cannot do.
+func EmitsRaw(parser Parser) bool {
+	p, ok := parser.(*errorModeParser)
+	return ok && p.mode != ParseErrorDrop
+}
+
+// CountError counts a parse error of the parser, for inputs parsing the data
+// without the parser.
+func CountError(parser Parser) {
+	if p, ok := parser.(*errorModeParser); ok {
+		p.errors.Incr(1)
+	}
+}
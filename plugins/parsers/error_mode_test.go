@@ -0,0 +1,127 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser(t *testing.T, mode string) Parser {
+	parser, err := NewParser(&Config{
+		DataFormat:     "influx",
+		MetricName:     "socket_listener",
+		ParseErrorMode: mode,
+	})
+	require.NoError(t, err)
+	parser.SetDefaultTags(map[string]string{"host": "a"})
+	return parser
+}
+
+func TestParseErrorModeUnset(t *testing.T) {
+	parser := newTestParser(t, "")
+	errors := parser.(*errorModeParser).errors
+	before := errors.Get()
+
+	_, err := parser.Parse([]byte("cpu value="))
+	require.Error(t, err)
+	require.Equal(t, before+1, errors.Get())
+}
+
+func TestParseErrorModeUnwrap(t *testing.T) {
+	parser := newTestParser(t, ParseErrorRoute)
+	_, ok := Unwrap(parser).(*influx.Parser)
+	require.True(t, ok)
+	require.True(t, EmitsRaw(parser))
+
+	inner := Unwrap(parser)
+	require.Equal(t, inner, Unwrap(inner))
+	require.False(t, EmitsRaw(inner))
+	require.False(t, EmitsRaw(newTestParser(t, ParseErrorDrop)))
+}
+
+func TestParseErrorModeDrop(t *testing.T) {
+	parser := newTestParser(t, ParseErrorDrop)
+	errors := parser.(*errorModeParser).errors
+	before := errors.Get()
+
+	metrics, err := parser.Parse([]byte("cpu value=42"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{"host": "a"}, metrics[0].Tags())
+
+	_, err = parser.Parse([]byte("cpu value="))
+	require.Error(t, err)
+	_, err = parser.ParseLine("cpu value=")
+	require.Error(t, err)
+	require.Equal(t, before+2, errors.Get())
+}
+
+func TestParseErrorModeEmitRaw(t *testing.T) {
+	parser := newTestParser(t, ParseErrorEmitRaw)
+
+	metrics, err := parser.Parse([]byte("cpu value="))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	m := metrics[0]
+	require.Equal(t, "socket_listener", m.Name())
+	require.Equal(t, map[string]string{"host": "a"}, m.Tags())
+	raw, _ := m.GetField("raw")
+	require.Equal(t, "cpu value=", raw)
+	msg, ok := m.GetField("error")
+	require.True(t, ok)
+	require.NotEmpty(t, msg)
+
+	m, err = parser.ParseLine("cpu value=")
+	require.NoError(t, err)
+	raw, _ = m.GetField("raw")
+	require.Equal(t, "cpu value=", raw)
+
+	// The lines parsed are not emitted along with the raw data
+	metrics, err = parser.Parse([]byte("cpu value=42\ncpu value=\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	raw, _ = metrics[0].GetField("raw")
+	require.Equal(t, "cpu value=42\ncpu value=\n", raw)
+}
+
+func TestParseErrorModeRoute(t *testing.T) {
+	parser := newTestParser(t, ParseErrorRoute)
+
+	m, err := parser.ParseLine("cpu value=")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "a", ParseErrorTag: "influx"}, m.Tags())
+
+	m, err = parser.ParseLine("cpu value=1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "a"}, m.Tags())
+}
+
+func TestParseErrorModeStats(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat: "influx",
+		MetricName: "socket_listener",
+		Alias:      "stats",
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, m := range selfstat.Metrics() {
+		if m.Name() != "internal_parser" {
+			continue
+		}
+		input, _ := m.GetTag("input")
+		alias, _ := m.GetTag("alias")
+		if input == "socket_listener" && alias == "stats" {
+			_, found = m.GetField("errors")
+		}
+	}
+	require.True(t, found)
+}
+
+func TestParseErrorModeInvalid(t *testing.T) {
+	_, err := NewParser(&Config{DataFormat: "influx", ParseErrorMode: "ignore"})
+	require.Error(t, err)
+}
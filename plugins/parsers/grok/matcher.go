@@ -0,0 +1,168 @@
+package grok
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// referenceRe matches a reference to a pattern, ie, %{NUMBER:bytes}
+var referenceRe = regexp.MustCompile(`%{(\w+)(?::[^}]*)?}`)
+
+// maxExpandDepth limits the expansion of the references of recursive patterns.
+const maxExpandDepth = 32
+
+// patternMatcher is one of the configured patterns.  Before trying the
+// pattern, lines are checked for the literal text any match starts with,
+// which is much cheaper than running the regular expression.
+type patternMatcher struct {
+	// name is the internal name of the pattern, ie, %{GROK_INTERNAL_PATTERN_0}
+	name string
+	// pattern is the configured pattern.
+	pattern string
+
+	// literal is the text starting every match, anchored is set when matches
+	// start at the beginning of the line.
+	literal  string
+	anchored bool
+
+	matched   selfstat.Stat
+	skipped   selfstat.Stat
+	matchTime selfstat.Stat
+}
+
+func (p *Parser) newPatternMatcher(name, pattern string) *patternMatcher {
+	m := &patternMatcher{
+		name:    "%{" + name + "}",
+		pattern: pattern,
+	}
+
+	m.literal, m.anchored = literalPrefix(p.expand(p.patterns[name], 0))
+
+	tags := map[string]string{"pattern": pattern}
+	m.matched = selfstat.Register("grok", "lines_matched", tags)
+	m.skipped = selfstat.Register("grok", "lines_skipped", tags)
+	m.matchTime = selfstat.RegisterTiming("grok", "match_time_ns", tags)
+	return m
+}
+
+// literalPrefix returns the text starting every match of the expression, and
+// whether matches start at the beginning of the text.  The prefix of an
+// anchored expression is computed without the anchor, the regexp package does
+// not report it otherwise.  Only an anchor starting the whole expression is
+// removed, in ^a|b it applies to the first alternative only.
+func literalPrefix(expr string) (string, bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var anchored bool
+	if re.Op == syntax.OpConcat && len(re.Sub) > 1 {
+		switch re.Sub[0].Op {
+		case syntax.OpBeginText:
+			re.Sub = re.Sub[1:]
+			anchored = true
+		case syntax.OpBeginLine:
+			// Multi-line mode, the match may start after any newline.
+			re.Sub = re.Sub[1:]
+		}
+	}
+
+	prog, err := regexp.Compile(re.String())
+	if err != nil {
+		return "", false
+	}
+	literal, _ := prog.LiteralPrefix()
+	return literal, anchored
+}
+
+// expand replaces the references of a pattern with the patterns they refer
+// to.  References to patterns only known to the grok library are replaced by
+// a match of anything, the result is only used to find the literal prefix.
+func (p *Parser) expand(pattern string, depth int) string {
+	return referenceRe.ReplaceAllStringFunc(pattern, func(ref string) string {
+		name := referenceRe.FindStringSubmatch(ref)[1]
+		sub, ok := p.patterns[name]
+		if !ok || depth >= maxExpandDepth {
+			return "(?s:.*)"
+		}
+		return "(?:" + p.expand(sub, depth+1) + ")"
+	})
+}
+
+// mayMatch returns false when the line can not match the pattern.
+func (m *patternMatcher) mayMatch(line string) bool {
+	if m.anchored {
+		return strings.HasPrefix(line, m.literal)
+	}
+	return strings.Contains(line, m.literal)
+}
+
+// match returns the matcher of the first pattern matching the line and the
+// captured values, or nil when no pattern matches.
+func (p *Parser) match(line string) (*patternMatcher, map[string]string, error) {
+	for _, m := range p.matchers {
+		if !m.mayMatch(line) {
+			m.skipped.Incr(1)
+			continue
+		}
+
+		start := time.Now()
+		values, err := p.g.Parse(m.name, line)
+		m.matchTime.Incr(time.Since(start).Nanoseconds())
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(values) != 0 {
+			m.matched.Incr(1)
+			return m, values, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// Match is the result of matching a line against the configured patterns.
+type Match struct {
+	// Pattern is the first configured pattern matching the line.
+	Pattern string
+	// Captures are the values captured by the pattern, sorted by name.
+	Captures []Capture
+}
+
+// Capture is a value captured by a pattern, with the modifier giving its
+// type, or the layout of timestamps.
+type Capture struct {
+	Name     string
+	Value    string
+	Modifier string
+}
+
+// Match returns the pattern matching the line and its captures, or nil when
+// no pattern matches.  It is meant to debug patterns.
+func (p *Parser) Match(line string) (*Match, error) {
+	m, values, err := p.match(line)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	match := &Match{Pattern: m.pattern}
+	for k, v := range values {
+		if k == "" {
+			continue
+		}
+		match.Captures = append(match.Captures, Capture{
+			Name:     k,
+			Value:    v,
+			Modifier: p.modifier(m.name, k),
+		})
+	}
+	sort.Slice(match.Captures, func(i, j int) bool {
+		return match.Captures[i].Name < match.Captures[j].Name
+	})
+	return match, nil
+}
@@ -0,0 +1,123 @@
+package grok
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherLiteralPrefix(t *testing.T) {
+	p := &Parser{
+		Measurement: "logs",
+		Patterns: []string{
+			`ERROR %{GREEDYDATA:message}`,
+			`^\[%{WORD:level}\] %{GREEDYDATA:message}`,
+			`%{APP_PREFIX} started in %{NUMBER:duration:float}s`,
+			`%{NUMBER:value:int} (?:GET|POST)`,
+			`(?i)warn %{GREEDYDATA:message}`,
+		},
+		CustomPatterns: `
+			APP_PREFIX app\[main\]:
+		`,
+	}
+	require.NoError(t, p.Compile())
+	require.Len(t, p.matchers, 5)
+
+	literals := make([]string, 0, len(p.matchers))
+	for _, m := range p.matchers {
+		literals = append(literals, m.literal)
+	}
+	require.Equal(t, []string{"ERROR ", "[", "app[main]: started in ", "", ""}, literals)
+	require.False(t, p.matchers[0].anchored)
+	require.True(t, p.matchers[1].anchored)
+
+	require.True(t, p.matchers[0].mayMatch("2020-01-01 ERROR disk full"))
+	require.False(t, p.matchers[0].mayMatch("2020-01-01 INFO disk ok"))
+	require.True(t, p.matchers[1].mayMatch("[info] started"))
+	require.False(t, p.matchers[1].mayMatch("x [info] started"))
+	require.True(t, p.matchers[3].mayMatch("anything"))
+}
+
+func TestMatcherAnchor(t *testing.T) {
+	tests := []struct {
+		expr     string
+		literal  string
+		anchored bool
+	}{
+		{expr: `^abc`, literal: "abc", anchored: true},
+		{expr: `^ab|ac`, literal: "", anchored: false},
+		{expr: `(?:^ab)|(?:^ac)`, literal: "", anchored: false},
+		{expr: `(?m)^abc`, literal: "abc", anchored: false},
+		{expr: `ab|ac`, literal: "a", anchored: false},
+		{expr: `^`, literal: "", anchored: false},
+	}
+	for _, tt := range tests {
+		literal, anchored := literalPrefix(tt.expr)
+		require.Equal(t, tt.literal, literal, tt.expr)
+		require.Equal(t, tt.anchored, anchored, tt.expr)
+	}
+
+	p := &Parser{
+		Measurement: "logs",
+		Patterns:    []string{`^ab|ac%{GREEDYDATA:message}`},
+	}
+	require.NoError(t, p.Compile())
+	require.True(t, p.matchers[0].mayMatch("xac"))
+}
+
+func TestMatcherOrderAndStats(t *testing.T) {
+	p := &Parser{
+		Measurement: "logs",
+		Patterns: []string{
+			`LEVEL=error %{GREEDYDATA:message}`,
+			`LEVEL=%{WORD:level:tag} %{GREEDYDATA:message}`,
+		},
+	}
+	require.NoError(t, p.Compile())
+
+	tags := map[string]string{"pattern": `LEVEL=error %{GREEDYDATA:message}`}
+	matched := selfstat.Register("grok", "lines_matched", tags)
+	skipped := selfstat.Register("grok", "lines_skipped", tags)
+	matched0, skipped0 := matched.Get(), skipped.Get()
+
+	// The first matching pattern wins
+	m, err := p.ParseLine("LEVEL=error disk full")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"message": "disk full"}, m.Fields())
+	require.Empty(t, m.Tags())
+
+	// The first pattern is skipped without running its regular expression
+	m, err = p.ParseLine("LEVEL=info disk ok")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"message": "disk ok"}, m.Fields())
+	require.Equal(t, map[string]string{"level": "info"}, m.Tags())
+
+	require.Equal(t, int64(1), matched.Get()-matched0)
+	require.Equal(t, int64(1), skipped.Get()-skipped0)
+}
+
+func TestMatch(t *testing.T) {
+	p := &Parser{
+		Measurement: "logs",
+		Patterns: []string{
+			`%{TIMESTAMP_ISO8601:time:ts-"2006-01-02T15:04:05Z07:00"} %{WORD:level:tag} took %{NUMBER:duration:float}ms`,
+		},
+	}
+	require.NoError(t, p.Compile())
+
+	match, err := p.Match("2020-09-13T12:26:40Z info took 4.5ms")
+	require.NoError(t, err)
+	require.Equal(t, &Match{
+		Pattern: p.Patterns[0],
+		Captures: []Capture{
+			{Name: "duration", Value: "4.5", Modifier: "float"},
+			{Name: "level", Value: "info", Modifier: "tag"},
+			{Name: "time", Value: "2020-09-13T12:26:40Z", Modifier: "2006-01-02T15:04:05Z07:00"},
+		},
+	}, match)
+
+	match, err = p.Match("nothing to see")
+	require.NoError(t, err)
+	require.Nil(t, match)
+}
@@ -124,6 +124,7 @@ type Parser struct {
 	timeFunc func() time.Time
 	g        *grok.Grok
 	tsModder *tsModder
+	matchers []*patternMatcher
 }
 
 // Compile is a bound method to Parser which will process the options for our parser
@@ -145,6 +146,7 @@ func (p *Parser) Compile() error {
 	// Give Patterns fake names so that they can be treated as named
 	// "custom patterns"
 	p.NamedPatterns = make([]string, 0, len(p.Patterns))
+	patterns := make(map[string]string, len(p.Patterns))
 	for i, pattern := range p.Patterns {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
@@ -153,6 +155,7 @@ func (p *Parser) Compile() error {
 		name := fmt.Sprintf("GROK_INTERNAL_PATTERN_%d", i)
 		p.CustomPatterns += "\n" + name + " " + pattern + "\n"
 		p.NamedPatterns = append(p.NamedPatterns, "%{"+name+"}")
+		patterns[name] = pattern
 	}
 
 	if len(p.NamedPatterns) == 0 {
@@ -188,30 +191,30 @@ func (p *Parser) Compile() error {
 		p.timeFunc = time.Now
 	}
 
-	return p.compileCustomPatterns()
+	err = p.compileCustomPatterns()
+
+	p.matchers = make([]*patternMatcher, 0, len(p.NamedPatterns))
+	for _, namedPattern := range p.NamedPatterns {
+		name := strings.TrimSuffix(strings.TrimPrefix(namedPattern, "%{"), "}")
+		p.matchers = append(p.matchers, p.newPatternMatcher(name, patterns[name]))
+	}
+	return err
 }
 
 // ParseLine is the primary function to process individual lines, returning the metrics
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
-	var err error
 	// values are the parsed fields from the log line
-	var values map[string]string
-	// the matching pattern string
-	var patternName string
-	for _, pattern := range p.NamedPatterns {
-		if values, err = p.g.Parse(pattern, line); err != nil {
-			return nil, err
-		}
-		if len(values) != 0 {
-			patternName = pattern
-			break
-		}
+	m, values, err := p.match(line)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(values) == 0 {
+	if m == nil {
 		log.Printf("D! Grok no match found for: %q", line)
 		return nil, nil
 	}
+	// the matching pattern string
+	patternName := m.name
 
 	fields := make(map[string]interface{})
 	tags := make(map[string]string)
@@ -227,24 +230,7 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 			continue
 		}
 		// t is the modifier of the field
-		var t string
-		// check if pattern has some modifiers
-		if types, ok := p.typeMap[patternName]; ok {
-			t = types[k]
-		}
-		// if we didn't find a modifier, check if we have a timestamp layout
-		if t == "" {
-			if ts, ok := p.tsMap[patternName]; ok {
-				// check if the modifier is a timestamp layout
-				if layout, ok := ts[k]; ok {
-					t = layout
-				}
-			}
-		}
-		// if we didn't find a type OR timestamp modifier, assume string
-		if t == "" {
-			t = STRING
-		}
+		t := p.modifier(patternName, k)
 
 		switch t {
 		case MEASUREMENT:
@@ -376,6 +362,26 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	return metric.New(p.Measurement, tags, fields, p.tsModder.tsMod(timestamp))
 }
 
+// modifier returns the modifier of a capture of a pattern, the type of the
+// value or the layout of a timestamp.
+func (p *Parser) modifier(patternName, capture string) string {
+	// check if pattern has some modifiers
+	if types, ok := p.typeMap[patternName]; ok {
+		if t := types[capture]; t != "" {
+			return t
+		}
+	}
+	// if we didn't find a modifier, check if we have a timestamp layout
+	if ts, ok := p.tsMap[patternName]; ok {
+		// check if the modifier is a timestamp layout
+		if layout, ok := ts[capture]; ok {
+			return layout
+		}
+	}
+	// if we didn't find a type OR timestamp modifier, assume string
+	return STRING
+}
+
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 
 	metrics := make([]telegraf.Metric, 0)
@@ -0,0 +1,154 @@
+// Package record maps the values of decoded records, such as Avro or
+// Protocol Buffers messages, to metrics.
+package record
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Separator joins the names of nested values, and the indexes of the
+// elements of lists.
+const Separator = "_"
+
+// Config selects the values of a record making up a metric.  The values are
+// named after their flattened path in the record, such as "device_id" for the
+// id of a nested device record.
+type Config struct {
+	// Measurement is the name of the metrics, MeasurementField the value
+	// naming the metric when present.
+	Measurement      string
+	MeasurementField string
+
+	// Tags are the values stored as tags, Fields the values stored as
+	// fields.  By default all values other than the tags are fields.
+	Tags   []string
+	Fields []string
+
+	// Timestamp is the value holding the time of the metric.  Time values
+	// are used as is, other values are parsed using the TimestampFormat,
+	// one of "unix", "unix_ms", "unix_us", "unix_ns", or a Go reference time
+	// layout.
+	Timestamp       string
+	TimestampFormat string
+
+	DefaultTags map[string]string
+}
+
+// Metric returns the metric of the flattened values of a record.
+func (c *Config) Metric(values map[string]interface{}, now time.Time) (telegraf.Metric, error) {
+	name := c.Measurement
+	if c.MeasurementField != "" {
+		if v, ok := values[c.MeasurementField]; ok {
+			if s := toString(v); s != "" {
+				name = s
+			}
+		}
+	}
+
+	tags := make(map[string]string, len(c.DefaultTags)+len(c.Tags))
+	for k, v := range c.DefaultTags {
+		tags[k] = v
+	}
+	for _, k := range c.Tags {
+		if v, ok := values[k]; ok {
+			tags[k] = toString(v)
+		}
+	}
+
+	t := now
+	if c.Timestamp != "" {
+		v, ok := values[c.Timestamp]
+		if !ok {
+			return nil, fmt.Errorf("timestamp %q not found", c.Timestamp)
+		}
+		var err error
+		t, err = c.parseTimestamp(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make(map[string]interface{})
+	if len(c.Fields) > 0 {
+		for _, k := range c.Fields {
+			if v, ok := values[k]; ok {
+				fields[k] = fieldValue(v)
+			}
+		}
+	} else {
+		skip := make(map[string]bool, len(c.Tags)+2)
+		for _, k := range c.Tags {
+			skip[k] = true
+		}
+		skip[c.Timestamp] = true
+		skip[c.MeasurementField] = true
+		for k, v := range values {
+			if !skip[k] {
+				fields[k] = fieldValue(v)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields found in record")
+	}
+
+	return metric.New(name, tags, fields, t)
+}
+
+func (c *Config) parseTimestamp(v interface{}) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+
+	format := c.TimestampFormat
+	if format == "" {
+		format = "unix"
+	}
+	t, err := internal.ParseTimestamp(format, toString(v), "UTC")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing timestamp %v: %v", v, err)
+	}
+	return t, nil
+}
+
+// fieldValue returns the value as a field, times are stored as RFC3339
+// strings.
+func fieldValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Join returns the name of a nested value.
+func Join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + Separator + name
+}
@@ -0,0 +1,552 @@
+package json_v2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/tidwall/gjson"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// Config is a [[json_v2]] block.  The metrics of a block are made of the
+// values selected by its field and tag paths combined with the metrics of
+// its objects.  When a path selects several values, one metric is created for
+// each of them.
+type Config struct {
+	MeasurementName     string `toml:"measurement_name"`
+	MeasurementNamePath string `toml:"measurement_name_path"`
+	TimestampPath       string `toml:"timestamp_path"`
+	TimestampFormat     string `toml:"timestamp_format"`
+	TimestampTimezone   string `toml:"timestamp_timezone"`
+
+	Fields  []DataSet `toml:"field"`
+	Tags    []DataSet `toml:"tag"`
+	Objects []Object  `toml:"object"`
+}
+
+// DataSet is a [[json_v2.field]] or [[json_v2.tag]] block selecting values
+// with a GJSON path.  The name defaults to the last key of the path.
+type DataSet struct {
+	Path   string `toml:"path"`
+	Rename string `toml:"rename"`
+	Type   string `toml:"type"`
+}
+
+// Object is a [[json_v2.object]] block selecting an object or an array of
+// objects with a GJSON path.  Each object is flattened into a metric, the
+// keys of nested objects are joined with an underscore.  Nested arrays create
+// one metric for each element, which also holds the other keys of its parent.
+type Object struct {
+	Path               string            `toml:"path"`
+	TimestampKey       string            `toml:"timestamp_key"`
+	TimestampFormat    string            `toml:"timestamp_format"`
+	TimestampTimezone  string            `toml:"timestamp_timezone"`
+	DisablePrependKeys bool              `toml:"disable_prepend_keys"`
+	IncludedKeys       []string          `toml:"included_keys"`
+	ExcludedKeys       []string          `toml:"excluded_keys"`
+	Tags               []string          `toml:"tags"`
+	Fields             map[string]string `toml:"fields"`
+	Renames            map[string]string `toml:"renames"`
+
+	included filter.Filter
+	excluded filter.Filter
+}
+
+type Parser struct {
+	configs     []Config
+	metricName  string
+	defaultTags map[string]string
+}
+
+// NewParser returns a parser for the blocks.  Metrics are named after the
+// metric name unless the block sets a measurement name.
+func NewParser(metricName string, configs []Config, defaultTags map[string]string) (*Parser, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("no json_v2 blocks configured")
+	}
+
+	for i := range configs {
+		c := &configs[i]
+		if c.TimestampPath != "" && c.TimestampFormat == "" {
+			return nil, errors.New("use of 'timestamp_path' requires 'timestamp_format'")
+		}
+		for _, ds := range c.Fields {
+			if ds.Path == "" {
+				return nil, errors.New("field without path")
+			}
+			if err := checkType(ds.Type); err != nil {
+				return nil, err
+			}
+		}
+		for _, ds := range c.Tags {
+			if ds.Path == "" {
+				return nil, errors.New("tag without path")
+			}
+		}
+		for j := range c.Objects {
+			o := &c.Objects[j]
+			if o.Path == "" {
+				return nil, errors.New("object without path")
+			}
+			if o.TimestampKey != "" && o.TimestampFormat == "" {
+				return nil, errors.New("use of 'timestamp_key' requires 'timestamp_format'")
+			}
+			for _, typ := range o.Fields {
+				if err := checkType(typ); err != nil {
+					return nil, err
+				}
+			}
+
+			var err error
+			if o.included, err = filter.Compile(o.IncludedKeys); err != nil {
+				return nil, fmt.Errorf("compiling 'included_keys': %v", err)
+			}
+			if o.excluded, err = filter.Compile(o.ExcludedKeys); err != nil {
+				return nil, fmt.Errorf("compiling 'excluded_keys': %v", err)
+			}
+		}
+	}
+
+	return &Parser{
+		configs:     configs,
+		metricName:  metricName,
+		defaultTags: defaultTags,
+	}, nil
+}
+
+// row holds the values of a metric being built.  Fields hold either a
+// gjson.Result or a value converted to the type of the field.
+type row struct {
+	fields map[string]interface{}
+	tags   map[string]string
+	time   time.Time
+}
+
+func (r row) merge(other row) row {
+	m := row{
+		fields: make(map[string]interface{}, len(r.fields)+len(other.fields)),
+		tags:   make(map[string]string, len(r.tags)+len(other.tags)),
+		time:   r.time,
+	}
+	for k, v := range r.fields {
+		m.fields[k] = v
+	}
+	for k, v := range other.fields {
+		m.fields[k] = v
+	}
+	for k, v := range r.tags {
+		m.tags[k] = v
+	}
+	for k, v := range other.tags {
+		m.tags[k] = v
+	}
+	if !other.time.IsZero() {
+		m.time = other.time
+	}
+	return m
+}
+
+// product combines every row with every other row.  Empty sets of rows are
+// ignored, as the values are missing from the document.
+func product(rows, others []row) []row {
+	if len(others) == 0 {
+		return rows
+	}
+	result := make([]row, 0, len(rows)*len(others))
+	for _, r := range rows {
+		for _, o := range others {
+			result = append(result, r.merge(o))
+		}
+	}
+	return result
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	buf = bytes.TrimSpace(buf)
+	buf = bytes.TrimPrefix(buf, utf8BOM)
+	if len(buf) == 0 {
+		return make([]telegraf.Metric, 0), nil
+	}
+	if !gjson.ValidBytes(buf) {
+		return nil, errors.New("invalid JSON")
+	}
+
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0)
+	for i := range p.configs {
+		m, err := p.parseConfig(&p.configs[i], buf, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: json_v2", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.defaultTags = tags
+}
+
+func (p *Parser) parseConfig(c *Config, buf []byte, now time.Time) ([]telegraf.Metric, error) {
+	name := p.metricName
+	if c.MeasurementName != "" {
+		name = c.MeasurementName
+	}
+	if c.MeasurementNamePath != "" {
+		if r := gjson.GetBytes(buf, c.MeasurementNamePath); r.Exists() && r.String() != "" {
+			name = r.String()
+		}
+	}
+
+	timestamp := now
+	if c.TimestampPath != "" {
+		r := gjson.GetBytes(buf, c.TimestampPath)
+		if !r.Exists() {
+			return nil, fmt.Errorf("timestamp path %q not found", c.TimestampPath)
+		}
+		var err error
+		timestamp, err = parseTimestamp(c.TimestampFormat, r, c.TimestampTimezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows := []row{{}}
+	for _, ds := range c.Fields {
+		values, err := dataSetRows(buf, ds, false)
+		if err != nil {
+			return nil, err
+		}
+		rows = product(rows, values)
+	}
+	for _, ds := range c.Tags {
+		values, err := dataSetRows(buf, ds, true)
+		if err != nil {
+			return nil, err
+		}
+		rows = product(rows, values)
+	}
+
+	var objects []row
+	for i := range c.Objects {
+		values, err := objectRows(buf, &c.Objects[i])
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, values...)
+	}
+	rows = product(rows, objects)
+
+	metrics := make([]telegraf.Metric, 0, len(rows))
+	for _, r := range rows {
+		if len(r.fields) == 0 {
+			continue
+		}
+
+		tags := make(map[string]string, len(p.defaultTags)+len(r.tags))
+		for k, v := range p.defaultTags {
+			tags[k] = v
+		}
+		for k, v := range r.tags {
+			tags[k] = v
+		}
+
+		fields := make(map[string]interface{}, len(r.fields))
+		for k, v := range r.fields {
+			if result, ok := v.(gjson.Result); ok {
+				value, err := convert(result, "")
+				if err != nil {
+					return nil, fmt.Errorf("field %q: %v", k, err)
+				}
+				v = value
+			}
+			fields[k] = v
+		}
+
+		t := timestamp
+		if !r.time.IsZero() {
+			t = r.time
+		}
+
+		m, err := metric.New(name, tags, fields, t)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// dataSetRows returns a row for each value selected by the path.
+func dataSetRows(buf []byte, ds DataSet, tag bool) ([]row, error) {
+	r := gjson.GetBytes(buf, ds.Path)
+	if !r.Exists() {
+		return nil, nil
+	}
+
+	name := ds.Rename
+	if name == "" {
+		name = pathName(ds.Path)
+	}
+
+	rows := flatten(name, r, false)
+	for _, row := range rows {
+		for k, f := range row.fields {
+			v := f.(gjson.Result)
+			if tag {
+				row.tags[k] = v.String()
+				delete(row.fields, k)
+				continue
+			}
+			if ds.Type == "" {
+				continue
+			}
+			value, err := convert(v, ds.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %v", k, err)
+			}
+			row.fields[k] = value
+		}
+	}
+	return rows, nil
+}
+
+// objectRows returns the rows of the objects selected by the path.
+func objectRows(buf []byte, o *Object) ([]row, error) {
+	r := gjson.GetBytes(buf, o.Path)
+	if !r.Exists() {
+		return nil, nil
+	}
+
+	var rows []row
+	if r.IsArray() {
+		for _, elem := range r.Array() {
+			if elem.IsObject() {
+				rows = append(rows, flatten("", elem, o.DisablePrependKeys)...)
+			}
+		}
+	} else if r.IsObject() {
+		rows = flatten("", r, o.DisablePrependKeys)
+	} else {
+		return nil, fmt.Errorf("object path %q must lead to an object or an array of objects", o.Path)
+	}
+
+	for i := range rows {
+		if err := o.apply(&rows[i]); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// apply sets the timestamp, filters the keys and sets the tags, types and
+// names of the row of an object.
+func (o *Object) apply(r *row) error {
+	if o.TimestampKey != "" {
+		v, ok := r.fields[o.TimestampKey]
+		if !ok {
+			return fmt.Errorf("timestamp key %q not found", o.TimestampKey)
+		}
+		t, err := parseTimestamp(o.TimestampFormat, v.(gjson.Result), o.TimestampTimezone)
+		if err != nil {
+			return err
+		}
+		r.time = t
+		delete(r.fields, o.TimestampKey)
+	}
+
+	for k := range r.fields {
+		if o.included != nil && !o.included.Match(k) {
+			delete(r.fields, k)
+		} else if o.excluded != nil && o.excluded.Match(k) {
+			delete(r.fields, k)
+		}
+	}
+
+	for _, k := range o.Tags {
+		v, ok := r.fields[k]
+		if !ok {
+			continue
+		}
+		r.tags[o.rename(k)] = v.(gjson.Result).String()
+		delete(r.fields, k)
+	}
+
+	fields := make(map[string]interface{}, len(r.fields))
+	for k, v := range r.fields {
+		if typ, ok := o.Fields[k]; ok {
+			value, err := convert(v.(gjson.Result), typ)
+			if err != nil {
+				return fmt.Errorf("field %q: %v", k, err)
+			}
+			v = value
+		}
+		fields[o.rename(k)] = v
+	}
+	r.fields = fields
+	return nil
+}
+
+func (o *Object) rename(key string) string {
+	if name, ok := o.Renames[key]; ok {
+		return name
+	}
+	return key
+}
+
+// flatten returns the rows of the value.  Objects are flattened into a
+// single row, the keys of nested objects are joined with an underscore unless
+// prepending is disabled.  Arrays return a row for each element, so that each
+// element of a nested array gets the other keys of its parents.
+func flatten(name string, r gjson.Result, disablePrepend bool) []row {
+	switch {
+	case r.IsArray():
+		var rows []row
+		for _, elem := range r.Array() {
+			rows = append(rows, flatten(name, elem, disablePrepend)...)
+		}
+		return rows
+	case r.IsObject():
+		rows := []row{{
+			fields: make(map[string]interface{}),
+			tags:   make(map[string]string),
+		}}
+		r.ForEach(func(key, value gjson.Result) bool {
+			k := key.String()
+			if name != "" && !disablePrepend {
+				k = name + "_" + k
+			}
+			rows = product(rows, flatten(k, value, disablePrepend))
+			return true
+		})
+		return rows
+	case r.Type == gjson.Null:
+		return nil
+	default:
+		return []row{{
+			fields: map[string]interface{}{name: r},
+			tags:   make(map[string]string),
+		}}
+	}
+}
+
+// pathName returns the last key of a GJSON path.
+func pathName(path string) string {
+	parts := strings.Split(path, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		part := parts[i]
+		if part == "" || strings.HasPrefix(part, "#") || strings.HasPrefix(part, "@") {
+			continue
+		}
+		if _, err := strconv.Atoi(part); err == nil {
+			continue
+		}
+		return part
+	}
+	return path
+}
+
+func checkType(typ string) error {
+	switch typ {
+	case "", "int", "uint", "float", "string", "bool":
+		return nil
+	default:
+		return fmt.Errorf("unknown type %q", typ)
+	}
+}
+
+// convert converts the value to the type.  Without a type numbers are floats,
+// strings and booleans keep their type.
+func convert(r gjson.Result, typ string) (interface{}, error) {
+	switch typ {
+	case "int":
+		switch r.Type {
+		case gjson.String:
+			return strconv.ParseInt(r.Str, 10, 64)
+		case gjson.Number:
+			return r.Int(), nil
+		case gjson.True:
+			return int64(1), nil
+		case gjson.False:
+			return int64(0), nil
+		}
+	case "uint":
+		switch r.Type {
+		case gjson.String:
+			return strconv.ParseUint(r.Str, 10, 64)
+		case gjson.Number:
+			if r.Num < 0 {
+				return nil, fmt.Errorf("cannot convert %s to uint", r.Raw)
+			}
+			return r.Uint(), nil
+		case gjson.True:
+			return uint64(1), nil
+		case gjson.False:
+			return uint64(0), nil
+		}
+	case "float":
+		switch r.Type {
+		case gjson.String:
+			return strconv.ParseFloat(r.Str, 64)
+		case gjson.Number:
+			return r.Num, nil
+		case gjson.True:
+			return float64(1), nil
+		case gjson.False:
+			return float64(0), nil
+		}
+	case "string":
+		return r.String(), nil
+	case "bool":
+		switch r.Type {
+		case gjson.String:
+			return strconv.ParseBool(r.Str)
+		case gjson.Number:
+			return r.Num != 0, nil
+		case gjson.True, gjson.False:
+			return r.Bool(), nil
+		}
+	default:
+		switch r.Type {
+		case gjson.String, gjson.Number, gjson.True, gjson.False:
+			return r.Value(), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %s to %q", r.Raw, typ)
+}
+
+// parseTimestamp parses the time of the value, numbers are parsed using
+// their text to keep their precision.
+func parseTimestamp(format string, r gjson.Result, timezone string) (time.Time, error) {
+	var value interface{} = r.String()
+	if r.Type == gjson.Number {
+		value = r.Raw
+	}
+	t, err := internal.ParseTimestamp(format, value, timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing timestamp %s: %v", r.Raw, err)
+	}
+	return t, nil
+}
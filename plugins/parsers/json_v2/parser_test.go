@@ -0,0 +1,200 @@
+package json_v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const booksJSON = `
+{
+  "library": "central",
+  "updated": "2021-03-04T05:06:07Z",
+  "books": [
+    {
+      "title": "The Hobbit",
+      "author": {"name": "Tolkien", "born": 1892},
+      "published": 1937,
+      "chapters": [{"number": 1, "pages": 30}, {"number": 2, "pages": 24}]
+    },
+    {
+      "title": "Dune",
+      "author": {"name": "Herbert", "born": 1920},
+      "published": 1965,
+      "chapters": [{"number": 1, "pages": 12}]
+    }
+  ],
+  "stats": {"visitors": "42", "open": true}
+}
+`
+
+func TestParseFieldsAndTags(t *testing.T) {
+	parser, err := NewParser("file", []Config{{
+		MeasurementName: "library",
+		TimestampPath:   "updated",
+		TimestampFormat: "2006-01-02T15:04:05Z07:00",
+		Fields: []DataSet{
+			{Path: "stats.visitors", Type: "int"},
+			{Path: "stats.open", Rename: "is_open"},
+		},
+		Tags: []DataSet{
+			{Path: "library", Rename: "name"},
+		},
+	}}, map[string]string{"host": "localhost"})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(booksJSON))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("library",
+			map[string]string{"host": "localhost", "name": "central"},
+			map[string]interface{}{"visitors": int64(42), "is_open": true},
+			time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseArrayPath(t *testing.T) {
+	parser, err := NewParser("books", []Config{{
+		Fields: []DataSet{
+			{Path: "books.#.published", Type: "int"},
+		},
+		Tags: []DataSet{
+			{Path: "library"},
+		},
+	}}, nil)
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(booksJSON))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("books",
+			map[string]string{"library": "central"},
+			map[string]interface{}{"published": int64(1937)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("books",
+			map[string]string{"library": "central"},
+			map[string]interface{}{"published": int64(1965)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
+}
+
+func TestParseObjects(t *testing.T) {
+	parser, err := NewParser("books", []Config{{
+		Tags: []DataSet{
+			{Path: "library"},
+		},
+		Objects: []Object{{
+			Path:         "books",
+			Tags:         []string{"title", "author_name"},
+			ExcludedKeys: []string{"author_born"},
+			Fields:       map[string]string{"published": "int", "chapters_number": "int", "chapters_pages": "int"},
+			Renames:      map[string]string{"author_name": "author", "chapters_number": "chapter"},
+		}},
+	}}, nil)
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(booksJSON))
+	require.NoError(t, err)
+
+	hobbit := map[string]string{"library": "central", "title": "The Hobbit", "author": "Tolkien"}
+	dune := map[string]string{"library": "central", "title": "Dune", "author": "Herbert"}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("books", hobbit,
+			map[string]interface{}{"published": int64(1937), "chapter": int64(1), "chapters_pages": int64(30)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("books", hobbit,
+			map[string]interface{}{"published": int64(1937), "chapter": int64(2), "chapters_pages": int64(24)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("books", dune,
+			map[string]interface{}{"published": int64(1965), "chapter": int64(1), "chapters_pages": int64(12)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
+}
+
+func TestParseObjectTimestampAndIncludedKeys(t *testing.T) {
+	parser, err := NewParser("events", []Config{{
+		Objects: []Object{{
+			Path:               "events",
+			TimestampKey:       "time",
+			TimestampFormat:    "unix_ms",
+			IncludedKeys:       []string{"time", "value"},
+			DisablePrependKeys: true,
+		}},
+	}}, nil)
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(`{"events": [
+		{"time": 1600000000123, "data": {"value": 1.5}, "ignored": 1},
+		{"time": 1600000001000, "data": {"value": 2}}
+	]}`))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("events", map[string]string{},
+			map[string]interface{}{"value": 1.5},
+			time.Unix(1600000000, 123000000)),
+		testutil.MustMetric("events", map[string]string{},
+			map[string]interface{}{"value": 2.0},
+			time.Unix(1600000001, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseMeasurementNamePath(t *testing.T) {
+	parser, err := NewParser("file", []Config{{
+		MeasurementNamePath: "library",
+		Fields:              []DataSet{{Path: "stats.visitors", Type: "float"}},
+	}}, nil)
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine(booksJSON)
+	require.NoError(t, err)
+	require.Equal(t, "central", m.Name())
+	require.Equal(t, map[string]interface{}{"visitors": 42.0}, m.Fields())
+}
+
+func TestParseErrors(t *testing.T) {
+	parser, err := NewParser("file", []Config{{
+		Fields: []DataSet{{Path: "stats.visitors", Type: "bool"}},
+	}}, nil)
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte(booksJSON))
+	require.Error(t, err)
+
+	_, err = parser.Parse([]byte(`{"stats":`))
+	require.Error(t, err)
+
+	metrics, err := parser.Parse([]byte(`{"other": 1}`))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestNewParserErrors(t *testing.T) {
+	for _, configs := range [][]Config{
+		nil,
+		{{TimestampPath: "time"}},
+		{{Fields: []DataSet{{Path: "a", Type: "integer"}}}},
+		{{Tags: []DataSet{{}}}},
+		{{Objects: []Object{{Path: "a", TimestampKey: "time"}}}},
+		{{Objects: []Object{{Path: "a", IncludedKeys: []string{"["}}}}},
+	} {
+		_, err := NewParser("file", configs, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestPathName(t *testing.T) {
+	require.Equal(t, "title", pathName("books.#.title"))
+	require.Equal(t, "books", pathName("books.#"))
+	require.Equal(t, "name", pathName("books.0.author.name"))
+	require.Equal(t, "value", pathName("value"))
+}
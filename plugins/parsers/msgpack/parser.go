@@ -0,0 +1,59 @@
+package msgpack
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/msgpack"
+)
+
+// Parser decodes the metrics written by the msgpack serializer.
+type Parser struct {
+	DefaultTags map[string]string
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	for len(buf) > 0 {
+		var m msgpack.Metric
+		var err error
+		buf, err = m.UnmarshalMsg(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		if m.Tags == nil {
+			m.Tags = make(map[string]string, len(p.DefaultTags))
+		}
+		for k, v := range p.DefaultTags {
+			if _, ok := m.Tags[k]; !ok {
+				m.Tags[k] = v
+			}
+		}
+
+		parsed, err := metric.New(m.Name, m.Tags, m.Fields, m.Time)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, parsed)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: msgpack", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
@@ -0,0 +1,61 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/msgpack"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var metrics = []telegraf.Metric{
+	testutil.MustMetric("cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"count":      int64(-3),
+			"total":      uint64(12),
+			"state":      "ok",
+			"active":     true,
+		},
+		time.Unix(1600000000, 123456789)),
+	testutil.MustMetric("mem",
+		map[string]string{},
+		map[string]interface{}{"used": int64(1024)},
+		time.Unix(1600000000, 0)),
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	buf, err := msgpack.NewSerializer().SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	parser := &Parser{}
+	parsed, err := parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, metrics, parsed)
+}
+
+func TestParseDefaultTags(t *testing.T) {
+	buf, err := msgpack.NewSerializer().Serialize(metrics[0])
+	require.NoError(t, err)
+
+	parser := &Parser{}
+	parser.SetDefaultTags(map[string]string{"host": "default", "region": "eu"})
+	m, err := parser.ParseLine(string(buf))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "a", "cpu": "cpu0", "region": "eu"}, m.Tags())
+}
+
+func TestParseInvalid(t *testing.T) {
+	buf, err := msgpack.NewSerializer().Serialize(metrics[0])
+	require.NoError(t, err)
+
+	parser := &Parser{}
+	_, err = parser.Parse(buf[:len(buf)-1])
+	require.Error(t, err)
+
+	_, err = parser.Parse([]byte("cpu value=1"))
+	require.Error(t, err)
+}
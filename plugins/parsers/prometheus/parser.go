@@ -20,10 +20,63 @@ import (
 	"github.com/prometheus/common/expfmt"
 )
 
+// Parser parses the Prometheus text exposition format, or the delimited
+// protocol buffer format when the header says so.
+type Parser struct {
+	// MetricVersion selects the mapping of Prometheus metrics to Telegraf
+	// metrics, see the metric_version option of the prometheus input.
+	MetricVersion int
+	// IgnoreTimestamp uses the time of parsing instead of the timestamps of
+	// the exposition.
+	IgnoreTimestamp bool
+	// Header is the header of the HTTP response holding the metrics.
+	Header      http.Header
+	DefaultTags map[string]string
+}
+
 // Parse returns a slice of Metrics from a text representation of a
 // metrics
-func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metricFamilies, err := p.metricFamilies(buf)
+	if err != nil {
+		return nil, err
+	}
+
 	var metrics []telegraf.Metric
+	if p.MetricVersion == 2 {
+		metrics = p.parseV2(metricFamilies)
+	} else {
+		metrics = p.parseV1(metricFamilies)
+	}
+
+	for _, m := range metrics {
+		for k, v := range p.DefaultTags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: prometheus", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) metricFamilies(buf []byte) (map[string]*dto.MetricFamily, error) {
 	var parser expfmt.TextParser
 	// parse even if the buffer begins with a newline
 	buf = bytes.TrimPrefix(buf, []byte("\n"))
@@ -31,7 +84,7 @@ func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 	buffer := bytes.NewBuffer(buf)
 	reader := bufio.NewReader(buffer)
 
-	mediatype, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	mediatype, params, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
 	// Prepare output
 	metricFamilies := make(map[string]*dto.MetricFamily)
 
@@ -54,6 +107,20 @@ func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 			return nil, fmt.Errorf("reading text format failed: %s", err)
 		}
 	}
+	return metricFamilies, nil
+}
+
+// timestamp returns the time of the metric, or now if the metric has none
+// or timestamps are ignored.
+func (p *Parser) timestamp(m *dto.Metric, now time.Time) time.Time {
+	if !p.IgnoreTimestamp && m.TimestampMs != nil && *m.TimestampMs > 0 {
+		return time.Unix(0, *m.TimestampMs*1000000)
+	}
+	return now
+}
+
+func (p *Parser) parseV2(metricFamilies map[string]*dto.MetricFamily) []telegraf.Metric {
+	var metrics []telegraf.Metric
 
 	// make sure all metrics have a consistent timestamp so that metrics don't straddle two different seconds
 	now := time.Now()
@@ -62,14 +129,15 @@ func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 		for _, m := range mf.Metric {
 			// reading tags
 			tags := makeLabels(m)
+			t := p.timestamp(m, now)
 
 			if mf.GetType() == dto.MetricType_SUMMARY {
 				// summary metric
-				telegrafMetrics := makeQuantilesV2(m, tags, metricName, mf.GetType(), now)
+				telegrafMetrics := makeQuantilesV2(m, tags, metricName, mf.GetType(), t)
 				metrics = append(metrics, telegrafMetrics...)
 			} else if mf.GetType() == dto.MetricType_HISTOGRAM {
 				// histogram metric
-				telegrafMetrics := makeBucketsV2(m, tags, metricName, mf.GetType(), now)
+				telegrafMetrics := makeBucketsV2(m, tags, metricName, mf.GetType(), t)
 				metrics = append(metrics, telegrafMetrics...)
 			} else {
 				// standard metric
@@ -77,12 +145,6 @@ func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 				fields := getNameAndValueV2(m, metricName)
 				// converting to telegraf metric
 				if len(fields) > 0 {
-					var t time.Time
-					if m.TimestampMs != nil && *m.TimestampMs > 0 {
-						t = time.Unix(0, *m.TimestampMs*1000000)
-					} else {
-						t = now
-					}
 					metric, err := metric.New("prometheus", tags, fields, t, valueType(mf.GetType()))
 					if err == nil {
 						metrics = append(metrics, metric)
@@ -92,19 +154,13 @@ func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 		}
 	}
 
-	return metrics, err
+	return metrics
 }
 
 // Get Quantiles for summary metric & Buckets for histogram
-func makeQuantilesV2(m *dto.Metric, tags map[string]string, metricName string, metricType dto.MetricType, now time.Time) []telegraf.Metric {
+func makeQuantilesV2(m *dto.Metric, tags map[string]string, metricName string, metricType dto.MetricType, t time.Time) []telegraf.Metric {
 	var metrics []telegraf.Metric
 	fields := make(map[string]interface{})
-	var t time.Time
-	if m.TimestampMs != nil && *m.TimestampMs > 0 {
-		t = time.Unix(0, *m.TimestampMs*1000000)
-	} else {
-		t = now
-	}
 	fields[metricName+"_count"] = float64(m.GetSummary().GetSampleCount())
 	fields[metricName+"_sum"] = float64(m.GetSummary().GetSampleSum())
 	met, err := metric.New("prometheus", tags, fields, t, valueType(metricType))
@@ -128,15 +184,9 @@ func makeQuantilesV2(m *dto.Metric, tags map[string]string, metricName string, m
 }
 
 // Get Buckets  from histogram metric
-func makeBucketsV2(m *dto.Metric, tags map[string]string, metricName string, metricType dto.MetricType, now time.Time) []telegraf.Metric {
+func makeBucketsV2(m *dto.Metric, tags map[string]string, metricName string, metricType dto.MetricType, t time.Time) []telegraf.Metric {
 	var metrics []telegraf.Metric
 	fields := make(map[string]interface{})
-	var t time.Time
-	if m.TimestampMs != nil && *m.TimestampMs > 0 {
-		t = time.Unix(0, *m.TimestampMs*1000000)
-	} else {
-		t = now
-	}
 	fields[metricName+"_count"] = float64(m.GetHistogram().GetSampleCount())
 	fields[metricName+"_sum"] = float64(m.GetHistogram().GetSampleSum())
 
@@ -159,40 +209,8 @@ func makeBucketsV2(m *dto.Metric, tags map[string]string, metricName string, met
 	return metrics
 }
 
-// Parse returns a slice of Metrics from a text representation of a
-// metrics
-func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
+func (p *Parser) parseV1(metricFamilies map[string]*dto.MetricFamily) []telegraf.Metric {
 	var metrics []telegraf.Metric
-	var parser expfmt.TextParser
-	// parse even if the buffer begins with a newline
-	buf = bytes.TrimPrefix(buf, []byte("\n"))
-	// Read raw data
-	buffer := bytes.NewBuffer(buf)
-	reader := bufio.NewReader(buffer)
-
-	mediatype, params, err := mime.ParseMediaType(header.Get("Content-Type"))
-	// Prepare output
-	metricFamilies := make(map[string]*dto.MetricFamily)
-
-	if err == nil && mediatype == "application/vnd.google.protobuf" &&
-		params["encoding"] == "delimited" &&
-		params["proto"] == "io.prometheus.client.MetricFamily" {
-		for {
-			mf := &dto.MetricFamily{}
-			if _, ierr := pbutil.ReadDelimited(reader, mf); ierr != nil {
-				if ierr == io.EOF {
-					break
-				}
-				return nil, fmt.Errorf("reading metric family protocol buffer failed: %s", ierr)
-			}
-			metricFamilies[mf.GetName()] = mf
-		}
-	} else {
-		metricFamilies, err = parser.TextToMetricFamilies(reader)
-		if err != nil {
-			return nil, fmt.Errorf("reading text format failed: %s", err)
-		}
-	}
 
 	// make sure all metrics have a consistent timestamp so that metrics don't straddle two different seconds
 	now := time.Now()
@@ -220,13 +238,7 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 			}
 			// converting to telegraf metric
 			if len(fields) > 0 {
-				var t time.Time
-				if m.TimestampMs != nil && *m.TimestampMs > 0 {
-					t = time.Unix(0, *m.TimestampMs*1000000)
-				} else {
-					t = now
-				}
-				metric, err := metric.New(metricName, tags, fields, t, valueType(mf.GetType()))
+				metric, err := metric.New(metricName, tags, fields, p.timestamp(m, now), valueType(mf.GetType()))
 				if err == nil {
 					metrics = append(metrics, metric)
 				}
@@ -234,7 +246,7 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 		}
 	}
 
-	return metrics, err
+	return metrics
 }
 
 func valueType(mt dto.MetricType) telegraf.ValueType {
@@ -1,10 +1,14 @@
 package prometheus
 
 import (
+	"bytes"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -102,8 +106,10 @@ cpu,host=foo,datacenter=us-east usage_idle=99,usage_busy=1
 `
 
 func TestParseValidPrometheus(t *testing.T) {
+	parser := Parser{}
+
 	// Gauge value
-	metrics, err := Parse([]byte(validUniqueGauge), http.Header{})
+	metrics, err := parser.Parse([]byte(validUniqueGauge))
 	assert.NoError(t, err)
 	assert.Len(t, metrics, 1)
 	assert.Equal(t, "cadvisor_version_info", metrics[0].Name())
@@ -119,7 +125,7 @@ func TestParseValidPrometheus(t *testing.T) {
 	}, metrics[0].Tags())
 
 	// Counter value
-	metrics, err = Parse([]byte(validUniqueCounter), http.Header{})
+	metrics, err = parser.Parse([]byte(validUniqueCounter))
 	assert.NoError(t, err)
 	assert.Len(t, metrics, 1)
 	assert.Equal(t, "get_token_fail_count", metrics[0].Name())
@@ -130,7 +136,7 @@ func TestParseValidPrometheus(t *testing.T) {
 
 	// Summary data
 	//SetDefaultTags(map[string]string{})
-	metrics, err = Parse([]byte(validUniqueSummary), http.Header{})
+	metrics, err = parser.Parse([]byte(validUniqueSummary))
 	assert.NoError(t, err)
 	assert.Len(t, metrics, 1)
 	assert.Equal(t, "http_request_duration_microseconds", metrics[0].Name())
@@ -144,7 +150,7 @@ func TestParseValidPrometheus(t *testing.T) {
 	assert.Equal(t, map[string]string{"handler": "prometheus"}, metrics[0].Tags())
 
 	// histogram data
-	metrics, err = Parse([]byte(validUniqueHistogram), http.Header{})
+	metrics, err = parser.Parse([]byte(validUniqueHistogram))
 	assert.NoError(t, err)
 	assert.Len(t, metrics, 1)
 	assert.Equal(t, "apiserver_request_latencies", metrics[0].Name())
@@ -165,3 +171,108 @@ func TestParseValidPrometheus(t *testing.T) {
 		metrics[0].Tags())
 
 }
+
+func TestParseMetricVersion2(t *testing.T) {
+	parser := Parser{MetricVersion: 2}
+
+	metrics, err := parser.Parse([]byte(validUniqueSummary))
+	assert.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("prometheus",
+			map[string]string{"handler": "prometheus"},
+			map[string]interface{}{
+				"http_request_duration_microseconds_count": 9.0,
+				"http_request_duration_microseconds_sum":   1.8909097205e+07,
+			},
+			time.Unix(0, 0), telegraf.Summary),
+		testutil.MustMetric("prometheus",
+			map[string]string{"handler": "prometheus", "quantile": "0.5"},
+			map[string]interface{}{"http_request_duration_microseconds": 552048.506},
+			time.Unix(0, 0), telegraf.Summary),
+		testutil.MustMetric("prometheus",
+			map[string]string{"handler": "prometheus", "quantile": "0.9"},
+			map[string]interface{}{"http_request_duration_microseconds": 5.876804288e+06},
+			time.Unix(0, 0), telegraf.Summary),
+		testutil.MustMetric("prometheus",
+			map[string]string{"handler": "prometheus", "quantile": "0.99"},
+			map[string]interface{}{"http_request_duration_microseconds": 5.876804288e+06},
+			time.Unix(0, 0), telegraf.Summary),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
+
+	metrics, err = parser.Parse([]byte(validUniqueCounter))
+	assert.NoError(t, err)
+	expected = []telegraf.Metric{
+		testutil.MustMetric("prometheus",
+			map[string]string{},
+			map[string]interface{}{"get_token_fail_count": 0.0},
+			time.Unix(0, 0), telegraf.Counter),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
+}
+
+const validTimestamped = `# TYPE node_textfile_mtime_seconds gauge
+node_textfile_mtime_seconds{file="backup.prom"} 1.6e+09 1600000000123
+node_textfile_mtime_seconds{file="other.prom"} 1.5e+09
+`
+
+func TestParseTimestamp(t *testing.T) {
+	parser := Parser{}
+	metrics, err := parser.Parse([]byte(validTimestamped))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	for _, m := range metrics {
+		if m.Tags()["file"] == "backup.prom" {
+			assert.Equal(t, time.Unix(1600000000, 123000000), m.Time())
+		} else {
+			assert.WithinDuration(t, time.Now(), m.Time(), time.Minute)
+		}
+	}
+
+	parser = Parser{IgnoreTimestamp: true}
+	metrics, err = parser.Parse([]byte(validTimestamped))
+	assert.NoError(t, err)
+	for _, m := range metrics {
+		assert.WithinDuration(t, time.Now(), m.Time(), time.Minute)
+	}
+}
+
+func TestParseDefaultTags(t *testing.T) {
+	parser := Parser{}
+	parser.SetDefaultTags(map[string]string{"file": "default", "host": "localhost"})
+
+	m, err := parser.ParseLine(validTimestamped)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", m.Tags()["host"])
+	assert.NotEqual(t, "default", m.Tags()["file"])
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := Parser{}
+	_, err := parser.Parse([]byte(prometheusMultiSomeInvalid))
+	assert.Error(t, err)
+
+	_, err = parser.ParseLine(validUniqueLine)
+	assert.Error(t, err)
+}
+
+func TestParseProtobuf(t *testing.T) {
+	families, err := (&Parser{}).metricFamilies([]byte(validUniqueCounter))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	for _, mf := range families {
+		_, err := pbutil.WriteDelimited(&buf, mf)
+		assert.NoError(t, err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited")
+	parser := Parser{Header: header}
+	metrics, err := parser.Parse(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "get_token_fail_count", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{"counter": 0.0}, metrics[0].Fields())
+}
@@ -0,0 +1,75 @@
+package prometheusremotewrite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/prompb"
+)
+
+// Parser parses snappy compressed remote write requests.  Metrics follow the
+// metric_version 2 conventions of the prometheus input: they are named
+// "prometheus" and have a field named after the series.
+type Parser struct {
+	DefaultTags map[string]string
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	req, err := prompb.Decode(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding remote write request failed: %s", err)
+	}
+
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0)
+	for _, ts := range req.Timeseries {
+		tags := make(map[string]string, len(p.DefaultTags)+len(ts.Labels))
+		for k, v := range p.DefaultTags {
+			tags[k] = v
+		}
+
+		var name string
+		for _, label := range ts.Labels {
+			if label.Name == prompb.MetricNameLabel {
+				name = label.Value
+				continue
+			}
+			tags[label.Name] = label.Value
+		}
+		if name == "" {
+			return nil, errors.New("series without metric name")
+		}
+
+		for _, sample := range ts.Samples {
+			// Stale markers are NaN, which cannot be written.
+			if math.IsNaN(sample.Value) {
+				continue
+			}
+
+			t := now
+			if sample.Timestamp > 0 {
+				t = time.Unix(0, sample.Timestamp*int64(time.Millisecond))
+			}
+
+			fields := map[string]interface{}{name: sample.Value}
+			m, err := metric.New("prometheus", tags, fields, t)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	return nil, errors.New("line parsing is not supported by the prometheusremotewrite data format")
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
@@ -0,0 +1,109 @@
+package prometheusremotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/prompb"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheusremotewrite"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	buf, err := prompb.Encode(&prompb.WriteRequest{
+		Timeseries: []*prompb.TimeSeries{
+			{
+				Labels: []*prompb.Label{
+					{Name: "__name__", Value: "go_gc_duration_seconds"},
+					{Name: "quantile", Value: "0.99"},
+				},
+				Samples: []*prompb.Sample{
+					{Value: 4.63, Timestamp: 1614889298859},
+					{Value: math.NaN(), Timestamp: 1614889299859},
+				},
+			},
+			{
+				Labels: []*prompb.Label{
+					{Name: "__name__", Value: "prometheus_target_interval_length_seconds"},
+					{Name: "job", Value: "prometheus"},
+				},
+				Samples: []*prompb.Sample{
+					{Value: 14.99, Timestamp: 1614889298859},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	parser := Parser{DefaultTags: map[string]string{"source": "remote", "job": "default"}}
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"quantile": "0.99", "source": "remote", "job": "default"},
+			map[string]interface{}{"go_gc_duration_seconds": 4.63},
+			time.Unix(0, 1614889298859*int64(time.Millisecond)),
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"job": "prometheus", "source": "remote"},
+			map[string]interface{}{"prometheus_target_interval_length_seconds": 14.99},
+			time.Unix(0, 1614889298859*int64(time.Millisecond)),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseErrors(t *testing.T) {
+	parser := Parser{}
+
+	_, err := parser.Parse([]byte("not snappy"))
+	require.Error(t, err)
+
+	buf, err := prompb.Encode(&prompb.WriteRequest{
+		Timeseries: []*prompb.TimeSeries{{
+			Labels:  []*prompb.Label{{Name: "job", Value: "prometheus"}},
+			Samples: []*prompb.Sample{{Value: 1}},
+		}},
+	})
+	require.NoError(t, err)
+	_, err = parser.Parse(buf)
+	require.Error(t, err)
+
+	_, err = parser.ParseLine("go_gc_duration_seconds 1")
+	require.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"code": "400", "method": "post"},
+			map[string]interface{}{"http_requests_total": 3.0},
+			time.Unix(1614889298, 0),
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"le": "0.05"},
+			map[string]interface{}{"http_request_duration_seconds_bucket": 24054.0},
+			time.Unix(1614889298, 0),
+		),
+	}
+
+	serializer, err := prometheusremotewrite.NewSerializer(prometheus.FormatConfig{})
+	require.NoError(t, err)
+	buf, err := serializer.SerializeBatch(input)
+	require.NoError(t, err)
+
+	parser := Parser{}
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	testutil.RequireMetricsEqual(t, input, metrics, testutil.SortMetrics())
+}
@@ -0,0 +1,213 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/internal/record"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+// Config is the configuration of the protobuf data format.  The messages are
+// of the MessageType defined in the .proto File.  With ConfluentWireFormat the
+// messages start with the header of the Confluent schema registry serializer,
+// which is skipped.
+type Config struct {
+	MetricName          string
+	File                string
+	ImportPaths         []string
+	MessageType         string
+	ConfluentWireFormat bool
+
+	Measurement      string
+	MeasurementField string
+	Tags             []string
+	Fields           []string
+	Timestamp        string
+	TimestampFormat  string
+
+	DefaultTags map[string]string
+}
+
+type Parser struct {
+	record     record.Config
+	descriptor *desc.MessageDescriptor
+	confluent  bool
+}
+
+func NewParser(config *Config) (*Parser, error) {
+	if config.File == "" {
+		return nil, errors.New("protobuf_file must be set")
+	}
+	if config.MessageType == "" {
+		return nil, errors.New("protobuf_message_type must be set")
+	}
+
+	// Files are looked up relative to the import paths, the directory of the
+	// file is one of them.
+	importPaths := append([]string{filepath.Dir(config.File)}, config.ImportPaths...)
+	parser := protoparse.Parser{ImportPaths: importPaths}
+	files, err := parser.ParseFiles(filepath.Base(config.File))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", config.File, err)
+	}
+
+	p := &Parser{
+		record: record.Config{
+			Measurement:      config.Measurement,
+			MeasurementField: config.MeasurementField,
+			Tags:             config.Tags,
+			Fields:           config.Fields,
+			Timestamp:        config.Timestamp,
+			TimestampFormat:  config.TimestampFormat,
+			DefaultTags:      config.DefaultTags,
+		},
+		confluent: config.ConfluentWireFormat,
+	}
+	if p.record.Measurement == "" {
+		p.record.Measurement = config.MetricName
+	}
+
+	for _, fd := range files {
+		if p.descriptor = fd.FindMessage(config.MessageType); p.descriptor != nil {
+			break
+		}
+	}
+	if p.descriptor == nil {
+		return nil, fmt.Errorf("message type %s not found in %s", config.MessageType, config.File)
+	}
+	return p, nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if p.confluent {
+		var err error
+		if buf, err = stripConfluentHeader(buf); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := dynamic.NewMessage(p.descriptor)
+	if err := msg.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	flattenMessage("", msg, values)
+	m, err := p.record.Metric(values, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return []telegraf.Metric{m}, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: protobuf", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.record.DefaultTags = tags
+}
+
+// stripConfluentHeader returns the message following the header of the
+// Confluent wire format: a zero magic byte, the 4 byte schema ID and the
+// indexes of the message type in the schema, as a varint count followed by
+// the varint indexes.  The message is always decoded as the configured
+// message type.
+func stripConfluentHeader(buf []byte) ([]byte, error) {
+	if len(buf) < 5 || buf[0] != 0 {
+		return nil, errors.New("message is not in the Confluent wire format")
+	}
+	buf = buf[5:]
+
+	count, n := binary.Varint(buf)
+	if n <= 0 || count < 0 {
+		return nil, errors.New("invalid message indexes in the Confluent header")
+	}
+	buf = buf[n:]
+	for i := int64(0); i < count; i++ {
+		if _, n = binary.Varint(buf); n <= 0 {
+			return nil, errors.New("invalid message indexes in the Confluent header")
+		}
+		buf = buf[n:]
+	}
+	return buf, nil
+}
+
+// flattenMessage stores the values of the fields of the message, the names
+// of nested values are joined with an underscore.  Unset message fields are
+// left out, google.protobuf.Timestamp messages are times.
+func flattenMessage(prefix string, msg *dynamic.Message, values map[string]interface{}) {
+	md := msg.GetMessageDescriptor()
+	if md.GetFullyQualifiedName() == "google.protobuf.Timestamp" {
+		seconds, _ := msg.GetFieldByName("seconds").(int64)
+		nanos, _ := msg.GetFieldByName("nanos").(int32)
+		values[prefix] = time.Unix(seconds, int64(nanos)).UTC()
+		return
+	}
+
+	for _, fd := range md.GetFields() {
+		if fd.GetMessageType() != nil && !fd.IsRepeated() && !msg.HasField(fd) {
+			continue
+		}
+
+		name := record.Join(prefix, fd.GetName())
+		switch v := msg.GetField(fd).(type) {
+		case map[interface{}]interface{}:
+			for key, elem := range v {
+				flattenValue(record.Join(name, fmt.Sprint(key)), fd.GetMapValueType(), elem, values)
+			}
+		case []interface{}:
+			for i, elem := range v {
+				flattenValue(record.Join(name, fmt.Sprint(i)), fd, elem, values)
+			}
+		default:
+			flattenValue(name, fd, v, values)
+		}
+	}
+}
+
+func flattenValue(name string, fd *desc.FieldDescriptor, value interface{}, values map[string]interface{}) {
+	switch v := value.(type) {
+	case *dynamic.Message:
+		flattenMessage(name, v, values)
+	case proto.Message:
+		// Well-known types are decoded to their generated types.
+		msg, err := dynamic.AsDynamicMessage(v)
+		if err == nil {
+			flattenMessage(name, msg, values)
+		}
+	case int32:
+		if et := fd.GetEnumType(); et != nil {
+			if ev := et.FindValueByNumber(v); ev != nil {
+				values[name] = ev.GetName()
+				return
+			}
+		}
+		values[name] = int64(v)
+	case uint32:
+		values[name] = uint64(v)
+	case float32:
+		values[name] = float64(v)
+	case []byte:
+		values[name] = string(v)
+	default:
+		values[name] = v
+	}
+}
@@ -0,0 +1,176 @@
+package protobuf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, parser *Parser, set func(msg *dynamic.Message)) []byte {
+	msg := dynamic.NewMessage(parser.descriptor)
+	set(msg)
+	buf, err := msg.Marshal()
+	require.NoError(t, err)
+	return buf
+}
+
+func reading(msg *dynamic.Message) {
+	md := msg.GetMessageDescriptor()
+
+	ts := dynamic.NewMessage(md.FindFieldByName("time").GetMessageType())
+	ts.SetFieldByName("seconds", int64(1600000000))
+	ts.SetFieldByName("nanos", int32(123000000))
+
+	location := dynamic.NewMessage(md.FindFieldByName("location").GetMessageType())
+	location.SetFieldByName("site", "north")
+	location.SetFieldByName("floor", int32(2))
+
+	msg.SetFieldByName("sensor", "s1")
+	msg.SetFieldByName("time", ts)
+	msg.SetFieldByName("temperature", 21.5)
+	msg.SetFieldByName("humidity", float32(0.5))
+	msg.SetFieldByName("location", location)
+	msg.SetFieldByName("state", int32(1))
+	msg.SetFieldByName("samples", []uint32{3, 4})
+	msg.PutMapFieldByName("labels", "rack", "r1")
+	msg.SetFieldByName("time_ms", int64(1600000000500))
+}
+
+func TestParse(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:  "protobuf",
+		File:        "testdata/reading.proto",
+		MessageType: "example.Reading",
+		Measurement: "reading",
+		Tags:        []string{"sensor", "location_site", "labels_rack"},
+		Timestamp:   "time",
+		DefaultTags: map[string]string{"source": "kafka"},
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(encode(t, parser, reading))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("reading",
+			map[string]string{"source": "kafka", "sensor": "s1", "location_site": "north", "labels_rack": "r1"},
+			map[string]interface{}{
+				"temperature":    21.5,
+				"humidity":       0.5,
+				"location_floor": int64(2),
+				"state":          "FAULT",
+				"samples_0":      uint64(3),
+				"samples_1":      uint64(4),
+				"time_ms":        int64(1600000000500),
+			},
+			time.Unix(1600000000, 123000000)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseFieldsAndTimestampFormat(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:       "protobuf",
+		File:             "testdata/reading.proto",
+		MessageType:      "example.Reading",
+		MeasurementField: "sensor",
+		Fields:           []string{"temperature", "time"},
+		Timestamp:        "time_ms",
+		TimestampFormat:  "unix_ms",
+	})
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine(string(encode(t, parser, reading)))
+	require.NoError(t, err)
+	require.Equal(t, "s1", m.Name())
+	require.Equal(t, map[string]interface{}{
+		"temperature": 21.5,
+		"time":        "2020-09-13T12:26:40.123Z",
+	}, m.Fields())
+	require.Empty(t, m.Tags())
+	require.True(t, time.Unix(1600000000, 500000000).Equal(m.Time()))
+}
+
+func TestParseUnsetMessage(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:  "protobuf",
+		File:        "testdata/reading.proto",
+		MessageType: "example.Reading",
+	})
+	require.NoError(t, err)
+
+	buf := encode(t, parser, func(msg *dynamic.Message) {
+		msg.SetFieldByName("temperature", 21.5)
+	})
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	fields := metrics[0].Fields()
+	require.NotContains(t, fields, "time")
+	require.NotContains(t, fields, "location_site")
+	require.Equal(t, "OK", fields["state"])
+	require.Equal(t, 21.5, fields["temperature"])
+}
+
+func TestParseConfluentWireFormat(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:          "protobuf",
+		File:                "testdata/reading.proto",
+		MessageType:         "example.Reading",
+		ConfluentWireFormat: true,
+	})
+	require.NoError(t, err)
+
+	msg := encode(t, parser, func(msg *dynamic.Message) {
+		msg.SetFieldByName("temperature", 21.5)
+	})
+	headers := [][]byte{
+		// Schema ID 42, message indexes [0] in their short form.
+		{0, 0, 0, 0, 42, 0},
+		// Schema ID 42, message indexes [1, 0] as zigzag varints.
+		{0, 0, 0, 0, 42, 4, 2, 0},
+	}
+	for _, header := range headers {
+		metrics, err := parser.Parse(append(header, msg...))
+		require.NoError(t, err)
+		require.Len(t, metrics, 1)
+		require.Equal(t, 21.5, metrics[0].Fields()["temperature"])
+	}
+
+	_, err = parser.Parse(msg)
+	require.Error(t, err)
+
+	_, err = parser.Parse([]byte{0, 0, 0, 0, 42, 4, 2})
+	require.Error(t, err)
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser, err := NewParser(&Config{
+		MetricName:  "protobuf",
+		File:        "testdata/reading.proto",
+		MessageType: "example.Reading",
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte{0xff, 0xff, 0xff})
+	require.Error(t, err)
+}
+
+func TestNewParserErrors(t *testing.T) {
+	_, err := NewParser(&Config{MessageType: "example.Reading"})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{File: "testdata/reading.proto"})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{File: "testdata/missing.proto", MessageType: "example.Reading"})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{File: "testdata/reading.proto", MessageType: "example.Missing"})
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/avro"
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/dropwizard"
@@ -12,10 +13,16 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/grok"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
+	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
 	"github.com/influxdata/telegraf/plugins/parsers/logfmt"
+	"github.com/influxdata/telegraf/plugins/parsers/msgpack"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheusremotewrite"
+	"github.com/influxdata/telegraf/plugins/parsers/protobuf"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	"github.com/influxdata/telegraf/plugins/parsers/wavefront"
+	"github.com/influxdata/telegraf/plugins/parsers/xml"
 )
 
 type ParserFunc func() (Parser, error)
@@ -62,6 +69,13 @@ type Config struct {
 	// Dataformat can be one of: json, influx, graphite, value, nagios
 	DataFormat string `toml:"data_format"`
 
+	// ParseErrorMode selects what happens to data the parser fails on, one of
+	// drop, emit_raw or route.  Unset is drop.
+	ParseErrorMode string `toml:"parse_error_mode"`
+
+	// Alias is the alias of the input, it tags the parse error counter.
+	Alias string `toml:"alias"`
+
 	// Separator only applied to Graphite data.
 	Separator string `toml:"separator"`
 	// Templates only apply to Graphite data.
@@ -91,6 +105,12 @@ type Config struct {
 	// Whether to continue if a JSON object can't be coerced
 	JSONStrict bool `toml:"json_strict"`
 
+	// JSONV2Config holds the [[json_v2]] blocks of the json_v2 parser
+	JSONV2Config []json_v2.Config `toml:"json_v2"`
+
+	// XMLConfig holds the [[xml]] blocks of the xml parser
+	XMLConfig []xml.Config `toml:"xml"`
+
 	// Authentication file for collectd
 	CollectdAuthFile string `toml:"collectd_auth_file"`
 	// One of none (default), sign, or encrypt
@@ -148,6 +168,32 @@ type Config struct {
 
 	// FormData configuration
 	FormUrlencodedTagKeys []string `toml:"form_urlencoded_tag_keys"`
+
+	// Prometheus configuration
+	PrometheusMetricVersion   int  `toml:"prometheus_metric_version"`
+	PrometheusIgnoreTimestamp bool `toml:"prometheus_ignore_timestamp"`
+
+	// Avro configuration
+	AvroSchemaFile       string   `toml:"avro_schema_file"`
+	AvroSchemaRegistry   string   `toml:"avro_schema_registry"`
+	AvroMeasurement      string   `toml:"avro_measurement"`
+	AvroMeasurementField string   `toml:"avro_measurement_field"`
+	AvroTags             []string `toml:"avro_tags"`
+	AvroFields           []string `toml:"avro_fields"`
+	AvroTimestamp        string   `toml:"avro_timestamp"`
+	AvroTimestampFormat  string   `toml:"avro_timestamp_format"`
+
+	// Protocol Buffers configuration
+	ProtobufFile             string   `toml:"protobuf_file"`
+	ProtobufImportPaths      []string `toml:"protobuf_import_paths"`
+	ProtobufMessageType      string   `toml:"protobuf_message_type"`
+	ProtobufConfluent        bool     `toml:"protobuf_confluent_wire_format"`
+	ProtobufMeasurement      string   `toml:"protobuf_measurement"`
+	ProtobufMeasurementField string   `toml:"protobuf_measurement_field"`
+	ProtobufTags             []string `toml:"protobuf_tags"`
+	ProtobufFields           []string `toml:"protobuf_fields"`
+	ProtobufTimestamp        string   `toml:"protobuf_timestamp"`
+	ProtobufTimestampFormat  string   `toml:"protobuf_timestamp_format"`
 }
 
 // NewParser returns a Parser interface based on the given config.
@@ -170,6 +216,12 @@ func NewParser(config *Config) (Parser, error) {
 				Strict:       config.JSONStrict,
 			},
 		)
+	case "json_v2":
+		parser, err = json_v2.NewParser(config.MetricName,
+			config.JSONV2Config, config.DefaultTags)
+	case "xml":
+		parser, err = xml.NewParser(config.MetricName,
+			config.XMLConfig, config.DefaultTags)
 	case "value":
 		parser, err = NewValueParser(config.MetricName,
 			config.DataType, config.DefaultTags)
@@ -232,10 +284,51 @@ func NewParser(config *Config) (Parser, error) {
 			config.DefaultTags,
 			config.FormUrlencodedTagKeys,
 		)
+	case "prometheus":
+		parser, err = NewPrometheusParser(
+			config.PrometheusMetricVersion,
+			config.PrometheusIgnoreTimestamp,
+			config.DefaultTags,
+		)
+	case "prometheusremotewrite":
+		parser, err = NewPrometheusRemoteWriteParser(config.DefaultTags)
+	case "msgpack":
+		parser, err = NewMsgpackParser(config.DefaultTags)
+	case "avro":
+		parser, err = avro.NewParser(&avro.Config{
+			MetricName:       config.MetricName,
+			SchemaFile:       config.AvroSchemaFile,
+			SchemaRegistry:   config.AvroSchemaRegistry,
+			Measurement:      config.AvroMeasurement,
+			MeasurementField: config.AvroMeasurementField,
+			Tags:             config.AvroTags,
+			Fields:           config.AvroFields,
+			Timestamp:        config.AvroTimestamp,
+			TimestampFormat:  config.AvroTimestampFormat,
+			DefaultTags:      config.DefaultTags,
+		})
+	case "protobuf":
+		parser, err = protobuf.NewParser(&protobuf.Config{
+			MetricName:          config.MetricName,
+			File:                config.ProtobufFile,
+			ImportPaths:         config.ProtobufImportPaths,
+			MessageType:         config.ProtobufMessageType,
+			ConfluentWireFormat: config.ProtobufConfluent,
+			Measurement:         config.ProtobufMeasurement,
+			MeasurementField:    config.ProtobufMeasurementField,
+			Tags:                config.ProtobufTags,
+			Fields:              config.ProtobufFields,
+			Timestamp:           config.ProtobufTimestamp,
+			TimestampFormat:     config.ProtobufTimestampFormat,
+			DefaultTags:         config.DefaultTags,
+		})
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
-	return parser, err
+	if err != nil {
+		return parser, err
+	}
+	return newErrorModeParser(parser, config)
 }
 
 func newGrokParser(metricName string,
@@ -339,3 +432,32 @@ func NewFormUrlencodedParser(
 		TagKeys:     tagKeys,
 	}, nil
 }
+
+func NewPrometheusParser(
+	metricVersion int,
+	ignoreTimestamp bool,
+	defaultTags map[string]string,
+) (Parser, error) {
+	switch metricVersion {
+	case 0, 1, 2:
+	default:
+		return nil, fmt.Errorf("invalid prometheus_metric_version: %d", metricVersion)
+	}
+	return &prometheus.Parser{
+		MetricVersion:   metricVersion,
+		IgnoreTimestamp: ignoreTimestamp,
+		DefaultTags:     defaultTags,
+	}, nil
+}
+
+func NewPrometheusRemoteWriteParser(defaultTags map[string]string) (Parser, error) {
+	return &prometheusremotewrite.Parser{
+		DefaultTags: defaultTags,
+	}, nil
+}
+
+func NewMsgpackParser(defaultTags map[string]string) (Parser, error) {
+	return &msgpack.Parser{
+		DefaultTags: defaultTags,
+	}, nil
+}
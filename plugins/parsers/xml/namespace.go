@@ -0,0 +1,37 @@
+package xml
+
+import (
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+)
+
+// navigator replaces the prefixes of the documents by the prefixes of the
+// configured namespaces, so that expressions select nodes by namespace URI
+// whatever the prefix, or lack of, used by the document.
+type navigator struct {
+	*xmlquery.NodeNavigator
+	prefixes map[string]string
+}
+
+func (n *navigator) Prefix() string {
+	if uri := n.NamespaceURL(); uri != "" {
+		if prefix, ok := n.prefixes[uri]; ok {
+			return prefix
+		}
+	}
+	return n.NodeNavigator.Prefix()
+}
+
+func (n *navigator) Copy() xpath.NodeNavigator {
+	return &navigator{
+		NodeNavigator: n.NodeNavigator.Copy().(*xmlquery.NodeNavigator),
+		prefixes:      n.prefixes,
+	}
+}
+
+func (n *navigator) MoveTo(other xpath.NodeNavigator) bool {
+	if o, ok := other.(*navigator); ok {
+		other = o.NodeNavigator
+	}
+	return n.NodeNavigator.MoveTo(other)
+}
@@ -0,0 +1,339 @@
+package xml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Config is a [[xml]] block.  Each node selected by the metric selection
+// creates a metric, the name, timestamp, tags and fields of the metric are
+// XPath expressions evaluated relative to the node.
+type Config struct {
+	MetricName        string            `toml:"metric_name"`
+	Selection         string            `toml:"metric_selection"`
+	Timestamp         string            `toml:"timestamp"`
+	TimestampFormat   string            `toml:"timestamp_format"`
+	TimestampTimezone string            `toml:"timestamp_timezone"`
+	Tags              map[string]string `toml:"tags"`
+	Fields            map[string]string `toml:"fields"`
+	FieldTypes        map[string]string `toml:"field_types"`
+
+	// Namespaces maps the prefixes used in the expressions to namespace
+	// URIs, independently of the prefixes used by the documents.
+	Namespaces map[string]string `toml:"namespaces"`
+
+	name      *xpath.Expr
+	selection *xpath.Expr
+	timestamp *xpath.Expr
+	tags      map[string]*xpath.Expr
+	fields    map[string]*xpath.Expr
+	prefixes  map[string]string
+}
+
+type Parser struct {
+	configs     []Config
+	metricName  string
+	defaultTags map[string]string
+}
+
+// NewParser returns a parser for the blocks.  Metrics are named after the
+// metric name unless the block sets a metric name expression.
+func NewParser(metricName string, configs []Config, defaultTags map[string]string) (*Parser, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("no xml blocks configured")
+	}
+
+	for i := range configs {
+		if err := configs[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Parser{
+		configs:     configs,
+		metricName:  metricName,
+		defaultTags: defaultTags,
+	}, nil
+}
+
+func (c *Config) compile() error {
+	var err error
+	if c.MetricName != "" {
+		if c.name, err = compile("metric_name", c.MetricName); err != nil {
+			return err
+		}
+	}
+
+	selection := c.Selection
+	if selection == "" {
+		selection = "/"
+	}
+	if c.selection, err = compile("metric_selection", selection); err != nil {
+		return err
+	}
+
+	if c.Timestamp != "" {
+		if c.TimestampFormat == "" {
+			return errors.New("use of 'timestamp' requires 'timestamp_format'")
+		}
+		if c.timestamp, err = compile("timestamp", c.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	c.tags = make(map[string]*xpath.Expr, len(c.Tags))
+	for name, query := range c.Tags {
+		if c.tags[name], err = compile("tag "+name, query); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Fields) == 0 {
+		return errors.New("no fields configured")
+	}
+	c.fields = make(map[string]*xpath.Expr, len(c.Fields))
+	for name, query := range c.Fields {
+		if c.fields[name], err = compile("field "+name, query); err != nil {
+			return err
+		}
+	}
+	for name, typ := range c.FieldTypes {
+		if _, ok := c.Fields[name]; !ok {
+			return fmt.Errorf("type of unknown field %q", name)
+		}
+		switch typ {
+		case "int", "uint", "float", "string", "bool":
+		default:
+			return fmt.Errorf("unknown type %q of field %q", typ, name)
+		}
+	}
+
+	c.prefixes = make(map[string]string, len(c.Namespaces))
+	for prefix, uri := range c.Namespaces {
+		if prefix == "" || uri == "" {
+			return errors.New("namespaces require a prefix and a URI")
+		}
+		c.prefixes[uri] = prefix
+	}
+	return nil
+}
+
+func compile(option, query string) (*xpath.Expr, error) {
+	expr, err := xpath.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("compiling %s %q: %v", option, query, err)
+	}
+	return expr, nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if len(bytes.TrimSpace(buf)) == 0 {
+		return make([]telegraf.Metric, 0), nil
+	}
+
+	doc, err := xmlquery.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0)
+	for i := range p.configs {
+		m, err := p.parseConfig(&p.configs[i], doc, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: xml", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.defaultTags = tags
+}
+
+func (p *Parser) parseConfig(c *Config, doc *xmlquery.Node, now time.Time) ([]telegraf.Metric, error) {
+	root := &navigator{
+		NodeNavigator: xmlquery.CreateXPathNavigator(doc),
+		prefixes:      c.prefixes,
+	}
+
+	metrics := make([]telegraf.Metric, 0)
+	it := c.selection.Select(root)
+	for it.MoveNext() {
+		m, err := p.parseNode(c, it.Current(), now)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+// parseNode returns the metric of a selected node, or nil when none of the
+// fields are found.
+func (p *Parser) parseNode(c *Config, node xpath.NodeNavigator, now time.Time) (telegraf.Metric, error) {
+	name := p.metricName
+	if c.name != nil {
+		if v, ok := evaluate(c.name, node); ok {
+			if s := toString(v); s != "" {
+				name = s
+			}
+		}
+	}
+
+	timestamp := now
+	if c.timestamp != nil {
+		v, ok := evaluate(c.timestamp, node)
+		if !ok {
+			return nil, fmt.Errorf("timestamp %q not found", c.Timestamp)
+		}
+		var err error
+		timestamp, err = internal.ParseTimestamp(c.TimestampFormat, toString(v), c.TimestampTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("parsing timestamp %v: %v", v, err)
+		}
+	}
+
+	tags := make(map[string]string, len(p.defaultTags)+len(c.tags))
+	for k, v := range p.defaultTags {
+		tags[k] = v
+	}
+	for k, expr := range c.tags {
+		if v, ok := evaluate(expr, node); ok {
+			tags[k] = toString(v)
+		}
+	}
+
+	fields := make(map[string]interface{}, len(c.fields))
+	for k, expr := range c.fields {
+		v, ok := evaluate(expr, node)
+		if !ok {
+			continue
+		}
+		if typ, ok := c.FieldTypes[k]; ok {
+			value, err := convert(v, typ)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %v", k, err)
+			}
+			v = value
+		}
+		fields[k] = v
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return metric.New(name, tags, fields, timestamp)
+}
+
+// evaluate returns the result of the expression evaluated on the node.  It is
+// a float, a boolean or a string, the value of the first node for node sets.
+// Empty node sets are not found.
+func evaluate(expr *xpath.Expr, node xpath.NodeNavigator) (interface{}, bool) {
+	switch v := expr.Evaluate(node.Copy()).(type) {
+	case *xpath.NodeIterator:
+		if !v.MoveNext() {
+			return nil, false
+		}
+		return v.Current().Value(), true
+	case float64, bool, string:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// convert converts the result of an expression to the type.
+func convert(v interface{}, typ string) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		switch typ {
+		case "int":
+			return strconv.ParseInt(v, 10, 64)
+		case "uint":
+			return strconv.ParseUint(v, 10, 64)
+		case "float":
+			return strconv.ParseFloat(v, 64)
+		case "bool":
+			return strconv.ParseBool(v)
+		case "string":
+			return v, nil
+		}
+	case float64:
+		switch typ {
+		case "int":
+			return int64(v), nil
+		case "uint":
+			if v < 0 {
+				return nil, fmt.Errorf("cannot convert %v to uint", v)
+			}
+			return uint64(v), nil
+		case "float":
+			return v, nil
+		case "bool":
+			return v != 0, nil
+		case "string":
+			return toString(v), nil
+		}
+	case bool:
+		switch typ {
+		case "int":
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case "uint":
+			if v {
+				return uint64(1), nil
+			}
+			return uint64(0), nil
+		case "float":
+			if v {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		case "bool":
+			return v, nil
+		case "string":
+			return toString(v), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %v to %q", v, typ)
+}
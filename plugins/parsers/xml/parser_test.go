@@ -0,0 +1,185 @@
+package xml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const statusXML = `<?xml version="1.0" encoding="UTF-8"?>
+<status updated="2021-03-04T05:06:07Z">
+  <host name="alpha" region="eu">
+    <load>0.5</load>
+    <sessions>12</sessions>
+    <up>true</up>
+  </host>
+  <host name="beta" region="us">
+    <load>1.25</load>
+    <sessions>3</sessions>
+    <up>false</up>
+  </host>
+  <host name="gamma"/>
+</status>
+`
+
+func TestParseSelection(t *testing.T) {
+	parser, err := NewParser("status", []Config{{
+		Selection:       "/status/host",
+		Timestamp:       "/status/@updated",
+		TimestampFormat: "2006-01-02T15:04:05Z07:00",
+		Tags: map[string]string{
+			"name":   "@name",
+			"region": "@region",
+		},
+		Fields: map[string]string{
+			"load":     "load",
+			"sessions": "sessions",
+			"up":       "up",
+		},
+		FieldTypes: map[string]string{
+			"load":     "float",
+			"sessions": "int",
+			"up":       "bool",
+		},
+	}}, map[string]string{"source": "test"})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(statusXML))
+	require.NoError(t, err)
+
+	updated := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	expected := []telegraf.Metric{
+		testutil.MustMetric("status",
+			map[string]string{"source": "test", "name": "alpha", "region": "eu"},
+			map[string]interface{}{"load": 0.5, "sessions": int64(12), "up": true},
+			updated),
+		testutil.MustMetric("status",
+			map[string]string{"source": "test", "name": "beta", "region": "us"},
+			map[string]interface{}{"load": 1.25, "sessions": int64(3), "up": false},
+			updated),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseExpressions(t *testing.T) {
+	parser, err := NewParser("status", []Config{{
+		MetricName: "name(/*)",
+		Fields: map[string]string{
+			"hosts":    "count(//host)",
+			"sessions": "sum(//sessions)",
+			"any_up":   "//up = 'true'",
+			"first":    "string(//host/@name)",
+		},
+		FieldTypes: map[string]string{
+			"hosts": "int",
+		},
+	}}, nil)
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine(statusXML)
+	require.NoError(t, err)
+	require.Equal(t, "status", m.Name())
+	require.Equal(t, map[string]interface{}{
+		"hosts":    int64(3),
+		"sessions": 15.0,
+		"any_up":   true,
+		"first":    "alpha",
+	}, m.Fields())
+}
+
+func TestParseNamespaces(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<report xmlns="urn:example:report" xmlns:d="urn:example:device">
+  <d:device d:id="7">
+    <d:temperature unit="C">21.5</d:temperature>
+  </d:device>
+</report>`
+
+	parser, err := NewParser("device", []Config{{
+		Selection: "/r:report/dev:device",
+		Tags: map[string]string{
+			"id": "@dev:id",
+		},
+		Fields: map[string]string{
+			"temperature": "dev:temperature",
+		},
+		FieldTypes: map[string]string{
+			"temperature": "float",
+		},
+		Namespaces: map[string]string{
+			"r":   "urn:example:report",
+			"dev": "urn:example:device",
+		},
+	}}, nil)
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(doc))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("device",
+			map[string]string{"id": "7"},
+			map[string]interface{}{"temperature": 21.5},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
+}
+
+func TestParseUnixTimestamp(t *testing.T) {
+	parser, err := NewParser("events", []Config{{
+		Selection:       "//event",
+		Timestamp:       "@time",
+		TimestampFormat: "unix_ms",
+		Fields:          map[string]string{"value": "number(.)"},
+	}}, nil)
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(`<events><event time="1600000000123">4</event></events>`))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("events",
+			map[string]string{},
+			map[string]interface{}{"value": 4.0},
+			time.Unix(1600000000, 123000000)),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseErrors(t *testing.T) {
+	parser, err := NewParser("status", []Config{{
+		Selection:  "//host",
+		Fields:     map[string]string{"load": "load"},
+		FieldTypes: map[string]string{"load": "int"},
+	}}, nil)
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte(statusXML))
+	require.Error(t, err)
+
+	_, err = parser.Parse([]byte(`<status><host>`))
+	require.Error(t, err)
+
+	metrics, err := parser.Parse([]byte(`<other/>`))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestNewParserErrors(t *testing.T) {
+	for _, configs := range [][]Config{
+		nil,
+		{{}},
+		{{Fields: map[string]string{"a": "a["}}},
+		{{Selection: "//[", Fields: map[string]string{"a": "a"}}},
+		{{Timestamp: "@time", Fields: map[string]string{"a": "a"}}},
+		{{Fields: map[string]string{"a": "a"}, FieldTypes: map[string]string{"a": "integer"}}},
+		{{Fields: map[string]string{"a": "a"}, FieldTypes: map[string]string{"b": "int"}}},
+		{{Fields: map[string]string{"a": "a"}, Namespaces: map[string]string{"a": ""}}},
+	} {
+		_, err := NewParser("status", configs, nil)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,285 @@
+package derivative
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Fields of the counters, other fields are passed unchanged.
+  # fields = ["*"]
+  # fields_exclude = []
+
+  ## Output the rate of the counters per rate_unit with "rate", or their
+  ## difference since the previous metric of the series with "delta".
+  # mode = "rate"
+  # rate_unit = "1s"
+
+  ## If true, the counters are replaced by their rate or delta, otherwise
+  ## fields suffixed with "_rate" or "_delta" are added.  Counters without
+  ## a previous value are then removed.
+  # replace = false
+
+  ## Size of the counters in bits, 32 or 64.  A decrease of a counter whose
+  ## previous value was in the upper half of the range is a wraparound.
+  ## Otherwise, or with 0, a decrease is a reset of the counter and its delta
+  ## is the new value.
+  # counter_bits = 0
+
+  ## Series without metrics for this long are forgotten, their next metric
+  ## starts over.  The time is that of the metrics, a series expires when the
+  ## newest metric seen is this much newer than its last metric.
+  # expire_after = "10m"
+`
+
+const (
+	modeRate  = "rate"
+	modeDelta = "delta"
+)
+
+type Derivative struct {
+	Fields        []string        `toml:"fields"`
+	FieldsExclude []string        `toml:"fields_exclude"`
+	Mode          string          `toml:"mode"`
+	RateUnit      config.Duration `toml:"rate_unit"`
+	Replace       bool            `toml:"replace"`
+	CounterBits   int             `toml:"counter_bits"`
+	ExpireAfter   config.Duration `toml:"expire_after"`
+
+	fieldFilter filter.Filter
+	suffix      string
+	cache       map[uint64]*series
+
+	// The series expire using the time of the metrics, newest is the time
+	// of the newest metric seen.
+	newest      time.Time
+	lastCleanup time.Time
+}
+
+// series holds the previous value of the counters of a series.
+type series struct {
+	last     time.Time
+	counters map[string]counter
+}
+
+type counter struct {
+	value interface{}
+	time  time.Time
+}
+
+func (d *Derivative) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Derivative) Description() string {
+	return "Compute the rate or delta of counters"
+}
+
+func (d *Derivative) Init() error {
+	switch d.Mode {
+	case modeRate:
+		if d.RateUnit <= 0 {
+			return fmt.Errorf("rate_unit must be positive")
+		}
+	case modeDelta:
+	default:
+		return fmt.Errorf("unknown mode %q", d.Mode)
+	}
+	d.suffix = "_" + d.Mode
+
+	switch d.CounterBits {
+	case 0, 32, 64:
+	default:
+		return fmt.Errorf("counter_bits must be 0, 32 or 64")
+	}
+	if d.ExpireAfter <= 0 {
+		return fmt.Errorf("expire_after must be positive")
+	}
+
+	var err error
+	d.fieldFilter, err = filter.NewIncludeExcludeFilter(d.Fields, d.FieldsExclude)
+	if err != nil {
+		return fmt.Errorf("creating field filter: %v", err)
+	}
+
+	d.cache = make(map[uint64]*series)
+	return nil
+}
+
+func (d *Derivative) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		if d.apply(m) {
+			out = append(out, m)
+		} else {
+			m.Drop()
+		}
+	}
+	d.cleanup()
+	return out
+}
+
+// apply adds the rate or delta of the counters to the metric, it returns
+// false if all the fields were replaced and none is left.
+func (d *Derivative) apply(m telegraf.Metric) bool {
+	id := m.HashID()
+	s, ok := d.cache[id]
+	if !ok || m.Time().Sub(s.last) > time.Duration(d.ExpireAfter) {
+		s = &series{counters: make(map[string]counter)}
+		d.cache[id] = s
+	}
+
+	var remove []string
+	for _, field := range m.FieldList() {
+		if !d.fieldFilter.Match(field.Key) {
+			continue
+		}
+		cur := counter{value: field.Value, time: m.Time()}
+		if _, ok := toFloat(cur.value); !ok {
+			continue
+		}
+
+		value, ok := d.compute(s.counters[field.Key], cur)
+		if ok {
+			if d.Replace {
+				m.AddField(field.Key, value)
+			} else {
+				m.AddField(field.Key+d.suffix, value)
+			}
+		} else if d.Replace {
+			remove = append(remove, field.Key)
+		}
+
+		// Metrics out of order are skipped, the previous value is kept.
+		if prev, ok := s.counters[field.Key]; !ok || cur.time.After(prev.time) {
+			s.counters[field.Key] = cur
+		}
+	}
+
+	if m.Time().After(s.last) {
+		s.last = m.Time()
+	}
+	if m.Time().After(d.newest) {
+		d.newest = m.Time()
+	}
+
+	for _, key := range remove {
+		m.RemoveField(key)
+	}
+	return len(m.FieldList()) > 0
+}
+
+// compute returns the rate or delta of a counter since its previous value.
+func (d *Derivative) compute(prev, cur counter) (interface{}, bool) {
+	if prev.value == nil {
+		return nil, false
+	}
+	elapsed := cur.time.Sub(prev.time)
+	if elapsed <= 0 {
+		return nil, false
+	}
+
+	delta, ok := d.delta(prev.value, cur.value)
+	if !ok {
+		return nil, false
+	}
+	if d.Mode == modeDelta {
+		return delta, true
+	}
+
+	f, _ := toFloat(delta)
+	return f * float64(d.RateUnit) / float64(elapsed), true
+}
+
+// delta returns the difference between two values of a counter, of the type
+// of the counter if both values have the same type.
+func (d *Derivative) delta(prev, cur interface{}) (interface{}, bool) {
+	switch c := cur.(type) {
+	case int64:
+		if p, ok := prev.(int64); ok && p >= 0 && c >= 0 {
+			return int64(d.counterDelta(uint64(p), uint64(c))), true
+		}
+	case uint64:
+		if p, ok := prev.(uint64); ok {
+			return d.counterDelta(p, c), true
+		}
+	}
+
+	p, ok := toFloat(prev)
+	if !ok {
+		return nil, false
+	}
+	c, _ := toFloat(cur)
+	if c < p {
+		// The counter was reset.
+		return c, true
+	}
+	return c - p, true
+}
+
+// counterDelta returns the difference between two values of an integer
+// counter, handling wraparounds and resets.
+func (d *Derivative) counterDelta(prev, cur uint64) uint64 {
+	if cur >= prev {
+		return cur - prev
+	}
+
+	var max uint64
+	switch d.CounterBits {
+	case 32:
+		max = math.MaxUint32
+	case 64:
+		max = math.MaxUint64
+	}
+	if prev <= max && cur <= max && prev > max/2 {
+		return max - prev + cur + 1
+	}
+	// The counter was reset.
+	return cur
+}
+
+// cleanup forgets the series without metrics for expire_after before the
+// newest metric, so that series of metrics with old timestamps are not
+// forgotten while they are still sent.
+func (d *Derivative) cleanup() {
+	// No need to cleanup the cache at every call.
+	if d.newest.Sub(d.lastCleanup) < time.Duration(d.ExpireAfter) {
+		return
+	}
+	d.lastCleanup = d.newest
+	for id, s := range d.cache {
+		if d.newest.Sub(s.last) > time.Duration(d.ExpireAfter) {
+			delete(d.cache, id)
+		}
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	processors.Add("derivative", func() telegraf.Processor {
+		return &Derivative{
+			Fields:      []string{"*"},
+			Mode:        modeRate,
+			RateUnit:    config.Duration(time.Second),
+			ExpireAfter: config.Duration(10 * time.Minute),
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package derivative
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newDerivative() *Derivative {
+	return &Derivative{
+		Fields:      []string{"*"},
+		Mode:        modeRate,
+		RateUnit:    config.Duration(time.Second),
+		ExpireAfter: config.Duration(10 * time.Minute),
+	}
+}
+
+func TestRate(t *testing.T) {
+	now := time.Now()
+	d := newDerivative()
+	d.FieldsExclude = []string{"speed"}
+	require.NoError(t, d.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": uint64(100), "speed": int64(1000), "up": true}, now),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"},
+			map[string]interface{}{"bytes_recv": uint64(1000)}, now),
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": uint64(300), "speed": int64(1000), "up": true}, now.Add(10*time.Second)),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"},
+			map[string]interface{}{"bytes_recv": uint64(1500)}, now.Add(5*time.Second)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": uint64(100), "speed": int64(1000), "up": true}, now),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"},
+			map[string]interface{}{"bytes_recv": uint64(1000)}, now),
+		testutil.MustMetric("net", map[string]string{"interface": "eth0"},
+			map[string]interface{}{"bytes_recv": uint64(300), "bytes_recv_rate": 20.0, "speed": int64(1000), "up": true}, now.Add(10*time.Second)),
+		testutil.MustMetric("net", map[string]string{"interface": "eth1"},
+			map[string]interface{}{"bytes_recv": uint64(1500), "bytes_recv_rate": 100.0}, now.Add(5*time.Second)),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, d.Apply(input...))
+}
+
+func TestDeltaReplace(t *testing.T) {
+	now := time.Now()
+	d := newDerivative()
+	d.Mode = modeDelta
+	d.Replace = true
+	d.Fields = []string{"*_count"}
+	require.NoError(t, d.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("app", map[string]string{},
+			map[string]interface{}{"request_count": int64(10), "error_count": 1.5}, now),
+		testutil.MustMetric("app", map[string]string{},
+			map[string]interface{}{"request_count": int64(15), "error_count": 4.0, "users": int64(3)}, now.Add(time.Minute)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("app", map[string]string{},
+			map[string]interface{}{"request_count": int64(5), "error_count": 2.5, "users": int64(3)}, now.Add(time.Minute)),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, d.Apply(input...))
+}
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name     string
+		bits     int
+		prev     uint64
+		cur      uint64
+		expected uint64
+	}{
+		{name: "increase", bits: 32, prev: 10, cur: 25, expected: 15},
+		{name: "reset", prev: math.MaxUint32 - 5, cur: 10, expected: 10},
+		{name: "32 bits wraparound", bits: 32, prev: math.MaxUint32 - 5, cur: 10, expected: 16},
+		{name: "32 bits reset", bits: 32, prev: 1000, cur: 10, expected: 10},
+		{name: "32 bits reset of larger counter", bits: 32, prev: math.MaxUint32 + 10, cur: 10, expected: 10},
+		{name: "64 bits wraparound", bits: 64, prev: math.MaxUint64 - 5, cur: 10, expected: 16},
+		{name: "64 bits reset", bits: 64, prev: math.MaxUint32 - 5, cur: 10, expected: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDerivative()
+			d.CounterBits = tt.bits
+			require.Equal(t, tt.expected, d.counterDelta(tt.prev, tt.cur))
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	now := time.Now()
+	d := newDerivative()
+	d.Mode = modeDelta
+	require.NoError(t, d.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("procstat", map[string]string{},
+			map[string]interface{}{"read_bytes": int64(1000), "cpu_time": 20.0}, now),
+		testutil.MustMetric("procstat", map[string]string{},
+			map[string]interface{}{"read_bytes": int64(300), "cpu_time": 2.0}, now.Add(time.Second)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("procstat", map[string]string{},
+			map[string]interface{}{"read_bytes": int64(1000), "cpu_time": 20.0}, now),
+		testutil.MustMetric("procstat", map[string]string{},
+			map[string]interface{}{"read_bytes": int64(300), "read_bytes_delta": int64(300), "cpu_time": 2.0, "cpu_time_delta": 2.0}, now.Add(time.Second)),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, d.Apply(input...))
+}
+
+func TestOutOfOrder(t *testing.T) {
+	now := time.Now()
+	d := newDerivative()
+	require.NoError(t, d.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("diskio", map[string]string{},
+			map[string]interface{}{"reads": int64(100)}, now),
+		testutil.MustMetric("diskio", map[string]string{},
+			map[string]interface{}{"reads": int64(50)}, now.Add(-time.Second)),
+		testutil.MustMetric("diskio", map[string]string{},
+			map[string]interface{}{"reads": int64(120)}, now.Add(2*time.Second)),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("diskio", map[string]string{},
+			map[string]interface{}{"reads": int64(100)}, now),
+		testutil.MustMetric("diskio", map[string]string{},
+			map[string]interface{}{"reads": int64(50)}, now.Add(-time.Second)),
+		testutil.MustMetric("diskio", map[string]string{},
+			map[string]interface{}{"reads": int64(120), "reads_rate": 10.0}, now.Add(2*time.Second)),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, d.Apply(input...))
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Now()
+	d := newDerivative()
+	d.ExpireAfter = config.Duration(time.Minute)
+	require.NoError(t, d.Init())
+
+	input := []telegraf.Metric{
+		testutil.MustMetric("nstat", map[string]string{},
+			map[string]interface{}{"TcpInSegs": int64(100)}, now.Add(-3*time.Minute)),
+		testutil.MustMetric("nstat", map[string]string{},
+			map[string]interface{}{"TcpInSegs": int64(200)}, now),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("nstat", map[string]string{},
+			map[string]interface{}{"TcpInSegs": int64(100)}, now.Add(-3*time.Minute)),
+		testutil.MustMetric("nstat", map[string]string{},
+			map[string]interface{}{"TcpInSegs": int64(200)}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, d.Apply(input...))
+
+	// The cleanup removes the series without metrics for too long before
+	// the newest metric.
+	d.Apply(testutil.MustMetric("other", map[string]string{},
+		map[string]interface{}{"value": int64(1)}, now.Add(-2*time.Minute)))
+	require.Len(t, d.cache, 2)
+	d.Apply(testutil.MustMetric("late", map[string]string{},
+		map[string]interface{}{"value": int64(1)}, now.Add(90*time.Second)))
+	require.Len(t, d.cache, 1)
+}
+
+func TestExpireOldTimestamps(t *testing.T) {
+	start := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newDerivative()
+	d.ExpireAfter = config.Duration(time.Minute)
+	require.NoError(t, d.Init())
+
+	// Series of metrics with old timestamps are kept while metrics are sent.
+	for i := 0; i < 3; i++ {
+		d.Apply(testutil.MustMetric("nstat", map[string]string{},
+			map[string]interface{}{"TcpInSegs": int64(100 * i)}, start.Add(time.Duration(i)*30*time.Second)))
+		require.Len(t, d.cache, 1)
+	}
+	d.lastCleanup = time.Time{}
+	out := d.Apply(testutil.MustMetric("nstat", map[string]string{},
+		map[string]interface{}{"TcpInSegs": int64(300)}, start.Add(90*time.Second)))
+	require.Len(t, d.cache, 1)
+	rate, ok := out[0].GetField("TcpInSegs_rate")
+	require.True(t, ok)
+	require.Equal(t, 100.0/30, rate)
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(d *Derivative)
+	}{
+		{name: "mode", modify: func(d *Derivative) { d.Mode = "integral" }},
+		{name: "rate unit", modify: func(d *Derivative) { d.RateUnit = 0 }},
+		{name: "counter bits", modify: func(d *Derivative) { d.CounterBits = 16 }},
+		{name: "expire after", modify: func(d *Derivative) { d.ExpireAfter = 0 }},
+		{name: "fields", modify: func(d *Derivative) { d.Fields = []string{"[a"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDerivative()
+			tt.modify(d)
+			require.Error(t, d.Init())
+		})
+	}
+}
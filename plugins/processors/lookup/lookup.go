@@ -0,0 +1,243 @@
+package lookup
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const sampleConfig = `
+  ## Files holding the lookup tables, the entries of later files take
+  ## precedence.
+  files = ["/etc/telegraf/hosts.csv"]
+
+  ## Format of the files, "csv" or "json".  CSV files have a header row, the
+  ## first column holds the keys and the other ones the tags to add.  JSON
+  ## files hold an object mapping the keys to objects of the tags to add.
+  # format = "csv"
+
+  ## Tags whose values make up the key, joined with the separator.  If
+  ## key_name is true, the key starts with the metric name.
+  key_tags = ["host"]
+  # key_name = false
+  # key_separator = ":"
+
+  ## Interval to check the files for changes, the tables are reloaded when
+  ## a file changed.  Set to 0 to never reload them.
+  # watch_interval = "30s"
+`
+
+type Lookup struct {
+	Files         []string        `toml:"files"`
+	Format        string          `toml:"format"`
+	KeyTags       []string        `toml:"key_tags"`
+	KeyName       bool            `toml:"key_name"`
+	KeySeparator  string          `toml:"key_separator"`
+	WatchInterval config.Duration `toml:"watch_interval"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	// alias of the processor, set with SetAlias.
+	alias string
+
+	// table holds the current table, it is replaced as a whole when the
+	// files are reloaded.
+	table atomic.Value
+	files []fileState
+
+	matches selfstat.Stat
+	misses  selfstat.Stat
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// fileState is used to detect changes of the files.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (l *Lookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Lookup) Description() string {
+	return "Add tags to metrics from lookup tables in CSV or JSON files"
+}
+
+func (l *Lookup) Init() error {
+	if len(l.Files) == 0 {
+		return fmt.Errorf("no files set")
+	}
+	if len(l.KeyTags) == 0 && !l.KeyName {
+		return fmt.Errorf("the key must have key_tags or key_name set")
+	}
+	switch l.Format {
+	case "csv", "json":
+	default:
+		return fmt.Errorf("unknown format %q", l.Format)
+	}
+
+	if err := l.reload(); err != nil {
+		return err
+	}
+
+	tags := map[string]string{"files": strings.Join(l.Files, ",")}
+	if l.alias != "" {
+		tags["alias"] = l.alias
+	}
+	l.matches = selfstat.Register("lookup", "matches", tags)
+	l.misses = selfstat.Register("lookup", "misses", tags)
+	return nil
+}
+
+// SetAlias sets the alias of the processor, added to the tags of the
+// internal metrics.
+func (l *Lookup) SetAlias(alias string) {
+	l.alias = alias
+}
+
+func (l *Lookup) Start(acc telegraf.Accumulator) error {
+	l.done = make(chan struct{})
+	if l.WatchInterval > 0 {
+		l.wg.Add(1)
+		go l.watch()
+	}
+	return nil
+}
+
+func (l *Lookup) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
+	l.apply(metric)
+	acc.AddMetric(metric)
+	return nil
+}
+
+func (l *Lookup) Stop() error {
+	close(l.done)
+	l.wg.Wait()
+	return nil
+}
+
+func (l *Lookup) apply(metric telegraf.Metric) {
+	key, ok := l.key(metric)
+	if !ok {
+		l.misses.Incr(1)
+		return
+	}
+
+	tags, ok := l.table.Load().(table)[key]
+	if !ok {
+		l.misses.Incr(1)
+		return
+	}
+	l.matches.Incr(1)
+	for k, v := range tags {
+		metric.AddTag(k, v)
+	}
+}
+
+// key returns the key of the metric, it is not found if the metric lacks one
+// of the key tags.
+func (l *Lookup) key(metric telegraf.Metric) (string, bool) {
+	parts := make([]string, 0, len(l.KeyTags)+1)
+	if l.KeyName {
+		parts = append(parts, metric.Name())
+	}
+	for _, tag := range l.KeyTags {
+		value, ok := metric.GetTag(tag)
+		if !ok {
+			return "", false
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, l.KeySeparator), true
+}
+
+// watch reloads the files when they change, until the processor stops.
+func (l *Lookup) watch() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(l.WatchInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			if !l.changed() {
+				continue
+			}
+			if err := l.reload(); err != nil {
+				l.Log.Errorf("Reloading the lookup tables, keeping the previous ones: %v", err)
+				continue
+			}
+			l.Log.Debugf("Reloaded the lookup tables")
+		}
+	}
+}
+
+// changed returns true if any of the files changed since they were loaded.
+func (l *Lookup) changed() bool {
+	files, err := l.stat()
+	if err != nil {
+		l.Log.Errorf("Checking the lookup files: %v", err)
+		return false
+	}
+	for i, f := range files {
+		if f != l.files[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Lookup) stat() ([]fileState, error) {
+	files := make([]fileState, 0, len(l.Files))
+	for _, path := range l.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fileState{modTime: info.ModTime(), size: info.Size()})
+	}
+	return files, nil
+}
+
+// reload loads the files and replaces the table, the previous table is kept
+// if any file fails to load.  The files are not loaded again until they
+// change.
+func (l *Lookup) reload() error {
+	// The files are checked before loading them, a change while loading is
+	// detected at the next check.
+	files, err := l.stat()
+	if err != nil {
+		return err
+	}
+	l.files = files
+
+	t, err := loadTable(l.Files, l.Format)
+	if err != nil {
+		return err
+	}
+	l.table.Store(t)
+	return nil
+}
+
+func init() {
+	processors.AddStreaming("lookup", func() telegraf.StreamingProcessor {
+		return &Lookup{
+			Format:        "csv",
+			KeySeparator:  ":",
+			WatchInterval: config.Duration(30 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package lookup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newLookup(files ...string) *Lookup {
+	return &Lookup{
+		Files:        files,
+		Format:       "csv",
+		KeyTags:      []string{"host"},
+		KeySeparator: ":",
+		Log:          testutil.Logger{},
+	}
+}
+
+func process(t *testing.T, l *Lookup, metrics ...telegraf.Metric) []telegraf.Metric {
+	acc := &testutil.Accumulator{}
+	require.NoError(t, l.Start(acc))
+	for _, m := range metrics {
+		require.NoError(t, l.Add(m, acc))
+	}
+	require.NoError(t, l.Stop())
+	return acc.GetTelegrafMetrics()
+}
+
+func TestLookupCSV(t *testing.T) {
+	l := newLookup("testdata/hosts.csv")
+	require.NoError(t, l.Init())
+	matches, misses := l.matches.Get(), l.misses.Get()
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "web01", "team": "unknown"},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "web02"},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "web03"},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+		testutil.MustMetric("cpu", map[string]string{},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "web01", "owner": "alice", "team": "frontend", "environment": "production"},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "web02", "owner": "bob", "team": "frontend"},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "web03"},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+		testutil.MustMetric("cpu", map[string]string{},
+			map[string]interface{}{"usage_idle": 42.0}, now),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, process(t, l, input...))
+	require.Equal(t, int64(2), l.matches.Get()-matches)
+	require.Equal(t, int64(2), l.misses.Get()-misses)
+}
+
+func TestLookupJSONCompositeKey(t *testing.T) {
+	l := newLookup("testdata/services.json")
+	l.Format = "json"
+	l.KeyName = true
+	require.NoError(t, l.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		testutil.MustMetric("nginx", map[string]string{"host": "web01"},
+			map[string]interface{}{"requests": int64(10)}, now),
+		testutil.MustMetric("nginx", map[string]string{"host": "db01"},
+			map[string]interface{}{"requests": int64(10)}, now),
+		testutil.MustMetric("postgresql", map[string]string{"host": "db01"},
+			map[string]interface{}{"xact_commit": int64(10)}, now),
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric("nginx", map[string]string{"host": "web01", "service": "shop", "cost_center": "1001"},
+			map[string]interface{}{"requests": int64(10)}, now),
+		testutil.MustMetric("nginx", map[string]string{"host": "db01"},
+			map[string]interface{}{"requests": int64(10)}, now),
+		testutil.MustMetric("postgresql", map[string]string{"host": "db01", "service": "billing", "cost_center": "2002"},
+			map[string]interface{}{"xact_commit": int64(10)}, now),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, process(t, l, input...))
+}
+
+func TestLoadTablePrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	override := filepath.Join(dir, "override.csv")
+	require.NoError(t, ioutil.WriteFile(override, []byte("host,team\nweb01,platform\n"), 0644))
+
+	tbl, err := loadTable([]string{"testdata/hosts.csv", override}, "csv")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"owner": "alice", "team": "platform", "environment": "production"}, tbl["web01"])
+}
+
+func TestLoadTableErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+	}{
+		{name: "csv without tag column", format: "csv", content: "host\nweb01\n"},
+		{name: "csv with missing column", format: "csv", content: "host,team\nweb01\n"},
+		{name: "json not an object", format: "json", content: `["web01"]`},
+		{name: "json tag not a string", format: "json", content: `{"web01": {"rack": 3}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "lookup")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "table")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tt.content), 0644))
+
+			_, err = loadTable([]string{path}, tt.format)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(l *Lookup)
+	}{
+		{name: "no files", modify: func(l *Lookup) { l.Files = nil }},
+		{name: "missing file", modify: func(l *Lookup) { l.Files = []string{"testdata/missing.csv"} }},
+		{name: "no key", modify: func(l *Lookup) { l.KeyTags = nil }},
+		{name: "format", modify: func(l *Lookup) { l.Format = "yaml" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLookup("testdata/hosts.csv")
+			tt.modify(l)
+			require.Error(t, l.Init())
+		})
+	}
+}
+
+func TestStatsAlias(t *testing.T) {
+	l := newLookup("testdata/hosts.csv")
+	require.NoError(t, l.Init())
+
+	aliased := newLookup("testdata/hosts.csv")
+	aliased.SetAlias("hosts")
+	require.NoError(t, aliased.Init())
+	require.Equal(t, "hosts", aliased.matches.Tags()["alias"])
+	matches, aliasedMatches := l.matches.Get(), aliased.matches.Get()
+
+	process(t, aliased, testutil.MustMetric("cpu", map[string]string{"host": "web01"},
+		map[string]interface{}{"usage_idle": 42.0}, time.Now()))
+	require.Equal(t, int64(1), aliased.matches.Get()-aliasedMatches)
+	require.Equal(t, matches, l.matches.Get())
+}
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hosts.csv")
+	require.NoError(t, ioutil.WriteFile(path, []byte("host,team\nweb01,frontend\n"), 0644))
+
+	l := newLookup(path)
+	l.WatchInterval = config.Duration(10 * time.Millisecond)
+	require.NoError(t, l.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, l.Start(acc))
+	defer l.Stop()
+
+	team := func() string {
+		m := testutil.MustMetric("cpu", map[string]string{"host": "web01"},
+			map[string]interface{}{"usage_idle": 42.0}, time.Now())
+		l.apply(m)
+		value, _ := m.GetTag("team")
+		return value
+	}
+	require.Equal(t, "frontend", team())
+
+	// Invalid files are not loaded, the previous table is kept.
+	require.NoError(t, ioutil.WriteFile(path, []byte("host,team\nweb01\n"), 0644))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, "frontend", team())
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("host,team\nweb01,platform\n"), 0644))
+	require.Eventually(t, func() bool {
+		return team() == "platform"
+	}, 5*time.Second, 10*time.Millisecond)
+}
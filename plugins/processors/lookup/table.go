@@ -0,0 +1,102 @@
+package lookup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// table maps the keys to the tags to add.
+type table map[string]map[string]string
+
+// loadTable loads the files, the entries of later files take precedence.
+func loadTable(files []string, format string) (table, error) {
+	t := make(table)
+	for _, path := range files {
+		if err := t.loadFile(path, format); err != nil {
+			return nil, fmt.Errorf("loading %s: %v", path, err)
+		}
+	}
+	return t, nil
+}
+
+func (t table) loadFile(path, format string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch format {
+	case "csv":
+		return t.loadCSV(f)
+	case "json":
+		return t.loadJSON(f)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// loadCSV loads a file with a header row, the first column holds the keys
+// and the other ones the tags named in the header.  Empty values are not
+// added.
+func (t table) loadCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(header) < 2 {
+		return fmt.Errorf("header must have a key column and at least one tag column")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		tags := make(map[string]string, len(record)-1)
+		for i, value := range record[1:] {
+			if value != "" {
+				tags[header[i+1]] = value
+			}
+		}
+		t.add(record[0], tags)
+	}
+}
+
+// loadJSON loads a file holding an object mapping the keys to objects of the
+// tags.
+func (t table) loadJSON(r io.Reader) error {
+	var entries map[string]map[string]string
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+	for key, tags := range entries {
+		t.add(key, tags)
+	}
+	return nil
+}
+
+func (t table) add(key string, tags map[string]string) {
+	entry, ok := t[key]
+	if !ok {
+		t[key] = tags
+		return
+	}
+	for k, v := range tags {
+		entry[k] = v
+	}
+}
@@ -1,15 +1,12 @@
 package starlark
 
 import (
-	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/influxdata/telegraf"
+	common "github.com/influxdata/telegraf/plugins/common/starlark"
 	"github.com/influxdata/telegraf/plugins/processors"
-	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
-	"go.starlark.net/starlarkjson"
 )
 
 const (
@@ -27,92 +24,63 @@ def apply(metric):
 
   ## File containing a Starlark script.
   # script = "/usr/local/bin/myscript.star"
+
+  ## Directory of the .star libraries the script can load, in addition to
+  ## the bundled json, math, time and logging modules.
+  # library_dir = "/usr/local/lib/telegraf/starlark"
+
+  ## File to save the state dict in when Telegraf stops, and to load it from
+  ## when it starts.  Only values which can be encoded in JSON are saved.
+  # state_file = "/var/lib/telegraf/starlark_state.json"
 `
 )
 
 type Starlark struct {
-	Source string `toml:"source"`
-	Script string `toml:"script"`
+	Source     string `toml:"source"`
+	Script     string `toml:"script"`
+	LibraryDir string `toml:"library_dir"`
+	StateFile  string `toml:"state_file"`
 
 	Log telegraf.Logger `toml:"-"`
 
-	thread    *starlark.Thread
+	script    *common.Script
 	applyFunc *starlark.Function
-	args      starlark.Tuple
+	state     *starlark.Dict
 	results   []telegraf.Metric
 }
 
 func (s *Starlark) Init() error {
-	if s.Source == "" && s.Script == "" {
-		return errors.New("one of source or script must be set")
-	}
-	if s.Source != "" && s.Script != "" {
-		return errors.New("both source or script cannot be set")
+	var err error
+	s.state = starlark.NewDict(0)
+	if s.StateFile != "" {
+		if s.state, err = common.LoadState(s.StateFile); err != nil {
+			return err
+		}
 	}
 
-	s.thread = &starlark.Thread{
-		Print: func(_ *starlark.Thread, msg string) { s.Log.Debug(msg) },
-		Load:  loadFunc,
+	// The state dict is kept between calls, unlike the frozen globals of the
+	// script.
+	opts := common.Options{
+		Predeclared: starlark.StringDict{"state": s.state},
+		LibraryDir:  s.LibraryDir,
 	}
-
-	builtins := starlark.StringDict{}
-	builtins["Metric"] = starlark.NewBuiltin("Metric", newMetric)
-	builtins["deepcopy"] = starlark.NewBuiltin("deepcopy", deepcopy)
-
-	program, err := s.sourceProgram(builtins)
+	s.script, err = common.NewScript("processor.starlark", s.Source, s.Script, opts, s.Log)
 	if err != nil {
 		return err
 	}
 
-	// Execute source
-	globals, err := program.Init(s.thread, builtins)
+	// The source should define an apply function.
+	s.applyFunc, err = s.script.Function("apply", 1)
 	if err != nil {
 		return err
 	}
 
-	// Freeze the global state.  This prevents modifications to the processor
-	// state and prevents scripts from containing errors storing tracking
-	// metrics.  Tasks that require global state will not be possible due to
-	// this, so maybe we should relax this in the future.
-	globals.Freeze()
-
-	// The source should define an apply function.
-	apply := globals["apply"]
-
-	if apply == nil {
-		return errors.New("apply is not defined")
-	}
-
-	var ok bool
-	if s.applyFunc, ok = apply.(*starlark.Function); !ok {
-		return errors.New("apply is not a function")
-	}
-
-	if s.applyFunc.NumParams() != 1 {
-		return errors.New("apply function must take one parameter")
-	}
-
-	// Reusing the same metric wrapper to skip an allocation.  This will cause
-	// any saved references to point to the new metric, but due to freezing the
-	// globals none should exist.
-	s.args = make(starlark.Tuple, 1)
-	s.args[0] = &Metric{}
-
 	// Preallocate a slice for return values.
 	s.results = make([]telegraf.Metric, 0, 10)
 
 	return nil
 }
 
-func (s *Starlark) sourceProgram(builtins starlark.StringDict) (*starlark.Program, error) {
-	if s.Source != "" {
-		_, program, err := starlark.SourceProgram("processor.starlark", s.Source, builtins.Has)
-		return program, err
-	}
-	_, program, err := starlark.SourceProgram(s.Script, nil, builtins.Has)
-	return program, err
-}
-
 func (s *Starlark) SampleConfig() string {
 	return sampleConfig
 }
@@ -126,15 +94,12 @@ func (s *Starlark) Start(acc telegraf.Accumulator) error {
 }
 
 func (s *Starlark) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
-	s.args[0].(*Metric).Wrap(metric)
+	// Scripts may keep the metric in the state, each gets its own wrapper.
+	sm := &common.Metric{}
+	sm.Wrap(metric)
 
-	rv, err := starlark.Call(s.thread, s.applyFunc, s.args, nil)
+	rv, err := s.script.Call(s.applyFunc, starlark.Tuple{sm})
 	if err != nil {
-		if err, ok := err.(*starlark.EvalError); ok {
-			for _, line := range strings.Split(err.Backtrace(), "\n") {
-				s.Log.Error(line)
-			}
-		}
 		metric.Reject()
 		return err
 	}
@@ -146,7 +111,7 @@ func (s *Starlark) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
 		var v starlark.Value
 		for iter.Next(&v) {
 			switch v := v.(type) {
-			case *Metric:
+			case *common.Metric:
 				m := v.Unwrap()
 				if containsMetric(s.results, m) {
 					s.Log.Errorf("Duplicate metric reference detected")
@@ -170,7 +135,7 @@ func (s *Starlark) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
 			s.results[i] = nil
 		}
 		s.results = s.results[:0]
-	case *Metric:
+	case *common.Metric:
 		m := rv.Unwrap()
 
 		// If the script returned a different metric, mark this metric as
@@ -188,7 +153,10 @@ func (s *Starlark) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
 }
 
 func (s *Starlark) Stop() error {
-	return nil
+	if s.StateFile == "" {
+		return nil
+	}
+	return common.SaveState(s.StateFile, s.state, s.Log)
 }
 
 func containsMetric(metrics []telegraf.Metric, metric telegraf.Metric) bool {
@@ -200,29 +168,8 @@ func containsMetric(metrics []telegraf.Metric, metric telegraf.Metric) bool {
 	return false
 }
 
-func init() {
-	// https://github.com/bazelbuild/starlark/issues/20
-	resolve.AllowNestedDef = true
-	resolve.AllowLambda = true
-	resolve.AllowFloat = true
-	resolve.AllowSet = true
-	resolve.AllowGlobalReassign = true
-	resolve.AllowRecursion = true
-}
-
 func init() {
 	processors.AddStreaming("starlark", func() telegraf.StreamingProcessor {
 		return &Starlark{}
 	})
 }
-
-func loadFunc(thread *starlark.Thread, module string) (starlark.StringDict, error) {
-	switch module {
-	case "json.star":
-		return starlark.StringDict{
-			"json": starlarkjson.Module,
-		}, nil
-	default:
-		return nil, errors.New("module " + module + " is not available")
-	}
-}
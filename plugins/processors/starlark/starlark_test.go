@@ -2798,7 +2798,11 @@ func TestAllScriptTestData(t *testing.T) {
 	// can be run from multiple folders
 	paths := []string{"testdata", "plugins/processors/starlark/testdata"}
 	for _, testdataPath := range paths {
+		libraryDir := filepath.Join(testdataPath, "lib")
 		filepath.Walk(testdataPath, func(path string, info os.FileInfo, err error) error {
+			if info != nil && info.IsDir() && path == libraryDir {
+				return filepath.SkipDir
+			}
 			if info == nil || info.IsDir() {
 				return nil
 			}
@@ -2810,8 +2814,9 @@ func TestAllScriptTestData(t *testing.T) {
 				inputMetrics := parseMetricsFrom(t, lines, "Example Input:")
 				outputMetrics := parseMetricsFrom(t, lines, "Example Output:")
 				plugin := &Starlark{
-					Script: fn,
-					Log:    testutil.Logger{},
+					Script:     fn,
+					LibraryDir: libraryDir,
+					Log:        testutil.Logger{},
 				}
 				require.NoError(t, plugin.Init())
 
@@ -2835,6 +2840,212 @@ func TestAllScriptTestData(t *testing.T) {
 	}
 }
 
+func TestState(t *testing.T) {
+	plugin := &Starlark{
+		Source: `
+def apply(metric):
+	state["count"] = state.get("count", 0) + 1
+	metric.fields["count"] = state["count"]
+	return metric
+`,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	for i := 0; i < 3; i++ {
+		require.NoError(t, plugin.Add(testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0)), acc))
+	}
+	require.NoError(t, plugin.Stop())
+
+	count, _ := acc.GetTelegrafMetrics()[2].GetField("count")
+	require.Equal(t, int64(3), count)
+}
+
+func TestStateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starlark")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	run := func() int64 {
+		plugin := &Starlark{
+			Source: `
+def apply(metric):
+	state["count"] = state.get("count", 0) + 1
+	state["last"] = {"name": metric.name, "time": metric.time}
+	metric.fields["count"] = state["count"]
+	return metric
+`,
+			StateFile: stateFile,
+			Log:       testutil.Logger{},
+		}
+		require.NoError(t, plugin.Init())
+
+		acc := &testutil.Accumulator{}
+		require.NoError(t, plugin.Start(acc))
+		require.NoError(t, plugin.Add(testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 42},
+			time.Unix(0, 0)), acc))
+		require.NoError(t, plugin.Stop())
+
+		count, _ := acc.GetTelegrafMetrics()[0].GetField("count")
+		return count.(int64)
+	}
+
+	require.Equal(t, int64(1), run())
+	require.Equal(t, int64(2), run())
+
+	buf, err := ioutil.ReadFile(stateFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"count": 2, "last": {"name": "cpu", "time": 0}}`, string(buf))
+}
+
+func TestStateFileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starlark")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The state file must hold a dict.
+	stateFile := filepath.Join(dir, "state.json")
+	require.NoError(t, ioutil.WriteFile(stateFile, []byte("[]"), 0644))
+	plugin := &Starlark{
+		Source:    "def apply(metric):\n\treturn metric\n",
+		StateFile: stateFile,
+		Log:       testutil.Logger{},
+	}
+	require.Error(t, plugin.Init())
+
+	// The state file must be writable.
+	require.NoError(t, os.Remove(stateFile))
+	plugin = &Starlark{
+		Source:    "def apply(metric):\n\treturn metric\n",
+		StateFile: filepath.Join(dir, "missing", "state.json"),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&testutil.Accumulator{}))
+	require.Error(t, plugin.Stop())
+}
+
+func TestStateFileSkipsValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "starlark")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	// Functions, and dict keys other than strings, can not be saved.
+	plugin := &Starlark{
+		Source: `
+def apply(metric):
+	state["apply"] = apply
+	state[1] = "int key"
+	state["count"] = 1
+	state["hosts"] = {("a", 1): "tuple key", "b": 2, "f": apply}
+	state["list"] = [1, "two"]
+	return metric
+`,
+		StateFile: stateFile,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	require.NoError(t, plugin.Add(testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": 42},
+		time.Unix(0, 0)), acc))
+	require.NoError(t, plugin.Stop())
+
+	buf, err := ioutil.ReadFile(stateFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"count": 1, "hosts": {"b": 2}, "list": [1, "two"]}`, string(buf))
+}
+
+func TestModules(t *testing.T) {
+	plugin := &Starlark{
+		Source: `
+load("math.star", "math")
+load("time.star", "time")
+load("logging.star", "log")
+
+def apply(metric):
+	log.info("applying")
+	metric.fields["sqrt"] = math.sqrt(metric.fields["value"])
+	metric.fields["floor"] = math.floor(2.5)
+	metric.fields["pow"] = math.pow(2, 10)
+	metric.fields["isnan"] = math.isnan(math.nan)
+	metric.fields["minute"] = time.parse_duration("1m") // time.second
+	metric.time = time.parse_time("2020-09-13T12:26:40Z")
+	metric.tags["time"] = time.format_time(metric.time, "2006-01-02")
+	return metric
+`,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Start(acc))
+	require.NoError(t, plugin.Add(testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": 16.0},
+		time.Unix(0, 0)), acc))
+	require.NoError(t, plugin.Stop())
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"time": "2020-09-13"},
+			map[string]interface{}{
+				"value":  16.0,
+				"sqrt":   4.0,
+				"floor":  2,
+				"pow":    1024.0,
+				"isnan":  true,
+				"minute": 60,
+			},
+			time.Unix(1600000000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestLibraryErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		libraryDir string
+	}{
+		{
+			name:   "libraries need a library directory",
+			source: "load(\"rates.star\", \"rate\")\ndef apply(metric):\n\treturn metric\n",
+		},
+		{
+			name:       "library not found",
+			source:     "load(\"missing.star\", \"rate\")\ndef apply(metric):\n\treturn metric\n",
+			libraryDir: "testdata/lib",
+		},
+		{
+			name:       "libraries outside of the library directory",
+			source:     "load(\"../ratio.star\", \"apply\")\n",
+			libraryDir: "testdata/lib",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Starlark{
+				Source:     tt.source,
+				LibraryDir: tt.libraryDir,
+				Log:        testutil.Logger{},
+			}
+			require.Error(t, plugin.Init())
+		})
+	}
+}
+
 var parser, _ = parsers.NewInfluxParser() // literally never returns errors.
 
 // parses metric lines out of line protocol following a header, with a trailing blank line
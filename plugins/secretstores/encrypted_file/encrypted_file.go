@@ -0,0 +1,224 @@
+package encrypted_file
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"golang.org/x/crypto/scrypt"
+)
+
+// File layout: magic | salt | nonce | AES-256-GCM sealed JSON object.
+var magic = []byte("TGSS\x01")
+
+const (
+	saltSize  = 16
+	nonceSize = 12
+)
+
+// EncryptedFile is a secret store keeping all secrets in a single file
+// encrypted with a key derived from a password.
+type EncryptedFile struct {
+	Path     string          `toml:"path"`
+	Password internal.Secret `toml:"password"`
+
+	mu   sync.Mutex
+	salt []byte
+	key  []byte
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, referenced as @{<id>:<key>}.
+  id = "keyring"
+
+  ## File containing the encrypted secrets, created on the first
+  ## "telegraf secrets set".
+  path = "/etc/telegraf/secrets.enc"
+
+  ## Password used to derive the encryption key.  It can itself reference a
+  ## different secret store, e.g. "@{env:KEYRING_PASSWORD}".
+  password = "$KEYRING_PASSWORD"
+`
+
+func (e *EncryptedFile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EncryptedFile) Description() string {
+	return "Read secrets from a password protected encrypted file"
+}
+
+func (e *EncryptedFile) Init() error {
+	if e.Path == "" {
+		return errors.New("path is required")
+	}
+	if e.Password.Empty() {
+		return errors.New("password is required")
+	}
+	return nil
+}
+
+func (e *EncryptedFile) Get(key string) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	secrets, err := e.read()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %q not found", key)
+	}
+	return value, nil
+}
+
+func (e *EncryptedFile) List() ([]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	secrets, err := e.read()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (e *EncryptedFile) Set(key string, value []byte) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	secrets, err := e.read()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return e.write(secrets)
+}
+
+// read decrypts the file, a missing file is an empty store.
+func (e *EncryptedFile) read() (map[string][]byte, error) {
+	data, err := ioutil.ReadFile(e.Path)
+	if os.IsNotExist(err) {
+		return make(map[string][]byte), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, magic) || len(data) < len(magic)+saltSize+nonceSize {
+		return nil, fmt.Errorf("%s is not an encrypted secret store", e.Path)
+	}
+	data = data[len(magic):]
+	salt, nonce, sealed := data[:saltSize], data[saltSize:saltSize+nonceSize], data[saltSize+nonceSize:]
+
+	gcm, err := e.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, nonce, sealed, magic)
+	if err != nil {
+		return nil, errors.New("decrypting secrets failed, wrong password?")
+	}
+
+	secrets := make(map[string][]byte)
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("decoding secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// write encrypts the secrets with a new salt and nonce and replaces the file.
+func (e *EncryptedFile) write(secrets map[string][]byte) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	gcm, err := e.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	data := append(append(append([]byte{}, magic...), salt...), nonce...)
+	data = gcm.Seal(data, nonce, plain, magic)
+
+	tmp, err := ioutil.TempFile(filepath.Dir(e.Path), ".secrets-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), e.Path)
+}
+
+// cipher returns the AEAD for the salt.  Deriving the key is deliberately
+// expensive, so the key of the last salt is kept.
+func (e *EncryptedFile) cipher(salt []byte) (cipher.AEAD, error) {
+	if e.key == nil || !bytes.Equal(salt, e.salt) {
+		password, err := e.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password: %w", err)
+		}
+		key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 32)
+		if err != nil {
+			return nil, err
+		}
+		e.salt = append([]byte{}, salt...)
+		e.key = key
+	}
+
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func init() {
+	secretstores.Add("encrypted_file", func() telegraf.SecretStore {
+		return &EncryptedFile{}
+	})
+}
@@ -0,0 +1,62 @@
+package encrypted_file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secrets.enc")
+	e := &EncryptedFile{Path: path, Password: internal.NewSecret("password")}
+	require.NoError(t, e.Init())
+
+	keys, err := e.List()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	require.NoError(t, e.Set("token", []byte("abc")))
+	require.NoError(t, e.Set("password", []byte("secret")))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+
+	// A new instance has to derive the key again.
+	e = &EncryptedFile{Path: path, Password: internal.NewSecret("password")}
+	value, err := e.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(value))
+
+	keys, err = e.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"password", "token"}, keys)
+
+	_, err = e.Get("missing")
+	require.Error(t, err)
+
+	e = &EncryptedFile{Path: path, Password: internal.NewSecret("wrong")}
+	_, err = e.Get("password")
+	require.Error(t, err)
+}
+
+func TestEncryptedFile_NotEncrypted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secrets.enc")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"password":"secret"}`), 0600))
+
+	e := &EncryptedFile{Path: path, Password: internal.NewSecret("password")}
+	_, err = e.Get("password")
+	require.Error(t, err)
+}
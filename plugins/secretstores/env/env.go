@@ -0,0 +1,64 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// Env is a read-only secret store backed by environment variables.
+type Env struct {
+	Prefix string `toml:"prefix"`
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, referenced as @{<id>:<key>}.
+  id = "env"
+
+  ## Prefix of the environment variables, the key of a secret is the name of
+  ## the variable without the prefix.
+  # prefix = "TELEGRAF_SECRET_"
+`
+
+func (e *Env) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Env) Description() string {
+	return "Read secrets from environment variables"
+}
+
+func (e *Env) Get(key string) ([]byte, error) {
+	value, ok := os.LookupEnv(e.Prefix + key)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %q not set", e.Prefix+key)
+	}
+	return []byte(value), nil
+}
+
+func (e *Env) List() ([]string, error) {
+	var keys []string
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if strings.HasPrefix(name, e.Prefix) && len(name) > len(e.Prefix) {
+			keys = append(keys, strings.TrimPrefix(name, e.Prefix))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (e *Env) Set(key string, value []byte) error {
+	return errors.New("environment secret store is read-only")
+}
+
+func init() {
+	secretstores.Add("env", func() telegraf.SecretStore {
+		return &Env{}
+	})
+}
@@ -0,0 +1,28 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnv(t *testing.T) {
+	os.Setenv("TELEGRAF_TEST_SECRET_PASSWORD", "secret")
+	defer os.Unsetenv("TELEGRAF_TEST_SECRET_PASSWORD")
+
+	e := &Env{Prefix: "TELEGRAF_TEST_SECRET_"}
+
+	value, err := e.Get("PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(value))
+
+	_, err = e.Get("MISSING")
+	require.Error(t, err)
+
+	keys, err := e.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"PASSWORD"}, keys)
+
+	require.Error(t, e.Set("PASSWORD", []byte("new")))
+}
@@ -0,0 +1,85 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// Exec is a read-only secret store running a command to get each secret.
+type Exec struct {
+	Command []string          `toml:"command"`
+	Timeout internal.Duration `toml:"timeout"`
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, referenced as @{<id>:<key>}.
+  id = "vault"
+
+  ## Command to run for every secret, the key is passed as last argument.
+  ## The secret is read from stdout, trailing newlines are removed.
+  command = ["/usr/local/bin/get-secret", "--field", "value"]
+
+  ## Timeout for the command to complete.
+  # timeout = "5s"
+`
+
+func (e *Exec) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Exec) Description() string {
+	return "Run a command to read secrets"
+}
+
+func (e *Exec) Init() error {
+	if len(e.Command) == 0 {
+		return errors.New("command is required")
+	}
+	return nil
+}
+
+func (e *Exec) Get(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.Timeout.Duration)
+	defer cancel()
+
+	args := append(append([]string{}, e.Command[1:]...), key)
+	cmd := exec.CommandContext(ctx, e.Command[0], args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Only the first line of stderr, the command output is not trusted
+		// to be free of secrets.
+		msg := bytes.SplitN(bytes.TrimSpace(stderr.Bytes()), []byte("\n"), 2)[0]
+		if len(msg) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return bytes.TrimRight(stdout.Bytes(), "\r\n"), nil
+}
+
+func (e *Exec) List() ([]string, error) {
+	return nil, errors.New("listing secrets is not supported by the exec secret store")
+}
+
+func (e *Exec) Set(key string, value []byte) error {
+	return errors.New("exec secret store is read-only")
+}
+
+func init() {
+	secretstores.Add("exec", func() telegraf.SecretStore {
+		return &Exec{
+			Timeout: internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,47 @@
+// +build !windows
+
+package exec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExec(t *testing.T) {
+	e := &Exec{
+		Command: []string{"echo", "secret-for"},
+		Timeout: internal.Duration{Duration: 5 * time.Second},
+	}
+	require.NoError(t, e.Init())
+
+	value, err := e.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "secret-for password", string(value))
+
+	require.Error(t, e.Set("password", []byte("new")))
+	_, err = e.List()
+	require.Error(t, err)
+}
+
+func TestExec_Error(t *testing.T) {
+	e := &Exec{
+		Command: []string{"sh", "-c", "echo not found >&2; exit 1", "sh"},
+		Timeout: internal.Duration{Duration: 5 * time.Second},
+	}
+
+	_, err := e.Get("password")
+	require.EqualError(t, err, "exit status 1: not found")
+}
+
+func TestExec_Timeout(t *testing.T) {
+	e := &Exec{
+		Command: []string{"sleep", "10"},
+		Timeout: internal.Duration{Duration: 10 * time.Millisecond},
+	}
+
+	_, err := e.Get("password")
+	require.Error(t, err)
+}
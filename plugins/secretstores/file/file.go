@@ -0,0 +1,119 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+// File is a secret store keeping every secret in a file of its own.
+type File struct {
+	Directory string `toml:"directory"`
+}
+
+var sampleConfig = `
+  ## Unique identifier of the store, referenced as @{<id>:<key>}.
+  id = "files"
+
+  ## Directory containing one file per secret, the file name is the key.
+  ## Trailing newlines are removed from the secrets.
+  directory = "/etc/telegraf/secrets"
+`
+
+func (f *File) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *File) Description() string {
+	return "Read secrets from the files in a directory"
+}
+
+func (f *File) Init() error {
+	if f.Directory == "" {
+		return errors.New("directory is required")
+	}
+	return nil
+}
+
+func (f *File) Get(key string) ([]byte, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("secret %q not found", key)
+		}
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+func (f *File) List() ([]string, error) {
+	files, err := ioutil.ReadDir(f.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(files))
+	for _, file := range files {
+		if !file.Mode().IsRegular() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		keys = append(keys, file.Name())
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (f *File) Set(key string, value []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Directory, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so readers never see a partial secret.
+	tmp, err := ioutil.TempFile(f.Directory, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *File) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(f.Directory, key), nil
+}
+
+func init() {
+	secretstores.Add("file", func() telegraf.SecretStore {
+		return &File{}
+	})
+}
@@ -0,0 +1,50 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("secret\n"), 0600))
+
+	f := &File{Directory: dir}
+	require.NoError(t, f.Init())
+
+	value, err := f.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(value))
+
+	_, err = f.Get("missing")
+	require.Error(t, err)
+
+	require.NoError(t, f.Set("token", []byte("abc")))
+	value, err = f.Get("token")
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(value))
+
+	info, err := os.Stat(filepath.Join(dir, "token"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	keys, err := f.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{"password", "token"}, keys)
+}
+
+func TestFile_InvalidKey(t *testing.T) {
+	f := &File{Directory: "testdata"}
+	for _, key := range []string{"", ".", "..", "../passwd", `a\b`} {
+		_, err := f.Get(key)
+		require.Error(t, err, key)
+		require.Error(t, f.Set(key, nil), key)
+	}
+}
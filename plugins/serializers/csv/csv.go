@@ -0,0 +1,226 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	timestampColumn   = "timestamp"
+	measurementColumn = "measurement"
+	tagPrefix         = "tag."
+	fieldPrefix       = "field."
+)
+
+type Config struct {
+	// Columns is the layout of the rows, a list of "timestamp",
+	// "measurement", "tag.<key>" and "field.<key>".  When empty each row
+	// holds the timestamp, the measurement, the tags and then the fields of
+	// the metric, sorted by key.
+	Columns []string
+
+	// Delimiter separates the columns, by default a comma.
+	Delimiter string
+
+	// Header adds a row of column names at the start of each batch.  It
+	// requires Columns, so that all rows have the same layout, with unique
+	// names.
+	Header bool
+
+	// TimestampFormat is one of "unix", "unix_ms", "unix_us", "unix_ns", or
+	// a Go reference time layout.  By default timestamps are in seconds.
+	TimestampFormat string
+}
+
+type column struct {
+	kind string
+	key  string
+}
+
+type Serializer struct {
+	columns         []column
+	delimiter       rune
+	header          bool
+	timestampFormat string
+}
+
+func NewSerializer(config *Config) (*Serializer, error) {
+	s := &Serializer{
+		delimiter:       ',',
+		header:          config.Header,
+		timestampFormat: config.TimestampFormat,
+	}
+
+	if config.Delimiter != "" {
+		runes := []rune(config.Delimiter)
+		if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+			return nil, fmt.Errorf("invalid csv_delimiter: %q", config.Delimiter)
+		}
+		s.delimiter = runes[0]
+	}
+
+	switch s.timestampFormat {
+	case "":
+		s.timestampFormat = "unix"
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+	default:
+		if strings.Contains(s.timestampFormat, "unix") {
+			return nil, fmt.Errorf("invalid csv_timestamp_format: %s", s.timestampFormat)
+		}
+	}
+
+	for _, c := range config.Columns {
+		switch {
+		case c == timestampColumn || c == measurementColumn:
+			s.columns = append(s.columns, column{kind: c, key: c})
+		case strings.HasPrefix(c, tagPrefix) && len(c) > len(tagPrefix):
+			s.columns = append(s.columns, column{kind: tagPrefix, key: c[len(tagPrefix):]})
+		case strings.HasPrefix(c, fieldPrefix) && len(c) > len(fieldPrefix):
+			s.columns = append(s.columns, column{kind: fieldPrefix, key: c[len(fieldPrefix):]})
+		default:
+			return nil, fmt.Errorf("invalid csv column: %q", c)
+		}
+	}
+
+	if s.header {
+		if len(s.columns) == 0 {
+			return nil, fmt.Errorf("csv_header requires csv_columns")
+		}
+
+		// The header holds the keys without prefix, so that the csv parser
+		// can use them as tag and field names.
+		names := make(map[string]bool, len(s.columns))
+		for _, c := range s.columns {
+			if names[c.key] {
+				return nil, fmt.Errorf("duplicate csv column name %q in header", c.key)
+			}
+			names[c.key] = true
+		}
+	}
+
+	return s, nil
+}
+
+// Serialize writes the row of the metric, without header.
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.serialize([]telegraf.Metric{metric}, false)
+}
+
+// SerializeBatch writes the rows of the metrics, preceded by the header.
+// Each batch is a complete document, such as the body of a request.
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	return s.serialize(metrics, s.header)
+}
+
+func (s *Serializer) serialize(metrics []telegraf.Metric, header bool) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = s.delimiter
+
+	var record []string
+	if header {
+		for _, c := range s.columns {
+			record = append(record, c.key)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range metrics {
+		columns := s.columns
+		if len(columns) == 0 {
+			columns = metricColumns(m)
+		}
+
+		record = record[:0]
+		for _, c := range columns {
+			record = append(record, s.value(m, c))
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// metricColumns returns the default layout of the row of a metric.
+func metricColumns(m telegraf.Metric) []column {
+	columns := make([]column, 0, 2+len(m.TagList())+len(m.FieldList()))
+	columns = append(columns,
+		column{kind: timestampColumn, key: timestampColumn},
+		column{kind: measurementColumn, key: measurementColumn})
+	for _, tag := range m.TagList() {
+		columns = append(columns, column{kind: tagPrefix, key: tag.Key})
+	}
+
+	n := len(columns)
+	for _, field := range m.FieldList() {
+		columns = append(columns, column{kind: fieldPrefix, key: field.Key})
+	}
+	fields := columns[n:]
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	return columns
+}
+
+func (s *Serializer) value(m telegraf.Metric, c column) string {
+	switch c.kind {
+	case timestampColumn:
+		return s.formatTimestamp(m.Time())
+	case measurementColumn:
+		return m.Name()
+	case tagPrefix:
+		v, _ := m.GetTag(c.key)
+		return v
+	default:
+		v, ok := m.GetField(c.key)
+		if !ok {
+			return ""
+		}
+		return formatValue(v)
+	}
+}
+
+func (s *Serializer) formatTimestamp(t time.Time) string {
+	switch s.timestampFormat {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	case "unix_us":
+		return strconv.FormatInt(t.UnixNano()/int64(time.Microsecond), 10)
+	case "unix_ns":
+		return strconv.FormatInt(t.UnixNano(), 10)
+	default:
+		return t.UTC().Format(s.timestampFormat)
+	}
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
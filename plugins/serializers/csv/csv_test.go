@@ -0,0 +1,182 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_user": 42.5, "usage_idle": 57.5},
+			time.Unix(1600000000, 0)),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_user": 10.0, "usage_idle": 90.0},
+			time.Unix(1600000010, 0)),
+	}
+}
+
+func TestSerializeDefaultLayout(t *testing.T) {
+	s, err := NewSerializer(&Config{})
+	require.NoError(t, err)
+
+	buf, err := s.SerializeBatch(testMetrics())
+	require.NoError(t, err)
+	require.Equal(t,
+		"1600000000,cpu,cpu0,a,57.5,42.5\n"+
+			"1600000010,cpu,cpu0,b,90,10\n",
+		string(buf))
+}
+
+func TestSerializeHeader(t *testing.T) {
+	s, err := NewSerializer(&Config{
+		Columns: []string{"timestamp", "tag.host", "field.usage_user"},
+		Header:  true,
+	})
+	require.NoError(t, err)
+
+	// Metrics serialized on their own have no header
+	buf, err := s.Serialize(testMetrics()[0])
+	require.NoError(t, err)
+	require.Equal(t, "1600000000,a,42.5\n", string(buf))
+	buf, err = s.Serialize(testMetrics()[1])
+	require.NoError(t, err)
+	require.Equal(t, "1600000010,b,10\n", string(buf))
+
+	// Each batch starts with the header
+	for i := 0; i < 2; i++ {
+		buf, err = s.SerializeBatch(testMetrics())
+		require.NoError(t, err)
+		require.Equal(t,
+			"timestamp,host,usage_user\n"+
+				"1600000000,a,42.5\n"+
+				"1600000010,b,10\n",
+			string(buf))
+	}
+
+	_, err = NewSerializer(&Config{Header: true})
+	require.Error(t, err)
+
+	_, err = NewSerializer(&Config{
+		Columns: []string{"tag.host", "field.host"},
+		Header:  true,
+	})
+	require.Error(t, err)
+
+	_, err = NewSerializer(&Config{Columns: []string{"tag.host", "field.host"}})
+	require.NoError(t, err)
+}
+
+func TestSerializeColumns(t *testing.T) {
+	s, err := NewSerializer(&Config{
+		Columns:         []string{"timestamp", "tag.host", "field.usage_user", "field.missing"},
+		Delimiter:       ";",
+		Header:          true,
+		TimestampFormat: time.RFC3339,
+	})
+	require.NoError(t, err)
+
+	m := testutil.MustMetric("event",
+		map[string]string{"host": "a"},
+		map[string]interface{}{
+			"usage_user": int64(3),
+			"message":    "a; b",
+		},
+		time.Unix(1600000000, 0))
+
+	buf, err := s.SerializeBatch([]telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Equal(t,
+		"timestamp;host;usage_user;missing\n"+
+			"2020-09-13T12:26:40Z;a;3;\n",
+		string(buf))
+}
+
+func TestSerializeQuoting(t *testing.T) {
+	s, err := NewSerializer(&Config{Columns: []string{"measurement", "field.message"}})
+	require.NoError(t, err)
+
+	m := testutil.MustMetric("event",
+		map[string]string{},
+		map[string]interface{}{"message": `say "hi", bye`},
+		time.Unix(0, 0))
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "event,\"say \"\"hi\"\", bye\"\n", string(buf))
+}
+
+func TestSerializeTimestampFormats(t *testing.T) {
+	m := testutil.MustMetric("cpu",
+		map[string]string{},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1600000000, 123456789))
+
+	tests := map[string]string{
+		"unix":                    "1600000000",
+		"unix_ms":                 "1600000000123",
+		"unix_us":                 "1600000000123456",
+		"unix_ns":                 "1600000000123456789",
+		"2006-01-02 15:04:05.000": "2020-09-13 12:26:40.123",
+	}
+	for format, expected := range tests {
+		t.Run(format, func(t *testing.T) {
+			s, err := NewSerializer(&Config{
+				Columns:         []string{"timestamp"},
+				TimestampFormat: format,
+			})
+			require.NoError(t, err)
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, expected+"\n", string(buf))
+		})
+	}
+}
+
+func TestNewSerializerErrors(t *testing.T) {
+	_, err := NewSerializer(&Config{Delimiter: ";;"})
+	require.Error(t, err)
+
+	_, err = NewSerializer(&Config{TimestampFormat: "unix_s"})
+	require.Error(t, err)
+
+	_, err = NewSerializer(&Config{Columns: []string{"tag."}})
+	require.Error(t, err)
+
+	_, err = NewSerializer(&Config{Columns: []string{"host"}})
+	require.Error(t, err)
+}
+
+func TestRoundTrip(t *testing.T) {
+	s, err := NewSerializer(&Config{
+		Columns: []string{"timestamp", "measurement", "tag.cpu", "tag.host",
+			"field.usage_idle", "field.usage_user"},
+		Header:          true,
+		TimestampFormat: "unix_ms",
+	})
+	require.NoError(t, err)
+
+	buf, err := s.SerializeBatch(testMetrics())
+	require.NoError(t, err)
+
+	parser, err := csv.NewParser(&csv.Config{
+		HeaderRowCount:    1,
+		MeasurementColumn: "measurement",
+		TagColumns:        []string{"cpu", "host"},
+		TimestampColumn:   "timestamp",
+		TimestampFormat:   "unix_ms",
+		ColumnTypes:       []string{"int", "string", "string", "string", "float", "float"},
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, testMetrics(), metrics)
+}
@@ -3,6 +3,7 @@ package json
 import (
 	"encoding/json"
 	"math"
+	"text/template"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -10,6 +11,9 @@ import (
 
 type serializer struct {
 	TimestampUnits time.Duration
+
+	metricTemplate *template.Template
+	batchTemplate  *template.Template
 }
 
 func NewSerializer(timestampUnits time.Duration) (*serializer, error) {
@@ -20,6 +24,14 @@ func NewSerializer(timestampUnits time.Duration) (*serializer, error) {
 }
 
 func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	if s.metricTemplate != nil {
+		values, err := execute(s.metricTemplate, s.templateMetric(metric))
+		if err != nil {
+			return []byte{}, err
+		}
+		return writeLines(values), nil
+	}
+
 	m := s.createObject(metric)
 	serialized, err := json.Marshal(m)
 	if err != nil {
@@ -31,8 +43,31 @@ func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 }
 
 func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if s.batchTemplate != nil {
+		batch := templateBatch{Metrics: make([]*templateMetric, 0, len(metrics))}
+		for _, metric := range metrics {
+			batch.Metrics = append(batch.Metrics, s.templateMetric(metric))
+		}
+		values, err := execute(s.batchTemplate, &batch)
+		if err != nil {
+			return []byte{}, err
+		}
+		return writeLines(values), nil
+	}
+
 	objects := make([]interface{}, 0, len(metrics))
 	for _, metric := range metrics {
+		if s.metricTemplate != nil {
+			values, err := execute(s.metricTemplate, s.templateMetric(metric))
+			if err != nil {
+				return []byte{}, err
+			}
+			for _, v := range values {
+				objects = append(objects, v)
+			}
+			continue
+		}
+
 		m := s.createObject(metric)
 		objects = append(objects, m)
 	}
@@ -75,6 +110,10 @@ func (s *serializer) createObject(metric telegraf.Metric) map[string]interface{}
 	return m
 }
 
+func (s *serializer) templateMetric(metric telegraf.Metric) *templateMetric {
+	return &templateMetric{metric: metric, units: s.TimestampUnits}
+}
+
 func truncateDuration(units time.Duration) time.Duration {
 	// Default precision is 1s
 	if units <= 0 {
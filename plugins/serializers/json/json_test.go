@@ -233,3 +233,113 @@ func TestSerializeBatchSkipInfAllFields(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte(`{"metrics":[{"fields":{},"name":"cpu","tags":{},"timestamp":0}]}`), buf)
 }
+
+func TestSerializeTemplatePerField(t *testing.T) {
+	m := testutil.MustMetric("cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"usage_user": 8.5,
+			"broken":     math.NaN(),
+		},
+		time.Unix(1600000000, 0))
+
+	s, err := NewSerializer(time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, s.SetTemplates(`
+{{- range .FieldList }}
+{
+  "metric": {{ json (printf "%s.%s" $.Name .Key) }},
+  "value": {{ json .Value }},
+  "dimensions": {{ json $.Tags }},
+  "timestamp": {{ $.Timestamp }}
+}
+{{- end }}`, ""))
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"metric":"cpu.usage_idle","value":91.5,"dimensions":{"cpu":"cpu0","host":"a"},"timestamp":1600000000000}`+"\n"+
+			`{"metric":"cpu.usage_user","value":8.5,"dimensions":{"cpu":"cpu0","host":"a"},"timestamp":1600000000000}`+"\n",
+		string(buf))
+
+	// Without a batch template the values are the metrics of the batch
+	buf, err = s.SerializeBatch([]telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"metrics":[`+
+			`{"metric":"cpu.usage_idle","value":91.5,"dimensions":{"cpu":"cpu0","host":"a"},"timestamp":1600000000000},`+
+			`{"metric":"cpu.usage_user","value":8.5,"dimensions":{"cpu":"cpu0","host":"a"},"timestamp":1600000000000}`+
+			`]}`,
+		string(buf))
+}
+
+func TestSerializeBatchTemplate(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 91.5},
+			time.Unix(1600000000, 0)),
+		testutil.MustMetric("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(1024)},
+			time.Unix(1600000010, 0)),
+	}
+
+	s, err := NewSerializer(0)
+	require.NoError(t, err)
+	require.NoError(t, s.SetTemplates("", `
+{"count": {{ len .Metrics }}, "series": [
+{{- range $i, $m := .Metrics }}{{ if $i }},{{ end }}
+  {"name": {{ json .Name }}, "host": {{ json (.Tag "host") }}, "time": {{ json .Time }}, "fields": {{ json .Fields }}}
+{{- end }}
+]}`))
+
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"count":2,"series":[`+
+			`{"name":"cpu","host":"a","time":"`+time.Unix(1600000000, 0).Format(time.RFC3339Nano)+`","fields":{"usage_idle":91.5}},`+
+			`{"name":"mem","host":"a","time":"`+time.Unix(1600000010, 0).Format(time.RFC3339Nano)+`","fields":{"used":1024}}`+
+			`]}`+"\n",
+		string(buf))
+
+	// The metric template is the default layout
+	buf, err = s.Serialize(metrics[0])
+	require.NoError(t, err)
+	require.Equal(t, `{"fields":{"usage_idle":91.5},"name":"cpu","tags":{"host":"a"},"timestamp":1600000000}`+"\n", string(buf))
+}
+
+func TestSerializeTemplateNest(t *testing.T) {
+	m := testutil.MustMetric("pod",
+		map[string]string{"k8s.pod": "web", "k8s.namespace": "prod", "k8s": "conflict", "host": "a"},
+		map[string]interface{}{"restarts": int64(2)},
+		time.Unix(1600000000, 0))
+
+	s, err := NewSerializer(0)
+	require.NoError(t, err)
+	require.NoError(t, s.SetTemplates(`{"tags": {{ json (nest "." .Tags) }}, "restarts": {{ .Field "restarts" }}}`, ""))
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, `{"tags":{"host":"a","k8s":"conflict","k8s.namespace":"prod","k8s.pod":"web"},"restarts":2}`+"\n", string(buf))
+
+	nested, err := nest(".", map[string]interface{}{"a.b.c": 1, "a.b.d": 2, "e": 3})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": 1, "d": 2}},
+		"e": 3,
+	}, nested)
+}
+
+func TestSerializeTemplateErrors(t *testing.T) {
+	s, err := NewSerializer(0)
+	require.NoError(t, err)
+	require.Error(t, s.SetTemplates(`{{ .Name `, ""))
+	require.Error(t, s.SetTemplates("", `{{ range }}`))
+
+	require.NoError(t, s.SetTemplates(`{"name": {{ .Name }}}`, ""))
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	_, err = s.Serialize(m)
+	require.Error(t, err)
+}
@@ -0,0 +1,216 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// templateMetric is the data of the metric template, and of each metric of
+// the batch template.
+type templateMetric struct {
+	metric telegraf.Metric
+	units  time.Duration
+}
+
+// templateField is a field of a metric.
+type templateField struct {
+	Key   string
+	Value interface{}
+}
+
+// templateBatch is the data of the batch template.
+type templateBatch struct {
+	Metrics []*templateMetric
+}
+
+func (m *templateMetric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *templateMetric) Tag(key string) string {
+	v, _ := m.metric.GetTag(key)
+	return v
+}
+
+func (m *templateMetric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+func (m *templateMetric) Field(key string) interface{} {
+	v, _ := m.metric.GetField(key)
+	return v
+}
+
+// Fields returns the fields of the metric that can be represented in JSON.
+func (m *templateMetric) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(m.metric.FieldList()))
+	for _, field := range m.FieldList() {
+		fields[field.Key] = field.Value
+	}
+	return fields
+}
+
+// FieldList returns the fields of the metric that can be represented in JSON,
+// sorted by key.
+func (m *templateMetric) FieldList() []templateField {
+	fields := make([]templateField, 0, len(m.metric.FieldList()))
+	for _, field := range m.metric.FieldList() {
+		if fv, ok := field.Value.(float64); ok && (math.IsNaN(fv) || math.IsInf(fv, 0)) {
+			continue
+		}
+		fields = append(fields, templateField{Key: field.Key, Value: field.Value})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+func (m *templateMetric) Time() time.Time {
+	return m.metric.Time()
+}
+
+// Timestamp returns the time of the metric in the timestamp units.
+func (m *templateMetric) Timestamp() int64 {
+	return m.metric.Time().UnixNano() / int64(m.units)
+}
+
+var templateFuncs = template.FuncMap{
+	"json": toJSON,
+	"nest": nest,
+}
+
+// toJSON returns the JSON encoding of a value, to write strings and maps
+// with the correct escaping.
+func toJSON(v interface{}) (string, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// nest turns the keys of a map containing the separator into nested maps, so
+// that {"k8s.pod": "web"} becomes {"k8s": {"pod": "web"}}.  Keys conflicting
+// with a nested map are kept as is.
+func nest(sep string, m interface{}) (map[string]interface{}, error) {
+	flat := make(map[string]interface{})
+	switch m := m.(type) {
+	case map[string]string:
+		for k, v := range m {
+			flat[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range m {
+			flat[k] = v
+		}
+	default:
+		return nil, fmt.Errorf("nest: unsupported type %T", m)
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	nested := make(map[string]interface{}, len(flat))
+	conflicts := make(map[string]interface{})
+outer:
+	for _, k := range keys {
+		parts := strings.Split(k, sep)
+		if sep == "" {
+			parts = []string{k}
+		}
+
+		node := nested
+		for _, part := range parts[:len(parts)-1] {
+			switch child := node[part].(type) {
+			case nil:
+				next := make(map[string]interface{})
+				node[part] = next
+				node = next
+			case map[string]interface{}:
+				node = child
+			default:
+				conflicts[k] = flat[k]
+				continue outer
+			}
+		}
+
+		leaf := parts[len(parts)-1]
+		if _, ok := node[leaf]; ok {
+			conflicts[k] = flat[k]
+			continue
+		}
+		node[leaf] = flat[k]
+	}
+
+	for k, v := range conflicts {
+		nested[k] = v
+	}
+	return nested, nil
+}
+
+// SetTemplates sets the Go templates rendering metrics and batches of
+// metrics.  Their output is a sequence of JSON values, written one per line.
+func (s *serializer) SetTemplates(metricTemplate, batchTemplate string) error {
+	var err error
+	if metricTemplate != "" {
+		s.metricTemplate, err = template.New("json_template").Funcs(templateFuncs).Parse(metricTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing json_template: %v", err)
+		}
+	}
+	if batchTemplate != "" {
+		s.batchTemplate, err = template.New("json_batch_template").Funcs(templateFuncs).Parse(batchTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing json_batch_template: %v", err)
+		}
+	}
+	return nil
+}
+
+// execute renders a template and returns the JSON values of its output.
+func execute(tmpl *template.Template, data interface{}) ([]json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	var values []json.RawMessage
+	dec := json.NewDecoder(&buf)
+	for {
+		var v json.RawMessage
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("output of %s is not valid JSON: %v", tmpl.Name(), err)
+		}
+
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, v); err != nil {
+			return nil, err
+		}
+		values = append(values, compact.Bytes())
+	}
+}
+
+// writeLines writes the values one per line.
+func writeLines(values []json.RawMessage) []byte {
+	var buf bytes.Buffer
+	for _, v := range values {
+		buf.Write(v)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
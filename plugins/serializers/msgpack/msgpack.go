@@ -0,0 +1,40 @@
+package msgpack
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/msgpack"
+)
+
+// Serializer encodes metrics as MessagePack maps.  The encoding of a batch is
+// the concatenation of the encodings of its metrics.
+type Serializer struct{}
+
+func NewSerializer() *Serializer {
+	return &Serializer{}
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return appendMetric(nil, metric)
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf []byte
+	for _, metric := range metrics {
+		var err error
+		buf, err = appendMetric(buf, metric)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func appendMetric(buf []byte, metric telegraf.Metric) ([]byte, error) {
+	m := msgpack.Metric{
+		Name:   metric.Name(),
+		Time:   metric.Time(),
+		Tags:   metric.Tags(),
+		Fields: metric.Fields(),
+	}
+	return m.MarshalMsg(buf)
+}
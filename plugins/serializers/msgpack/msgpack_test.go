@@ -0,0 +1,59 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/msgpack"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	m := testutil.MustMetric("cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 91.5},
+		time.Unix(1600000000, 0))
+
+	s := NewSerializer()
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	var decoded msgpack.Metric
+	rest, err := decoded.UnmarshalMsg(buf)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	require.Equal(t, msgpack.Metric{
+		Name:   "cpu",
+		Time:   time.Unix(1600000000, 0),
+		Tags:   map[string]string{"host": "a"},
+		Fields: map[string]interface{}{"usage_idle": 91.5},
+	}, decoded)
+}
+
+func TestSerializeBatch(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 91.5},
+			time.Unix(1600000000, 0)),
+		testutil.MustMetric("mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": uint64(1024)},
+			time.Unix(1600000000, 0)),
+	}
+
+	s := NewSerializer()
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	// A batch is the concatenation of its metrics
+	var expected []byte
+	for _, m := range metrics {
+		b, err := s.Serialize(m)
+		require.NoError(t, err)
+		expected = append(expected, b...)
+	}
+	require.Equal(t, expected, buf)
+}
@@ -0,0 +1,164 @@
+package prometheusremotewrite
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/prompb"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+// Serializer serializes metrics into snappy compressed remote write
+// requests.  Metric names and labels follow the metric_version 2 conventions
+// of the prometheus serializer.
+type Serializer struct {
+	config prometheus.FormatConfig
+}
+
+func NewSerializer(config prometheus.FormatConfig) (*Serializer, error) {
+	s := &Serializer{config: config}
+	return s, nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	entries := make(map[prometheus.MetricKey]*prompb.TimeSeries)
+	for _, metric := range metrics {
+		labels := s.createLabels(metric)
+		for _, field := range metric.FieldList() {
+			metricName, ok := prometheus.SanitizeMetricName(seriesName(metric, field.Key))
+			if !ok {
+				continue
+			}
+
+			var value float64
+			switch metric.Type() {
+			case telegraf.Histogram, telegraf.Summary:
+				switch {
+				case strings.HasSuffix(field.Key, "_count"), strings.HasSuffix(field.Key, "_bucket"):
+					count, ok := prometheus.SampleCount(field.Value)
+					if !ok {
+						continue
+					}
+					value = float64(count)
+				default:
+					value, ok = prometheus.SampleSum(field.Value)
+					if !ok {
+						continue
+					}
+				}
+			default:
+				value, ok = prometheus.SampleValue(field.Value)
+				if !ok {
+					continue
+				}
+			}
+
+			seriesLabels := make([]prometheus.LabelPair, 0, len(labels)+1)
+			seriesLabels = append(seriesLabels, prometheus.LabelPair{Name: prompb.MetricNameLabel, Value: metricName})
+			seriesLabels = append(seriesLabels, labels...)
+			sort.Slice(seriesLabels, func(i, j int) bool {
+				return seriesLabels[i].Name < seriesLabels[j].Name
+			})
+
+			key := prometheus.MakeMetricKey(seriesLabels)
+			ts, ok := entries[key]
+			if !ok {
+				ts = &prompb.TimeSeries{}
+				for _, label := range seriesLabels {
+					ts.Labels = append(ts.Labels, &prompb.Label{Name: label.Name, Value: label.Value})
+				}
+				entries[key] = ts
+			}
+			ts.Samples = append(ts.Samples, &prompb.Sample{
+				Value:     value,
+				Timestamp: metric.Time().UnixNano() / int64(time.Millisecond),
+			})
+		}
+	}
+
+	req := &prompb.WriteRequest{
+		Timeseries: make([]*prompb.TimeSeries, 0, len(entries)),
+	}
+	for _, ts := range entries {
+		// Samples of a series must be in order.
+		sort.SliceStable(ts.Samples, func(i, j int) bool {
+			return ts.Samples[i].Timestamp < ts.Samples[j].Timestamp
+		})
+		req.Timeseries = append(req.Timeseries, ts)
+	}
+
+	if s.config.MetricSortOrder == prometheus.SortMetrics {
+		sort.Slice(req.Timeseries, func(i, j int) bool {
+			lhs := req.Timeseries[i].Labels
+			rhs := req.Timeseries[j].Labels
+			for index := 0; index < len(lhs) && index < len(rhs); index++ {
+				if lhs[index].Name != rhs[index].Name {
+					return lhs[index].Name < rhs[index].Name
+				}
+				if lhs[index].Value != rhs[index].Value {
+					return lhs[index].Value < rhs[index].Value
+				}
+			}
+			return len(lhs) < len(rhs)
+		})
+	}
+
+	return prompb.Encode(req)
+}
+
+// seriesName returns the name of the series of the field.  The fields of
+// histograms and summaries keep their _bucket, _sum and _count suffixes, the
+// buckets and quantiles are told apart by their le and quantile tags.
+func seriesName(metric telegraf.Metric, fieldKey string) string {
+	if metric.Name() == "prometheus" {
+		return fieldKey
+	}
+	return metric.Name() + "_" + fieldKey
+}
+
+func (s *Serializer) createLabels(metric telegraf.Metric) []prometheus.LabelPair {
+	labels := make([]prometheus.LabelPair, 0, len(metric.TagList()))
+	names := map[string]bool{prompb.MetricNameLabel: true}
+	for _, tag := range metric.TagList() {
+		name, ok := prometheus.SanitizeLabelName(tag.Key)
+		if !ok || names[name] {
+			continue
+		}
+		names[name] = true
+
+		labels = append(labels, prometheus.LabelPair{Name: name, Value: tag.Value})
+	}
+
+	if s.config.StringHandling != prometheus.StringAsLabel {
+		return labels
+	}
+
+	for _, field := range metric.FieldList() {
+		value, ok := field.Value.(string)
+		if !ok {
+			continue
+		}
+
+		name, ok := prometheus.SanitizeLabelName(field.Key)
+		if !ok {
+			continue
+		}
+
+		// If there is a tag with the same name as the string field, discard
+		// the field and use the tag instead.
+		if names[name] {
+			continue
+		}
+		names[name] = true
+
+		labels = append(labels, prometheus.LabelPair{Name: name, Value: value})
+	}
+
+	return labels
+}
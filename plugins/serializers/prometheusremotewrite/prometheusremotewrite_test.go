@@ -0,0 +1,190 @@
+package prometheusremotewrite
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/prompb"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// text returns the series of the request, one sample per line.
+func text(t *testing.T, buf []byte) string {
+	req, err := prompb.Decode(buf)
+	require.NoError(t, err)
+
+	var b strings.Builder
+	for _, ts := range req.Timeseries {
+		var labels []string
+		for _, label := range ts.Labels {
+			labels = append(labels, fmt.Sprintf("%s=%q", label.Name, label.Value))
+		}
+		for _, sample := range ts.Samples {
+			fmt.Fprintf(&b, "{%s} %v %d\n", strings.Join(labels, ","), sample.Value, sample.Timestamp)
+		}
+	}
+	return b.String()
+}
+
+func TestSerializeBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   prometheus.FormatConfig
+		metrics  []telegraf.Metric
+		expected string
+	}{
+		{
+			name: "simple",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{"host": "example.org"},
+					map[string]interface{}{"time_idle": 42.0, "time_user": int64(3)},
+					time.Unix(1, 0),
+				),
+			},
+			expected: `{__name__="cpu_time_idle",host="example.org"} 42 1000
+{__name__="cpu_time_user",host="example.org"} 3 1000
+`,
+		},
+		{
+			name: "prometheus input",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"code": "400", "method": "post"},
+					map[string]interface{}{"http_requests_total": 3.0},
+					time.Unix(2, 0),
+					telegraf.Counter,
+				),
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"code": "400", "method": "post"},
+					map[string]interface{}{"http_requests_total": 1.0},
+					time.Unix(1, 0),
+					telegraf.Counter,
+				),
+			},
+			expected: `{__name__="http_requests_total",code="400",method="post"} 1 1000
+{__name__="http_requests_total",code="400",method="post"} 3 2000
+`,
+		},
+		{
+			name: "histogram",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{},
+					map[string]interface{}{
+						"http_request_duration_seconds_sum":   53423.0,
+						"http_request_duration_seconds_count": 144320.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"le": "0.05"},
+					map[string]interface{}{"http_request_duration_seconds_bucket": 24054.0},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"le": "+Inf"},
+					map[string]interface{}{"http_request_duration_seconds_bucket": 144320.0},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+			expected: `{__name__="http_request_duration_seconds_bucket",le="+Inf"} 144320 0
+{__name__="http_request_duration_seconds_bucket",le="0.05"} 24054 0
+{__name__="http_request_duration_seconds_count"} 144320 0
+{__name__="http_request_duration_seconds_sum"} 53423 0
+`,
+		},
+		{
+			name: "summary",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{},
+					map[string]interface{}{
+						"rpc_duration_seconds_sum":   1.7560473e+07,
+						"rpc_duration_seconds_count": 2693.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Summary,
+				),
+				testutil.MustMetric(
+					"prometheus",
+					map[string]string{"quantile": "0.5"},
+					map[string]interface{}{"rpc_duration_seconds": 4773.0},
+					time.Unix(0, 0),
+					telegraf.Summary,
+				),
+			},
+			expected: `{__name__="rpc_duration_seconds",quantile="0.5"} 4773 0
+{__name__="rpc_duration_seconds_count"} 2693 0
+{__name__="rpc_duration_seconds_sum"} 1.7560473e+07 0
+`,
+		},
+		{
+			name: "invalid names are sanitized",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu:usage",
+					map[string]string{"host-name": "example.org", "__name__": "ignored"},
+					map[string]interface{}{"time idle": 42.0},
+					time.Unix(0, 0),
+				),
+			},
+			expected: `{__name__="cpu:usage_time_idle",host_name="example.org"} 42 0
+`,
+		},
+		{
+			name: "strings are discarded",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{"time_idle": 42.0, "state": "idle"},
+					time.Unix(0, 0),
+				),
+			},
+			expected: `{__name__="cpu_time_idle"} 42 0
+`,
+		},
+		{
+			name: "strings as labels",
+			config: prometheus.FormatConfig{
+				StringHandling: prometheus.StringAsLabel,
+			},
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{"time_idle": 42.0, "state": "idle"},
+					time.Unix(0, 0),
+				),
+			},
+			expected: `{__name__="cpu_time_idle",state="idle"} 42 0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.MetricSortOrder = prometheus.SortMetrics
+			s, err := NewSerializer(tt.config)
+			require.NoError(t, err)
+			actual, err := s.SerializeBatch(tt.metrics)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, text(t, actual))
+		})
+	}
+}
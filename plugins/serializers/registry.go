@@ -6,11 +6,14 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
+	"github.com/influxdata/telegraf/plugins/serializers/csv"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/msgpack"
 	"github.com/influxdata/telegraf/plugins/serializers/nowmetric"
 	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheusremotewrite"
 	"github.com/influxdata/telegraf/plugins/serializers/splunkmetric"
 	"github.com/influxdata/telegraf/plugins/serializers/wavefront"
 )
@@ -80,6 +83,11 @@ type Config struct {
 	// Timestamp units to use for JSON formatted output
 	TimestampUnits time.Duration `toml:"timestamp_units"`
 
+	// Go templates rendering each metric and each batch of metrics of the
+	// JSON output
+	JSONTemplate      string `toml:"json_template"`
+	JSONBatchTemplate string `toml:"json_batch_template"`
+
 	// Include HEC routing fields for splunkmetric output
 	HecRouting bool `toml:"hec_routing"`
 
@@ -103,6 +111,19 @@ type Config struct {
 	// Output string fields as metric labels; when false string fields are
 	// discarded.
 	PrometheusStringAsLabel bool `toml:"prometheus_string_as_label"`
+
+	// Columns of the rows of the csv format, "timestamp", "measurement",
+	// "tag.<key>" or "field.<key>".
+	CSVColumns []string `toml:"csv_columns"`
+
+	// Character separating the columns of the csv format.
+	CSVDelimiter string `toml:"csv_delimiter"`
+
+	// Write a header row with the names of the columns of the csv format.
+	CSVHeader bool `toml:"csv_header"`
+
+	// Format of the timestamps of the csv format.
+	CSVTimestampFormat string `toml:"csv_timestamp_format"`
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -115,7 +136,7 @@ func NewSerializer(config *Config) (Serializer, error) {
 	case "graphite":
 		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template, config.GraphiteTagSupport, config.GraphiteSeparator, config.Templates)
 	case "json":
-		serializer, err = NewJsonSerializer(config.TimestampUnits)
+		serializer, err = NewJsonSerializerConfig(config)
 	case "splunkmetric":
 		serializer, err = NewSplunkmetricSerializer(config.HecRouting, config.SplunkmetricMultiMetric)
 	case "nowmetric":
@@ -126,6 +147,12 @@ func NewSerializer(config *Config) (Serializer, error) {
 		serializer, err = NewWavefrontSerializer(config.Prefix, config.WavefrontUseStrict, config.WavefrontSourceOverride)
 	case "prometheus":
 		serializer, err = NewPrometheusSerializer(config)
+	case "prometheusremotewrite":
+		serializer, err = NewPrometheusRemoteWriteSerializer(config)
+	case "csv":
+		serializer, err = NewCSVSerializer(config)
+	case "msgpack":
+		serializer, err = NewMsgpackSerializer()
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -155,6 +182,36 @@ func NewPrometheusSerializer(config *Config) (Serializer, error) {
 	})
 }
 
+func NewPrometheusRemoteWriteSerializer(config *Config) (Serializer, error) {
+	sortMetrics := prometheus.NoSortMetrics
+	if config.PrometheusSortMetrics {
+		sortMetrics = prometheus.SortMetrics
+	}
+
+	stringAsLabels := prometheus.DiscardStrings
+	if config.PrometheusStringAsLabel {
+		stringAsLabels = prometheus.StringAsLabel
+	}
+
+	return prometheusremotewrite.NewSerializer(prometheus.FormatConfig{
+		MetricSortOrder: sortMetrics,
+		StringHandling:  stringAsLabels,
+	})
+}
+
+func NewCSVSerializer(config *Config) (Serializer, error) {
+	return csv.NewSerializer(&csv.Config{
+		Columns:         config.CSVColumns,
+		Delimiter:       config.CSVDelimiter,
+		Header:          config.CSVHeader,
+		TimestampFormat: config.CSVTimestampFormat,
+	})
+}
+
+func NewMsgpackSerializer() (Serializer, error) {
+	return msgpack.NewSerializer(), nil
+}
+
 func NewWavefrontSerializer(prefix string, useStrict bool, sourceOverride []string) (Serializer, error) {
 	return wavefront.NewSerializer(prefix, useStrict, sourceOverride)
 }
@@ -163,6 +220,17 @@ func NewJsonSerializer(timestampUnits time.Duration) (Serializer, error) {
 	return json.NewSerializer(timestampUnits)
 }
 
+func NewJsonSerializerConfig(config *Config) (Serializer, error) {
+	s, err := json.NewSerializer(config.TimestampUnits)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SetTemplates(config.JSONTemplate, config.JSONBatchTemplate); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 func NewCarbon2Serializer(carbon2format string) (Serializer, error) {
 	return carbon2.NewSerializer(carbon2format)
 }
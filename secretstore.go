@@ -0,0 +1,15 @@
+package telegraf
+
+// SecretStore is a plugin providing the secrets referenced in the plugin
+// configurations as @{id:key}.
+type SecretStore interface {
+	PluginDescriber
+
+	// Get returns the value of the secret stored under key.
+	Get(key string) ([]byte, error)
+	// List returns the keys of all secrets in the store.
+	List() ([]string, error)
+	// Set stores the value under key, stores that are read-only return an
+	// error.
+	Set(key string, value []byte) error
+}